@@ -36,6 +36,16 @@ type Dash0MonitoringSpec struct {
 	// +kubebuilder:validation:Optional
 	Export *Export `json:"export"`
 
+	// The name of the Dash0 dataset to use for this namespace. This property is optional. If set, it overrides the
+	// dataset from the Dash0 export settings. Telemetry from workloads in this namespace is routed to this dataset by the
+	// OpenTelemetry collector, and dashboards and check rules from this namespace are synchronized to this dataset via the
+	// Dash0 API. When this setting is changed, the dashboards and check rules from this namespace are deleted from the
+	// previous dataset and created in the new dataset. If omitted, the dataset from the export settings (or the dataset
+	// "default") will be used.
+	//
+	// +kubebuilder:validation:Optional
+	Dataset string `json:"dataset,omitempty"`
+
 	// Global opt-out for workload instrumentation for the target namespace. There are three possible settings: `all`,
 	// `created-and-updated` and `none`. By default, the setting `all` is assumed.
 	//
@@ -70,6 +80,41 @@ type Dash0MonitoringSpec struct {
 	// +kubebuilder:default=all
 	InstrumentWorkloads InstrumentWorkloadsMode `json:"instrumentWorkloads,omitempty"`
 
+	// Determines which workloads in the target namespace are eligible for instrumentation. There are two possible
+	// settings: `opt-out` and `opt-in`.
+	//
+	// With `opt-out`, all workloads in the target namespace are instrumented, except for workloads that have the label
+	// dash0.com/enable=false.
+	//
+	// With `opt-in`, only workloads that have the label dash0.com/enable=true are instrumented, all other workloads in
+	// the target namespace are left alone. Workloads that have been instrumented before and do not have the label
+	// dash0.com/enable=true will be uninstrumented.
+	//
+	// The setting `instrumentWorkloads` still determines when eligible workloads are instrumented. This setting is
+	// optional, if it is omitted, the value `opt-out` is assumed.
+	//
+	// +kubebuilder:validation:Optional
+	InstrumentationPolicy InstrumentationPolicy `json:"instrumentationPolicy,omitempty"`
+
+	// Determines how the operator removes the instrumentation from workloads when this Dash0 monitoring resource is
+	// deleted or when instrumentWorkloads is changed to `none`. There are two possible settings: `immediate` and `lazy`.
+	//
+	// With `immediate`, the operator removes the instrumentation from all instrumented workloads right away, which restarts
+	// their pods.
+	//
+	// With `lazy`, the operator leaves the instrumentation in place and only adds the label
+	// dash0.com/uninstrumentation-pending=true to the workloads, which does not restart their pods. The instrumentation is
+	// removed the next time the pod template of a workload is changed (e.g. when a new version is deployed), since that
+	// rolls out new pods anyway. This avoids restarting all workloads at once when a namespace is no longer monitored.
+	//
+	// When the operator is uninstalled, the instrumentation is always removed immediately, regardless of this setting,
+	// since there would be nothing left that could remove it later.
+	//
+	// This setting is optional, if it is omitted, the value `immediate` is assumed.
+	//
+	// +kubebuilder:validation:Optional
+	UninstrumentationPolicy UninstrumentationPolicy `json:"uninstrumentationPolicy,omitempty"`
+
 	// If enabled, the operator will watch Perses dashboard resources in this namespace and create corresponding
 	// dashboards in Dash0 via the Dash0 API.
 	// See https://github.com/dash0hq/dash0-operator/blob/main/helm-chart/dash0-operator/README.md#managing-dash0-dashboards-with-the-operator
@@ -92,8 +137,105 @@ type Dash0MonitoringSpec struct {
 	//
 	// +kubebuilder:default=true
 	PrometheusScrapingEnabled *bool `json:"prometheusScrapingEnabled,omitempty"`
+
+	// The telemetry signals the operator collects for this namespace, a subset of `logs`, `metrics` and `traces`. This
+	// setting is optional, if it is omitted or empty, all signals are collected. Telemetry of a signal that is not listed
+	// is dropped by the OpenTelemetry collector. If no Dash0Monitoring resource in the cluster lists `logs`, the
+	// operator does not collect pod log files at all, that is, the collector daemonset does not mount the pod log
+	// directories of the nodes and does not run the filelog receiver.
+	//
+	// +kubebuilder:validation:Optional
+	Collect []TelemetrySignal `json:"collect,omitempty"`
+
+	// Conditions for dropping spans and log records from this namespace in the OpenTelemetry collector, for example to
+	// avoid ingesting the spans and access logs of health check requests. The conditions are OpenTelemetry
+	// Transformation Language (OTTL) conditions as used by the OpenTelemetry filter processor, e.g.
+	// `attributes["url.path"] == "/healthz"` or `IsMatch(name, "GET /(healthz|readyz)")`. A span or log record is
+	// dropped if it matches at least one condition. The conditions only apply to telemetry from workloads in the
+	// namespace of this Dash0Monitoring resource. This setting is optional, by default no telemetry is dropped.
+	//
+	// +kubebuilder:validation:Optional
+	Filter *Filter `json:"filter,omitempty"`
+
+	// Statements for modifying spans, metric data points and log records from this namespace in the OpenTelemetry
+	// collector before they are exported, for example to redact personally identifiable information or to rename
+	// attributes. The statements are OpenTelemetry Transformation Language (OTTL) statements as used by the
+	// OpenTelemetry transform processor, e.g. `replace_pattern(attributes["http.url"], "token=[^&]*", "token=***")`
+	// or `set(attributes["user.id"], attributes["uid"]) where attributes["uid"] != nil`. The statements only apply to
+	// telemetry from workloads in the namespace of this Dash0Monitoring resource. This setting is optional, by default
+	// telemetry is exported unchanged.
+	//
+	// +kubebuilder:validation:Optional
+	Transform *Transform `json:"transform,omitempty"`
+}
+
+// Filter contains the conditions for dropping telemetry in the OpenTelemetry collector. See
+// Dash0MonitoringSpec#Filter for more details.
+type Filter struct {
+	// Conditions for dropping spans. Within a condition, the span can be referenced directly (e.g. `name`,
+	// `attributes["url.path"]`), its resource via `resource`.
+	//
+	// +kubebuilder:validation:Optional
+	Traces *TraceFilter `json:"traces,omitempty"`
+
+	// Conditions for dropping log records. Within a condition, the log record can be referenced directly (e.g. `body`,
+	// `attributes["url.path"]`), its resource via `resource`.
+	//
+	// +kubebuilder:validation:Optional
+	Logs *LogFilter `json:"logs,omitempty"`
+}
+
+// TraceFilter contains the conditions for dropping spans.
+type TraceFilter struct {
+	// A list of OTTL conditions, a span is dropped if it matches at least one of them.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:MinLength=1
+	SpanFilter []string `json:"span,omitempty"`
+}
+
+// LogFilter contains the conditions for dropping log records.
+type LogFilter struct {
+	// A list of OTTL conditions, a log record is dropped if it matches at least one of them.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:MinLength=1
+	LogRecordFilter []string `json:"logRecord,omitempty"`
+}
+
+// Transform contains the statements for modifying telemetry in the OpenTelemetry collector. See
+// Dash0MonitoringSpec#Transform for more details.
+type Transform struct {
+	// OTTL statements for modifying spans, executed in the span context of the transform processor.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:MinLength=1
+	Traces []string `json:"traces,omitempty"`
+
+	// OTTL statements for modifying metrics, executed in the datapoint context of the transform processor.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:MinLength=1
+	Metrics []string `json:"metrics,omitempty"`
+
+	// OTTL statements for modifying log records, executed in the log context of the transform processor.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:MinLength=1
+	Logs []string `json:"logs,omitempty"`
 }
 
+// TelemetrySignal is one of the telemetry signals the operator can collect for a namespace.
+//
+// +kubebuilder:validation:Enum=logs;metrics;traces
+type TelemetrySignal string
+
+const (
+	TelemetrySignalLogs    TelemetrySignal = "logs"
+	TelemetrySignalMetrics TelemetrySignal = "metrics"
+	TelemetrySignalTraces  TelemetrySignal = "traces"
+)
+
 // InstrumentWorkloadsMode describes when exactly workloads will be instrumented.  Only one of the following modes
 // may be specified. If none of the following policies is specified, the default one is All. See
 // Dash0MonitoringSpec#InstrumentWorkloads for more details.
@@ -114,6 +256,35 @@ const (
 
 var allInstrumentWorkloadsMode = []InstrumentWorkloadsMode{All, CreatedAndUpdated, None}
 
+// InstrumentationPolicy describes whether workloads need to opt in or opt out of instrumentation. If no policy is
+// specified, the default one is OptOut. See Dash0MonitoringSpec#InstrumentationPolicy for more details.
+//
+// +kubebuilder:validation:Enum=opt-out;opt-in
+type InstrumentationPolicy string
+
+const (
+	// OptOut instruments all workloads, except for those with the label dash0.com/enable=false.
+	OptOut InstrumentationPolicy = "opt-out"
+
+	// OptIn only instruments workloads with the label dash0.com/enable=true.
+	OptIn InstrumentationPolicy = "opt-in"
+)
+
+// UninstrumentationPolicy describes how the instrumentation is removed from workloads. If no policy is specified, the
+// default one is Immediate. See Dash0MonitoringSpec#UninstrumentationPolicy for more details.
+//
+// +kubebuilder:validation:Enum=immediate;lazy
+type UninstrumentationPolicy string
+
+const (
+	// Immediate removes the instrumentation from workloads right away, which restarts their pods.
+	Immediate UninstrumentationPolicy = "immediate"
+
+	// Lazy marks workloads for uninstrumentation, the instrumentation is removed with the next change of their pod
+	// template.
+	Lazy UninstrumentationPolicy = "lazy"
+)
+
 // SynchronizationStatus describes the result of synchronizing a third-party Kubernetes resource (Perses
 // dashboard, Prometheus rule) to the Dash0 API.
 //
@@ -136,6 +307,8 @@ type PersesDashboardSynchronizationResults struct {
 	SynchronizedAt        metav1.Time           `json:"synchronizedAt"`
 	SynchronizationError  string                `json:"synchronizationError,omitempty"`
 	ValidationIssues      []string              `json:"validationIssues,omitempty"`
+	ContentHash           string                `json:"contentHash,omitempty"`
+	DashboardUrl          string                `json:"dashboardUrl,omitempty"`
 }
 
 type PrometheusRuleSynchronizationResult struct {
@@ -148,6 +321,7 @@ type PrometheusRuleSynchronizationResult struct {
 	SynchronizationErrors      map[string]string     `json:"synchronizationErrors,omitempty"`
 	InvalidRulesTotal          int                   `json:"invalidRulesTotal"`
 	InvalidRules               map[string][]string   `json:"invalidRules,omitempty"`
+	ContentHash                string                `json:"contentHash,omitempty"`
 }
 
 // Dash0MonitoringStatus defines the observed state of the Dash0Monitoring monitoring resource.
@@ -158,6 +332,10 @@ type Dash0MonitoringStatus struct {
 	// +kubebuilder:validation:Optional
 	PreviousInstrumentWorkloads InstrumentWorkloadsMode `json:"previousInstrumentWorkloads,omitempty"`
 
+	// The spec.dataset setting that has been observed in the previous reconcile cycle.
+	// +kubebuilder:validation:Optional
+	PreviousDataset string `json:"previousDataset,omitempty"`
+
 	// Shows results of synchronizing Perses dashboard resources in this namespace via the Dash0 API.
 	// +kubebuilder:validation:Optional
 	PersesDashboardSynchronizationResults map[string]PersesDashboardSynchronizationResults `json:"persesDashboardSynchronizationResults,omitempty"`
@@ -165,8 +343,24 @@ type Dash0MonitoringStatus struct {
 	// Shows results of synchronizing Prometheus rule resources in this namespace via the Dash0 API.
 	// +kubebuilder:validation:Optional
 	PrometheusRuleSynchronizationResults map[string]PrometheusRuleSynchronizationResult `json:"prometheusRuleSynchronizationResults,omitempty"`
+
+	// Lists the workloads in this namespace that still carry the Dash0 instrumentation although spec.instrumentWorkloads
+	// is set to none, e.g. because they wait for their next rollout with spec.uninstrumentationPolicy=lazy. Each entry
+	// has the form kind/name. The operator updates this list periodically. Only the first 50 workloads are listed, see
+	// staleInstrumentationWorkloadCount for the total number.
+	// +kubebuilder:validation:Optional
+	StaleInstrumentationWorkloads []string `json:"staleInstrumentationWorkloads,omitempty"`
+
+	// The number of workloads in this namespace that still carry the Dash0 instrumentation although
+	// spec.instrumentWorkloads is set to none.
+	// +kubebuilder:validation:Optional
+	StaleInstrumentationWorkloadCount int `json:"staleInstrumentationWorkloadCount,omitempty"`
 }
 
+// maxListedStaleInstrumentationWorkloads limits the number of workloads listed in
+// status.staleInstrumentationWorkloads, to keep the size of the resource bounded in namespaces with many workloads.
+const maxListedStaleInstrumentationWorkloads = 50
+
 // Dash0Monitoring is the schema for the Dash0Monitoring API
 //
 // +kubebuilder:object:root=true
@@ -192,6 +386,30 @@ func (d *Dash0Monitoring) ReadInstrumentWorkloadsSetting() InstrumentWorkloadsMo
 	return instrumentWorkloads
 }
 
+// ReadInstrumentationPolicy returns the instrumentation policy for the namespace of this Dash0Monitoring resource,
+// falling back to OptOut if the policy is not set or invalid.
+func (d *Dash0Monitoring) ReadInstrumentationPolicy() InstrumentationPolicy {
+	if d.Spec.InstrumentationPolicy == OptIn {
+		return OptIn
+	}
+	return OptOut
+}
+
+// ReadUninstrumentationPolicy returns the uninstrumentation policy for the namespace of this Dash0Monitoring resource,
+// falling back to Immediate if the policy is not set or invalid.
+func (d *Dash0Monitoring) ReadUninstrumentationPolicy() UninstrumentationPolicy {
+	if d.Spec.UninstrumentationPolicy == Lazy {
+		return Lazy
+	}
+	return Immediate
+}
+
+// CollectsSignal returns true if the operator collects the given telemetry signal for the namespace of this
+// Dash0Monitoring resource, that is, if spec.collect is empty or lists the signal.
+func (d *Dash0Monitoring) CollectsSignal(signal TelemetrySignal) bool {
+	return len(d.Spec.Collect) == 0 || slices.Contains(d.Spec.Collect, signal)
+}
+
 func (d *Dash0Monitoring) IsMarkedForDeletion() bool {
 	deletionTimestamp := d.GetDeletionTimestamp()
 	return deletionTimestamp != nil && !deletionTimestamp.IsZero()
@@ -280,6 +498,55 @@ func (d *Dash0Monitoring) EnsureResourceIsMarkedAsDegraded(
 		})
 }
 
+// SetStaleInstrumentation records the workloads that still carry the Dash0 instrumentation after workload
+// instrumentation has been disabled for the namespace, together with the UninstrumentationComplete condition. It
+// reports whether the status has been changed.
+func (d *Dash0Monitoring) SetStaleInstrumentation(workloads []string) bool {
+	listed := workloads
+	if len(listed) > maxListedStaleInstrumentationWorkloads {
+		listed = listed[:maxListedStaleInstrumentationWorkloads]
+	}
+	if len(listed) == 0 {
+		listed = nil
+	}
+	changed := !slices.Equal(d.Status.StaleInstrumentationWorkloads, listed) ||
+		d.Status.StaleInstrumentationWorkloadCount != len(workloads)
+	d.Status.StaleInstrumentationWorkloads = listed
+	d.Status.StaleInstrumentationWorkloadCount = len(workloads)
+
+	condition := metav1.Condition{
+		Type:    string(ConditionTypeUninstrumentationComplete),
+		Status:  metav1.ConditionTrue,
+		Reason:  "NoStaleInstrumentation",
+		Message: "No workload in this namespace carries the Dash0 instrumentation anymore.",
+	}
+	if len(workloads) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "StaleInstrumentation"
+		condition.Message = fmt.Sprintf(
+			"%d workload(s) in this namespace still carry the Dash0 instrumentation, see "+
+				"status.staleInstrumentationWorkloads.",
+			len(workloads),
+		)
+	}
+	if meta.SetStatusCondition(&d.Status.Conditions, condition) {
+		changed = true
+	}
+	return changed
+}
+
+// ClearStaleInstrumentation removes the information recorded by SetStaleInstrumentation, e.g. after workload
+// instrumentation has been enabled again for the namespace. It reports whether the status has been changed.
+func (d *Dash0Monitoring) ClearStaleInstrumentation() bool {
+	changed := len(d.Status.StaleInstrumentationWorkloads) > 0 || d.Status.StaleInstrumentationWorkloadCount != 0
+	d.Status.StaleInstrumentationWorkloads = nil
+	d.Status.StaleInstrumentationWorkloadCount = 0
+	if meta.RemoveStatusCondition(&d.Status.Conditions, string(ConditionTypeUninstrumentationComplete)) {
+		changed = true
+	}
+	return changed
+}
+
 func (d *Dash0Monitoring) GetResourceTypeName() string {
 	return "Dash0Monitoring"
 }
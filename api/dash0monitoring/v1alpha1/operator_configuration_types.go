@@ -37,8 +37,47 @@ type Dash0OperatorConfigurationSpec struct {
 	//
 	// +kubebuilder:default=true
 	KubernetesInfrastructureMetricsCollectionEnabled *bool `json:"kubernetesInfrastructureMetricsCollectionEnabled,omitempty"`
+
+	// If enabled, the operator will collect Kubernetes events and send them as logs. The events are collected by the
+	// same collector that collects the Kubernetes infrastructure metrics, hence this setting has no effect if
+	// kubernetesInfrastructureMetricsCollectionEnabled is false. This setting is optional, it defaults to false.
+	//
+	// +kubebuilder:default=false
+	KubernetesEventsCollectionEnabled *bool `json:"kubernetesEventsCollectionEnabled,omitempty"`
+
+	// The list of workload kinds that the operator will not instrument in any namespace, for example `StatefulSet`. This
+	// setting is optional, by default workloads of all supported kinds are instrumented. Workloads of an excluded kind
+	// are neither modified when they are deployed or updated, nor when the operator instruments the existing workloads
+	// in a namespace. Adding a kind to this list does not remove the instrumentation from workloads of that kind that
+	// have already been instrumented.
+	//
+	// +kubebuilder:validation:Optional
+	ExcludedWorkloadKinds []WorkloadKind `json:"excludedWorkloadKinds,omitempty"`
+
+	// The human-readable name of the Kubernetes cluster. If set, the OpenTelemetry collectors managed by the operator
+	// add it as the resource attribute `k8s.cluster.name` to all telemetry that does not already have this attribute,
+	// which makes it possible to tell apart telemetry from different clusters sending to the same backend. This setting
+	// is optional, by default no cluster name is added.
+	//
+	// +kubebuilder:validation:Optional
+	ClusterName string `json:"clusterName,omitempty"`
 }
 
+// WorkloadKind is one of the workload kinds the operator can instrument.
+//
+// +kubebuilder:validation:Enum=CronJob;DaemonSet;Deployment;Job;Pod;ReplicaSet;StatefulSet
+type WorkloadKind string
+
+const (
+	WorkloadKindCronJob     WorkloadKind = "CronJob"
+	WorkloadKindDaemonSet   WorkloadKind = "DaemonSet"
+	WorkloadKindDeployment  WorkloadKind = "Deployment"
+	WorkloadKindJob         WorkloadKind = "Job"
+	WorkloadKindPod         WorkloadKind = "Pod"
+	WorkloadKindReplicaSet  WorkloadKind = "ReplicaSet"
+	WorkloadKindStatefulSet WorkloadKind = "StatefulSet"
+)
+
 // SelfMonitoring describes how the operator will report telemetry about its working to the backend.
 type SelfMonitoring struct {
 	// If enabled, the operator will collect self-monitoring telemetry and send it to the Dash0 Insights dataset of
@@ -46,6 +85,15 @@ type SelfMonitoring struct {
 	//
 	// +kubebuilder:default=true
 	Enabled *bool `json:"enabled"`
+
+	// An optional dedicated backend for the operator's self-monitoring telemetry. If set, self-monitoring telemetry is
+	// sent to this backend instead of the backend configured in spec.export, which keeps the operator's own telemetry
+	// separate from the telemetry of the monitored workloads. For a Dash0 backend, the dataset configured here is used
+	// instead of the Dash0 Insights dataset. Self-monitoring telemetry is only sent to one backend, with the same
+	// precedence as for spec.export, and per-signal export settings are ignored.
+	//
+	// +kubebuilder:validation:Optional
+	Export *Export `json:"export,omitempty"`
 }
 
 // Dash0OperatorConfigurationStatus defines the observed state of the Dash0 operator configuration resource.
@@ -155,6 +203,24 @@ func (d *Dash0OperatorConfiguration) EnsureResourceIsMarkedAsDegraded(
 		})
 }
 
+// SetHealthCondition sets one of the health conditions (ApiReachable, CollectorHealthy,
+// CollectorConfigurationValid, CollectorCoversAllNodes) and reports whether the conditions have been changed.
+func (d *Dash0OperatorConfiguration) SetHealthCondition(
+	conditionType ConditionType,
+	status metav1.ConditionStatus,
+	reason string,
+	message string,
+) bool {
+	return meta.SetStatusCondition(
+		&d.Status.Conditions,
+		metav1.Condition{
+			Type:    string(conditionType),
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+}
+
 func (d *Dash0OperatorConfiguration) HasDash0ApiAccessConfigured() bool {
 	return d.Spec.Export != nil &&
 		d.Spec.Export.Dash0 != nil &&
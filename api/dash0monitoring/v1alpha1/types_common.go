@@ -8,6 +8,26 @@ type ConditionType string
 const (
 	ConditionTypeAvailable ConditionType = "Available"
 	ConditionTypeDegraded  ConditionType = "Degraded"
+
+	// ConditionTypeApiReachable is set on the Dash0 operator configuration resource and reports whether the operator
+	// can reach the Dash0 API with the configured authorization.
+	ConditionTypeApiReachable ConditionType = "ApiReachable"
+	// ConditionTypeCollectorHealthy is set on the Dash0 operator configuration resource and reports whether the
+	// OpenTelemetry collectors managed by the operator are up and running.
+	ConditionTypeCollectorHealthy ConditionType = "CollectorHealthy"
+	// ConditionTypeCollectorConfigurationValid is set on the Dash0 operator configuration resource and reports whether
+	// the configuration the operator has rendered for the OpenTelemetry collectors (including a custom configuration
+	// snippet) is valid. The operator does not apply an invalid configuration.
+	ConditionTypeCollectorConfigurationValid ConditionType = "CollectorConfigurationValid"
+	// ConditionTypeCollectorCoversAllNodes is set on the Dash0 operator configuration resource if the node coverage check
+	// is enabled, and reports whether the OpenTelemetry collector daemonset has a ready pod on every ready node.
+	ConditionTypeCollectorCoversAllNodes ConditionType = "CollectorCoversAllNodes"
+	// ConditionTypePermissionsSufficient is set on the Dash0 operator configuration resource and reports whether the
+	// service account of the operator holds all permissions the operator requires, as verified once at startup.
+	ConditionTypePermissionsSufficient ConditionType = "PermissionsSufficient"
+	// ConditionTypeUninstrumentationComplete is set on a Dash0 monitoring resource with spec.instrumentWorkloads=none
+	// and reports whether workloads in the namespace still carry the Dash0 instrumentation.
+	ConditionTypeUninstrumentationComplete ConditionType = "UninstrumentationComplete"
 )
 
 // Export describes the observability backend to which telemetry data will be sent. This can either be Dash0 or another
@@ -30,6 +50,42 @@ type Export struct {
 	//
 	// +kubebuilder:validation:Optional
 	Grpc *GrpcConfiguration `json:"grpc,omitempty"`
+
+	// Optional export settings for traces. If set, traces will be sent to the exporters configured here instead of
+	// the exporters configured directly in the export settings.
+	//
+	// +kubebuilder:validation:Optional
+	Traces *SignalExport `json:"traces,omitempty"`
+
+	// Optional export settings for metrics. If set, metrics will be sent to the exporters configured here instead of
+	// the exporters configured directly in the export settings.
+	//
+	// +kubebuilder:validation:Optional
+	Metrics *SignalExport `json:"metrics,omitempty"`
+
+	// Optional export settings for logs. If set, logs will be sent to the exporters configured here instead of the
+	// exporters configured directly in the export settings.
+	//
+	// +kubebuilder:validation:Optional
+	Logs *SignalExport `json:"logs,omitempty"`
+}
+
+// SignalExport describes the observability backend to which one type of telemetry data (traces, metrics or logs) will
+// be sent, instead of the backend configured directly in the export settings. Only arbitrary OTLP-compatible backends
+// can be configured per signal, a Dash0 backend can only be configured directly in the export settings. You can
+// combine a gRPC and an HTTP exporter. At least one exporter has to be defined.
+//
+// +kubebuilder:validation:MinProperties=1
+type SignalExport struct {
+	// The settings for an exporter to send telemetry to an arbitrary OTLP-compatible receiver via HTTP.
+	//
+	// +kubebuilder:validation:Optional
+	Http *HttpConfiguration `json:"http,omitempty"`
+
+	// The settings for an exporter to send telemetry to an arbitrary OTLP-compatible receiver via gRPC.
+	//
+	// +kubebuilder:validation:Optional
+	Grpc *GrpcConfiguration `json:"grpc,omitempty"`
 }
 
 // Dash0Configuration describes to which Dash0 ingress endpoint telemetry data will be sent.
@@ -61,6 +117,14 @@ type Dash0Configuration struct {
 	//
 	// +kubebuilder:validation:Optional
 	ApiEndpoint string `json:"apiEndpoint,omitempty"`
+
+	// Additional headers to be sent with each request to the Dash0 API, for example a routing header required by an
+	// API gateway in front of the Dash0 API. The Authorization and Content-Type headers are always set by the operator
+	// and cannot be overridden. This property is optional, and is only evaluated on the Dash0 operator configuration
+	// resource.
+	//
+	// +kubebuilder:validation:Optional
+	ApiHeaders []Header `json:"apiHeaders,omitempty"`
 }
 
 // Authorization contains the authorization settings for Dash0.
@@ -92,6 +156,15 @@ type SecretRef struct {
 	// The key of the value which contains the Dash0 authorization token. Defaults to "token"
 	// +kubebuilder:default=token
 	Key string `json:"key"`
+
+	// The namespace of the secret containing the Dash0 authorization token. This property is optional, if it is not
+	// set, the secret is expected to be in the namespace of the operator. Since pods can only reference secrets in
+	// their own namespace, the operator copies the referenced key of a secret in a different namespace to a secret in
+	// its own namespace, and keeps that copy up to date when the original secret changes.
+	// In a Dash0 monitoring resource, only the namespace of the monitoring resource itself can be used.
+	//
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // HttpConfiguration describe the settings for an exporter to send telemetry to an arbitrary OTLP-compatible receiver
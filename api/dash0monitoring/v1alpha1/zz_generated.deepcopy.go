@@ -41,6 +41,11 @@ func (in *Authorization) DeepCopy() *Authorization {
 func (in *Dash0Configuration) DeepCopyInto(out *Dash0Configuration) {
 	*out = *in
 	in.Authorization.DeepCopyInto(&out.Authorization)
+	if in.ApiHeaders != nil {
+		in, out := &in.ApiHeaders, &out.ApiHeaders
+		*out = make([]Header, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dash0Configuration.
@@ -135,6 +140,21 @@ func (in *Dash0MonitoringSpec) DeepCopyInto(out *Dash0MonitoringSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Collect != nil {
+		in, out := &in.Collect, &out.Collect
+		*out = make([]TelemetrySignal, len(*in))
+		copy(*out, *in)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(Filter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transform != nil {
+		in, out := &in.Transform, &out.Transform
+		*out = new(Transform)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dash0MonitoringSpec.
@@ -171,6 +191,11 @@ func (in *Dash0MonitoringStatus) DeepCopyInto(out *Dash0MonitoringStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.StaleInstrumentationWorkloads != nil {
+		in, out := &in.StaleInstrumentationWorkloads, &out.StaleInstrumentationWorkloads
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dash0MonitoringStatus.
@@ -256,6 +281,16 @@ func (in *Dash0OperatorConfigurationSpec) DeepCopyInto(out *Dash0OperatorConfigu
 		*out = new(bool)
 		**out = **in
 	}
+	if in.KubernetesEventsCollectionEnabled != nil {
+		in, out := &in.KubernetesEventsCollectionEnabled, &out.KubernetesEventsCollectionEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludedWorkloadKinds != nil {
+		in, out := &in.ExcludedWorkloadKinds, &out.ExcludedWorkloadKinds
+		*out = make([]WorkloadKind, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dash0OperatorConfigurationSpec.
@@ -308,6 +343,21 @@ func (in *Export) DeepCopyInto(out *Export) {
 		*out = new(GrpcConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Traces != nil {
+		in, out := &in.Traces, &out.Traces
+		*out = new(SignalExport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(SignalExport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = new(SignalExport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Export.
@@ -320,6 +370,31 @@ func (in *Export) DeepCopy() *Export {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Filter) DeepCopyInto(out *Filter) {
+	*out = *in
+	if in.Traces != nil {
+		in, out := &in.Traces, &out.Traces
+		*out = new(TraceFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = new(LogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Filter.
+func (in *Filter) DeepCopy() *Filter {
+	if in == nil {
+		return nil
+	}
+	out := new(Filter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrpcConfiguration) DeepCopyInto(out *GrpcConfiguration) {
 	*out = *in
@@ -375,6 +450,26 @@ func (in *HttpConfiguration) DeepCopy() *HttpConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogFilter) DeepCopyInto(out *LogFilter) {
+	*out = *in
+	if in.LogRecordFilter != nil {
+		in, out := &in.LogRecordFilter, &out.LogRecordFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogFilter.
+func (in *LogFilter) DeepCopy() *LogFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(LogFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersesDashboardSynchronizationResults) DeepCopyInto(out *PersesDashboardSynchronizationResults) {
 	*out = *in
@@ -463,6 +558,11 @@ func (in *SelfMonitoring) DeepCopyInto(out *SelfMonitoring) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(Export)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfMonitoring.
@@ -474,3 +574,78 @@ func (in *SelfMonitoring) DeepCopy() *SelfMonitoring {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalExport) DeepCopyInto(out *SignalExport) {
+	*out = *in
+	if in.Http != nil {
+		in, out := &in.Http, &out.Http
+		*out = new(HttpConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Grpc != nil {
+		in, out := &in.Grpc, &out.Grpc
+		*out = new(GrpcConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalExport.
+func (in *SignalExport) DeepCopy() *SignalExport {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceFilter) DeepCopyInto(out *TraceFilter) {
+	*out = *in
+	if in.SpanFilter != nil {
+		in, out := &in.SpanFilter, &out.SpanFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceFilter.
+func (in *TraceFilter) DeepCopy() *TraceFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Transform) DeepCopyInto(out *Transform) {
+	*out = *in
+	if in.Traces != nil {
+		in, out := &in.Traces, &out.Traces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Transform.
+func (in *Transform) DeepCopy() *Transform {
+	if in == nil {
+		return nil
+	}
+	out := new(Transform)
+	in.DeepCopyInto(out)
+	return out
+}
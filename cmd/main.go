@@ -8,8 +8,11 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -23,6 +26,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -33,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	k8swebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -43,6 +48,7 @@ import (
 	"github.com/dash0hq/dash0-operator/internal/controller"
 	"github.com/dash0hq/dash0-operator/internal/instrumentation"
 	"github.com/dash0hq/dash0-operator/internal/predelete"
+	"github.com/dash0hq/dash0-operator/internal/prometheusexport"
 	"github.com/dash0hq/dash0-operator/internal/selfmonitoringapiaccess"
 	"github.com/dash0hq/dash0-operator/internal/startup"
 	"github.com/dash0hq/dash0-operator/internal/util"
@@ -57,6 +63,7 @@ type environmentVariables struct {
 	operatorImage                        string
 	initContainerImage                   string
 	initContainerImagePullPolicy         corev1.PullPolicy
+	initContainerImageAllowedRegistries  []string
 	collectorImage                       string
 	collectorImagePullPolicy             corev1.PullPolicy
 	configurationReloaderImage           string
@@ -65,6 +72,13 @@ type environmentVariables struct {
 	filelogOffsetSynchImagePullPolicy    corev1.PullPolicy
 	selfMonitoringAndApiAuthToken        string
 	podIp                                string
+	collectorDebugFileExport             bool
+	initContainerSecurityContext         util.InitContainerSecurityContext
+	initContainerResources               corev1.ResourceRequirements
+	propagatedWorkloadLabels             []string
+	webhookMaxObjectSize                 int64
+	maxConcurrentReconciles              int
+	collectorImageVersionCheck           string
 }
 
 const (
@@ -74,6 +88,7 @@ const (
 	operatorImageEnvVarName                        = "DASH0_OPERATOR_IMAGE"
 	initContainerImageEnvVarName                   = "DASH0_INIT_CONTAINER_IMAGE"
 	initContainerImagePullPolicyEnvVarName         = "DASH0_INIT_CONTAINER_IMAGE_PULL_POLICY"
+	initContainerImageAllowedRegistriesEnvVarName  = "DASH0_INIT_CONTAINER_IMAGE_ALLOWED_REGISTRIES"
 	collectorImageEnvVarName                       = "DASH0_COLLECTOR_IMAGE"
 	collectorImageImagePullPolicyEnvVarName        = "DASH0_COLLECTOR_IMAGE_PULL_POLICY"
 	configurationReloaderImageEnvVarName           = "DASH0_CONFIGURATION_RELOADER_IMAGE"
@@ -82,7 +97,28 @@ const (
 	filelogOffsetSynchImagePullPolicyEnvVarName    = "DASH0_FILELOG_OFFSET_SYNCH_IMAGE_PULL_POLICY"
 	podIpEnvVarName                                = "MY_POD_IP"
 
-	developmentModeEnvVarName = "DASH0_DEVELOPMENT_MODE"
+	developmentModeEnvVarName          = "DASH0_DEVELOPMENT_MODE"
+	collectorDebugFileExportEnvVarName = "DASH0_COLLECTOR_DEBUG_FILE_EXPORT"
+
+	initContainerSeccompProfileTypeEnvVarName = "DASH0_INIT_CONTAINER_SECCOMP_PROFILE_TYPE"
+	initContainerAddCapabilitiesEnvVarName    = "DASH0_INIT_CONTAINER_ADD_CAPABILITIES"
+	initContainerCpuRequestEnvVarName         = "DASH0_INIT_CONTAINER_CPU_REQUEST"
+	initContainerMemoryRequestEnvVarName      = "DASH0_INIT_CONTAINER_MEMORY_REQUEST"
+	initContainerCpuLimitEnvVarName           = "DASH0_INIT_CONTAINER_CPU_LIMIT"
+	initContainerMemoryLimitEnvVarName        = "DASH0_INIT_CONTAINER_MEMORY_LIMIT"
+	propagatedWorkloadLabelsEnvVarName        = "DASH0_PROPAGATED_WORKLOAD_LABELS"
+	webhookMaxObjectSizeEnvVarName            = "DASH0_WEBHOOK_MAX_OBJECT_SIZE"
+	maxConcurrentReconcilesEnvVarName         = "DASH0_MAX_CONCURRENT_RECONCILES"
+	collectorImageVersionCheckEnvVarName      = "DASH0_COLLECTOR_IMAGE_VERSION_CHECK"
+
+	// collectorImageVersionCheckWarn logs a warning if the collector image version is not compatible with the operator
+	// version, this is the default.
+	collectorImageVersionCheckWarn = "warn"
+	// collectorImageVersionCheckStrict refuses to start the operator if the collector image version is not compatible
+	// with the operator version.
+	collectorImageVersionCheckStrict = "strict"
+	// collectorImageVersionCheckDisabled skips the collector image version check.
+	collectorImageVersionCheckDisabled = "disabled"
 
 	oTelColResourceSpecConfigFile = "/etc/config/otelcolresources.yaml"
 
@@ -90,6 +126,18 @@ const (
 	mandatoryEnvVarMissingMessageTemplate = "cannot start the Dash0 operator, the mandatory environment variable \"%s\" is missing"
 
 	meterName = "dash0.operator.manager"
+
+	// collectorResourcesResyncInterval is the interval in which the OpenTelemetry collector resources are reconciled
+	// even if no watch event has been received.
+	collectorResourcesResyncInterval = 10 * time.Minute
+
+	// backendConnectionHealthCheckInterval is the interval in which the ApiReachable and CollectorHealthy conditions
+	// (and the CollectorCoversAllNodes condition, if enabled) of the operator configuration resource are updated.
+	backendConnectionHealthCheckInterval = 1 * time.Minute
+
+	// staleInstrumentationCheckInterval is the interval in which the list of workloads that still carry the Dash0
+	// instrumentation in namespaces with spec.instrumentWorkloads=none is updated.
+	staleInstrumentationCheckInterval = 5 * time.Minute
 )
 
 var (
@@ -123,6 +171,12 @@ func main() {
 	var operatorConfigurationApiEndpoint string
 	var operatorConfigurationSelfMonitoringEnabled bool
 	var operatorConfigurationKubernetesInfrastructureMetricsCollectionEnabled bool
+	var operatorConfigurationKubernetesEventsCollectionEnabled bool
+	var operatorConfigurationClusterName string
+	var apiIdempotencyKeyHeaderName string
+	var apiProxyUrlRaw string
+	var dashboardExcludedFieldsRaw string
+	var instrumentationAuditLogTarget string
 	var isUninstrumentAll bool
 	var metricsAddr string
 	var enableLeaderElection bool
@@ -183,6 +237,52 @@ func main() {
 		true,
 		"Whether to set kubernetesInfrastructureMetricsCollectionEnabled on the operator configuration resource; "+
 			"will be ignored if operator-configuration-endpoint is not set.")
+	flag.BoolVar(
+		&operatorConfigurationKubernetesEventsCollectionEnabled,
+		"operator-configuration-kubernetes-events-collection-enabled",
+		false,
+		"Whether to set kubernetesEventsCollectionEnabled on the operator configuration resource; will be ignored if "+
+			"operator-configuration-endpoint is not set.",
+	)
+	flag.StringVar(
+		&operatorConfigurationClusterName,
+		"operator-configuration-cluster-name",
+		"",
+		"The cluster name to set on the operator configuration resource; will be ignored if "+
+			"operator-configuration-endpoint is not set.",
+	)
+	flag.StringVar(
+		&apiIdempotencyKeyHeaderName,
+		"api-idempotency-key-header-name",
+		controller.DefaultIdempotencyKeyHeaderName,
+		"The name of the HTTP header carrying the idempotency key for requests that create or update dashboards and "+
+			"check rules via the Dash0 API.",
+	)
+	flag.StringVar(
+		&apiProxyUrlRaw,
+		"api-proxy-url",
+		"",
+		"The URL of a forward proxy for the operator's requests to the Dash0 API (for example for synchronizing "+
+			"dashboards and check rules). This is independent of the proxy settings of the OpenTelemetry collectors. "+
+			"If not set, the standard proxy environment variables of the operator manager apply.",
+	)
+	flag.StringVar(
+		&dashboardExcludedFieldsRaw,
+		"dashboard-excluded-fields",
+		"",
+		"A comma-separated list of fields that are removed from Perses dashboards before they are sent to the Dash0 "+
+			"API, for example \"spec.datasources,spec.panels.*.spec.queries.*.spec.plugin.spec.datasource\". Each "+
+			"field is a dot-separated path relative to the dashboard (kind and spec), * matches all fields of an "+
+			"object or all elements of an array.",
+	)
+	flag.StringVar(
+		&instrumentationAuditLogTarget,
+		"instrumentation-audit-log",
+		"",
+		"If set, the operator writes an audit record (as a JSON object on a separate line) for each workload it "+
+			"instruments or reverts. Either \"stdout\" or the path of a file to which the records are appended. "+
+			"The operator's regular logs are written to stderr.",
+	)
 	flag.StringVar(
 		&metricsAddr,
 		"metrics-bind-address",
@@ -265,6 +365,21 @@ func main() {
 	if err = readEnvironmentVariables(); err != nil {
 		os.Exit(1)
 	}
+	var instrumentationAuditLog util.InstrumentationAuditLog
+	if instrumentationAuditLog, err = util.OpenInstrumentationAuditLog(instrumentationAuditLogTarget); err != nil {
+		setupLog.Error(err, "Cannot open the instrumentation audit log.")
+		os.Exit(1)
+	}
+	var apiProxyUrl *url.URL
+	if apiProxyUrl, err = util.ParseApiProxyUrl(apiProxyUrlRaw); err != nil {
+		setupLog.Error(err, "Invalid value for --api-proxy-url.")
+		os.Exit(1)
+	}
+	var dashboardExcludedFields []util.JsonFieldPath
+	if dashboardExcludedFields, err = util.ParseJsonFieldPaths(dashboardExcludedFieldsRaw); err != nil {
+		setupLog.Error(err, "Invalid value for --dashboard-excluded-fields.")
+		os.Exit(1)
+	}
 	if err = initStartupTasksK8sClient(&setupLog); err != nil {
 		os.Exit(1)
 	}
@@ -279,11 +394,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Besides being exported via OTLP (if self-monitoring is enabled), the operator's own metrics are also exposed in the
+	// Prometheus format on the metrics endpoint of the manager.
+	prometheusCollector, prometheusReader := prometheusexport.NewCollector()
+	ctrlmetrics.Registry.MustRegister(prometheusCollector)
 	meter =
 		common.InitOTelSdk(
 			ctx,
 			meterName,
 			map[string]string{semconv.AttributeK8SDeploymentUID: string(deploymentSelfReference.UID)},
+			prometheusReader,
 		)
 
 	var operatorConfiguration *startup.OperatorConfigurationValues
@@ -298,6 +418,8 @@ func main() {
 			SelfMonitoringEnabled: operatorConfigurationSelfMonitoringEnabled,
 			//nolint:lll
 			KubernetesInfrastructureMetricsCollectionEnabled: operatorConfigurationKubernetesInfrastructureMetricsCollectionEnabled,
+			KubernetesEventsCollectionEnabled:                operatorConfigurationKubernetesEventsCollectionEnabled,
+			ClusterName:                                      operatorConfigurationClusterName,
 		}
 		if len(operatorConfigurationApiEndpoint) > 0 {
 			operatorConfiguration.ApiEndpoint = operatorConfigurationApiEndpoint
@@ -313,6 +435,10 @@ func main() {
 		probeAddr,
 		enableLeaderElection,
 		operatorConfiguration,
+		apiIdempotencyKeyHeaderName,
+		apiProxyUrl,
+		dashboardExcludedFields,
+		instrumentationAuditLog,
 		developmentMode,
 	); err != nil {
 		setupLog.Error(err, "The Dash0 operator manager process failed to start.")
@@ -329,6 +455,10 @@ func startOperatorManager(
 	probeAddr string,
 	enableLeaderElection bool,
 	operatorConfiguration *startup.OperatorConfigurationValues,
+	apiIdempotencyKeyHeaderName string,
+	apiProxyUrl *url.URL,
+	dashboardExcludedFields []util.JsonFieldPath,
+	instrumentationAuditLog util.InstrumentationAuditLog,
 	developmentMode bool,
 ) error {
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -338,6 +468,13 @@ func startOperatorManager(
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
 		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// Secrets are read directly from the API server instead of caching all secrets in the cluster. The
+				// operator only reads the few secrets that it mirrors into its own namespace.
+				DisableFor: []client.Object{&corev1.Secret{}},
+			},
+		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -373,6 +510,8 @@ func startOperatorManager(
 		envVars.initContainerImage,
 		"init container image pull policy override",
 		envVars.initContainerImagePullPolicy,
+		"init container image allowed registries",
+		envVars.initContainerImageAllowedRegistries,
 
 		"collector image",
 		envVars.collectorImage,
@@ -393,9 +532,35 @@ func startOperatorManager(
 
 		"development mode",
 		developmentMode,
+		"collector debug file export",
+		envVars.collectorDebugFileExport,
+		"init container seccomp profile type override",
+		envVars.initContainerSecurityContext.SeccompProfileType,
+		"init container additional capabilities",
+		envVars.initContainerSecurityContext.AddCapabilities,
+		"init container resource requests override",
+		envVars.initContainerResources.Requests,
+		"init container resource limits override",
+		envVars.initContainerResources.Limits,
+		"webhook maximum object size",
+		envVars.webhookMaxObjectSize,
+		"monitoring controller maximum concurrent reconciles",
+		envVars.maxConcurrentReconciles,
+		"collector image version check",
+		envVars.collectorImageVersionCheck,
 	)
 
-	err = startDash0Controllers(ctx, mgr, clientset, operatorConfiguration, developmentMode)
+	err = startDash0Controllers(
+		ctx,
+		mgr,
+		clientset,
+		operatorConfiguration,
+		apiIdempotencyKeyHeaderName,
+		apiProxyUrl,
+		dashboardExcludedFields,
+		instrumentationAuditLog,
+		developmentMode,
+	)
 	if err != nil {
 		return err
 	}
@@ -475,6 +640,73 @@ func readEnvironmentVariables() error {
 		return fmt.Errorf(mandatoryEnvVarMissingMessageTemplate, podIpEnvVarName)
 	}
 
+	collectorDebugFileExportRaw, isSet := os.LookupEnv(collectorDebugFileExportEnvVarName)
+	collectorDebugFileExport := isSet && strings.ToLower(collectorDebugFileExportRaw) == "true"
+
+	initContainerSecurityContext := readInitContainerSecurityContextFromEnvironmentVariables()
+	initContainerResources := readInitContainerResourcesFromEnvironmentVariables()
+
+	var propagatedWorkloadLabels []string
+	for _, labelKey := range strings.Split(os.Getenv(propagatedWorkloadLabelsEnvVarName), ",") {
+		labelKey = strings.TrimSpace(labelKey)
+		if labelKey != "" {
+			propagatedWorkloadLabels = append(propagatedWorkloadLabels, labelKey)
+		}
+	}
+
+	var initContainerImageAllowedRegistries []string
+	for _, registry := range strings.Split(os.Getenv(initContainerImageAllowedRegistriesEnvVarName), ",") {
+		registry = strings.TrimSpace(registry)
+		if registry != "" {
+			initContainerImageAllowedRegistries = append(initContainerImageAllowedRegistries, registry)
+		}
+	}
+
+	var webhookMaxObjectSize int64
+	if webhookMaxObjectSizeRaw := os.Getenv(webhookMaxObjectSizeEnvVarName); webhookMaxObjectSizeRaw != "" {
+		if quantity, err := resource.ParseQuantity(webhookMaxObjectSizeRaw); err == nil && quantity.Value() > 0 {
+			webhookMaxObjectSize = quantity.Value()
+		} else {
+			setupLog.Info(
+				fmt.Sprintf(
+					"Ignoring invalid webhook maximum object size setting (%s): %s.",
+					webhookMaxObjectSizeEnvVarName,
+					webhookMaxObjectSizeRaw,
+				))
+		}
+	}
+
+	var maxConcurrentReconciles int
+	if maxConcurrentReconcilesRaw := os.Getenv(maxConcurrentReconcilesEnvVarName); maxConcurrentReconcilesRaw != "" {
+		if value, err := strconv.Atoi(maxConcurrentReconcilesRaw); err == nil && value > 0 {
+			maxConcurrentReconciles = value
+		} else {
+			setupLog.Info(
+				fmt.Sprintf(
+					"Ignoring invalid maximum concurrent reconciles setting (%s): %s.",
+					maxConcurrentReconcilesEnvVarName,
+					maxConcurrentReconcilesRaw,
+				))
+		}
+	}
+
+	collectorImageVersionCheck := collectorImageVersionCheckWarn
+	collectorImageVersionCheckRaw := os.Getenv(collectorImageVersionCheckEnvVarName)
+	if collectorImageVersionCheckRaw != "" {
+		if collectorImageVersionCheckRaw == collectorImageVersionCheckWarn ||
+			collectorImageVersionCheckRaw == collectorImageVersionCheckStrict ||
+			collectorImageVersionCheckRaw == collectorImageVersionCheckDisabled {
+			collectorImageVersionCheck = collectorImageVersionCheckRaw
+		} else {
+			setupLog.Info(
+				fmt.Sprintf(
+					"Ignoring unknown collector image version check setting (%s): %s.",
+					collectorImageVersionCheckEnvVarName,
+					collectorImageVersionCheckRaw,
+				))
+		}
+	}
+
 	envVars = environmentVariables{
 		operatorNamespace:                    operatorNamespace,
 		deploymentName:                       deploymentName,
@@ -482,6 +714,7 @@ func readEnvironmentVariables() error {
 		operatorImage:                        operatorImage,
 		initContainerImage:                   initContainerImage,
 		initContainerImagePullPolicy:         initContainerImagePullPolicy,
+		initContainerImageAllowedRegistries:  initContainerImageAllowedRegistries,
 		collectorImage:                       collectorImage,
 		collectorImagePullPolicy:             collectorImagePullPolicy,
 		configurationReloaderImage:           configurationReloaderImage,
@@ -490,11 +723,52 @@ func readEnvironmentVariables() error {
 		filelogOffsetSynchImagePullPolicy:    filelogOffsetSynchImagePullPolicy,
 		selfMonitoringAndApiAuthToken:        selfMonitoringAndApiAuthToken,
 		podIp:                                podIp,
+		collectorDebugFileExport:             collectorDebugFileExport,
+		initContainerSecurityContext:         initContainerSecurityContext,
+		initContainerResources:               initContainerResources,
+		propagatedWorkloadLabels:             propagatedWorkloadLabels,
+		webhookMaxObjectSize:                 webhookMaxObjectSize,
+		maxConcurrentReconciles:              maxConcurrentReconciles,
+		collectorImageVersionCheck:           collectorImageVersionCheck,
 	}
 
 	return nil
 }
 
+// checkCollectorImageVersion verifies that the configured collector image is compatible with this operator version.
+// Depending on the collector image version check setting, an incompatible collector image is only logged, or prevents
+// the operator from starting.
+func checkCollectorImageVersion(images util.Images) error {
+	if envVars.collectorImageVersionCheck == collectorImageVersionCheckDisabled {
+		return nil
+	}
+	checked, err := images.CheckCollectorImageVersion()
+	if !checked {
+		setupLog.Info(
+			"Cannot determine whether the collector image is compatible with this operator version, skipping the "+
+				"collector image version check.",
+			"collector image",
+			images.CollectorImage,
+			"operator image",
+			images.OperatorImage,
+		)
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	if envVars.collectorImageVersionCheck == collectorImageVersionCheckStrict {
+		setupLog.Error(err, "The collector image is not compatible with this operator version.")
+		return err
+	}
+	setupLog.Error(
+		err,
+		"The collector image is not compatible with this operator version, the OpenTelemetry collectors might fail "+
+			"to start or drop telemetry.",
+	)
+	return nil
+}
+
 func readConfiguration() (*otelcolresources.OTelColResourceSpecs, error) {
 	oTelColResourceSpec, err := otelcolresources.ReadOTelColResourcesConfiguration(oTelColResourceSpecConfigFile)
 	if err != nil {
@@ -519,11 +793,75 @@ func readOptionalPullPolicyFromEnvironmentVariable(envVarName string) corev1.Pul
 	return ""
 }
 
+func readInitContainerSecurityContextFromEnvironmentVariables() util.InitContainerSecurityContext {
+	initContainerSecurityContext := util.InitContainerSecurityContext{}
+	seccompProfileTypeRaw := os.Getenv(initContainerSeccompProfileTypeEnvVarName)
+	if seccompProfileTypeRaw != "" {
+		if seccompProfileTypeRaw == string(corev1.SeccompProfileTypeRuntimeDefault) ||
+			seccompProfileTypeRaw == string(corev1.SeccompProfileTypeUnconfined) {
+			initContainerSecurityContext.SeccompProfileType = corev1.SeccompProfileType(seccompProfileTypeRaw)
+		} else {
+			setupLog.Info(
+				fmt.Sprintf(
+					"Ignoring unknown seccomp profile type setting (%s): %s.",
+					initContainerSeccompProfileTypeEnvVarName,
+					seccompProfileTypeRaw,
+				))
+		}
+	}
+	for _, capability := range strings.Split(os.Getenv(initContainerAddCapabilitiesEnvVarName), ",") {
+		capability = strings.TrimSpace(capability)
+		if capability != "" {
+			initContainerSecurityContext.AddCapabilities =
+				append(initContainerSecurityContext.AddCapabilities, corev1.Capability(capability))
+		}
+	}
+	return initContainerSecurityContext
+}
+
+func readInitContainerResourcesFromEnvironmentVariables() corev1.ResourceRequirements {
+	initContainerResources := corev1.ResourceRequirements{}
+	for _, setting := range []struct {
+		envVarName   string
+		resourceName corev1.ResourceName
+		list         *corev1.ResourceList
+	}{
+		{initContainerCpuRequestEnvVarName, corev1.ResourceCPU, &initContainerResources.Requests},
+		{initContainerMemoryRequestEnvVarName, corev1.ResourceMemory, &initContainerResources.Requests},
+		{initContainerCpuLimitEnvVarName, corev1.ResourceCPU, &initContainerResources.Limits},
+		{initContainerMemoryLimitEnvVarName, corev1.ResourceMemory, &initContainerResources.Limits},
+	} {
+		quantityRaw := os.Getenv(setting.envVarName)
+		if quantityRaw == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(quantityRaw)
+		if err != nil {
+			setupLog.Info(
+				fmt.Sprintf(
+					"Ignoring invalid init container resource setting (%s): %s.",
+					setting.envVarName,
+					quantityRaw,
+				))
+			continue
+		}
+		if *setting.list == nil {
+			*setting.list = corev1.ResourceList{}
+		}
+		(*setting.list)[setting.resourceName] = quantity
+	}
+	return initContainerResources
+}
+
 func startDash0Controllers(
 	ctx context.Context,
 	mgr manager.Manager,
 	clientset *kubernetes.Clientset,
 	operatorConfiguration *startup.OperatorConfigurationValues,
+	apiIdempotencyKeyHeaderName string,
+	apiProxyUrl *url.URL,
+	dashboardExcludedFields []util.JsonFieldPath,
+	instrumentationAuditLog util.InstrumentationAuditLog,
 	developmentMode bool,
 ) error {
 	oTelColResourceSpecs, err := readConfiguration()
@@ -531,11 +869,10 @@ func startDash0Controllers(
 		os.Exit(1)
 	}
 
-	oTelCollectorBaseUrl :=
-		fmt.Sprintf(
-			"http://%s-opentelemetry-collector-service.%s.svc.cluster.local:4318",
-			envVars.oTelCollectorNamePrefix,
-			envVars.operatorNamespace)
+	if err = otelcolresources.ValidateNamePrefix(envVars.oTelCollectorNamePrefix); err != nil {
+		setupLog.Error(err, "The collector name prefix exceeds the recommended length.")
+	}
+	oTelCollectorBaseUrl := otelcolresources.ServiceBaseUrl(envVars.oTelCollectorNamePrefix, envVars.operatorNamespace)
 	images := util.Images{
 		OperatorImage:                        envVars.operatorImage,
 		InitContainerImage:                   envVars.initContainerImage,
@@ -546,8 +883,13 @@ func startDash0Controllers(
 		ConfigurationReloaderImagePullPolicy: envVars.configurationReloaderImagePullPolicy,
 		FilelogOffsetSynchImage:              envVars.filelogOffsetSynchImage,
 		FilelogOffsetSynchImagePullPolicy:    envVars.filelogOffsetSynchImagePullPolicy,
+		InitContainerImageAllowedRegistries:  envVars.initContainerImageAllowedRegistries,
+	}
+	if err = checkCollectorImageVersion(images); err != nil {
+		return err
 	}
 	isIPv6Cluster := strings.Count(envVars.podIp, ":") >= 2
+	collectorGatewayMode := oTelColResourceSpecs.CollectorMode == otelcolresources.CollectorModeGateway
 
 	executeStartupTasks(
 		ctx,
@@ -557,19 +899,30 @@ func startDash0Controllers(
 		images,
 		oTelCollectorBaseUrl,
 		isIPv6Cluster,
+		collectorGatewayMode,
+		envVars.initContainerSecurityContext,
+		envVars.initContainerResources,
+		envVars.propagatedWorkloadLabels,
+		instrumentationAuditLog,
 		&setupLog,
 	)
 
 	logCurrentSelfMonitoringSettings(deploymentSelfReference)
 
 	k8sClient := mgr.GetClient()
+	apiUserAgent := util.ApiUserAgent(images)
 	instrumenter := &instrumentation.Instrumenter{
-		Client:               k8sClient,
-		Clientset:            clientset,
-		Recorder:             mgr.GetEventRecorderFor("dash0-monitoring-controller"),
-		Images:               images,
-		OTelCollectorBaseUrl: oTelCollectorBaseUrl,
-		IsIPv6Cluster:        isIPv6Cluster,
+		Client:                       k8sClient,
+		Clientset:                    clientset,
+		Recorder:                     mgr.GetEventRecorderFor("dash0-monitoring-controller"),
+		Images:                       images,
+		OTelCollectorBaseUrl:         oTelCollectorBaseUrl,
+		IsIPv6Cluster:                isIPv6Cluster,
+		CollectorGatewayMode:         collectorGatewayMode,
+		InitContainerSecurityContext: envVars.initContainerSecurityContext,
+		InitContainerResources:       envVars.initContainerResources,
+		PropagatedWorkloadLabels:     envVars.propagatedWorkloadLabels,
+		AuditLog:                     instrumentationAuditLog,
 	}
 	oTelColResourceManager := &otelcolresources.OTelColResourceManager{
 		Client:                  k8sClient,
@@ -579,6 +932,7 @@ func startDash0Controllers(
 		OTelColResourceSpecs:    oTelColResourceSpecs,
 		IsIPv6Cluster:           isIPv6Cluster,
 		DevelopmentMode:         developmentMode,
+		DebugFileExport:         envVars.collectorDebugFileExport,
 	}
 	backendConnectionManager := &backendconnection.BackendConnectionManager{
 		Client:                 k8sClient,
@@ -591,14 +945,20 @@ func startDash0Controllers(
 		Images:                   images,
 		OperatorNamespace:        envVars.operatorNamespace,
 		OTelCollectorNamePrefix:  envVars.oTelCollectorNamePrefix,
+		ResyncInterval:           collectorResourcesResyncInterval,
 	}
 	if err := backendConnectionReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to set up the backend connection reconciler: %w", err)
 	}
 
 	persesDashboardCrdReconciler := &controller.PersesDashboardCrdReconciler{
-		Client:    k8sClient,
-		AuthToken: envVars.selfMonitoringAndApiAuthToken,
+		Client:                   k8sClient,
+		AuthToken:                envVars.selfMonitoringAndApiAuthToken,
+		IdempotencyKeyHeaderName: apiIdempotencyKeyHeaderName,
+		ApiProxyUrl:              apiProxyUrl,
+		ApiUserAgent:             apiUserAgent,
+		ExcludedFields:           dashboardExcludedFields,
+		Recorder:                 mgr.GetEventRecorderFor("dash0-perses-dashboard-controller"),
 	}
 	if err := persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, startupTasksK8sClient, &setupLog); err != nil {
 		return fmt.Errorf("unable to set up the Perses dashboard reconciler: %w", err)
@@ -609,8 +969,11 @@ func startDash0Controllers(
 		&setupLog,
 	)
 	prometheusRuleCrdReconciler := &controller.PrometheusRuleCrdReconciler{
-		Client:    k8sClient,
-		AuthToken: envVars.selfMonitoringAndApiAuthToken,
+		Client:                   k8sClient,
+		AuthToken:                envVars.selfMonitoringAndApiAuthToken,
+		IdempotencyKeyHeaderName: apiIdempotencyKeyHeaderName,
+		ApiProxyUrl:              apiProxyUrl,
+		ApiUserAgent:             apiUserAgent,
 	}
 	if err := prometheusRuleCrdReconciler.SetupWithManager(ctx, mgr, startupTasksK8sClient, &setupLog); err != nil {
 		return fmt.Errorf("unable to set up the Prometheus rule reconciler: %w", err)
@@ -628,15 +991,44 @@ func startDash0Controllers(
 			persesDashboardCrdReconciler,
 			prometheusRuleCrdReconciler,
 		},
-		Scheme:                  mgr.GetScheme(),
-		Recorder:                mgr.GetEventRecorderFor("dash0-operator-configuration-controller"),
-		DeploymentSelfReference: deploymentSelfReference,
-		Images:                  images,
-		DevelopmentMode:         developmentMode,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorderFor("dash0-operator-configuration-controller"),
+		DeploymentSelfReference:  deploymentSelfReference,
+		Images:                   images,
+		DevelopmentMode:          developmentMode,
+		BackendConnectionManager: backendConnectionManager,
+		OperatorNamespace:        envVars.operatorNamespace,
 	}
 	if err := operatorConfigurationReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to set up the operator configuration reconciler: %w", err)
 	}
+	if err := mgr.Add(&controller.BackendConnectionHealthChecker{
+		Client:                   k8sClient,
+		Recorder:                 mgr.GetEventRecorderFor("dash0-backend-connection-health-checker"),
+		HttpClient:               util.NewApiHttpClient(apiProxyUrl, apiUserAgent, 10*time.Second),
+		AuthToken:                envVars.selfMonitoringAndApiAuthToken,
+		OperatorNamespace:        envVars.operatorNamespace,
+		OTelCollectorNamePrefix:  envVars.oTelCollectorNamePrefix,
+		CollectorGatewayMode:     collectorGatewayMode,
+		NodeCoverageCheckEnabled: oTelColResourceSpecs.CollectorNodeCoverageCheckEnabled,
+		Interval:                 backendConnectionHealthCheckInterval,
+	}); err != nil {
+		return fmt.Errorf("unable to set up the backend connection health checker: %w", err)
+	}
+	if err := mgr.Add(&controller.StaleInstrumentationChecker{
+		Client:    k8sClient,
+		Clientset: clientset,
+		Interval:  staleInstrumentationCheckInterval,
+	}); err != nil {
+		return fmt.Errorf("unable to set up the stale instrumentation checker: %w", err)
+	}
+	if err := mgr.Add(&startup.PermissionChecker{
+		Client:            k8sClient,
+		Clientset:         clientset,
+		OperatorNamespace: envVars.operatorNamespace,
+	}); err != nil {
+		return fmt.Errorf("unable to set up the permission check: %w", err)
+	}
 	operatorConfigurationReconciler.InitializeSelfMonitoringMetrics(
 		meter,
 		metricNamePrefix,
@@ -650,6 +1042,12 @@ func startDash0Controllers(
 		BackendConnectionManager: backendConnectionManager,
 		Images:                   images,
 		OperatorNamespace:        envVars.operatorNamespace,
+		OTelCollectorNamePrefix:  envVars.oTelCollectorNamePrefix,
+		DatasetChangeHandlers: []controller.DatasetChangeHandler{
+			persesDashboardCrdReconciler,
+			prometheusRuleCrdReconciler,
+		},
+		MaxConcurrentReconciles: envVars.maxConcurrentReconciles,
 	}
 	if err := monitoringReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to set up the monitoring reconciler: %w", err)
@@ -660,15 +1058,28 @@ func startDash0Controllers(
 		&setupLog,
 	)
 
-	if err := (&webhooks.InstrumentationWebhookHandler{
-		Client:               k8sClient,
-		Recorder:             mgr.GetEventRecorderFor("dash0-instrumentation-webhook"),
-		Images:               images,
-		OTelCollectorBaseUrl: oTelCollectorBaseUrl,
-		IsIPv6Cluster:        isIPv6Cluster,
-	}).SetupWebhookWithManager(mgr); err != nil {
+	instrumentationWebhookHandler := &webhooks.InstrumentationWebhookHandler{
+		Client:                       k8sClient,
+		Recorder:                     mgr.GetEventRecorderFor("dash0-instrumentation-webhook"),
+		Images:                       images,
+		OTelCollectorBaseUrl:         oTelCollectorBaseUrl,
+		IsIPv6Cluster:                isIPv6Cluster,
+		CollectorGatewayMode:         collectorGatewayMode,
+		InitContainerSecurityContext: envVars.initContainerSecurityContext,
+		InitContainerResources:       envVars.initContainerResources,
+		PropagatedWorkloadLabels:     envVars.propagatedWorkloadLabels,
+		MaxObjectSize:                envVars.webhookMaxObjectSize,
+		OperatorNamespace:            envVars.operatorNamespace,
+		AuditLog:                     instrumentationAuditLog,
+	}
+	if err := instrumentationWebhookHandler.SetupWebhookWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create the instrumentation webhook: %w", err)
 	}
+	instrumentationWebhookHandler.InitializeSelfMonitoringMetrics(
+		meter,
+		metricNamePrefix,
+		&setupLog,
+	)
 
 	if err := (&webhooks.OperatorConfigurationValidationWebhookHandler{
 		Client: k8sClient,
@@ -680,6 +1091,11 @@ func startDash0Controllers(
 	}).SetupWebhookWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create the monitoring validation webhook: %w", err)
 	}
+	if err := (&webhooks.MonitoringMutatingWebhookHandler{
+		Client: k8sClient,
+	}).SetupWebhookWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create the monitoring mutating webhook: %w", err)
+	}
 
 	return nil
 }
@@ -730,6 +1146,11 @@ func executeStartupTasks(
 	images util.Images,
 	oTelCollectorBaseUrl string,
 	isIPv6Cluster bool,
+	collectorGatewayMode bool,
+	initContainerSecurityContext util.InitContainerSecurityContext,
+	initContainerResources corev1.ResourceRequirements,
+	propagatedWorkloadLabels []string,
+	instrumentationAuditLog util.InstrumentationAuditLog,
 	logger *logr.Logger,
 ) {
 	createOperatorConfiguration(
@@ -746,6 +1167,11 @@ func executeStartupTasks(
 		images,
 		oTelCollectorBaseUrl,
 		isIPv6Cluster,
+		collectorGatewayMode,
+		initContainerSecurityContext,
+		initContainerResources,
+		propagatedWorkloadLabels,
+		instrumentationAuditLog,
 	)
 }
 
@@ -757,14 +1183,24 @@ func instrumentAtStartup(
 	images util.Images,
 	oTelCollectorBaseUrl string,
 	isIPv6Cluster bool,
+	collectorGatewayMode bool,
+	initContainerSecurityContext util.InitContainerSecurityContext,
+	initContainerResources corev1.ResourceRequirements,
+	propagatedWorkloadLabels []string,
+	instrumentationAuditLog util.InstrumentationAuditLog,
 ) {
 	startupInstrumenter := &instrumentation.Instrumenter{
-		Client:               startupTasksK8sClient,
-		Clientset:            clientset,
-		Recorder:             eventRecorder,
-		Images:               images,
-		OTelCollectorBaseUrl: oTelCollectorBaseUrl,
-		IsIPv6Cluster:        isIPv6Cluster,
+		Client:                       startupTasksK8sClient,
+		Clientset:                    clientset,
+		Recorder:                     eventRecorder,
+		Images:                       images,
+		OTelCollectorBaseUrl:         oTelCollectorBaseUrl,
+		IsIPv6Cluster:                isIPv6Cluster,
+		CollectorGatewayMode:         collectorGatewayMode,
+		InitContainerSecurityContext: initContainerSecurityContext,
+		InitContainerResources:       initContainerResources,
+		PropagatedWorkloadLabels:     propagatedWorkloadLabels,
+		AuditLog:                     instrumentationAuditLog,
 	}
 
 	// Trigger an unconditional apply/update of instrumentation for all workloads in Dash0-enabled namespaces, according
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// The validate command checks Dash0 monitoring resources and Dash0 operator configuration resources in YAML manifests
+// without access to a Kubernetes cluster, for example in a CI pipeline before the manifests are applied.
+//
+// Usage:
+//
+//	validate <manifest> [<manifest>...]
+//
+// Use - to read a manifest from stdin. The command exits with status 0 if all Dash0 resources are valid, with status 1
+// if at least one of them is invalid, and with status 2 if a manifest cannot be read or parsed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dash0hq/dash0-operator/internal/manifestvalidation"
+)
+
+const (
+	exitCodeInvalid = 1
+	exitCodeError   = 2
+)
+
+func main() {
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s <manifest> [<manifest>...]\n\n", os.Args[0])
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(),
+			"Validates the Dash0 monitoring resources and Dash0 operator configuration resources in the given YAML "+
+				"manifests. Use - to read a manifest from stdin.")
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(exitCodeError)
+	}
+
+	exitCode := 0
+	for _, path := range flag.Args() {
+		results, err := validateFile(path)
+		for _, result := range results {
+			printResult(path, result)
+			if !result.IsValid() && exitCode == 0 {
+				exitCode = exitCodeInvalid
+			}
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			exitCode = exitCodeError
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func validateFile(path string) ([]manifestvalidation.Result, error) {
+	var manifest io.Reader
+	if path == "-" {
+		manifest = os.Stdin
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		manifest = file
+	}
+	return manifestvalidation.ValidateManifest(manifest)
+}
+
+func printResult(path string, result manifestvalidation.Result) {
+	if result.IsValid() {
+		fmt.Printf("%s: %s %s is valid\n", path, result.Kind, result.Name)
+		return
+	}
+	fmt.Printf("%s: %s %s (document %d) is invalid:\n", path, result.Kind, result.Name, result.Document)
+	for _, problem := range result.Problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+}
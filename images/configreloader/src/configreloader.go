@@ -8,6 +8,7 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -32,6 +33,8 @@ const (
 	PID_MAX_LIMIT = 32_768
 
 	meterName = "dash0.operator.configuration_reloader"
+
+	collectorExitPollInterval = 100 * time.Millisecond
 )
 
 var (
@@ -89,7 +92,9 @@ func main() {
 				}
 			case <-shutdown:
 				ticker.Stop()
+				waitForCollectorToExit(*collectorPidFilePath)
 				done <- true
+				return
 			}
 		}
 	}()
@@ -237,6 +242,30 @@ func triggerConfigurationReload(collectorPid OTelColPid) error {
 	return nil
 }
 
+// waitForCollectorToExit blocks until the collector process has terminated. The kubelet sends SIGTERM to all
+// containers of the collector pod at the same time (or, for the collector container, after its preStop hook). Staying
+// alive until the collector is gone makes sure that the collector is never left without a configuration reloader while
+// it is still running, e.g. while it is flushing its sending queues. If the collector does not terminate, the kubelet
+// kills the configuration reloader at the end of the termination grace period.
+func waitForCollectorToExit(collectorPidFilePath string) {
+	collectorPid, err := parsePidFile(collectorPidFilePath)
+	if err != nil {
+		log.Printf("Cannot determine the collector pid, not waiting for the collector to exit: %v\n", err)
+		return
+	}
+	log.Printf("Waiting for the collector process with pid '%v' to exit\n", collectorPid)
+	for isProcessAlive(collectorPid) {
+		time.Sleep(collectorExitPollInterval)
+	}
+	log.Println("The collector process has exited, shutting down")
+}
+
+func isProcessAlive(pid OTelColPid) bool {
+	// Sending signal 0 only checks whether the process exists, without actually sending a signal.
+	err := syscall.Kill(int(pid), syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
 func initializeSelfMonitoringMetrics(meter otelmetric.Meter) {
 	var err error
 
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWaitForCollectorToExitReturnsAfterTheCollectorHasExited(t *testing.T) {
+	collector := exec.Command("sleep", "1")
+	if err := collector.Start(); err != nil {
+		t.Fatalf("cannot start the fake collector process: %v", err)
+	}
+	collectorExited := make(chan struct{})
+	go func() {
+		// Reap the child process, otherwise it stays around as a zombie and still counts as alive.
+		_ = collector.Wait()
+		close(collectorExited)
+	}()
+	pidFilePath := writePidFile(t, strconv.Itoa(collector.Process.Pid))
+
+	waitReturned := make(chan struct{})
+	go func() {
+		waitForCollectorToExit(pidFilePath)
+		close(waitReturned)
+	}()
+
+	select {
+	case <-waitReturned:
+		t.Fatal("waitForCollectorToExit returned while the collector process was still running")
+	case <-collectorExited:
+	}
+
+	select {
+	case <-waitReturned:
+	case <-time.After(10 * collectorExitPollInterval):
+		t.Fatal("waitForCollectorToExit did not return after the collector process has exited")
+	}
+}
+
+func TestWaitForCollectorToExitReturnsImmediatelyWithoutPidFile(t *testing.T) {
+	assertReturnsImmediately(t, filepath.Join(t.TempDir(), "does-not-exist.pid"))
+}
+
+func TestWaitForCollectorToExitReturnsImmediatelyWithInvalidPidFile(t *testing.T) {
+	assertReturnsImmediately(t, writePidFile(t, "not-a-pid"))
+}
+
+func writePidFile(t *testing.T, content string) string {
+	pidFilePath := filepath.Join(t.TempDir(), "otelcol.pid")
+	if err := os.WriteFile(pidFilePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("cannot write the pid file: %v", err)
+	}
+	return pidFilePath
+}
+
+func assertReturnsImmediately(t *testing.T, pidFilePath string) {
+	waitReturned := make(chan struct{})
+	go func() {
+		waitForCollectorToExit(pidFilePath)
+		close(waitReturned)
+	}()
+	select {
+	case <-waitReturned:
+	case <-time.After(time.Second):
+		t.Fatal("waitForCollectorToExit did not return immediately")
+	}
+}
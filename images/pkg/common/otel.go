@@ -25,10 +25,15 @@ var (
 	shutdownFunctions []func(ctx context.Context) error
 )
 
+// InitOTelSdk initializes the global meter provider and returns a meter with the given name. Metrics are exported via
+// OTLP if OTEL_EXPORTER_OTLP_ENDPOINT is set; additional readers (e.g. for exposing the metrics in the Prometheus
+// format) can be passed in as well. Without an OTLP endpoint and without additional readers, a no-op meter is
+// returned.
 func InitOTelSdk(
 	ctx context.Context,
 	meterName string,
 	extraResourceAttributes map[string]string,
+	additionalReaders ...sdkmetric.Reader,
 ) otelmetric.Meter {
 	podUid, isSet := os.LookupEnv("K8S_POD_UID")
 	if !isSet {
@@ -43,6 +48,7 @@ func InitOTelSdk(
 	daemonSetUid := os.Getenv("K8S_DAEMONSET_UID")
 	deploymentUid := os.Getenv("K8S_DEPLOYMENT_UID")
 
+	readers := additionalReaders
 	if _, isSet = os.LookupEnv("OTEL_EXPORTER_OTLP_ENDPOINT"); isSet {
 		var metricExporter sdkmetric.Exporter
 
@@ -68,7 +74,14 @@ func InitOTelSdk(
 		default:
 			log.Fatalf("Unexpected OTLP protocol set as value of the 'OTEL_EXPORTER_OTLP_PROTOCOL' environment variable: %v", protocol)
 		}
+		readers = append(readers, sdkmetric.NewPeriodicReader(
+			metricExporter,
+			sdkmetric.WithTimeout(10*time.Second),
+			sdkmetric.WithInterval(15*time.Second),
+		))
+	}
 
+	if len(readers) > 0 {
 		attributes := make([]attribute.KeyValue, 0, len(extraResourceAttributes)+2)
 		attributes = append(attributes, semconv.K8SPodUID(podUid))
 		attributes = append(attributes, semconv.K8SNodeName(nodeName))
@@ -88,15 +101,11 @@ func InitOTelSdk(
 			log.Fatalf("Cannot initialize the OpenTelemetry resource: %v", err)
 		}
 
-		sdkMeterProvider := sdkmetric.NewMeterProvider(
-			sdkmetric.WithResource(resourceAttributes),
-			sdkmetric.WithReader(
-				sdkmetric.NewPeriodicReader(
-					metricExporter,
-					sdkmetric.WithTimeout(10*time.Second),
-					sdkmetric.WithInterval(15*time.Second),
-				)),
-		)
+		meterProviderOptions := []sdkmetric.Option{sdkmetric.WithResource(resourceAttributes)}
+		for _, reader := range readers {
+			meterProviderOptions = append(meterProviderOptions, sdkmetric.WithReader(reader))
+		}
+		sdkMeterProvider := sdkmetric.NewMeterProvider(meterProviderOptions...)
 
 		meterProvider = sdkMeterProvider
 		shutdownFunctions = []func(ctx context.Context) error{
@@ -6,6 +6,7 @@ package backendconnection
 import (
 	"context"
 	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
@@ -14,9 +15,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -31,34 +34,48 @@ type BackendConnectionReconciler struct {
 	Images                   util.Images
 	OperatorNamespace        string
 	OTelCollectorNamePrefix  string
+	// ResyncInterval is the interval in which the collector resources are reconciled, independent of watch events.
+	// This restores the desired state even if a watch event has been missed. A value of zero disables the periodic
+	// resync.
+	ResyncInterval time.Duration
 }
 
 func (r *BackendConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := ctrl.NewControllerManagedBy(mgr).
 		Named("dash0backendconnectioncontroller").
+		WithOptions(controller.Options{
+			RateLimiter: r.BackendConnectionManager.ReconcileRateLimiter(),
+		}).
 		Watches(
 			&corev1.ConfigMap{},
 			&handler.EnqueueRequestForObject{},
-			r.withNamePredicate([]string{
-				otelcolresources.DaemonSetCollectorConfigConfigMapName(r.OTelCollectorNamePrefix),
-				otelcolresources.DeploymentCollectorConfigConfigMapName(r.OTelCollectorNamePrefix),
-				// Note: We are deliberately not watching the filelog receiver offsets ConfigMap, since it is updated
-				// frequently by the filelog offset synch container and does not require reconciliation.
-			})).
+			r.withNamePredicate(r.configMapNamesToWatch())).
+		Watches(
+			&corev1.ServiceAccount{},
+			&handler.EnqueueRequestForObject{},
+			r.withNamePredicate(r.managedResourceNamesOfKind("ServiceAccount"))).
 		Watches(
 			&rbacv1.ClusterRole{},
 			&handler.EnqueueRequestForObject{},
-			r.withNamePredicate([]string{
+			r.withClusterScopedNamePredicate([]string{
 				otelcolresources.DaemonSetClusterRoleName(r.OTelCollectorNamePrefix),
 				otelcolresources.DeploymentClusterRoleName(r.OTelCollectorNamePrefix),
 			})).
 		Watches(
 			&rbacv1.ClusterRoleBinding{},
 			&handler.EnqueueRequestForObject{},
-			r.withNamePredicate([]string{
+			r.withClusterScopedNamePredicate([]string{
+				otelcolresources.DaemonSetClusterRoleBindingName(r.OTelCollectorNamePrefix),
 				otelcolresources.DeploymentClusterRoleBindingName(r.OTelCollectorNamePrefix),
-				otelcolresources.DeploymentClusterRoleName(r.OTelCollectorNamePrefix),
 			})).
+		Watches(
+			&rbacv1.Role{},
+			&handler.EnqueueRequestForObject{},
+			r.withNamePredicate(r.managedResourceNamesOfKind("Role"))).
+		Watches(
+			&rbacv1.RoleBinding{},
+			&handler.EnqueueRequestForObject{},
+			r.withNamePredicate(r.managedResourceNamesOfKind("RoleBinding"))).
 		Watches(
 			&corev1.Service{},
 			&handler.EnqueueRequestForObject{},
@@ -68,24 +85,60 @@ func (r *BackendConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&appsv1.DaemonSet{},
 			&handler.EnqueueRequestForObject{},
-			r.withNamePredicate([]string{
-				otelcolresources.DaemonSetName(r.OTelCollectorNamePrefix),
-			})).
+			r.withNamePredicate(r.managedResourceNamesOfKind("DaemonSet"))).
 		Watches(
 			&appsv1.Deployment{},
 			&handler.EnqueueRequestForObject{},
-			r.withNamePredicate([]string{
-				otelcolresources.DeploymentName(r.OTelCollectorNamePrefix),
-			})).
-		Complete(r)
+			r.withNamePredicate(r.managedResourceNamesOfKind("Deployment"))).
+		Complete(r); err != nil {
+		return err
+	}
+	if r.ResyncInterval > 0 {
+		return mgr.Add(manager.RunnableFunc(r.resyncPeriodically))
+	}
+	return nil
+}
+
+func (r *BackendConnectionReconciler) configMapNamesToWatch() []string {
+	names := []string{
+		otelcolresources.DaemonSetCollectorConfigConfigMapName(r.OTelCollectorNamePrefix),
+		otelcolresources.DeploymentCollectorConfigConfigMapName(r.OTelCollectorNamePrefix),
+		// Note: We are deliberately not watching the filelog receiver offsets ConfigMap, since it is updated
+		// frequently by the filelog offset synch container and does not require reconciliation.
+	}
+	// The user-provided config maps with a collector configuration snippet or with collector configuration templates
+	// are not managed by the operator, but changing them needs to update the collector configuration.
+	resourceSpecs := r.BackendConnectionManager.OTelColResourceManager.OTelColResourceSpecs
+	for _, userProvidedConfigMapName := range []string{
+		resourceSpecs.CollectorConfigSnippetConfigMapName,
+		resourceSpecs.CollectorConfigTemplateConfigMapName,
+	} {
+		if userProvidedConfigMapName != "" {
+			names = append(names, userProvidedConfigMapName)
+		}
+	}
+	return names
+}
+
+func (r *BackendConnectionReconciler) managedResourceNamesOfKind(kind string) []string {
+	var names []string
+	for _, managedResource := range otelcolresources.ManagedResourceNames(r.OTelCollectorNamePrefix) {
+		if managedResource.Kind == kind {
+			names = append(names, managedResource.Name)
+		}
+	}
+	return names
 }
 
 func (r *BackendConnectionReconciler) withNamePredicate(resourceNames []string) builder.Predicates {
-	return builder.WithPredicates(r.createFilterPredicate(resourceNames))
+	return builder.WithPredicates(createFilterPredicate(r.OperatorNamespace, resourceNames))
+}
+
+func (r *BackendConnectionReconciler) withClusterScopedNamePredicate(resourceNames []string) builder.Predicates {
+	return builder.WithPredicates(createFilterPredicate("", resourceNames))
 }
 
-func (r *BackendConnectionReconciler) createFilterPredicate(resourceNames []string) predicate.Funcs {
-	resourceNamespace := r.OperatorNamespace
+func createFilterPredicate(resourceNamespace string, resourceNames []string) predicate.Funcs {
 	return predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
 			return resourceMatches(e.Object, resourceNamespace, resourceNames)
@@ -110,6 +163,23 @@ func resourceMatches(object client.Object, resourceNamespace string, resourceNam
 	return slices.Contains(resourceNames, object.GetName())
 }
 
+// resyncPeriodically reconciles the collector resources every ResyncInterval until the context is cancelled.
+func (r *BackendConnectionReconciler) resyncPeriodically(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	ticker := time.NewTicker(r.ResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := r.Reconcile(ctx, reconcile.Request{}); err != nil {
+				logger.Error(err, "The periodic resync of the backend connection resources has failed.")
+			}
+		}
+	}
+}
+
 func (r *BackendConnectionReconciler) Reconcile(
 	ctx context.Context,
 	request reconcile.Request,
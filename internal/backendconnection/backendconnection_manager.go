@@ -5,6 +5,7 @@ package backendconnection
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 
@@ -33,8 +34,17 @@ type BackendConnectionReconcileTrigger string
 const (
 	TriggeredByWatchEvent         BackendConnectionReconcileTrigger = "watch"
 	TriggeredByMonitoringResource BackendConnectionReconcileTrigger = "resource"
+	// TriggeredByOperatorConfiguration is used when the operator configuration resource has changed, e.g. its export
+	// settings. In contrast to the other triggers, a reconciliation request with this trigger is not skipped silently
+	// when another update is in progress, but fails with ErrCollectorUpdateInProgress, so that the caller can retry.
+	TriggeredByOperatorConfiguration BackendConnectionReconcileTrigger = "operatorconfiguration"
 )
 
+// ErrCollectorUpdateInProgress is returned for reconciliation requests triggered by the operator configuration resource
+// while another creation/update of the OpenTelemetry collector resources is in progress.
+var ErrCollectorUpdateInProgress = errors.New(
+	"the creation/update of the OpenTelemetry collector resources is already in progress")
+
 func (m *BackendConnectionManager) ReconcileOpenTelemetryCollector(
 	ctx context.Context,
 	images util.Images,
@@ -44,7 +54,7 @@ func (m *BackendConnectionManager) ReconcileOpenTelemetryCollector(
 ) error {
 	logger := log.FromContext(ctx)
 	if m.resourcesHaveBeenDeletedByOperator.Load() {
-		if trigger == TriggeredByWatchEvent {
+		if trigger == TriggeredByWatchEvent || trigger == TriggeredByOperatorConfiguration {
 			if m.DevelopmentMode {
 				logger.Info("OpenTelemetry collector resources have already been deleted, ignoring reconciliation request.")
 			}
@@ -57,6 +67,9 @@ func (m *BackendConnectionManager) ReconcileOpenTelemetryCollector(
 		}
 	}
 	if m.updateInProgress.Load() {
+		if trigger == TriggeredByOperatorConfiguration {
+			return ErrCollectorUpdateInProgress
+		}
 		if m.DevelopmentMode {
 			logger.Info("creation/update of the OpenTelemetry collector resources is already in progress, skipping " +
 				"additional reconciliation request.")
@@ -86,6 +99,7 @@ func (m *BackendConnectionManager) ReconcileOpenTelemetryCollector(
 			monitoringResource,
 			&logger,
 		)
+	m.updateCollectorConfigurationValidCondition(ctx, err, &logger)
 
 	if err != nil {
 		logger.Error(
@@ -104,6 +118,86 @@ func (m *BackendConnectionManager) ReconcileOpenTelemetryCollector(
 	return nil
 }
 
+// ReconcileOpenTelemetryCollectorForOperatorConfiguration updates the OpenTelemetry collector resources after the
+// operator configuration resource has changed, for example when its export endpoint has been modified. Updating the
+// collector config map makes the collector reload its configuration. If there are no available monitoring resources,
+// there are no collector resources to update, and the call is a no-op.
+func (m *BackendConnectionManager) ReconcileOpenTelemetryCollectorForOperatorConfiguration(
+	ctx context.Context,
+	images util.Images,
+	operatorNamespace string,
+) error {
+	logger := log.FromContext(ctx)
+	allMonitoringResources, err := m.findAllMonitoringResources(ctx, &logger)
+	if err != nil {
+		return err
+	}
+	if len(allMonitoringResources) == 0 {
+		return nil
+	}
+	return m.ReconcileOpenTelemetryCollector(
+		ctx,
+		images,
+		operatorNamespace,
+		&allMonitoringResources[0],
+		TriggeredByOperatorConfiguration,
+	)
+}
+
+// updateCollectorConfigurationValidCondition records on the operator configuration resource (if there is one) whether
+// the rendered collector configuration has been valid. Errors other than an invalid collector configuration leave the
+// condition unchanged, since they do not tell whether the configuration is valid. Failing to update the condition is
+// only logged, the outcome of the reconciliation is reported by its error anyway.
+func (m *BackendConnectionManager) updateCollectorConfigurationValidCondition(
+	ctx context.Context,
+	reconcileErr error,
+	logger *logr.Logger,
+) {
+	var invalidConfigurationErr *otelcolresources.InvalidCollectorConfigurationError
+	isInvalidConfiguration := errors.As(reconcileErr, &invalidConfigurationErr)
+	if reconcileErr != nil && !isInvalidConfiguration {
+		return
+	}
+	resource, err := util.FindUniqueOrMostRecentResourceInScope(
+		ctx,
+		m.Client,
+		"", /* cluster-scope, thus no namespace */
+		&dash0v1alpha1.Dash0OperatorConfiguration{},
+		logger,
+	)
+	if err != nil || resource == nil {
+		return
+	}
+	operatorConfigurationResource := resource.(*dash0v1alpha1.Dash0OperatorConfiguration)
+	if util.IsReconciliationPaused(&operatorConfigurationResource.ObjectMeta) {
+		// The collector resources have not been reconciled at all.
+		return
+	}
+
+	status := metav1.ConditionTrue
+	reason := "CollectorConfigurationValid"
+	message := "The OpenTelemetry collector configuration is valid."
+	if isInvalidConfiguration {
+		status = metav1.ConditionFalse
+		reason = "InvalidCollectorConfiguration"
+		message = fmt.Sprintf(
+			"The OpenTelemetry collector configuration is invalid and has not been applied: %v",
+			invalidConfigurationErr,
+		)
+	}
+	if !operatorConfigurationResource.SetHealthCondition(
+		dash0v1alpha1.ConditionTypeCollectorConfigurationValid,
+		status,
+		reason,
+		message,
+	) {
+		return
+	}
+	if err = m.Client.Status().Update(ctx, operatorConfigurationResource); err != nil {
+		logger.Error(err, "Failed to update the collector configuration condition of the Dash0 operator configuration resource.")
+	}
+}
+
 func (m *BackendConnectionManager) RemoveOpenTelemetryCollectorIfNoMonitoringResourceIsLeft(
 	ctx context.Context,
 	operatorNamespace string,
@@ -7,6 +7,7 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -259,6 +260,163 @@ var _ = Describe("The backend connection manager", Ordered, func() {
 		})
 	})
 
+	Describe("when the collector configuration is invalid", func() {
+
+		BeforeEach(func() {
+			resource := EnsureMonitoringResourceExistsAndIsAvailable(
+				ctx,
+				k8sClient,
+			)
+			createdObjects = append(createdObjects, resource)
+			CreateDefaultOperatorConfigurationResource(
+				ctx,
+				k8sClient,
+			)
+			resourceSpecs := otelcolresources.DefaultOTelColResourceSpecs
+			resourceSpecs.CollectorConfigSnippetConfigMapName = "collector-config-snippet"
+			manager.OTelColResourceSpecs = &resourceSpecs
+		})
+
+		AfterEach(func() {
+			err := manager.OTelColResourceManager.DeleteResources(
+				ctx,
+				operatorNamespace,
+				&logger,
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			DeleteOperatorConfigurationResource(ctx, k8sClient)
+		})
+
+		It("should not apply the configuration and record the problem as a status condition", func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "collector-config-snippet",
+					Namespace: operatorNamespace,
+				},
+				Data: map[string]string{
+					"config.yaml": "service:\n  pipelines:\n    traces/downstream:\n      receivers: [statsd]\n",
+				},
+			})).To(Succeed())
+
+			err := manager.ReconcileOpenTelemetryCollector(
+				ctx,
+				TestImages,
+				operatorNamespace,
+				assembleMonitoringResource(),
+				TriggeredByMonitoringResource,
+			)
+			Expect(err).To(MatchError(ContainSubstring(
+				"pipeline \"traces/downstream\" references the undefined receiver \"statsd\"")))
+			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, operatorNamespace)
+
+			condition := meta.FindStatusCondition(
+				LoadOperatorConfigurationResourceOrFail(ctx, k8sClient, Default).Status.Conditions,
+				string(dash0v1alpha1.ConditionTypeCollectorConfigurationValid),
+			)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("InvalidCollectorConfiguration"))
+			Expect(condition.Message).To(ContainSubstring("undefined receiver \"statsd\""))
+		})
+
+		It("should mark the configuration as valid again once the problem has been fixed", func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "collector-config-snippet",
+					Namespace: operatorNamespace,
+				},
+				Data: map[string]string{
+					"config.yaml": "service:\n  pipelines:\n    traces/downstream:\n      receivers: [statsd]\n",
+				},
+			})).To(Succeed())
+			Expect(manager.ReconcileOpenTelemetryCollector(
+				ctx,
+				TestImages,
+				operatorNamespace,
+				assembleMonitoringResource(),
+				TriggeredByMonitoringResource,
+			)).ToNot(Succeed())
+
+			Expect(k8sClient.Update(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "collector-config-snippet",
+					Namespace: operatorNamespace,
+				},
+				Data: map[string]string{
+					"config.yaml": "receivers:\n  statsd: {}\nservice:\n  pipelines:\n    traces/downstream:\n      receivers: [otlp, statsd]\n",
+				},
+			})).To(Succeed())
+			Expect(manager.ReconcileOpenTelemetryCollector(
+				ctx,
+				TestImages,
+				operatorNamespace,
+				assembleMonitoringResource(),
+				TriggeredByMonitoringResource,
+			)).To(Succeed())
+			VerifyCollectorResources(ctx, k8sClient, operatorNamespace)
+
+			condition := meta.FindStatusCondition(
+				LoadOperatorConfigurationResourceOrFail(ctx, k8sClient, Default).Status.Conditions,
+				string(dash0v1alpha1.ConditionTypeCollectorConfigurationValid),
+			)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		})
+	})
+
+	Describe("when the operator configuration resource has changed", func() {
+		AfterEach(func() {
+			err := manager.OTelColResourceManager.DeleteResources(
+				ctx,
+				operatorNamespace,
+				&logger,
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should do nothing if there are no available monitoring resources", func() {
+			Expect(manager.ReconcileOpenTelemetryCollectorForOperatorConfiguration(
+				ctx,
+				TestImages,
+				operatorNamespace,
+			)).To(Succeed())
+			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, operatorNamespace)
+		})
+
+		It("should create or update the collector resources", func() {
+			resource := EnsureMonitoringResourceExistsAndIsAvailable(
+				ctx,
+				k8sClient,
+			)
+			createdObjects = append(createdObjects, resource)
+
+			Expect(manager.ReconcileOpenTelemetryCollectorForOperatorConfiguration(
+				ctx,
+				TestImages,
+				operatorNamespace,
+			)).To(Succeed())
+			VerifyCollectorResources(ctx, k8sClient, operatorNamespace)
+		})
+
+		It("should fail instead of skipping the request if another update is in progress", func() {
+			resource := EnsureMonitoringResourceExistsAndIsAvailable(
+				ctx,
+				k8sClient,
+			)
+			createdObjects = append(createdObjects, resource)
+
+			manager.updateInProgress.Store(true)
+			defer manager.updateInProgress.Store(false)
+			Expect(manager.ReconcileOpenTelemetryCollectorForOperatorConfiguration(
+				ctx,
+				TestImages,
+				operatorNamespace,
+			)).To(MatchError(ErrCollectorUpdateInProgress))
+			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, operatorNamespace)
+		})
+	})
+
 	Describe("when cleaning up OpenTelemetry collector resources when the resource is deleted", func() {
 		It("should not delete the collector if there are still Dash0 monitoring resources", func() {
 			// create multiple Dash0 monitoring resources
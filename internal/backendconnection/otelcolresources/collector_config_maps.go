@@ -6,11 +6,17 @@ package otelcolresources
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
 	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
 	"github.com/dash0hq/dash0-operator/internal/util"
@@ -18,11 +24,38 @@ import (
 
 type collectorConfigurationTemplateValues struct {
 	Exporters                                        []OtlpExporter
+	ExporterNamesPerSignal                           map[string][]string
+	DatasetRoutes                                    []DatasetRoute
+	DatasetRoutingSignals                            []string
 	IgnoreLogsFromNamespaces                         []string
+	NamespacesWithoutTraceCollection                 []string
+	NamespacesWithoutMetricCollection                []string
+	NamespacesWithoutLogCollection                   []string
+	SpanFilterConditions                             []string
+	LogRecordFilterConditions                        []string
+	TraceTransformStatements                         []TransformStatementGroup
+	MetricTransformStatements                        []TransformStatementGroup
+	LogTransformStatements                           []TransformStatementGroup
+	PodLogCollectionEnabled                          bool
+	GatewayMode                                      bool
+	FilelogReceiverInclude                           []string
+	FilelogReceiverExclude                           []string
+	FilelogReceiverExtraLogRoots                     []string
+	LogCollectionOptOutAnnotation                    string
+	K8sAttributesMetadata                            []string
+	K8sAttributesLabels                              []K8sAttributesFieldExtraction
+	K8sAttributesAnnotations                         []K8sAttributesFieldExtraction
+	KubeletStatsReceiver                             KubeletStatsReceiverSettings
 	KubernetesInfrastructureMetricsCollectionEnabled bool
+	KubernetesEventsCollectionEnabled                bool
+	ClusterName                                      string
+	ClusterNameEnvVarName                            string
 	NamespacesWithPrometheusScraping                 []string
 	SelfIpReference                                  string
+	CollectorLogLevel                                string
 	DevelopmentMode                                  bool
+	DebugFileExport                                  bool
+	PersistentSendingQueue                           bool
 }
 
 type OtlpExporter struct {
@@ -31,8 +64,58 @@ type OtlpExporter struct {
 	Headers  []dash0v1alpha1.Header
 	Encoding string
 	Insecure bool
+	// PersistentSendingQueue lets the exporter store its sending queue in the file_storage/sending_queue extension.
+	PersistentSendingQueue bool
 }
 
+// DatasetRoute describes a group of namespaces whose telemetry is sent to a Dash0 dataset other than the dataset from
+// the export settings, because their Dash0Monitoring resources set spec.dataset. Telemetry from these namespaces is
+// routed to a dedicated pipeline, which uses a copy of the Dash0 exporter with a different dataset header. All other
+// exporters are used unchanged.
+type DatasetRoute struct {
+	Name                   string
+	Condition              string
+	Dash0Exporter          OtlpExporter
+	ExporterNamesPerSignal map[string][]string
+}
+
+// TransformStatementGroup holds the statements from spec.transform of one Dash0Monitoring resource for one signal.
+// The transform processor only applies them to telemetry from the namespace of the Dash0Monitoring resource.
+type TransformStatementGroup struct {
+	Namespace  string
+	Statements []string
+}
+
+var defaultK8sAttributesMetadata = []string{
+	"k8s.namespace.name",
+	"k8s.deployment.name",
+	"k8s.statefulset.name",
+	"k8s.daemonset.name",
+	"k8s.cronjob.name",
+	"k8s.job.name",
+	"k8s.node.name",
+	"k8s.pod.name",
+	"k8s.pod.uid",
+	"k8s.pod.start_time",
+}
+
+const (
+	dash0ExporterName = "otlp/dash0"
+
+	podLogFilesGlob                      = "*/*/*.log"
+	defaultFilelogReceiverInclude        = "/var/log/pods/" + podLogFilesGlob
+	defaultLogCollectionOptOutAnnotation = "dash0.com/log-collection"
+
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+
+	// the keys of the user-provided collector configuration templates in the config map referenced by
+	// collectorConfigTemplateConfigMapName
+	daemonSetCollectorConfigurationTemplateKey  = "daemonset.config.yaml.template"
+	deploymentCollectorConfigurationTemplateKey = "deployment.config.yaml.template"
+)
+
 var (
 	//go:embed daemonset.config.yaml.template
 	daemonSetCollectorConfigurationTemplateSource string
@@ -55,8 +138,11 @@ func assembleDaemonSetCollectorConfigMap(
 	return assembleCollectorConfigMap(
 		config,
 		namespacesWithPrometheusScraping,
+		[]string{signalTraces, signalMetrics, signalLogs},
 		daemonSetCollectorConfigurationTemplate,
+		config.DaemonSetCollectorConfigTemplate,
 		DaemonSetCollectorConfigConfigMapName(config.NamePrefix),
+		config.CollectorConfigSnippet,
 		forDeletion,
 	)
 }
@@ -65,8 +151,11 @@ func assembleDeploymentCollectorConfigMap(config *oTelColConfig, forDeletion boo
 	return assembleCollectorConfigMap(
 		config,
 		nil,
+		[]string{signalMetrics},
 		deploymentCollectorConfigurationTemplate,
+		config.DeploymentCollectorConfigTemplate,
 		DeploymentCollectorConfigConfigMapName(config.NamePrefix),
+		"",
 		forDeletion,
 	)
 }
@@ -74,47 +163,225 @@ func assembleDeploymentCollectorConfigMap(config *oTelColConfig, forDeletion boo
 func assembleCollectorConfigMap(
 	config *oTelColConfig,
 	namespacesWithPrometheusScraping []string,
-	template *template.Template,
+	signals []string,
+	builtInTemplate *template.Template,
+	customTemplateSource string,
 	configMapName string,
+	configSnippet string,
 	forDeletion bool,
 ) (*corev1.ConfigMap, error) {
 	var configMapData map[string]string
 	if forDeletion {
 		configMapData = map[string]string{}
 	} else {
-		exporters, err := ConvertExportSettingsToExporterList(config.Export)
-		if err != nil {
-			return nil, fmt.Errorf("cannot assemble the exporters for the configuration: %w", err)
-		}
-
-		selfIpReference := "${env:MY_POD_IP}"
-		if config.IsIPv6Cluster {
-			selfIpReference = "[${env:MY_POD_IP}]"
-		}
-		collectorConfiguration, err := renderCollectorConfiguration(template,
-			&collectorConfigurationTemplateValues{
-				Exporters: exporters,
-				IgnoreLogsFromNamespaces: []string{
-					// Skipping kube-system, it requires bespoke filtering work
-					"kube-system",
-					// Skipping logs from the operator and the daemonset, otherwise
-					// logs will compound in case of log parsing errors
-					config.Namespace,
-				},
-				KubernetesInfrastructureMetricsCollectionEnabled: config.KubernetesInfrastructureMetricsCollectionEnabled,
-				NamespacesWithPrometheusScraping:                 namespacesWithPrometheusScraping,
-				SelfIpReference:                                  selfIpReference,
-				DevelopmentMode:                                  config.DevelopmentMode,
-			})
+		collectorConfigurationTemplate := builtInTemplate
+		if customTemplateSource != "" {
+			customTemplate, err := template.New(configMapName).Parse(customTemplateSource)
+			if err != nil {
+				return nil, &InvalidCollectorConfigurationError{
+					ConfigMapName: configMapName,
+					Err:           fmt.Errorf("cannot parse the custom collector configuration template: %w", err),
+				}
+			}
+			collectorConfigurationTemplate = customTemplate
+		}
+		collectorConfiguration, err := renderCollectorConfigurationForSignals(
+			config,
+			namespacesWithPrometheusScraping,
+			signals,
+			collectorConfigurationTemplate,
+		)
+		var templateExecutionErr template.ExecError
+		if customTemplateSource != "" && errors.As(err, &templateExecutionErr) {
+			// The custom template refers to values that do not exist or uses them incorrectly.
+			return nil, &InvalidCollectorConfigurationError{ConfigMapName: configMapName, Err: err}
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot render the collector configuration template: %w", err)
+			return nil, err
+		}
+		if configSnippet != "" {
+			collectorConfiguration, err = mergeCollectorConfigSnippet(collectorConfiguration, configSnippet)
+			if err != nil {
+				return nil, &InvalidCollectorConfigurationError{ConfigMapName: configMapName, Err: err}
+			}
+		}
+		if err = validateCollectorConfiguration(collectorConfiguration); err != nil {
+			return nil, &InvalidCollectorConfigurationError{ConfigMapName: configMapName, Err: err}
 		}
-
 		configMapData = map[string]string{
 			collectorConfigurationYaml: collectorConfiguration,
 		}
 	}
+	return newCollectorConfigMap(config, configMapName, configMapData), nil
+}
+
+// assembleCollectorConfigExportConfigMap assembles the optional config map that holds a copy of the configuration of
+// the daemonset collector (or the gateway deployment in gateway mode), for other tools to read it. The operator only
+// writes this config map when it updates the collector resources, it does not watch it for changes.
+func assembleCollectorConfigExportConfigMap(config *oTelColConfig, daemonSetCollectorConfigMap *corev1.ConfigMap) *corev1.ConfigMap {
+	return newCollectorConfigMap(config, config.CollectorConfigExportConfigMapName, maps.Clone(daemonSetCollectorConfigMap.Data))
+}
+
+// renderCollectorConfigurationForSignals renders the collector configuration for the given signals from the given
+// template.
+func renderCollectorConfigurationForSignals(
+	config *oTelColConfig,
+	namespacesWithPrometheusScraping []string,
+	signals []string,
+	template *template.Template,
+) (string, error) {
+	exporters, exporterNamesPerSignal, err := convertExportSettingsToExportersPerSignal(config.Export)
+	if err != nil {
+		return "", fmt.Errorf("cannot assemble the exporters for the configuration: %w", err)
+	}
+	for i := range exporters {
+		exporters[i].PersistentSendingQueue = config.PersistentSendingQueue.Enabled
+	}
+	datasetRoutes :=
+		computeDatasetRoutes(config.Export, exporters, exporterNamesPerSignal, config.DatasetsPerNamespace)
+	var datasetRoutingSignals []string
+	if len(datasetRoutes) > 0 {
+		datasetRoutingSignals = signals
+	}
+
+	filelogReceiverInclude := config.FilelogReceiverPaths.Include
+	if len(filelogReceiverInclude) == 0 {
+		filelogReceiverInclude = []string{defaultFilelogReceiverInclude}
+	}
+	extraLogRoots := extraLogHostPaths(config.FilelogReceiverPaths)
+	for _, extraLogRoot := range extraLogRoots {
+		filelogReceiverInclude = append(filelogReceiverInclude, filepath.Join(extraLogRoot, podLogFilesGlob))
+	}
+	logCollectionOptOutAnnotation := config.FilelogReceiverPaths.OptOutAnnotation
+	if logCollectionOptOutAnnotation == "" {
+		logCollectionOptOutAnnotation = defaultLogCollectionOptOutAnnotation
+	}
+
+	k8sAttributesMetadata := slices.Clone(defaultK8sAttributesMetadata)
+	for _, metadata := range config.K8sAttributesProcessorSettings.ExtraMetadata {
+		if !slices.Contains(k8sAttributesMetadata, metadata) {
+			k8sAttributesMetadata = append(k8sAttributesMetadata, metadata)
+		}
+	}
+
+	namespacesWithoutTraceCollection := config.NamespacesWithoutSignalCollection[dash0v1alpha1.TelemetrySignalTraces]
+	namespacesWithoutMetricCollection := config.NamespacesWithoutSignalCollection[dash0v1alpha1.TelemetrySignalMetrics]
+	namespacesWithoutLogCollection := config.NamespacesWithoutSignalCollection[dash0v1alpha1.TelemetrySignalLogs]
+
+	spanFilterConditions, logRecordFilterConditions := computeFilterConditions(config.FiltersPerNamespace)
+	traceTransformStatements, metricTransformStatements, logTransformStatements :=
+		computeTransformStatements(config.TransformsPerNamespace)
+
+	kubeletStatsReceiver := config.KubeletStatsReceiverSettings
+	if kubeletStatsReceiver.AuthType == "" {
+		kubeletStatsReceiver.AuthType = KubeletStatsAuthTypeServiceAccount
+	}
+	if config.DevelopmentMode {
+		// On Docker Desktop, Kind, etc. the kubelet uses a self-signed certificate.
+		kubeletStatsReceiver.InsecureSkipVerify = true
+	}
+
+	collectorLogLevel := config.CollectorLogLevel
+	if collectorLogLevel == "" {
+		collectorLogLevel = CollectorLogLevelInfo
+	}
+
+	// Kubernetes events are exported as logs, the pipeline for them would be invalid without any log exporter.
+	kubernetesEventsCollectionEnabled :=
+		config.KubernetesEventsCollectionEnabled && len(exporterNamesPerSignal[signalLogs]) > 0
+
+	selfIpReference := "${env:MY_POD_IP}"
+	if config.IsIPv6Cluster {
+		selfIpReference = "[${env:MY_POD_IP}]"
+	}
+	collectorConfiguration, err := renderCollectorConfiguration(template,
+		&collectorConfigurationTemplateValues{
+			Exporters:              exporters,
+			ExporterNamesPerSignal: exporterNamesPerSignal,
+			DatasetRoutes:          datasetRoutes,
+			DatasetRoutingSignals:  datasetRoutingSignals,
+			IgnoreLogsFromNamespaces: append([]string{
+				// Skipping kube-system, it requires bespoke filtering work
+				"kube-system",
+				// Skipping logs from the operator and the daemonset, otherwise
+				// logs will compound in case of log parsing errors
+				config.Namespace,
+			}, namespacesWithoutLogCollection...),
+			NamespacesWithoutTraceCollection:                 namespacesWithoutTraceCollection,
+			NamespacesWithoutMetricCollection:                namespacesWithoutMetricCollection,
+			NamespacesWithoutLogCollection:                   namespacesWithoutLogCollection,
+			SpanFilterConditions:                             spanFilterConditions,
+			LogRecordFilterConditions:                        logRecordFilterConditions,
+			TraceTransformStatements:                         traceTransformStatements,
+			MetricTransformStatements:                        metricTransformStatements,
+			LogTransformStatements:                           logTransformStatements,
+			PodLogCollectionEnabled:                          config.collectsPodLogs(),
+			GatewayMode:                                      config.GatewayMode,
+			FilelogReceiverInclude:                           filelogReceiverInclude,
+			FilelogReceiverExclude:                           config.FilelogReceiverPaths.Exclude,
+			FilelogReceiverExtraLogRoots:                     extraLogRoots,
+			LogCollectionOptOutAnnotation:                    logCollectionOptOutAnnotation,
+			K8sAttributesMetadata:                            k8sAttributesMetadata,
+			K8sAttributesLabels:                              k8sAttributesFieldExtractions(config.K8sAttributesProcessorSettings.Labels),
+			K8sAttributesAnnotations:                         k8sAttributesFieldExtractions(config.K8sAttributesProcessorSettings.Annotations),
+			KubeletStatsReceiver:                             kubeletStatsReceiver,
+			KubernetesInfrastructureMetricsCollectionEnabled: config.KubernetesInfrastructureMetricsCollectionEnabled,
+			KubernetesEventsCollectionEnabled:                kubernetesEventsCollectionEnabled,
+			ClusterName:                                      config.ClusterName,
+			ClusterNameEnvVarName:                            clusterNameEnvVarName,
+			NamespacesWithPrometheusScraping:                 namespacesWithPrometheusScraping,
+			SelfIpReference:                                  selfIpReference,
+			CollectorLogLevel:                                collectorLogLevel,
+			DevelopmentMode:                                  config.DevelopmentMode,
+			DebugFileExport:                                  config.DebugFileExport,
+			PersistentSendingQueue:                           config.PersistentSendingQueue.Enabled,
+		})
+	if err != nil {
+		return "", fmt.Errorf("cannot render the collector configuration template: %w", err)
+	}
+	return collectorConfiguration, nil
+}
+
+// mergeCollectorConfigSnippet deep-merges a user-provided snippet into the rendered collector configuration. Maps are
+// merged recursively, all other values from the snippet (including lists, e.g. the receivers of a pipeline) replace the
+// corresponding values of the rendered configuration.
+func mergeCollectorConfigSnippet(collectorConfiguration string, configSnippet string) (string, error) {
+	var renderedConfiguration map[string]interface{}
+	if err := yaml.Unmarshal([]byte(collectorConfiguration), &renderedConfiguration); err != nil {
+		return "", fmt.Errorf("cannot parse the rendered collector configuration: %w", err)
+	}
+	var snippet map[string]interface{}
+	if err := yaml.Unmarshal([]byte(configSnippet), &snippet); err != nil {
+		return "", fmt.Errorf("cannot parse the collector configuration snippet, it needs to be a YAML map: %w", err)
+	}
+	mergedConfiguration, err := yaml.Marshal(deepMerge(renderedConfiguration, snippet))
+	if err != nil {
+		return "", fmt.Errorf("cannot serialize the collector configuration merged with the snippet: %w", err)
+	}
+	var parsedMergedConfiguration map[string]interface{}
+	if err = yaml.Unmarshal(mergedConfiguration, &parsedMergedConfiguration); err != nil {
+		return "", fmt.Errorf("the collector configuration merged with the snippet cannot be parsed: %w", err)
+	}
+	return string(mergedConfiguration), nil
+}
+
+func deepMerge(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	if base == nil {
+		base = make(map[string]interface{}, len(overrides))
+	}
+	for key, overrideValue := range overrides {
+		baseMap, baseIsMap := base[key].(map[string]interface{})
+		overrideMap, overrideIsMap := overrideValue.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			base[key] = deepMerge(baseMap, overrideMap)
+		} else {
+			base[key] = overrideValue
+		}
+	}
+	return base
+}
 
+func newCollectorConfigMap(config *oTelColConfig, configMapName string, configMapData map[string]string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ConfigMap",
@@ -126,7 +393,178 @@ func assembleCollectorConfigMap(
 			Labels:    labels(false),
 		},
 		Data: configMapData,
-	}, nil
+	}
+}
+
+// computeFilterConditions converts the filters from the Dash0Monitoring resources into conditions for the filter
+// processor. Each condition is restricted to the namespace of the Dash0Monitoring resource it stems from, and it is
+// escaped to be rendered as a single-quoted YAML string. Conditions that do not pass util.CheckOttlCondition are left
+// out; the validation webhook rejects them, but monitoring resources that have been created before the check existed
+// could still contain them, and a single malformed condition would break the collectors for all namespaces.
+func computeFilterConditions(filtersPerNamespace map[string]dash0v1alpha1.Filter) ([]string, []string) {
+	var spanFilterConditions []string
+	var logRecordFilterConditions []string
+	for _, namespace := range slices.Sorted(maps.Keys(filtersPerNamespace)) {
+		filter := filtersPerNamespace[namespace]
+		if filter.Traces != nil {
+			for _, condition := range filter.Traces.SpanFilter {
+				if util.CheckOttlCondition(condition) != nil {
+					continue
+				}
+				spanFilterConditions =
+					append(spanFilterConditions, restrictFilterConditionToNamespace(condition, namespace))
+			}
+		}
+		if filter.Logs != nil {
+			for _, condition := range filter.Logs.LogRecordFilter {
+				if util.CheckOttlCondition(condition) != nil {
+					continue
+				}
+				logRecordFilterConditions =
+					append(logRecordFilterConditions, restrictFilterConditionToNamespace(condition, namespace))
+			}
+		}
+	}
+	return spanFilterConditions, logRecordFilterConditions
+}
+
+// restrictFilterConditionToNamespace combines the namespace check and the user-provided condition with "and". The
+// user-provided condition is put in parentheses, since it has passed util.CheckOttlCondition, its own parentheses are
+// balanced and it cannot escape from them to weaken the namespace check.
+func restrictFilterConditionToNamespace(condition string, namespace string) string {
+	return escapeForSingleQuotedYamlString(
+		fmt.Sprintf("resource.attributes[\"k8s.namespace.name\"] == \"%s\" and (%s)", namespace, condition),
+	)
+}
+
+// computeTransformStatements converts the transforms from the Dash0Monitoring resources into statement groups for the
+// transform processor, one group per namespace and signal. The statements are escaped to be rendered as single-quoted
+// YAML strings. Statements that do not pass util.CheckOttlStatement are left out, for the same reason as in
+// computeFilterConditions.
+func computeTransformStatements(
+	transformsPerNamespace map[string]dash0v1alpha1.Transform,
+) ([]TransformStatementGroup, []TransformStatementGroup, []TransformStatementGroup) {
+	var traceStatements []TransformStatementGroup
+	var metricStatements []TransformStatementGroup
+	var logStatements []TransformStatementGroup
+	for _, namespace := range slices.Sorted(maps.Keys(transformsPerNamespace)) {
+		transform := transformsPerNamespace[namespace]
+		traceStatements = appendTransformStatementGroup(traceStatements, namespace, transform.Traces)
+		metricStatements = appendTransformStatementGroup(metricStatements, namespace, transform.Metrics)
+		logStatements = appendTransformStatementGroup(logStatements, namespace, transform.Logs)
+	}
+	return traceStatements, metricStatements, logStatements
+}
+
+func appendTransformStatementGroup(
+	groups []TransformStatementGroup,
+	namespace string,
+	statements []string,
+) []TransformStatementGroup {
+	if len(statements) == 0 {
+		return groups
+	}
+	escapedStatements := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		if util.CheckOttlStatement(statement) != nil {
+			continue
+		}
+		escapedStatements = append(escapedStatements, escapeForSingleQuotedYamlString(statement))
+	}
+	if len(escapedStatements) == 0 {
+		return groups
+	}
+	return append(groups, TransformStatementGroup{
+		Namespace:  namespace,
+		Statements: escapedStatements,
+	})
+}
+
+func escapeForSingleQuotedYamlString(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// computeDatasetRoutes groups the namespaces that have a dataset different from the dataset of the Dash0 export
+// settings by dataset, and returns one route per dataset. No routes are returned if there is no Dash0 export.
+func computeDatasetRoutes(
+	export dash0v1alpha1.Export,
+	exporters []OtlpExporter,
+	exporterNamesPerSignal map[string][]string,
+	datasetsPerNamespace map[string]string,
+) []DatasetRoute {
+	if export.Dash0 == nil || len(datasetsPerNamespace) == 0 {
+		return nil
+	}
+	var baseDash0Exporter *OtlpExporter
+	for i := range exporters {
+		if exporters[i].Name == dash0ExporterName {
+			baseDash0Exporter = &exporters[i]
+		}
+	}
+	if baseDash0Exporter == nil {
+		return nil
+	}
+	defaultDataset := export.Dash0.Dataset
+	if defaultDataset == "" {
+		defaultDataset = util.DatasetDefault
+	}
+
+	namespacesPerDataset := make(map[string][]string)
+	for namespace, dataset := range datasetsPerNamespace {
+		if dataset == "" || dataset == defaultDataset {
+			continue
+		}
+		namespacesPerDataset[dataset] = append(namespacesPerDataset[dataset], namespace)
+	}
+	datasets := slices.Sorted(maps.Keys(namespacesPerDataset))
+
+	routes := make([]DatasetRoute, 0, len(datasets))
+	for i, dataset := range datasets {
+		namespaces := namespacesPerDataset[dataset]
+		slices.Sort(namespaces)
+		conditions := make([]string, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			conditions = append(conditions, fmt.Sprintf("resource.attributes[\"k8s.namespace.name\"] == \"%s\"", namespace))
+		}
+
+		routeName := fmt.Sprintf("dataset-%d", i)
+		headers := make([]dash0v1alpha1.Header, 0, len(baseDash0Exporter.Headers)+1)
+		for _, header := range baseDash0Exporter.Headers {
+			if header.Name != util.Dash0DatasetHeaderName {
+				headers = append(headers, header)
+			}
+		}
+		if dataset != util.DatasetDefault {
+			headers = append(headers, dash0v1alpha1.Header{
+				Name:  util.Dash0DatasetHeaderName,
+				Value: dataset,
+			})
+		}
+		routeDash0Exporter := *baseDash0Exporter
+		routeDash0Exporter.Name = fmt.Sprintf("%s-%s", dash0ExporterName, routeName)
+		routeDash0Exporter.Headers = headers
+
+		routeExporterNamesPerSignal := make(map[string][]string, len(exporterNamesPerSignal))
+		for signal, exporterNames := range exporterNamesPerSignal {
+			routeExporterNames := make([]string, 0, len(exporterNames))
+			for _, exporterName := range exporterNames {
+				if exporterName == dash0ExporterName {
+					routeExporterNames = append(routeExporterNames, routeDash0Exporter.Name)
+				} else {
+					routeExporterNames = append(routeExporterNames, exporterName)
+				}
+			}
+			routeExporterNamesPerSignal[signal] = routeExporterNames
+		}
+
+		routes = append(routes, DatasetRoute{
+			Name:                   routeName,
+			Condition:              strings.Join(conditions, " or "),
+			Dash0Exporter:          routeDash0Exporter,
+			ExporterNamesPerSignal: routeExporterNamesPerSignal,
+		})
+	}
+	return routes
 }
 
 func ConvertExportSettingsToExporterList(export dash0v1alpha1.Export) ([]OtlpExporter, error) {
@@ -152,7 +590,7 @@ func ConvertExportSettingsToExporterList(export dash0v1alpha1.Export) ([]OtlpExp
 			})
 		}
 		dash0Exporter := OtlpExporter{
-			Name:     "otlp/dash0",
+			Name:     dash0ExporterName,
 			Endpoint: export.Dash0.Endpoint,
 			Headers:  headers,
 		}
@@ -161,43 +599,130 @@ func ConvertExportSettingsToExporterList(export dash0v1alpha1.Export) ([]OtlpExp
 	}
 
 	if export.Grpc != nil {
-		grpc := export.Grpc
-		if grpc.Endpoint == "" {
-			return nil, fmt.Errorf("no endpoint provided for the gRPC exporter, unable to create the OpenTelemetry collector")
-		}
-		grpcExporter := OtlpExporter{
-			Name:     "otlp/grpc",
-			Endpoint: grpc.Endpoint,
-			Headers:  grpc.Headers,
-		}
-		setGrpcTls(grpc.Endpoint, &grpcExporter)
-		if len(grpc.Headers) > 0 {
-			grpcExporter.Headers = grpc.Headers
+		grpcExporter, err := convertGrpcExportSettings(export.Grpc, "")
+		if err != nil {
+			return nil, err
 		}
 		exporters = append(exporters, grpcExporter)
 	}
 
 	if export.Http != nil {
-		http := export.Http
-		if http.Endpoint == "" {
-			return nil, fmt.Errorf("no endpoint provided for the HTTP exporter, unable to create the OpenTelemetry collector")
+		httpExporter, err := convertHttpExportSettings(export.Http, "")
+		if err != nil {
+			return nil, err
 		}
-		if http.Encoding == "" {
-			return nil, fmt.Errorf("no encoding provided for the HTTP exporter, unable to create the OpenTelemetry collector")
+		exporters = append(exporters, httpExporter)
+	}
+
+	return exporters, nil
+}
+
+// ValidateExportSettings checks whether the given export settings can be converted to the exporters of the collector
+// configuration, that is, whether the operator would be able to create the OpenTelemetry collector resources for them.
+func ValidateExportSettings(export dash0v1alpha1.Export) error {
+	_, _, err := convertExportSettingsToExportersPerSignal(export)
+	return err
+}
+
+// convertExportSettingsToExportersPerSignal returns all exporters that need to be defined in the collector
+// configuration, together with the names of the exporters to use per signal. Signals without signal-specific export
+// settings use the exporters that are configured directly in the export settings.
+func convertExportSettingsToExportersPerSignal(
+	export dash0v1alpha1.Export,
+) ([]OtlpExporter, map[string][]string, error) {
+	exporters, err := ConvertExportSettingsToExporterList(export)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaultExporterNames := make([]string, 0, len(exporters))
+	for _, exporter := range exporters {
+		defaultExporterNames = append(defaultExporterNames, exporter.Name)
+	}
+
+	exporterNamesPerSignal := make(map[string][]string)
+	for _, signalAndExport := range []struct {
+		signal string
+		export *dash0v1alpha1.SignalExport
+	}{
+		{signal: signalTraces, export: export.Traces},
+		{signal: signalMetrics, export: export.Metrics},
+		{signal: signalLogs, export: export.Logs},
+	} {
+		signal := signalAndExport.signal
+		signalExport := signalAndExport.export
+		if signalExport == nil {
+			exporterNamesPerSignal[signal] = defaultExporterNames
+			continue
 		}
-		encoding := string(http.Encoding)
-		httpExporter := OtlpExporter{
-			Name:     fmt.Sprintf("otlphttp/%s", encoding),
-			Endpoint: http.Endpoint,
-			Encoding: encoding,
+		if signalExport.Grpc == nil && signalExport.Http == nil {
+			return nil, nil, fmt.Errorf("no exporter configuration found for %s", signal)
 		}
-		if len(http.Headers) > 0 {
-			httpExporter.Headers = http.Headers
+		var signalExporterNames []string
+		if signalExport.Grpc != nil {
+			grpcExporter, err := convertGrpcExportSettings(signalExport.Grpc, signal)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid export settings for %s: %w", signal, err)
+			}
+			exporters = append(exporters, grpcExporter)
+			signalExporterNames = append(signalExporterNames, grpcExporter.Name)
 		}
-		exporters = append(exporters, httpExporter)
+		if signalExport.Http != nil {
+			httpExporter, err := convertHttpExportSettings(signalExport.Http, signal)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid export settings for %s: %w", signal, err)
+			}
+			exporters = append(exporters, httpExporter)
+			signalExporterNames = append(signalExporterNames, httpExporter.Name)
+		}
+		exporterNamesPerSignal[signal] = signalExporterNames
 	}
+	return exporters, exporterNamesPerSignal, nil
+}
 
-	return exporters, nil
+// convertGrpcExportSettings converts gRPC export settings to an exporter. For signal-specific export settings, the
+// signal is appended to the exporter name, e.g. "otlp/grpc-logs".
+func convertGrpcExportSettings(grpc *dash0v1alpha1.GrpcConfiguration, signal string) (OtlpExporter, error) {
+	if grpc.Endpoint == "" {
+		return OtlpExporter{}, fmt.Errorf(
+			"no endpoint provided for the gRPC exporter, unable to create the OpenTelemetry collector")
+	}
+	grpcExporter := OtlpExporter{
+		Name:     exporterName("otlp/grpc", signal),
+		Endpoint: grpc.Endpoint,
+		Headers:  grpc.Headers,
+	}
+	setGrpcTls(grpc.Endpoint, &grpcExporter)
+	return grpcExporter, nil
+}
+
+// convertHttpExportSettings converts HTTP export settings to an exporter. For signal-specific export settings, the
+// signal is appended to the exporter name, e.g. "otlphttp/json-logs".
+func convertHttpExportSettings(http *dash0v1alpha1.HttpConfiguration, signal string) (OtlpExporter, error) {
+	if http.Endpoint == "" {
+		return OtlpExporter{}, fmt.Errorf(
+			"no endpoint provided for the HTTP exporter, unable to create the OpenTelemetry collector")
+	}
+	if http.Encoding == "" {
+		return OtlpExporter{}, fmt.Errorf(
+			"no encoding provided for the HTTP exporter, unable to create the OpenTelemetry collector")
+	}
+	encoding := string(http.Encoding)
+	httpExporter := OtlpExporter{
+		Name:     exporterName(fmt.Sprintf("otlphttp/%s", encoding), signal),
+		Endpoint: http.Endpoint,
+		Encoding: encoding,
+	}
+	if len(http.Headers) > 0 {
+		httpExporter.Headers = http.Headers
+	}
+	return httpExporter, nil
+}
+
+func exporterName(baseName string, signal string) string {
+	if signal == "" {
+		return baseName
+	}
+	return fmt.Sprintf("%s-%s", baseName, signal)
 }
 
 func renderCollectorConfiguration(
@@ -216,3 +741,15 @@ func setGrpcTls(endpoint string, exporter *OtlpExporter) {
 		exporter.Insecure = true
 	}
 }
+
+// k8sAttributesFieldExtractions applies the default source (pod) to the given label or annotation extractions.
+func k8sAttributesFieldExtractions(extractions []K8sAttributesFieldExtraction) []K8sAttributesFieldExtraction {
+	result := make([]K8sAttributesFieldExtraction, 0, len(extractions))
+	for _, extraction := range extractions {
+		if extraction.From == "" {
+			extraction.From = K8sAttributesFromPod
+		}
+		result = append(result, extraction)
+	}
+	return result
+}
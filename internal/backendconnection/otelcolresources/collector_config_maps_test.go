@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
@@ -206,6 +207,69 @@ var _ = Describe("The OpenTelemetry Collector ConfigMaps", func() {
 			verifyDownstreamExportersInPipelines(collectorConfig, testConfig, "debug", "otlp/dash0")
 		}, testConfigs)
 
+		DescribeTable("should render file exporters if the debug file export is enabled", func(testConfig testConfig) {
+			configMap, err := testConfig.assembleConfigMapFunction(&oTelColConfig{
+				Namespace:       namespace,
+				NamePrefix:      namePrefix,
+				Export:          Dash0ExportWithEndpointAndToken(),
+				DebugFileExport: true,
+			}, false)
+
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			exporters := collectorConfig["exporters"].(map[string]interface{})
+			Expect(exporters["debug"]).To(BeNil())
+
+			pipelines := readPipelines(collectorConfig)
+			for _, pipelineName := range testConfig.pipelineNames {
+				signal := strings.Split(pipelineName, "/")[0]
+				fileExporterName := fmt.Sprintf("file/%s", signal)
+				fileExporter := exporters[fileExporterName]
+				Expect(fileExporter).ToNot(BeNil())
+				Expect(fileExporter.(map[string]interface{})["path"]).To(
+					Equal(fmt.Sprintf("/var/otelcol/debug/%s.jsonl", signal)))
+
+				pipelineExporters := readPipelineExporters(pipelines, pipelineName)
+				Expect(pipelineExporters).To(HaveLen(2))
+				Expect(pipelineExporters).To(ContainElements(fileExporterName, "otlp/dash0"))
+			}
+		}, testConfigs)
+
+		DescribeTable("should use a file-backed sending queue if the persistent sending queue is enabled", func(testConfig testConfig) {
+			configMap, err := testConfig.assembleConfigMapFunction(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				PersistentSendingQueue: PersistentSendingQueueSettings{
+					Enabled: true,
+				},
+			}, false)
+
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"extensions", "file_storage/sending_queue", "directory"})).To(
+				Equal("/var/otelcol/sending_queue"))
+			Expect(readFromMap(collectorConfig, []string{"service", "extensions"})).To(
+				ContainElement("file_storage/sending_queue"))
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlp/dash0", "sending_queue", "storage"})).To(
+				Equal("file_storage/sending_queue"))
+		}, testConfigs)
+
+		DescribeTable("should not use a file-backed sending queue by default", func(testConfig testConfig) {
+			configMap, err := testConfig.assembleConfigMapFunction(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, false)
+
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"extensions", "file_storage/sending_queue"})).To(BeNil())
+			Expect(readFromMap(collectorConfig, []string{"service", "extensions"})).ToNot(
+				ContainElement("file_storage/sending_queue"))
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlp/dash0", "sending_queue"})).To(BeNil())
+		}, testConfigs)
+
 		DescribeTable("should fail to render a gRPC exporter when no endpoint is provided", func(testConfig testConfig) {
 			_, err := testConfig.assembleConfigMapFunction(&oTelColConfig{
 				Namespace:  namespace,
@@ -608,6 +672,141 @@ var _ = Describe("The OpenTelemetry Collector ConfigMaps", func() {
 		}, testConfigs)
 	})
 
+	Describe("per-signal export settings", func() {
+		It("should send logs to a signal-specific exporter and traces and metrics to the default exporters", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Logs = &dash0v1alpha1.SignalExport{
+				Http: &dash0v1alpha1.HttpConfiguration{
+					Endpoint: HttpEndpointTest,
+					Encoding: dash0v1alpha1.Json,
+				},
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			logsExporter := readFromMap(collectorConfig, []string{"exporters", "otlphttp/json-logs"})
+			Expect(logsExporter).ToNot(BeNil())
+			Expect(logsExporter.(map[string]interface{})["endpoint"]).To(Equal(HttpEndpointTest))
+			Expect(logsExporter.(map[string]interface{})["encoding"]).To(Equal("json"))
+
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "traces/downstream")).To(Equal([]interface{}{"otlp/dash0"}))
+			Expect(readPipelineExporters(pipelines, "metrics/downstream")).To(Equal([]interface{}{"otlp/dash0"}))
+			Expect(readPipelineExporters(pipelines, "logs/downstream")).To(Equal([]interface{}{"otlphttp/json-logs"}))
+		})
+
+		It("should combine a gRPC and an HTTP exporter for one signal", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Traces = &dash0v1alpha1.SignalExport{
+				Grpc: &dash0v1alpha1.GrpcConfiguration{
+					Endpoint: GrpcEndpointTest,
+					Headers: []dash0v1alpha1.Header{{
+						Name:  "Key",
+						Value: "Value",
+					}},
+				},
+				Http: &dash0v1alpha1.HttpConfiguration{
+					Endpoint: HttpEndpointTest,
+					Encoding: dash0v1alpha1.Proto,
+				},
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlp/grpc-traces", "endpoint"})).To(
+				Equal(GrpcEndpointTest))
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlp/grpc-traces", "headers", "Key"})).To(
+				Equal("Value"))
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlphttp/proto-traces", "endpoint"})).To(
+				Equal(HttpEndpointTest))
+
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "traces/downstream")).To(
+				Equal([]interface{}{"otlp/grpc-traces", "otlphttp/proto-traces"}))
+			Expect(readPipelineExporters(pipelines, "logs/downstream")).To(Equal([]interface{}{"otlp/dash0"}))
+		})
+
+		It("should use the signal-specific exporter for metrics in the deployment collector", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Metrics = &dash0v1alpha1.SignalExport{
+				Grpc: &dash0v1alpha1.GrpcConfiguration{
+					Endpoint: GrpcEndpointTest,
+				},
+			}
+			configMap, err := assembleDeploymentCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "metrics/downstream")).To(
+				Equal([]interface{}{"otlp/grpc-metrics"}))
+		})
+
+		It("should fail if signal-specific export settings contain no exporter", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Logs = &dash0v1alpha1.SignalExport{}
+			_, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+			}, false)
+			Expect(err).To(MatchError(ContainSubstring("no exporter configuration found for logs")))
+		})
+
+		It("should fail if a signal-specific exporter has no endpoint", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Traces = &dash0v1alpha1.SignalExport{
+				Grpc: &dash0v1alpha1.GrpcConfiguration{},
+			}
+			_, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+			}, false)
+			Expect(err).To(MatchError(ContainSubstring(
+				"invalid export settings for traces: no endpoint provided for the gRPC exporter")))
+		})
+
+		It("should only replace the Dash0 exporter of signals that use it in dataset routes", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Logs = &dash0v1alpha1.SignalExport{
+				Grpc: &dash0v1alpha1.GrpcConfiguration{
+					Endpoint: GrpcEndpointTest,
+				},
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+				DatasetsPerNamespace: map[string]string{
+					"namespace1": "dataset",
+				},
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "traces/downstream-dataset-0")).To(
+				Equal([]interface{}{"otlp/dash0-dataset-0"}))
+			Expect(readPipelineExporters(pipelines, "logs/downstream-dataset-0")).To(
+				Equal([]interface{}{"otlp/grpc-logs"}))
+			Expect(readPipelineExporters(pipelines, "logs/downstream-default")).To(
+				Equal([]interface{}{"otlp/grpc-logs"}))
+		})
+	})
+
 	Describe("should enable/disable kubernetes infrastructure metrics collection", func() {
 		It("should not render the kubeletstats receiver if kubernetes infrastructure metrics collection is disabled", func() {
 			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
@@ -645,6 +844,549 @@ var _ = Describe("The OpenTelemetry Collector ConfigMaps", func() {
 			metricsReceivers := readPipelineReceivers(pipelines, "metrics/downstream")
 			Expect(metricsReceivers).To(ContainElement("kubeletstats"))
 		})
+
+		It("should render the configured kubeletstats receiver authentication settings", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				KubernetesInfrastructureMetricsCollectionEnabled: true,
+				KubeletStatsReceiverSettings: KubeletStatsReceiverSettings{
+					AuthType:           KubeletStatsAuthTypeTls,
+					InsecureSkipVerify: true,
+					CAFile:             "/var/lib/kubelet/pki/ca.crt",
+					CertFile:           "/var/lib/kubelet/pki/kubelet-client.crt",
+					KeyFile:            "/var/lib/kubelet/pki/kubelet-client.key",
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			kubeletstatsReceiver := readFromMap(collectorConfig, []string{"receivers", "kubeletstats"}).(map[string]interface{})
+			Expect(kubeletstatsReceiver["auth_type"]).To(Equal("tls"))
+			Expect(kubeletstatsReceiver["insecure_skip_verify"]).To(BeTrue())
+			Expect(kubeletstatsReceiver["ca_file"]).To(Equal("/var/lib/kubelet/pki/ca.crt"))
+			Expect(kubeletstatsReceiver["cert_file"]).To(Equal("/var/lib/kubelet/pki/kubelet-client.crt"))
+			Expect(kubeletstatsReceiver["key_file"]).To(Equal("/var/lib/kubelet/pki/kubelet-client.key"))
+		})
+
+		It("should use the service account auth type for the kubeletstats receiver by default", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				KubernetesInfrastructureMetricsCollectionEnabled: true,
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			kubeletstatsReceiver := readFromMap(collectorConfig, []string{"receivers", "kubeletstats"}).(map[string]interface{})
+			Expect(kubeletstatsReceiver["auth_type"]).To(Equal("serviceAccount"))
+			Expect(kubeletstatsReceiver).ToNot(HaveKey("ca_file"))
+			Expect(kubeletstatsReceiver).ToNot(HaveKey("cert_file"))
+			Expect(kubeletstatsReceiver).ToNot(HaveKey("key_file"))
+		})
+	})
+
+	Describe("filelog receiver paths", func() {
+		It("should use the default include path if no include paths are configured", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			include := readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods", "include"})
+			Expect(include).To(Equal([]interface{}{"/var/log/pods/*/*/*.log"}))
+			exclude := readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods", "exclude"})
+			Expect(exclude).To(Equal([]interface{}{
+				"/var/log/pods/kube-system_*/*/*.log",
+				fmt.Sprintf("/var/log/pods/%s_*/*/*.log", namespace),
+			}))
+		})
+
+		It("should render the configured include and exclude paths", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				FilelogReceiverPaths: FilelogReceiverPaths{
+					Include: []string{"/var/log/pods/*/*/*.log", "/mnt/logs/pods/*/*/*.log"},
+					Exclude: []string{"/var/log/pods/noisy-namespace_*/*/*.log"},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			include := readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods", "include"})
+			Expect(include).To(Equal([]interface{}{"/var/log/pods/*/*/*.log", "/mnt/logs/pods/*/*/*.log"}))
+			exclude := readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods", "exclude"})
+			Expect(exclude).To(Equal([]interface{}{
+				"/var/log/pods/kube-system_*/*/*.log",
+				fmt.Sprintf("/var/log/pods/%s_*/*/*.log", namespace),
+				"/var/log/pods/noisy-namespace_*/*/*.log",
+			}))
+		})
+
+		It("should drop the logs of pods that opt out of log collection via the default annotation", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			annotations := readFromMap(collectorConfig, []string{"processors", "k8sattributes", "extract", "annotations"})
+			Expect(annotations).To(Equal([]interface{}{
+				map[string]interface{}{
+					"key":      "dash0.com/log-collection",
+					"tag_name": "dash0.log_collection",
+					"from":     "pod",
+				},
+			}))
+			pipelines := readPipelines(collectorConfig)
+			logsProcessors := readPipelineList(pipelines, "logs/monitoredpods", "processors")
+			Expect(logsProcessors).To(ContainElement("filter/log_collection_opt_out"))
+		})
+
+		It("should use the configured log collection opt-out annotation", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				FilelogReceiverPaths: FilelogReceiverPaths{
+					OptOutAnnotation: "example.com/collect-logs",
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			annotationKey := readFromMap(
+				collectorConfig,
+				[]string{"processors", "k8sattributes", "extract", "annotations", "0", "key"},
+			)
+			Expect(annotationKey).To(Equal("example.com/collect-logs"))
+		})
+	})
+
+	Describe("k8sattributes processor", func() {
+		It("should add the configured metadata, labels and annotations", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				K8sAttributesProcessorSettings: K8sAttributesProcessorSettings{
+					ExtraMetadata: []string{"k8s.pod.name", "k8s.container.name"},
+					Labels: []K8sAttributesFieldExtraction{
+						{Key: "example.com/team", TagName: "team"},
+					},
+					Annotations: []K8sAttributesFieldExtraction{
+						{Key: "example.com/cost-center", From: K8sAttributesFromNamespace},
+					},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			metadata := readFromMap(collectorConfig, []string{"processors", "k8sattributes", "extract", "metadata"})
+			Expect(metadata).To(HaveLen(len(defaultK8sAttributesMetadata) + 1))
+			Expect(metadata).To(ContainElement("k8s.pod.name"))
+			Expect(metadata).To(ContainElement("k8s.container.name"))
+			labels := readFromMap(collectorConfig, []string{"processors", "k8sattributes", "extract", "labels"})
+			Expect(labels).To(Equal([]interface{}{
+				map[string]interface{}{
+					"key":      "dash0.com/instrumented",
+					"tag_name": "dash0.monitoring.instrumented",
+					"from":     "pod",
+				},
+				map[string]interface{}{
+					"key":      "example.com/team",
+					"tag_name": "team",
+					"from":     "pod",
+				},
+			}))
+			annotations := readFromMap(collectorConfig, []string{"processors", "k8sattributes", "extract", "annotations"})
+			Expect(annotations).To(Equal([]interface{}{
+				map[string]interface{}{
+					"key":      "dash0.com/log-collection",
+					"tag_name": "dash0.log_collection",
+					"from":     "pod",
+				},
+				map[string]interface{}{
+					"key":  "example.com/cost-center",
+					"from": "namespace",
+				},
+			}))
+		})
+	})
+
+	Describe("signal collection", func() {
+		It("should not render the uncollected signals filter if all namespaces collect all signals", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"processors", "filter/uncollected_signals"})).To(BeNil())
+			Expect(readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods"})).ToNot(BeNil())
+		})
+
+		It("should drop signals from namespaces that do not collect them", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				NamespacesWithoutSignalCollection: map[dash0v1alpha1.TelemetrySignal][]string{
+					dash0v1alpha1.TelemetrySignalMetrics: {"namespace-1"},
+					dash0v1alpha1.TelemetrySignalLogs:    {"namespace-1", "namespace-2"},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			filter := readFromMap(collectorConfig, []string{"processors", "filter/uncollected_signals"})
+			Expect(filter).ToNot(BeNil())
+			Expect(readFromMap(filter, []string{"traces"})).To(BeNil())
+			Expect(readFromMap(filter, []string{"metrics", "metric"})).To(Equal([]interface{}{
+				`resource.attributes["k8s.namespace.name"] == "namespace-1"`,
+			}))
+			Expect(readFromMap(filter, []string{"logs", "log_record"})).To(Equal([]interface{}{
+				`resource.attributes["k8s.namespace.name"] == "namespace-1"`,
+				`resource.attributes["k8s.namespace.name"] == "namespace-2"`,
+			}))
+
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineList(pipelines, "traces/downstream", "processors")).
+				ToNot(ContainElement("filter/uncollected_signals"))
+			Expect(readPipelineList(pipelines, "metrics/downstream", "processors")).
+				To(ContainElement("filter/uncollected_signals"))
+			Expect(readPipelineList(pipelines, "logs/otlp", "processors")).
+				To(ContainElement("filter/uncollected_signals"))
+
+			exclude := readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods", "exclude"})
+			Expect(exclude).To(ContainElements(
+				"/var/log/pods/namespace-1_*/*/*.log",
+				"/var/log/pods/namespace-2_*/*/*.log",
+			))
+		})
+
+		It("should not render the filelog receiver if pod log collection is disabled", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:                namespace,
+				NamePrefix:               namePrefix,
+				Export:                   Dash0ExportWithEndpointAndToken(),
+				PodLogCollectionDisabled: true,
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			Expect(readFromMap(collectorConfig, []string{"receivers", "filelog/monitored_pods"})).To(BeNil())
+			Expect(readFromMap(collectorConfig, []string{"extensions", "file_storage/filelogreceiver_offsets"})).To(BeNil())
+			Expect(readFromMap(collectorConfig, []string{"service", "extensions"})).
+				ToNot(ContainElement("file_storage/filelogreceiver_offsets"))
+			pipelines := readPipelines(collectorConfig)
+			Expect(pipelines["logs/monitoredpods"]).To(BeNil())
+			Expect(pipelines["logs/otlp"]).ToNot(BeNil())
+		})
+	})
+
+	Describe("cluster name", func() {
+		It("should not add the cluster name if it is not set", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"processors", "resource/cluster_name"})).To(BeNil())
+		})
+
+		It("should add the cluster name to all telemetry", func() {
+			config := &oTelColConfig{
+				Namespace:   namespace,
+				NamePrefix:  namePrefix,
+				Export:      Dash0ExportWithEndpointAndToken(),
+				ClusterName: "production-eu-west-1",
+			}
+			daemonSetConfigMap, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			deploymentConfigMap, err := assembleDeploymentCollectorConfigMap(config, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, configMap := range []*corev1.ConfigMap{daemonSetConfigMap, deploymentConfigMap} {
+				collectorConfig := parseConfigMapContent(configMap)
+				Expect(readFromMap(collectorConfig, []string{"processors", "resource/cluster_name", "attributes"})).To(
+					Equal([]interface{}{
+						map[string]interface{}{
+							"key":    "k8s.cluster.name",
+							"value":  "${env:K8S_CLUSTER_NAME}",
+							"action": "insert",
+						},
+					}))
+			}
+
+			pipelines := readPipelines(parseConfigMapContent(daemonSetConfigMap))
+			for _, pipeline := range []string{"traces/downstream", "metrics/downstream", "logs/downstream"} {
+				Expect(readPipelineList(pipelines, pipeline, "processors")).To(ContainElement("resource/cluster_name"))
+			}
+			pipelines = readPipelines(parseConfigMapContent(deploymentConfigMap))
+			Expect(readPipelineList(pipelines, "metrics/downstream", "processors")).
+				To(ContainElement("resource/cluster_name"))
+		})
+	})
+
+	Describe("Kubernetes events", func() {
+		It("should not collect Kubernetes events by default", func() {
+			configMap, err := assembleDeploymentCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"receivers", "k8sobjects"})).To(BeNil())
+			Expect(readPipelines(collectorConfig)).ToNot(HaveKey("logs/kubernetes-events"))
+		})
+
+		It("should collect Kubernetes events as logs", func() {
+			export := Dash0ExportWithEndpointAndToken()
+			export.Logs = &dash0v1alpha1.SignalExport{
+				Http: &dash0v1alpha1.HttpConfiguration{
+					Endpoint: HttpEndpointTest,
+					Encoding: dash0v1alpha1.Json,
+				},
+			}
+			configMap, err := assembleDeploymentCollectorConfigMap(&oTelColConfig{
+				Namespace:                         namespace,
+				NamePrefix:                        namePrefix,
+				Export:                            export,
+				KubernetesEventsCollectionEnabled: true,
+				ClusterName:                       "production-eu-west-1",
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			Expect(readFromMap(collectorConfig, []string{"receivers", "k8sobjects", "objects"})).To(
+				Equal([]interface{}{
+					map[string]interface{}{
+						"name":  "events",
+						"group": "events.k8s.io",
+						"mode":  "watch",
+					},
+				}))
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineReceivers(pipelines, "logs/kubernetes-events")).To(Equal([]interface{}{"k8sobjects"}))
+			Expect(readPipelineList(pipelines, "logs/kubernetes-events", "processors")).To(
+				Equal([]interface{}{"memory_limiter", "resource/cluster_name", "batch"}))
+			Expect(readPipelineExporters(pipelines, "logs/kubernetes-events")).To(
+				Equal([]interface{}{"otlphttp/json-logs"}))
+			Expect(readPipelineExporters(pipelines, "metrics/downstream")).To(Equal([]interface{}{"otlp/dash0"}))
+		})
+
+		It("should not collect Kubernetes events in the daemonset collector", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:                         namespace,
+				NamePrefix:                        namePrefix,
+				Export:                            Dash0ExportWithEndpointAndToken(),
+				KubernetesEventsCollectionEnabled: true,
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"receivers", "k8sobjects"})).To(BeNil())
+		})
+	})
+
+	Describe("telemetry filters", func() {
+		It("should not render the user defined filter if no namespace has a filter", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"processors", "filter/user_defined"})).To(BeNil())
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineList(pipelines, "traces/downstream", "processors")).
+				ToNot(ContainElement("filter/user_defined"))
+			Expect(readPipelineList(pipelines, "logs/otlp", "processors")).
+				ToNot(ContainElement("filter/user_defined"))
+		})
+
+		It("should drop spans and log records matching the filter conditions of a namespace", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				FiltersPerNamespace: map[string]dash0v1alpha1.Filter{
+					"namespace-2": {
+						Traces: &dash0v1alpha1.TraceFilter{
+							SpanFilter: []string{`IsMatch(name, "GET /(healthz|readyz)")`},
+						},
+					},
+					"namespace-1": {
+						Traces: &dash0v1alpha1.TraceFilter{
+							SpanFilter: []string{`attributes["url.path"] == "/healthz"`},
+						},
+						Logs: &dash0v1alpha1.LogFilter{
+							LogRecordFilter: []string{`IsMatch(body, ".*'GET /healthz'.*")`},
+						},
+					},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			filter := readFromMap(collectorConfig, []string{"processors", "filter/user_defined"})
+			Expect(filter).ToNot(BeNil())
+			Expect(readFromMap(filter, []string{"traces", "span"})).To(Equal([]interface{}{
+				`resource.attributes["k8s.namespace.name"] == "namespace-1" and (attributes["url.path"] == "/healthz")`,
+				`resource.attributes["k8s.namespace.name"] == "namespace-2" and (IsMatch(name, "GET /(healthz|readyz)"))`,
+			}))
+			Expect(readFromMap(filter, []string{"logs", "log_record"})).To(Equal([]interface{}{
+				`resource.attributes["k8s.namespace.name"] == "namespace-1" and (IsMatch(body, ".*'GET /healthz'.*"))`,
+			}))
+
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineList(pipelines, "traces/downstream", "processors")).
+				To(ContainElement("filter/user_defined"))
+			Expect(readPipelineList(pipelines, "metrics/downstream", "processors")).
+				ToNot(ContainElement("filter/user_defined"))
+			Expect(readPipelineList(pipelines, "logs/otlp", "processors")).
+				To(ContainElement("filter/user_defined"))
+			Expect(readPipelineList(pipelines, "logs/monitoredpods", "processors")).
+				To(ContainElement("filter/user_defined"))
+		})
+
+		It("should leave out malformed filter conditions", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				FiltersPerNamespace: map[string]dash0v1alpha1.Filter{
+					"namespace-1": {
+						Traces: &dash0v1alpha1.TraceFilter{
+							SpanFilter: []string{`true) or (true`, `name == "GET /healthz"`},
+						},
+						Logs: &dash0v1alpha1.LogFilter{
+							LogRecordFilter: []string{`IsMatch(body, "unterminated)`},
+						},
+					},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			filter := readFromMap(collectorConfig, []string{"processors", "filter/user_defined"})
+			Expect(readFromMap(filter, []string{"traces", "span"})).To(Equal([]interface{}{
+				`resource.attributes["k8s.namespace.name"] == "namespace-1" and (name == "GET /healthz")`,
+			}))
+			Expect(readFromMap(filter, []string{"logs"})).To(BeNil())
+		})
+	})
+
+	Describe("telemetry transforms", func() {
+		It("should not render the user defined transform if no namespace has a transform", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"processors", "transform/user_defined"})).To(BeNil())
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineList(pipelines, "traces/downstream", "processors")).
+				ToNot(ContainElement("transform/user_defined"))
+		})
+
+		It("should render the transform statements per namespace", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				TransformsPerNamespace: map[string]dash0v1alpha1.Transform{
+					"namespace-2": {
+						Traces: []string{`delete_key(attributes, "user.email")`},
+					},
+					"namespace-1": {
+						Traces: []string{
+							`replace_pattern(attributes["http.url"], "token=[^&]*", "token=***")`,
+							`set(attributes["user.id"], attributes["uid"]) where attributes["uid"] != nil`,
+						},
+						Logs: []string{`replace_pattern(body, "'password': '[^']*'", "'password': '***'")`},
+					},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			transform := readFromMap(collectorConfig, []string{"processors", "transform/user_defined"})
+			Expect(transform).ToNot(BeNil())
+			Expect(readFromMap(transform, []string{"trace_statements"})).To(Equal([]interface{}{
+				map[string]interface{}{
+					"context":    "span",
+					"conditions": []interface{}{`resource.attributes["k8s.namespace.name"] == "namespace-1"`},
+					"statements": []interface{}{
+						`replace_pattern(attributes["http.url"], "token=[^&]*", "token=***")`,
+						`set(attributes["user.id"], attributes["uid"]) where attributes["uid"] != nil`,
+					},
+				},
+				map[string]interface{}{
+					"context":    "span",
+					"conditions": []interface{}{`resource.attributes["k8s.namespace.name"] == "namespace-2"`},
+					"statements": []interface{}{`delete_key(attributes, "user.email")`},
+				},
+			}))
+			Expect(readFromMap(transform, []string{"metric_statements"})).To(BeNil())
+			Expect(readFromMap(transform, []string{"log_statements"})).To(Equal([]interface{}{
+				map[string]interface{}{
+					"context":    "log",
+					"conditions": []interface{}{`resource.attributes["k8s.namespace.name"] == "namespace-1"`},
+					"statements": []interface{}{`replace_pattern(body, "'password': '[^']*'", "'password': '***'")`},
+				},
+			}))
+
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineList(pipelines, "traces/downstream", "processors")).
+				To(ContainElement("transform/user_defined"))
+			Expect(readPipelineList(pipelines, "metrics/downstream", "processors")).
+				ToNot(ContainElement("transform/user_defined"))
+			Expect(readPipelineList(pipelines, "logs/otlp", "processors")).
+				To(ContainElement("transform/user_defined"))
+			Expect(readPipelineList(pipelines, "logs/monitoredpods", "processors")).
+				To(ContainElement("transform/user_defined"))
+		})
+
+		It("should leave out malformed transform statements", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				TransformsPerNamespace: map[string]dash0v1alpha1.Transform{
+					"namespace-1": {
+						Traces: []string{`set(attributes["a"], "b"`, `delete_key(attributes, "user.email")`},
+						Logs:   []string{`attributes["a"] == "b"`},
+					},
+				},
+			}, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			transform := readFromMap(collectorConfig, []string{"processors", "transform/user_defined"})
+			Expect(readFromMap(transform, []string{"trace_statements"})).To(Equal([]interface{}{
+				map[string]interface{}{
+					"context":    "span",
+					"conditions": []interface{}{`resource.attributes["k8s.namespace.name"] == "namespace-1"`},
+					"statements": []interface{}{`delete_key(attributes, "user.email")`},
+				},
+			}))
+			Expect(readFromMap(transform, []string{"log_statements"})).To(BeNil())
+		})
 	})
 
 	Describe("prometheus scraping config", func() {
@@ -681,6 +1423,91 @@ var _ = Describe("The OpenTelemetry Collector ConfigMaps", func() {
 		})
 	})
 
+	Describe("routing by dataset", func() {
+		It("should not render routing connectors if no namespace has a dataset other than the default", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				DatasetsPerNamespace: map[string]string{
+					"namespace1": util.DatasetDefault,
+				},
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"connectors", "routing/traces"})).To(BeNil())
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "traces/downstream")).To(Equal([]interface{}{"otlp/dash0"}))
+			Expect(pipelines["traces/downstream-default"]).To(BeNil())
+		})
+
+		It("should group namespaces with the same dataset into one route", func() {
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				DatasetsPerNamespace: map[string]string{
+					"namespace1": "dataset",
+					"namespace2": "dataset",
+				},
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			routingTable := readFromMap(collectorConfig, []string{"connectors", "routing/logs", "table"}).([]interface{})
+			Expect(routingTable).To(HaveLen(1))
+			Expect(routingTable[0].(map[string]interface{})["statement"]).To(Equal(
+				"route() where resource.attributes[\"k8s.namespace.name\"] == \"namespace1\" or " +
+					"resource.attributes[\"k8s.namespace.name\"] == \"namespace2\""))
+			Expect(readFromMap(
+				collectorConfig,
+				[]string{"exporters", "otlp/dash0-dataset-0", "headers", util.Dash0DatasetHeaderName},
+			)).To(Equal("dataset"))
+			Expect(readFromMap(
+				collectorConfig,
+				[]string{"exporters", "otlp/dash0-dataset-0", "headers", util.AuthorizationHeaderName},
+			)).To(Equal(bearerWithAuthToken))
+		})
+
+		It("should keep the non-Dash0 exporters in the dataset pipelines", func() {
+			export := HttpExportTest()
+			export.Dash0 = Dash0ExportWithEndpointAndToken().Dash0
+			configMap, err := assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     export,
+				DatasetsPerNamespace: map[string]string{
+					"namespace1": "dataset",
+				},
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "metrics/downstream-dataset-0")).To(
+				Equal([]interface{}{"otlp/dash0-dataset-0", "otlphttp/proto"}))
+			Expect(readPipelineExporters(pipelines, "metrics/downstream-default")).To(
+				Equal([]interface{}{"otlp/dash0", "otlphttp/proto"}))
+		})
+
+		It("should only route metrics in the deployment collector", func() {
+			configMap, err := assembleDeploymentCollectorConfigMap(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				DatasetsPerNamespace: map[string]string{
+					"namespace1": "dataset",
+				},
+			}, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"connectors", "routing/metrics"})).ToNot(BeNil())
+			Expect(readFromMap(collectorConfig, []string{"connectors", "routing/traces"})).To(BeNil())
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineExporters(pipelines, "metrics/downstream")).To(Equal([]interface{}{"routing/metrics"}))
+			Expect(readPipelineExporters(pipelines, "metrics/downstream-dataset-0")).To(
+				Equal([]interface{}{"otlp/dash0-dataset-0"}))
+		})
+	})
+
 	Describe("on an IPv4 or IPv6 cluster", func() {
 		type ipVersionTestConfig struct {
 			ipv6     bool
@@ -750,6 +1577,220 @@ var _ = Describe("The OpenTelemetry Collector ConfigMaps", func() {
 			}),
 		})
 	})
+
+	Describe("collector log level", func() {
+		DescribeTable("should set the log level of the collectors' own logs", func(
+			configuredLogLevel string,
+			developmentMode bool,
+			expectedLogLevel string,
+		) {
+			config := &oTelColConfig{
+				Namespace:         namespace,
+				NamePrefix:        namePrefix,
+				Export:            Dash0ExportWithEndpointAndToken(),
+				CollectorLogLevel: configuredLogLevel,
+				DevelopmentMode:   developmentMode,
+			}
+			logLevelPath := []string{"service", "telemetry", "logs", "level"}
+
+			configMap, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readFromMap(parseConfigMapContent(configMap), logLevelPath)).To(Equal(expectedLogLevel))
+
+			configMap, err = assembleDeploymentCollectorConfigMap(config, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readFromMap(parseConfigMapContent(configMap), logLevelPath)).To(Equal(expectedLogLevel))
+		},
+			Entry("default", "", false, "info"),
+			Entry("debug", "debug", false, "debug"),
+			Entry("independent of the development mode", "warn", true, "warn"),
+		)
+	})
+
+	Describe("custom collector configuration snippet", func() {
+		It("should merge the snippet into the daemonset collector configuration", func() {
+			config := &oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				CollectorConfigSnippet: `
+receivers:
+  statsd:
+    endpoint: 0.0.0.0:8125
+service:
+  pipelines:
+    metrics/downstream:
+      receivers:
+      - statsd
+  telemetry:
+    logs:
+      level: debug
+`,
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			Expect(readFromMap(collectorConfig, []string{"receivers", "statsd", "endpoint"})).To(Equal("0.0.0.0:8125"))
+			// receivers from the generated configuration are kept
+			Expect(readFromMap(collectorConfig, []string{"receivers", "otlp"})).ToNot(BeNil())
+			// lists are replaced, not merged
+			pipelines := readPipelines(collectorConfig)
+			Expect(readPipelineReceivers(pipelines, "metrics/downstream")).To(Equal([]interface{}{"statsd"}))
+			// other keys of the same pipeline are kept
+			Expect(readPipelineExporters(pipelines, "metrics/downstream")).To(ContainElement("otlp/dash0"))
+			// scalars are replaced
+			Expect(readFromMap(collectorConfig, []string{"service", "telemetry", "logs", "level"})).To(Equal("debug"))
+		})
+
+		It("should not merge the snippet into the deployment collector configuration", func() {
+			config := &oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				CollectorConfigSnippet: `
+receivers:
+  statsd: {}
+`,
+			}
+			configMap, err := assembleDeploymentCollectorConfigMap(config, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parseConfigMapContent(configMap)["receivers"]).ToNot(HaveKey("statsd"))
+		})
+
+		It("should reject a snippet that is not valid YAML", func() {
+			config := &oTelColConfig{
+				Namespace:              namespace,
+				NamePrefix:             namePrefix,
+				Export:                 Dash0ExportWithEndpointAndToken(),
+				CollectorConfigSnippet: "receivers: [statsd",
+			}
+			_, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).To(MatchError(ContainSubstring("cannot parse the collector configuration snippet")))
+		})
+
+		It("should reject a snippet that is not a YAML map", func() {
+			config := &oTelColConfig{
+				Namespace:              namespace,
+				NamePrefix:             namePrefix,
+				Export:                 Dash0ExportWithEndpointAndToken(),
+				CollectorConfigSnippet: "- statsd",
+			}
+			_, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).To(MatchError(ContainSubstring("cannot parse the collector configuration snippet")))
+		})
+	})
+
+	Describe("custom collector configuration template", func() {
+		customTemplate := `
+exporters:
+{{- range $i, $exporter := .Exporters }}
+  {{ $exporter.Name }}:
+    endpoint: "{{ $exporter.Endpoint }}"
+{{- end }}
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: "{{ .SelfIpReference }}:4317"
+service:
+  pipelines:
+    traces/custom:
+      receivers:
+      - otlp
+      exporters:
+{{- range $i, $exporterName := index .ExporterNamesPerSignal "traces" }}
+      - {{ $exporterName }}
+{{- end }}
+`
+
+		It("should render the custom template instead of the built-in template", func() {
+			config := &oTelColConfig{
+				Namespace:                        namespace,
+				NamePrefix:                       namePrefix,
+				Export:                           Dash0ExportWithEndpointAndToken(),
+				DaemonSetCollectorConfigTemplate: customTemplate,
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			Expect(readFromMap(collectorConfig, []string{"exporters", "otlp/dash0", "endpoint"})).To(
+				Equal(EndpointDash0Test))
+			Expect(readFromMap(collectorConfig, []string{"receivers", "otlp", "protocols", "grpc", "endpoint"})).To(
+				Equal("${env:MY_POD_IP}:4317"))
+			pipelines := readPipelines(collectorConfig)
+			Expect(pipelines).To(HaveLen(1))
+			Expect(readPipelineExporters(pipelines, "traces/custom")).To(Equal([]interface{}{"otlp/dash0"}))
+		})
+
+		It("should use the built-in template for a collector without a custom template", func() {
+			config := &oTelColConfig{
+				Namespace:                        namespace,
+				NamePrefix:                       namePrefix,
+				Export:                           Dash0ExportWithEndpointAndToken(),
+				DaemonSetCollectorConfigTemplate: customTemplate,
+			}
+			configMap, err := assembleDeploymentCollectorConfigMap(config, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readPipelines(parseConfigMapContent(configMap))).To(HaveKey("metrics/downstream"))
+		})
+
+		It("should merge the snippet into the configuration rendered from the custom template", func() {
+			config := &oTelColConfig{
+				Namespace:                        namespace,
+				NamePrefix:                       namePrefix,
+				Export:                           Dash0ExportWithEndpointAndToken(),
+				DaemonSetCollectorConfigTemplate: customTemplate,
+				CollectorConfigSnippet: `
+service:
+  telemetry:
+    logs:
+      level: debug
+`,
+			}
+			configMap, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+			Expect(err).ToNot(HaveOccurred())
+			collectorConfig := parseConfigMapContent(configMap)
+			Expect(readFromMap(collectorConfig, []string{"service", "telemetry", "logs", "level"})).To(Equal("debug"))
+			Expect(readPipelines(collectorConfig)).To(HaveKey("traces/custom"))
+		})
+
+		DescribeTable("should reject an invalid custom template",
+			func(invalidTemplate string, expectedMessage string) {
+				config := &oTelColConfig{
+					Namespace:                         namespace,
+					NamePrefix:                        namePrefix,
+					Export:                            Dash0ExportWithEndpointAndToken(),
+					DeploymentCollectorConfigTemplate: invalidTemplate,
+				}
+				_, err := assembleDeploymentCollectorConfigMap(config, false)
+				var invalidConfigurationErr *InvalidCollectorConfigurationError
+				Expect(err).To(BeAssignableToTypeOf(invalidConfigurationErr))
+				Expect(err).To(MatchError(ContainSubstring(
+					"the collector configuration for the config map %s is invalid: %s",
+					ExpectedDeploymentCollectorConfigMapName,
+					expectedMessage,
+				)))
+			},
+			Entry("with a syntax error",
+				"receivers: {{ .ClusterName",
+				"cannot parse the custom collector configuration template",
+			),
+			Entry("with an unknown value",
+				"receivers: {{ .UnknownValue }}",
+				"cannot render the collector configuration template",
+			),
+			Entry("that renders invalid YAML",
+				"receivers: [{{ .ClusterName }}",
+				"cannot parse the collector configuration",
+			),
+			Entry("that renders an invalid collector configuration",
+				"receivers: {}",
+				"service.pipelines does not define any pipeline",
+			),
+		)
+	})
 })
 
 func assembleDaemonSetCollectorConfigMapWithoutScrapingNamespaces(
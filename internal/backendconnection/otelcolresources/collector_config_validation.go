@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelcolresources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// InvalidCollectorConfigurationError is returned when the configuration rendered for one of the collector config maps
+// (including a user-provided configuration snippet merged into it) is not a valid collector configuration. The
+// operator does not apply such a configuration, since the collector would not start with it.
+type InvalidCollectorConfigurationError struct {
+	ConfigMapName string
+	Err           error
+}
+
+func (e *InvalidCollectorConfigurationError) Error() string {
+	return fmt.Sprintf("the collector configuration for the config map %s is invalid: %v", e.ConfigMapName, e.Err)
+}
+
+func (e *InvalidCollectorConfigurationError) Unwrap() error {
+	return e.Err
+}
+
+var pipelineTypes = []string{signalTraces, signalMetrics, signalLogs}
+
+// validateCollectorConfiguration checks the structure of a rendered collector configuration, that is, whether it
+// defines at least one pipeline, whether every pipeline has a supported type, at least one receiver and at least one
+// exporter, whether all components referenced by pipelines and service extensions are defined, and whether every
+// connector is used as an exporter in one pipeline and as a receiver in another one. It does not check the settings of
+// the individual components, those are only known to the collector.
+func validateCollectorConfiguration(collectorConfiguration string) error {
+	var parsedConfiguration map[string]interface{}
+	if err := yaml.Unmarshal([]byte(collectorConfiguration), &parsedConfiguration); err != nil {
+		return fmt.Errorf("cannot parse the collector configuration: %w", err)
+	}
+
+	var problems []string
+	componentSections := make(map[string]map[string]interface{})
+	for _, section := range []string{"receivers", "processors", "exporters", "connectors", "extensions"} {
+		components, err := readOptionalMap(parsedConfiguration, section)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		componentSections[section] = components
+	}
+
+	service, err := readOptionalMap(parsedConfiguration, "service")
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	extensions, err := readOptionalStringList(service, "extensions", "service")
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, extension := range extensions {
+		if _, defined := componentSections["extensions"][extension]; !defined {
+			problems = append(problems, fmt.Sprintf("service references the undefined extension \"%s\"", extension))
+		}
+	}
+
+	pipelines, err := readOptionalMap(service, "pipelines")
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else if len(pipelines) == 0 {
+		problems = append(problems, "service.pipelines does not define any pipeline")
+	}
+	connectorsUsedAsReceiver := make(map[string]bool)
+	connectorsUsedAsExporter := make(map[string]bool)
+	for pipelineName, pipelineRaw := range pipelines {
+		pipelineType, _, _ := strings.Cut(pipelineName, "/")
+		if !slices.Contains(pipelineTypes, pipelineType) {
+			problems = append(problems, fmt.Sprintf("pipeline \"%s\" has the unsupported type \"%s\"", pipelineName, pipelineType))
+		}
+		pipeline, isMap := pipelineRaw.(map[string]interface{})
+		if !isMap {
+			problems = append(problems, fmt.Sprintf("pipeline \"%s\" is not a map", pipelineName))
+			continue
+		}
+		pipelinePath := fmt.Sprintf("pipeline \"%s\"", pipelineName)
+
+		receivers, err := readOptionalStringList(pipeline, "receivers", pipelinePath)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if len(receivers) == 0 {
+			problems = append(problems, fmt.Sprintf("%s has no receivers", pipelinePath))
+		}
+		for _, receiver := range receivers {
+			if _, isConnector := componentSections["connectors"][receiver]; isConnector {
+				connectorsUsedAsReceiver[receiver] = true
+			} else if _, defined := componentSections["receivers"][receiver]; !defined {
+				problems = append(problems, fmt.Sprintf("%s references the undefined receiver \"%s\"", pipelinePath, receiver))
+			}
+		}
+
+		processors, err := readOptionalStringList(pipeline, "processors", pipelinePath)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		for _, processor := range processors {
+			if _, defined := componentSections["processors"][processor]; !defined {
+				problems = append(problems, fmt.Sprintf("%s references the undefined processor \"%s\"", pipelinePath, processor))
+			}
+		}
+
+		exporters, err := readOptionalStringList(pipeline, "exporters", pipelinePath)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if len(exporters) == 0 {
+			problems = append(problems, fmt.Sprintf("%s has no exporters", pipelinePath))
+		}
+		for _, exporter := range exporters {
+			if _, isConnector := componentSections["connectors"][exporter]; isConnector {
+				connectorsUsedAsExporter[exporter] = true
+			} else if _, defined := componentSections["exporters"][exporter]; !defined {
+				problems = append(problems, fmt.Sprintf("%s references the undefined exporter \"%s\"", pipelinePath, exporter))
+			}
+		}
+	}
+	for connector := range componentSections["connectors"] {
+		if connectorsUsedAsReceiver[connector] != connectorsUsedAsExporter[connector] {
+			problems = append(problems, fmt.Sprintf(
+				"connector \"%s\" needs to be used both as an exporter and as a receiver, or not at all", connector))
+		}
+	}
+
+	if len(problems) > 0 {
+		// Pipelines and connectors are iterated in random order, sort the problems to get a stable error message.
+		slices.Sort(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func readOptionalMap(parent map[string]interface{}, key string) (map[string]interface{}, error) {
+	value := parent[key]
+	if value == nil {
+		return nil, nil
+	}
+	valueAsMap, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, fmt.Errorf("%s is not a map", key)
+	}
+	return valueAsMap, nil
+}
+
+func readOptionalStringList(parent map[string]interface{}, key string, parentPath string) ([]string, error) {
+	value := parent[key]
+	if value == nil {
+		return nil, nil
+	}
+	valueAsList, isList := value.([]interface{})
+	if !isList {
+		return nil, fmt.Errorf("%s.%s is not a list", parentPath, key)
+	}
+	stringList := make([]string, 0, len(valueAsList))
+	for _, item := range valueAsList {
+		itemAsString, isString := item.(string)
+		if !isString {
+			return nil, fmt.Errorf("%s.%s contains an item that is not a string: %v", parentPath, key, item)
+		}
+		stringList = append(stringList, itemAsString)
+	}
+	return stringList, nil
+}
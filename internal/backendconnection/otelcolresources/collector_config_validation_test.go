@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelcolresources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+const validCollectorConfiguration = `
+extensions:
+  health_check: {}
+receivers:
+  otlp: {}
+processors:
+  batch: {}
+exporters:
+  debug: {}
+connectors:
+  forward/logs: {}
+service:
+  extensions:
+  - health_check
+  pipelines:
+    traces:
+      receivers:
+      - otlp
+      processors:
+      - batch
+      exporters:
+      - debug
+    logs/upstream:
+      receivers:
+      - otlp
+      exporters:
+      - forward/logs
+    logs/downstream:
+      receivers:
+      - forward/logs
+      exporters:
+      - debug
+`
+
+var _ = Describe("Validating the collector configuration", func() {
+
+	It("should accept a valid configuration", func() {
+		Expect(validateCollectorConfiguration(validCollectorConfiguration)).To(Succeed())
+	})
+
+	DescribeTable("should reject an invalid configuration", func(
+		collectorConfiguration string,
+		expectedProblem string,
+	) {
+		Expect(validateCollectorConfiguration(collectorConfiguration)).To(MatchError(ContainSubstring(expectedProblem)))
+	},
+		Entry("not YAML", "service: [", "cannot parse the collector configuration"),
+		Entry("no pipelines", `
+receivers:
+  otlp: {}
+service: {}
+`, "service.pipelines does not define any pipeline"),
+		Entry("unsupported pipeline type", `
+receivers:
+  otlp: {}
+exporters:
+  debug: {}
+service:
+  pipelines:
+    profiles:
+      receivers: [otlp]
+      exporters: [debug]
+`, "pipeline \"profiles\" has the unsupported type \"profiles\""),
+		Entry("undefined receiver", `
+exporters:
+  debug: {}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+`, "pipeline \"traces\" references the undefined receiver \"otlp\""),
+		Entry("undefined processor", `
+receivers:
+  otlp: {}
+exporters:
+  debug: {}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [debug]
+`, "pipeline \"traces\" references the undefined processor \"batch\""),
+		Entry("undefined exporter", `
+receivers:
+  otlp: {}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+`, "pipeline \"traces\" references the undefined exporter \"debug\""),
+		Entry("pipeline without exporters", `
+receivers:
+  otlp: {}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+`, "pipeline \"traces\" has no exporters"),
+		Entry("undefined extension", `
+receivers:
+  otlp: {}
+exporters:
+  debug: {}
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+`, "service references the undefined extension \"health_check\""),
+		Entry("connector only used as exporter", `
+receivers:
+  otlp: {}
+connectors:
+  forward: {}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [forward]
+`, "connector \"forward\" needs to be used both as an exporter and as a receiver"),
+		Entry("component list that is not a list", `
+receivers:
+  otlp: {}
+exporters:
+  debug: {}
+service:
+  pipelines:
+    traces:
+      receivers: otlp
+      exporters: [debug]
+`, "pipeline \"traces\".receivers is not a list"),
+	)
+
+	It("should report all problems in a stable order", func() {
+		collectorConfiguration := `
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+    metrics:
+      receivers: [prometheus]
+      exporters: [debug]
+`
+		Expect(validateCollectorConfiguration(collectorConfiguration)).To(MatchError(
+			"pipeline \"metrics\" references the undefined exporter \"debug\"; " +
+				"pipeline \"metrics\" references the undefined receiver \"prometheus\"; " +
+				"pipeline \"traces\" references the undefined exporter \"debug\"; " +
+				"pipeline \"traces\" references the undefined receiver \"otlp\"",
+		))
+	})
+
+	It("should reject a snippet that breaks the generated configuration", func() {
+		config := &oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			CollectorConfigSnippet: `
+service:
+  pipelines:
+    metrics/downstream:
+      receivers:
+      - statsd
+`,
+		}
+		_, err := assembleDaemonSetCollectorConfigMap(config, nil, false)
+		var invalidConfigurationErr *InvalidCollectorConfigurationError
+		Expect(err).To(BeAssignableToTypeOf(invalidConfigurationErr))
+		Expect(err).To(MatchError(ContainSubstring(
+			"the collector configuration for the config map %s is invalid: "+
+				"pipeline \"metrics/downstream\" references the undefined receiver \"statsd\"",
+			DaemonSetCollectorConfigConfigMapName(namePrefix),
+		)))
+	})
+})
@@ -4,8 +4,13 @@
 package otelcolresources
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -28,9 +33,40 @@ type oTelColConfig struct {
 	Export                                           dash0v1alpha1.Export
 	SelfMonitoringAndApiAccessConfiguration          selfmonitoringapiaccess.SelfMonitoringAndApiAccessConfiguration
 	KubernetesInfrastructureMetricsCollectionEnabled bool
+	KubernetesEventsCollectionEnabled                bool
+	ClusterName                                      string
+	DatasetsPerNamespace                             map[string]string
+	NamespacesWithoutSignalCollection                map[dash0v1alpha1.TelemetrySignal][]string
+	FiltersPerNamespace                              map[string]dash0v1alpha1.Filter
+	TransformsPerNamespace                           map[string]dash0v1alpha1.Transform
+	PodLogCollectionDisabled                         bool
 	Images                                           util.Images
 	IsIPv6Cluster                                    bool
 	DevelopmentMode                                  bool
+	DebugFileExport                                  bool
+	FilelogReceiverPaths                             FilelogReceiverPaths
+	KubeletStatsReceiverSettings                     KubeletStatsReceiverSettings
+	K8sAttributesProcessorSettings                   K8sAttributesProcessorSettings
+	SecurityContextSettings                          CollectorSecurityContextSettings
+	PriorityClassName                                string
+	TerminationGracePeriodSeconds                    *int64
+	PreStopSleepSeconds                              *int64
+	PersistentSendingQueue                           PersistentSendingQueueSettings
+	Proxy                                            CollectorProxySettings
+	GatewayMode                                      bool
+	GatewayReplicas                                  int32
+	CollectorLogLevel                                string
+	ConfigurationReloaderCheckFrequency              *metav1.Duration
+	CollectorConfigExportConfigMapName               string
+	CollectorConfigSnippet                           string
+	DaemonSetCollectorConfigTemplate                 string
+	DeploymentCollectorConfigTemplate                string
+}
+
+// collectsPodLogs returns true if the collector reads the pod log files on the nodes. This requires the collector
+// daemonset, hence pod logs are never collected in gateway mode.
+func (c *oTelColConfig) collectsPodLogs() bool {
+	return !c.PodLogCollectionDisabled && !c.GatewayMode
 }
 
 // This type just exists to ensure all created objects go through addCommonMetadata.
@@ -56,9 +92,11 @@ const (
 	openTelemetryCollector                     = "opentelemetry-collector"
 	openTelemetryCollectorDaemonSetNameSuffix  = "opentelemetry-collector-agent"
 	openTelemetryCollectorDeploymentNameSuffix = "cluster-metrics-collector"
+	openTelemetryCollectorGatewayNameSuffix    = "opentelemetry-collector-gateway"
 
 	daemonSetServiceComponent  = "agent-collector"
 	deploymentServiceComponent = openTelemetryCollectorDeploymentNameSuffix
+	gatewayServiceComponent    = "gateway-collector"
 
 	configReloader = "configuration-reloader"
 
@@ -74,7 +112,8 @@ const (
 	appKubernetesIoInstanceValue  = "dash0-operator"
 	appKubernetesIoManagedByValue = "dash0-operator"
 
-	authTokenEnvVarName = "AUTH_TOKEN"
+	authTokenEnvVarName   = "AUTH_TOKEN"
+	clusterNameEnvVarName = "K8S_CLUSTER_NAME"
 
 	configMapVolumeName            = "opentelemetry-collector-configmap"
 	collectorConfigurationYaml     = "config.yaml"
@@ -83,6 +122,28 @@ const (
 	collectorPidFilePath = "/etc/otelcol/run/pid.file"
 	pidFileVolumeName    = "opentelemetry-collector-pidfile"
 	offsetsDirPath       = "/var/otelcol/filelogreceiver_offsets"
+
+	containerRuntimeLogsVolumeName = "node-docker-container-logs"
+	dockerContainerLogsPath        = "/var/lib/docker/containers"
+	extraHostPathVolumeNamePrefix  = "filelogreceiver-extra-host-path-"
+
+	debugFilesVolumeName = "opentelemetry-collector-debug-files"
+	debugFilesDirPath    = "/var/otelcol/debug"
+
+	sendingQueueVolumeName = "opentelemetry-collector-sending-queue"
+	sendingQueueDirPath    = "/var/otelcol/sending_queue"
+
+	// defaultNoProxy lists the destinations that are never accessed via a configured proxy. The $(VAR) references are
+	// expanded by the kubelet, MY_POD_IP and K8S_NODE_NAME are set earlier in the collector container's environment,
+	// and KUBERNETES_SERVICE_HOST is provided by Kubernetes.
+	defaultNoProxy = "localhost,127.0.0.1,::1,.svc,.cluster.local,$(KUBERNETES_SERVICE_HOST),$(K8S_NODE_NAME),$(MY_POD_IP)"
+
+	// maxNameLength is the maximum length of the names of the generated resources. Most resource names only need to
+	// be valid DNS subdomains (253 characters), but service names need to be valid DNS labels, and the names of the
+	// daemonset and deployment end up in label values of their pods, both of which are limited to 63 characters.
+	maxNameLength = 63
+	// nameHashLength is the number of hex characters of the hash that is appended to truncated names.
+	nameHashLength = 8
 )
 
 var (
@@ -98,6 +159,11 @@ var (
 		appKubernetesIoInstanceKey:       appKubernetesIoInstanceValue,
 		appKubernetesIoComponentLabelKey: deploymentServiceComponent,
 	}
+	gatewayMatchLabels = map[string]string{
+		appKubernetesIoNameKey:           appKubernetesIoNameValue,
+		appKubernetesIoInstanceKey:       appKubernetesIoInstanceValue,
+		appKubernetesIoComponentLabelKey: gatewayServiceComponent,
+	}
 
 	nodeNameFieldSpec = corev1.ObjectFieldSelector{
 		FieldPath: "spec.nodeName",
@@ -138,6 +204,16 @@ var (
 		MountPath: offsetsDirPath,
 		ReadOnly:  false,
 	}
+	debugFilesVolumeMount = corev1.VolumeMount{
+		Name:      debugFilesVolumeName,
+		MountPath: debugFilesDirPath,
+		ReadOnly:  false,
+	}
+	sendingQueueVolumeMount = corev1.VolumeMount{
+		Name:      sendingQueueVolumeName,
+		MountPath: sendingQueueDirPath,
+		ReadOnly:  false,
+	}
 
 	collectorProbe = corev1.Probe{
 		ProbeHandler: corev1.ProbeHandler{
@@ -149,6 +225,21 @@ var (
 	}
 
 	deploymentReplicas int32 = 1
+
+	// applyOrder lists the kinds of the resources in the desired state in the order in which they need to be created:
+	// service accounts and roles before the bindings that reference them, config maps before the workloads that mount
+	// them, and the workloads last.
+	applyOrder = []string{
+		"ServiceAccount",
+		"ConfigMap",
+		"ClusterRole",
+		"Role",
+		"ClusterRoleBinding",
+		"RoleBinding",
+		"Service",
+		"DaemonSet",
+		"Deployment",
+	}
 )
 
 func assembleDesiredStateForUpsert(
@@ -157,9 +248,14 @@ func assembleDesiredStateForUpsert(
 	resourceSpecs *OTelColResourceSpecs,
 ) ([]clientObject, error) {
 	namespacesWithPrometheusScraping := make([]string, 0, len(allMonitoringResources))
-	for _, monitoringResource := range allMonitoringResources {
-		if util.ReadBoolPointerWithDefault(monitoringResource.Spec.PrometheusScrapingEnabled, true) {
-			namespacesWithPrometheusScraping = append(namespacesWithPrometheusScraping, monitoringResource.Namespace)
+	// Each daemonset collector pod only scrapes the pods on its own node, there is no equivalent for the replicas of the
+	// gateway deployment, hence Prometheus scraping is not available in gateway mode.
+	if !config.GatewayMode {
+		for _, monitoringResource := range allMonitoringResources {
+			if util.ReadBoolPointerWithDefault(monitoringResource.Spec.PrometheusScrapingEnabled, true) &&
+				monitoringResource.CollectsSignal(dash0v1alpha1.TelemetrySignalMetrics) {
+				namespacesWithPrometheusScraping = append(namespacesWithPrometheusScraping, monitoringResource.Namespace)
+			}
 		}
 	}
 	return assembleDesiredState(
@@ -170,6 +266,80 @@ func assembleDesiredStateForUpsert(
 	)
 }
 
+// collectDatasetsPerNamespace maps the namespace of each Dash0Monitoring resource which has spec.dataset set to that
+// dataset. Namespaces without spec.dataset are not contained in the result.
+func collectDatasetsPerNamespace(allMonitoringResources []dash0v1alpha1.Dash0Monitoring) map[string]string {
+	datasetsPerNamespace := make(map[string]string)
+	for _, monitoringResource := range allMonitoringResources {
+		if monitoringResource.Spec.Dataset != "" {
+			datasetsPerNamespace[monitoringResource.Namespace] = monitoringResource.Spec.Dataset
+		}
+	}
+	return datasetsPerNamespace
+}
+
+// collectNamespacesWithoutSignalCollection maps each telemetry signal to the namespaces whose Dash0Monitoring resource
+// does not list that signal in spec.collect. Signals that are collected for all namespaces are not contained in the
+// result.
+func collectNamespacesWithoutSignalCollection(
+	allMonitoringResources []dash0v1alpha1.Dash0Monitoring,
+) map[dash0v1alpha1.TelemetrySignal][]string {
+	namespacesWithoutSignalCollection := make(map[dash0v1alpha1.TelemetrySignal][]string)
+	for _, monitoringResource := range allMonitoringResources {
+		for _, signal := range []dash0v1alpha1.TelemetrySignal{
+			dash0v1alpha1.TelemetrySignalTraces,
+			dash0v1alpha1.TelemetrySignalMetrics,
+			dash0v1alpha1.TelemetrySignalLogs,
+		} {
+			if !monitoringResource.CollectsSignal(signal) {
+				namespacesWithoutSignalCollection[signal] =
+					append(namespacesWithoutSignalCollection[signal], monitoringResource.Namespace)
+			}
+		}
+	}
+	return namespacesWithoutSignalCollection
+}
+
+// collectFiltersPerNamespace maps the namespace of each Dash0Monitoring resource which has spec.filter set to that
+// filter. Namespaces without spec.filter are not contained in the result.
+func collectFiltersPerNamespace(allMonitoringResources []dash0v1alpha1.Dash0Monitoring) map[string]dash0v1alpha1.Filter {
+	filtersPerNamespace := make(map[string]dash0v1alpha1.Filter)
+	for _, monitoringResource := range allMonitoringResources {
+		if monitoringResource.Spec.Filter != nil {
+			filtersPerNamespace[monitoringResource.Namespace] = *monitoringResource.Spec.Filter
+		}
+	}
+	return filtersPerNamespace
+}
+
+// collectTransformsPerNamespace maps the namespace of each Dash0Monitoring resource which has spec.transform set to
+// that transform. Namespaces without spec.transform are not contained in the result.
+func collectTransformsPerNamespace(
+	allMonitoringResources []dash0v1alpha1.Dash0Monitoring,
+) map[string]dash0v1alpha1.Transform {
+	transformsPerNamespace := make(map[string]dash0v1alpha1.Transform)
+	for _, monitoringResource := range allMonitoringResources {
+		if monitoringResource.Spec.Transform != nil {
+			transformsPerNamespace[monitoringResource.Namespace] = *monitoringResource.Spec.Transform
+		}
+	}
+	return transformsPerNamespace
+}
+
+// isPodLogCollectionDisabled returns true if there is at least one Dash0Monitoring resource and none of them collects
+// logs. In that case, the collector daemonset does not need to read pod log files at all.
+func isPodLogCollectionDisabled(allMonitoringResources []dash0v1alpha1.Dash0Monitoring) bool {
+	if len(allMonitoringResources) == 0 {
+		return false
+	}
+	for _, monitoringResource := range allMonitoringResources {
+		if monitoringResource.CollectsSignal(dash0v1alpha1.TelemetrySignalLogs) {
+			return false
+		}
+	}
+	return true
+}
+
 func assembleDesiredStateForDelete(
 	config *oTelColConfig,
 	resourceSpecs *OTelColResourceSpecs,
@@ -199,17 +369,39 @@ func assembleDesiredState(
 		return desiredState, err
 	}
 	desiredState = append(desiredState, addCommonMetadata(daemonSetCollectorConfigMap))
-	desiredState = append(desiredState, addCommonMetadata(assembleFilelogOffsetsConfigMap(config)))
+	if config.CollectorConfigExportConfigMapName != "" {
+		if err = validateCollectorConfigExportConfigMapName(config, resourceSpecs); err != nil {
+			return desiredState, err
+		}
+		desiredState = append(
+			desiredState,
+			addCommonMetadata(assembleCollectorConfigExportConfigMap(config, daemonSetCollectorConfigMap)),
+		)
+	}
+	if config.collectsPodLogs() {
+		desiredState = append(desiredState, addCommonMetadata(assembleFilelogOffsetsConfigMap(config)))
+	}
 	desiredState = append(desiredState, addCommonMetadata(assembleClusterRoleForDaemonSet(config)))
 	desiredState = append(desiredState, addCommonMetadata(assembleClusterRoleBindingForDaemonSet(config)))
-	desiredState = append(desiredState, addCommonMetadata(assembleRole(config)))
-	desiredState = append(desiredState, addCommonMetadata(assembleRoleBinding(config)))
+	if config.collectsPodLogs() {
+		// The role only grants the filelog offset synch containers access to the offsets config map.
+		desiredState = append(desiredState, addCommonMetadata(assembleRole(config)))
+		desiredState = append(desiredState, addCommonMetadata(assembleRoleBinding(config)))
+	}
 	desiredState = append(desiredState, addCommonMetadata(assembleService(config)))
-	collectorDaemonSet, err := assembleCollectorDaemonSet(config, resourceSpecs)
-	if err != nil {
-		return desiredState, err
+	if config.GatewayMode {
+		collectorGatewayDeployment, err := assembleCollectorGatewayDeployment(config, resourceSpecs)
+		if err != nil {
+			return desiredState, err
+		}
+		desiredState = append(desiredState, addCommonMetadata(collectorGatewayDeployment))
+	} else {
+		collectorDaemonSet, err := assembleCollectorDaemonSet(config, resourceSpecs)
+		if err != nil {
+			return desiredState, err
+		}
+		desiredState = append(desiredState, addCommonMetadata(collectorDaemonSet))
 	}
-	desiredState = append(desiredState, addCommonMetadata(collectorDaemonSet))
 
 	if config.KubernetesInfrastructureMetricsCollectionEnabled {
 		desiredState = append(desiredState, addCommonMetadata(assembleServiceAccountForDeployment(config)))
@@ -227,9 +419,64 @@ func assembleDesiredState(
 		desiredState = append(desiredState, addCommonMetadata(collectorDeployment))
 	}
 
+	sortInApplyOrder(desiredState)
 	return desiredState, nil
 }
 
+// sortInApplyOrder sorts the desired state so that resources are created before the resources that reference them
+// (see applyOrder), and by name within the same kind. This avoids transient errors when applying the desired state for
+// the first time, and keeps the order stable, independent of the order in which the resources have been assembled.
+func sortInApplyOrder(desiredState []clientObject) {
+	slices.SortStableFunc(desiredState, func(a, b clientObject) int {
+		return compareInApplyOrder(
+			NamedResource{Kind: a.object.GetObjectKind().GroupVersionKind().Kind, Name: a.object.GetName()},
+			NamedResource{Kind: b.object.GetObjectKind().GroupVersionKind().Kind, Name: b.object.GetName()},
+		)
+	})
+}
+
+func compareInApplyOrder(a NamedResource, b NamedResource) int {
+	return cmp.Or(
+		cmp.Compare(applyOrderRank(a.Kind), applyOrderRank(b.Kind)),
+		cmp.Compare(a.Name, b.Name),
+	)
+}
+
+func applyOrderRank(kind string) int {
+	rank := slices.Index(applyOrder, kind)
+	if rank < 0 {
+		// kinds that are not listed explicitly go last
+		return len(applyOrder)
+	}
+	return rank
+}
+
+// validateCollectorConfigExportConfigMapName returns an error if the config map the collector configuration is
+// exported to would overwrite a config map managed by the operator, or one of the user-provided config maps with the
+// collector configuration snippet or templates.
+func validateCollectorConfigExportConfigMapName(config *oTelColConfig, resourceSpecs *OTelColResourceSpecs) error {
+	exportConfigMapName := config.CollectorConfigExportConfigMapName
+	for _, managedResource := range ManagedResourceNames(config.NamePrefix) {
+		if managedResource.Kind == "ConfigMap" && managedResource.Name == exportConfigMapName {
+			return fmt.Errorf(
+				"the collector configuration cannot be exported to the config map %s, since the operator already "+
+					"manages a config map with this name",
+				exportConfigMapName,
+			)
+		}
+	}
+	if resourceSpecs != nil &&
+		(exportConfigMapName == resourceSpecs.CollectorConfigSnippetConfigMapName ||
+			exportConfigMapName == resourceSpecs.CollectorConfigTemplateConfigMapName) {
+		return fmt.Errorf(
+			"the collector configuration cannot be exported to the config map %s, since this config map provides "+
+				"the collector configuration snippet or templates",
+			exportConfigMapName,
+		)
+	}
+	return nil
+}
+
 func assembleServiceAccountForDaemonSet(config *oTelColConfig) *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		TypeMeta: metav1.TypeMeta{
@@ -271,9 +518,12 @@ func assembleRole(config *oTelColConfig) *rbacv1.Role {
 		},
 		Rules: []rbacv1.PolicyRule{
 			{
-				APIGroups: []string{""},
-				Resources: []string{"configmaps"},
-				Verbs:     []string{"get", "watch", "list", "update", "patch"},
+				// The filelog offset synch containers only need to read and write the offsets config map. (Read access
+				// to config maps in general is granted by the daemonset cluster role.)
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				Verbs:         []string{"get", "update", "patch"},
+				ResourceNames: []string{FilelogReceiverOffsetsConfigMapName(config.NamePrefix)},
 			},
 		},
 	}
@@ -374,7 +624,7 @@ func assembleClusterRoleBindingForDaemonSet(config *oTelColConfig) *rbacv1.Clust
 }
 
 func assembleService(config *oTelColConfig) *corev1.Service {
-	return &corev1.Service{
+	service := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
@@ -401,14 +651,17 @@ func assembleService(config *oTelColConfig) *corev1.Service {
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
-			Selector: map[string]string{
-				appKubernetesIoNameKey:           appKubernetesIoNameValue,
-				appKubernetesIoInstanceKey:       appKubernetesIoInstanceValue,
-				appKubernetesIoComponentLabelKey: daemonSetServiceComponent,
-			},
+			Selector:              daemonSetMatchLabels,
 			InternalTrafficPolicy: ptr.To(corev1.ServiceInternalTrafficPolicyLocal),
 		},
 	}
+	if config.GatewayMode {
+		// The gateway pods are not running on every node, hence the service must not be restricted to node-local
+		// endpoints.
+		service.Spec.Selector = gatewayMatchLabels
+		service.Spec.InternalTrafficPolicy = ptr.To(corev1.ServiceInternalTrafficPolicyCluster)
+	}
+	return service
 }
 
 func assembleCollectorDaemonSet(config *oTelColConfig, resourceSpecs *OTelColResourceSpecs) (*appsv1.DaemonSet, error) {
@@ -443,24 +696,20 @@ func assembleCollectorDaemonSet(config *oTelColConfig, resourceSpecs *OTelColRes
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: daemonsetServiceAccountName(config.NamePrefix),
-					SecurityContext:    &corev1.PodSecurityContext{},
+					PriorityClassName:  config.PriorityClassName,
+					// The collector flushes its sending queues when it receives SIGTERM, the grace period needs to be
+					// long enough for that (plus the preStop sleep).
+					TerminationGracePeriodSeconds: config.TerminationGracePeriodSeconds,
+					SecurityContext:               assemblePodSecurityContext(config),
 					// This setting is required to enable the configuration reloader process to send Unix signals to the
 					// collector process.
 					ShareProcessNamespace: ptr.To(true),
-					InitContainers: []corev1.Container{assembleFileLogOffsetSynchInitContainer(
-						config,
-						resourceSpecs.CollectorDaemonSetFileLogOffsetSynchContainerResources,
-					)},
 					Containers: []corev1.Container{
 						collectorContainer,
 						assembleConfigurationReloaderContainer(
 							config,
 							resourceSpecs.CollectorDaemonSetConfigurationReloaderContainerResources,
 						),
-						assembleFileLogOffsetSynchContainer(
-							config,
-							resourceSpecs.CollectorDaemonSetFileLogOffsetSynchContainerResources,
-						),
 					},
 					Volumes:     assembleCollectorDaemonSetVolumes(config, configMapItems),
 					HostNetwork: false,
@@ -469,6 +718,20 @@ func assembleCollectorDaemonSet(config *oTelColConfig, resourceSpecs *OTelColRes
 		},
 	}
 
+	if config.collectsPodLogs() {
+		// The filelog offset synch containers persist the offsets of the filelog receiver in a config map, so that pod
+		// logs are neither lost nor duplicated when the collector pod is restarted.
+		podSpec := &collectorDaemonSet.Spec.Template.Spec
+		podSpec.InitContainers = []corev1.Container{assembleFileLogOffsetSynchInitContainer(
+			config,
+			resourceSpecs.CollectorDaemonSetFileLogOffsetSynchContainerResources,
+		)}
+		podSpec.Containers = append(podSpec.Containers, assembleFileLogOffsetSynchContainer(
+			config,
+			resourceSpecs.CollectorDaemonSetFileLogOffsetSynchContainerResources,
+		))
+	}
+
 	if config.SelfMonitoringAndApiAccessConfiguration.SelfMonitoringEnabled {
 		err = selfmonitoringapiaccess.EnableSelfMonitoringInCollectorDaemonSet(
 			collectorDaemonSet,
@@ -484,6 +747,127 @@ func assembleCollectorDaemonSet(config *oTelColConfig, resourceSpecs *OTelColRes
 	return collectorDaemonSet, nil
 }
 
+// assembleCollectorGatewayDeployment creates the collector deployment that replaces the collector daemonset in
+// gateway mode. It uses the same collector configuration, service account and cluster role as the daemonset, and the
+// collector service routes the telemetry from workloads to its pods.
+func assembleCollectorGatewayDeployment(
+	config *oTelColConfig,
+	resourceSpecs *OTelColResourceSpecs,
+) (*appsv1.Deployment, error) {
+	collectorContainer, err := assembleDaemonSetCollectorContainer(
+		config,
+		resourceSpecs.CollectorDaemonSetCollectorContainerResources,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := config.GatewayReplicas
+	if replicas < 1 {
+		replicas = 1
+	}
+	collectorGatewayDeployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GatewayDeploymentName(config.NamePrefix),
+			Namespace: config.Namespace,
+			Labels:    labels(true),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: gatewayMatchLabels,
+			},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: gatewayMatchLabels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: daemonsetServiceAccountName(config.NamePrefix),
+					PriorityClassName:  config.PriorityClassName,
+					// The collector flushes its sending queues when it receives SIGTERM, the grace period needs to be
+					// long enough for that (plus the preStop sleep).
+					TerminationGracePeriodSeconds: config.TerminationGracePeriodSeconds,
+					SecurityContext:               assemblePodSecurityContext(config),
+					// This setting is required to enable the configuration reloader process to send Unix signals to the
+					// collector process.
+					ShareProcessNamespace: ptr.To(true),
+					Containers: []corev1.Container{
+						collectorContainer,
+						assembleConfigurationReloaderContainer(
+							config,
+							resourceSpecs.CollectorDaemonSetConfigurationReloaderContainerResources,
+						),
+					},
+					// Spread the replicas across nodes, so that a single node failure does not take down the gateway.
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "kubernetes.io/hostname",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: gatewayMatchLabels,
+						},
+					}},
+					Volumes:     assembleCollectorDaemonSetVolumes(config, configMapItems),
+					HostNetwork: false,
+				},
+			},
+		},
+	}
+
+	if config.SelfMonitoringAndApiAccessConfiguration.SelfMonitoringEnabled {
+		err = selfmonitoringapiaccess.EnableSelfMonitoringInCollectorDeployment(
+			collectorGatewayDeployment,
+			config.SelfMonitoringAndApiAccessConfiguration,
+			config.Images.GetOperatorVersion(),
+			config.DevelopmentMode,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collectorGatewayDeployment, nil
+}
+
+// assemblePodSecurityContext creates the pod security context for the collector pods. Note that the collector daemonset
+// pods cannot satisfy the restricted Pod Security Standard, since they require host path volumes (to read pod log files)
+// and host ports (to receive telemetry from workloads on the same node). The operator namespace needs to allow these
+// explicitly, for example with the label pod-security.kubernetes.io/enforce=privileged.
+func assemblePodSecurityContext(config *oTelColConfig) *corev1.PodSecurityContext {
+	seccompProfileType := config.SecurityContextSettings.SeccompProfileType
+	if seccompProfileType == "" {
+		seccompProfileType = corev1.SeccompProfileTypeRuntimeDefault
+	}
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: ptr.To(util.ReadBoolPointerWithDefault(config.SecurityContextSettings.RunAsNonRoot, true)),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: seccompProfileType,
+		},
+	}
+}
+
+func assembleContainerSecurityContext(config *oTelColConfig) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		ReadOnlyRootFilesystem: ptr.To(
+			util.ReadBoolPointerWithDefault(config.SecurityContextSettings.ReadOnlyRootFilesystem, true)),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+	if len(config.SecurityContextSettings.AddCapabilities) > 0 {
+		securityContext.Capabilities.Add = config.SecurityContextSettings.AddCapabilities
+	}
+	return securityContext
+}
+
 func assembleFileLogOffsetSynchContainer(
 	config *oTelColConfig,
 	resourceRequirements ResourceRequirementsWithGoMemLimit,
@@ -491,7 +875,7 @@ func assembleFileLogOffsetSynchContainer(
 	filelogOffsetSynchContainer := corev1.Container{
 		Name:            "filelog-offset-synch",
 		Args:            []string{"--mode=synch"},
-		SecurityContext: &corev1.SecurityContext{},
+		SecurityContext: assembleContainerSecurityContext(config),
 		Image:           config.Images.FilelogOffsetSynchImage,
 		Env: []corev1.EnvVar{
 			{
@@ -529,32 +913,29 @@ func assembleCollectorDaemonSetVolumes(
 ) []corev1.Volume {
 	pidFileVolumeSizeLimit := resource.MustParse("1M")
 	offsetsVolumeSizeLimit := resource.MustParse("10M")
-	return []corev1.Volume{
-		{
-			Name: "filelogreceiver-offsets",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{
-					SizeLimit: &offsetsVolumeSizeLimit,
-				},
-			},
-		},
-		{
-			Name: "node-pod-logs",
-			VolumeSource: corev1.VolumeSource{
-				HostPath: &corev1.HostPathVolumeSource{
-					Path: "/var/log/pods/",
+	var volumes []corev1.Volume
+	if config.collectsPodLogs() {
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: "filelogreceiver-offsets",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{
+						SizeLimit: &offsetsVolumeSizeLimit,
+					},
 				},
 			},
-		},
-		{
-			Name: "node-docker-container-logs",
-			VolumeSource: corev1.VolumeSource{
-				HostPath: &corev1.HostPathVolumeSource{
-					Path: "/var/lib/docker/containers",
+			corev1.Volume{
+				Name: "node-pod-logs",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: "/var/log/pods/",
+					},
 				},
 			},
-		},
-		{
+		)
+	}
+	volumes = append(volumes,
+		corev1.Volume{
 			Name: configMapVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
@@ -565,7 +946,7 @@ func assembleCollectorDaemonSetVolumes(
 				},
 			},
 		},
-		{
+		corev1.Volume{
 			Name: pidFileVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				EmptyDir: &corev1.EmptyDirVolumeSource{
@@ -573,26 +954,151 @@ func assembleCollectorDaemonSetVolumes(
 				},
 			},
 		},
+	)
+	if runtimeLogPath := containerRuntimeLogPath(config.FilelogReceiverPaths); runtimeLogPath != "" &&
+		config.collectsPodLogs() {
+		volumes = append(volumes, corev1.Volume{
+			Name: containerRuntimeLogsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: runtimeLogPath,
+				},
+			},
+		})
+	}
+	for i, extraHostPath := range extraHostPaths(config) {
+		volumes = append(volumes, corev1.Volume{
+			Name: extraHostPathVolumeName(i),
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: extraHostPath,
+				},
+			},
+		})
+	}
+	if config.DebugFileExport {
+		volumes = append(volumes, assembleDebugFilesVolume())
+	}
+	if config.PersistentSendingQueue.Enabled {
+		volumes = append(volumes, assembleSendingQueueVolume(config))
 	}
+	return volumes
 }
 
-func assembleCollectorDaemonSetVolumeMounts() []corev1.VolumeMount {
-	return []corev1.VolumeMount{
+func assembleCollectorDaemonSetVolumeMounts(config *oTelColConfig) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{
 		collectorConfigVolume,
 		collectorPidFileMountRW,
-		{
-			Name:      "node-pod-logs",
-			MountPath: "/var/log/pods",
+	}
+	if config.collectsPodLogs() {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{
+				Name:      "node-pod-logs",
+				MountPath: "/var/log/pods",
+				ReadOnly:  true,
+			},
+			filelogReceiverOffsetsVolumeMount,
+		)
+		if runtimeLogPath := containerRuntimeLogPath(config.FilelogReceiverPaths); runtimeLogPath != "" {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      containerRuntimeLogsVolumeName,
+				MountPath: runtimeLogPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+	for i, extraHostPath := range extraHostPaths(config) {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      extraHostPathVolumeName(i),
+			MountPath: extraHostPath,
 			ReadOnly:  true,
+		})
+	}
+	if config.DebugFileExport {
+		volumeMounts = append(volumeMounts, debugFilesVolumeMount)
+	}
+	if config.PersistentSendingQueue.Enabled {
+		volumeMounts = append(volumeMounts, sendingQueueVolumeMount)
+	}
+	return volumeMounts
+}
+
+// containerRuntimeLogPath returns the host directory the files in /var/log/pods are symlinked to, if any. On Docker
+// desktop and other runtimes using docker, this is /var/lib/docker/containers. Containerd and CRI-O write the log files
+// to /var/log/pods directly, so no additional directory needs to be mounted for them unless configured explicitly.
+func containerRuntimeLogPath(paths FilelogReceiverPaths) string {
+	if paths.ContainerRuntimeLogPath != "" {
+		return paths.ContainerRuntimeLogPath
+	}
+	switch paths.ContainerRuntime {
+	case "", ContainerRuntimeDocker:
+		return dockerContainerLogsPath
+	default:
+		return ""
+	}
+}
+
+// extraHostPaths returns the additional host directories to mount into the collector container, that is, the extra
+// host paths and, if pod logs are collected, the extra log host paths. Host paths are not mounted in gateway mode,
+// since neither the filelog receiver nor the kubeletstats receiver are used there.
+func extraHostPaths(config *oTelColConfig) []string {
+	if config.GatewayMode {
+		return nil
+	}
+	hostPaths := slices.Clone(config.FilelogReceiverPaths.ExtraHostPaths)
+	if config.collectsPodLogs() {
+		for _, extraLogHostPath := range extraLogHostPaths(config.FilelogReceiverPaths) {
+			// Mounting the same directory twice would be rejected by the API server.
+			if !slices.Contains(hostPaths, extraLogHostPath) {
+				hostPaths = append(hostPaths, extraLogHostPath)
+			}
+		}
+	}
+	return hostPaths
+}
+
+// extraLogHostPaths returns the extra log host paths without trailing slashes, so they can be used as mount paths and
+// as roots for the filelog receiver's include and exclude globs.
+func extraLogHostPaths(paths FilelogReceiverPaths) []string {
+	var cleaned []string
+	for _, extraLogHostPath := range paths.ExtraLogHostPaths {
+		cleaned = append(cleaned, filepath.Clean(extraLogHostPath))
+	}
+	return cleaned
+}
+
+func extraHostPathVolumeName(index int) string {
+	return fmt.Sprintf("%s%d", extraHostPathVolumeNamePrefix, index)
+}
+
+// assembleDebugFilesVolume creates the volume the file exporters write to when the debug file export is enabled. The
+// files can be inspected with kubectl exec or copied with kubectl cp.
+func assembleDebugFilesVolume() corev1.Volume {
+	debugFilesVolumeSizeLimit := resource.MustParse("100M")
+	return corev1.Volume{
+		Name: debugFilesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: &debugFilesVolumeSizeLimit,
+			},
 		},
-		// On Docker desktop and other runtimes using docker, the files in /var/log/pods
-		// are symlinked to this folder.
-		{
-			Name:      "node-docker-container-logs",
-			MountPath: "/var/lib/docker/containers",
-			ReadOnly:  true,
+	}
+}
+
+// assembleSendingQueueVolume creates the volume for the file-backed sending queue of the exporters. An emptyDir volume
+// survives restarts of the collector container, but not the replacement of the pod.
+func assembleSendingQueueVolume(config *oTelColConfig) corev1.Volume {
+	sendingQueueVolumeSizeLimit := resource.MustParse("500Mi")
+	if config.PersistentSendingQueue.SizeLimit != nil {
+		sendingQueueVolumeSizeLimit = *config.PersistentSendingQueue.SizeLimit
+	}
+	return corev1.Volume{
+		Name: sendingQueueVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: &sendingQueueVolumeSizeLimit,
+			},
 		},
-		filelogReceiverOffsetsVolumeMount,
 	}
 }
 
@@ -618,6 +1124,15 @@ func assembleCollectorEnvVars(config *oTelColConfig, goMemLimit string) ([]corev
 		},
 	}
 
+	if config.ClusterName != "" {
+		collectorEnv = append(collectorEnv, corev1.EnvVar{
+			Name:  clusterNameEnvVarName,
+			Value: config.ClusterName,
+		})
+	}
+
+	collectorEnv = append(collectorEnv, assembleProxyEnvVars(config)...)
+
 	if config.Export.Dash0 != nil {
 		authTokenEnvVar, err := util.CreateEnvVarForAuthorization(
 			(*(config.Export.Dash0)).Authorization,
@@ -632,11 +1147,31 @@ func assembleCollectorEnvVars(config *oTelColConfig, goMemLimit string) ([]corev
 	return collectorEnv, nil
 }
 
+// assembleProxyEnvVars returns the standard proxy environment variables for the collector container, if a proxy has
+// been configured. In-cluster traffic always bypasses the proxy.
+func assembleProxyEnvVars(config *oTelColConfig) []corev1.EnvVar {
+	if config.Proxy.HttpProxy == "" && config.Proxy.HttpsProxy == "" {
+		return nil
+	}
+	var proxyEnv []corev1.EnvVar
+	if config.Proxy.HttpProxy != "" {
+		proxyEnv = append(proxyEnv, corev1.EnvVar{Name: "HTTP_PROXY", Value: config.Proxy.HttpProxy})
+	}
+	if config.Proxy.HttpsProxy != "" {
+		proxyEnv = append(proxyEnv, corev1.EnvVar{Name: "HTTPS_PROXY", Value: config.Proxy.HttpsProxy})
+	}
+	noProxy := defaultNoProxy
+	if config.Proxy.NoProxy != "" {
+		noProxy = noProxy + "," + config.Proxy.NoProxy
+	}
+	return append(proxyEnv, corev1.EnvVar{Name: "NO_PROXY", Value: noProxy})
+}
+
 func assembleDaemonSetCollectorContainer(
 	config *oTelColConfig,
 	resourceRequirements ResourceRequirementsWithGoMemLimit,
 ) (corev1.Container, error) {
-	collectorVolumeMounts := assembleCollectorDaemonSetVolumeMounts()
+	collectorVolumeMounts := assembleCollectorDaemonSetVolumeMounts(config)
 	collectorEnv, err := assembleCollectorEnvVars(config, resourceRequirements.GoMemLimit)
 	if err != nil {
 		return corev1.Container{}, err
@@ -645,7 +1180,7 @@ func assembleDaemonSetCollectorContainer(
 	collectorContainer := corev1.Container{
 		Name:            openTelemetryCollector,
 		Args:            []string{"--config=file:" + collectorConfigurationFilePath},
-		SecurityContext: &corev1.SecurityContext{},
+		SecurityContext: assembleContainerSecurityContext(config),
 		Image:           config.Images.CollectorImage,
 		Ports: []corev1.ContainerPort{
 			{
@@ -664,25 +1199,53 @@ func assembleDaemonSetCollectorContainer(
 		Env:            collectorEnv,
 		LivenessProbe:  &collectorProbe,
 		ReadinessProbe: &collectorProbe,
+		Lifecycle:      assembleCollectorLifecycle(config),
 		Resources:      resourceRequirements.ToResourceRequirements(),
 		VolumeMounts:   collectorVolumeMounts,
 	}
 	if config.Images.CollectorImagePullPolicy != "" {
 		collectorContainer.ImagePullPolicy = config.Images.CollectorImagePullPolicy
 	}
+	if config.GatewayMode {
+		// The gateway pods receive telemetry via the collector service only.
+		for i := range collectorContainer.Ports {
+			collectorContainer.Ports[i].HostPort = 0
+		}
+	}
 	return collectorContainer, nil
 }
 
+// assembleCollectorLifecycle adds a preStop sleep to the collector container, which keeps the collector running for a
+// few seconds after the pod has been marked as terminating. This gives workloads time to stop sending telemetry to the
+// terminating pod before the collector shuts down and flushes its sending queues.
+func assembleCollectorLifecycle(config *oTelColConfig) *corev1.Lifecycle {
+	if config.PreStopSleepSeconds == nil || *config.PreStopSleepSeconds <= 0 {
+		return nil
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(*config.PreStopSleepSeconds, 10)},
+			},
+		},
+	}
+}
+
+// assembleConfigurationReloaderContainer assembles the container that signals the collector to reload its configuration
+// when the configuration file changes. The container deliberately has no preStop hook (its image does not contain a
+// shell or a sleep binary), instead the configuration reloader waits for the collector process to exit after receiving
+// SIGTERM, so that it is always shut down after the collector.
 func assembleConfigurationReloaderContainer(config *oTelColConfig, resourceRequirements ResourceRequirementsWithGoMemLimit) corev1.Container {
 	collectorPidFileMountRO := collectorPidFileMountRW
 	collectorPidFileMountRO.ReadOnly = true
+	args := []string{"--pidfile=" + collectorPidFilePath}
+	if config.ConfigurationReloaderCheckFrequency != nil {
+		args = append(args, "--frequency="+config.ConfigurationReloaderCheckFrequency.Duration.String())
+	}
 	configurationReloaderContainer := corev1.Container{
-		Name: configReloader,
-		Args: []string{
-			"--pidfile=" + collectorPidFilePath,
-			collectorConfigurationFilePath,
-		},
-		SecurityContext: &corev1.SecurityContext{},
+		Name:            configReloader,
+		Args:            append(args, collectorConfigurationFilePath),
+		SecurityContext: assembleContainerSecurityContext(config),
 		Image:           config.Images.ConfigurationReloaderImage,
 		Env: []corev1.EnvVar{
 			{
@@ -705,7 +1268,7 @@ func assembleFileLogOffsetSynchInitContainer(config *oTelColConfig, resourceRequ
 	initFilelogOffsetSynchContainer := corev1.Container{
 		Name:            "filelog-offset-init",
 		Args:            []string{"--mode=init"},
-		SecurityContext: &corev1.SecurityContext{},
+		SecurityContext: assembleContainerSecurityContext(config),
 		Image:           config.Images.FilelogOffsetSynchImage,
 		Env: []corev1.EnvVar{
 			{
@@ -833,6 +1396,17 @@ func assembleClusterRoleForDeployment(config *oTelColConfig) *rbacv1.ClusterRole
 					"watch",
 				},
 			},
+			{
+				APIGroups: []string{"events.k8s.io"},
+				Resources: []string{
+					"events",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
 		},
 	}
 }
@@ -896,7 +1470,11 @@ func assembleCollectorDeployment(
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: deploymentServiceAccountName(config.NamePrefix),
-					SecurityContext:    &corev1.PodSecurityContext{},
+					PriorityClassName:  config.PriorityClassName,
+					// The collector flushes its sending queues when it receives SIGTERM, the grace period needs to be
+					// long enough for that (plus the preStop sleep).
+					TerminationGracePeriodSeconds: config.TerminationGracePeriodSeconds,
+					SecurityContext:               assemblePodSecurityContext(config),
 					// This setting is required to enable the configuration reloader process to send Unix signals to the
 					// collector process.
 					ShareProcessNamespace: ptr.To(true),
@@ -934,7 +1512,7 @@ func assembleCollectorDeploymentVolumes(
 	configMapItems []corev1.KeyToPath,
 ) []corev1.Volume {
 	pidFileVolumeSizeLimit := resource.MustParse("1M")
-	return []corev1.Volume{
+	volumes := []corev1.Volume{
 		{
 			Name: configMapVolumeName,
 			VolumeSource: corev1.VolumeSource{
@@ -955,6 +1533,13 @@ func assembleCollectorDeploymentVolumes(
 			},
 		},
 	}
+	if config.DebugFileExport {
+		volumes = append(volumes, assembleDebugFilesVolume())
+	}
+	if config.PersistentSendingQueue.Enabled {
+		volumes = append(volumes, assembleSendingQueueVolume(config))
+	}
+	return volumes
 }
 
 func assembleDeploymentCollectorContainer(
@@ -965,6 +1550,12 @@ func assembleDeploymentCollectorContainer(
 		collectorConfigVolume,
 		collectorPidFileMountRW,
 	}
+	if config.DebugFileExport {
+		collectorVolumeMounts = append(collectorVolumeMounts, debugFilesVolumeMount)
+	}
+	if config.PersistentSendingQueue.Enabled {
+		collectorVolumeMounts = append(collectorVolumeMounts, sendingQueueVolumeMount)
+	}
 	collectorEnv, err := assembleCollectorEnvVars(config, resourceRequirements.GoMemLimit)
 	if err != nil {
 		return corev1.Container{}, err
@@ -973,11 +1564,12 @@ func assembleDeploymentCollectorContainer(
 	collectorContainer := corev1.Container{
 		Name:            openTelemetryCollector,
 		Args:            []string{"--config=file:" + collectorConfigurationFilePath},
-		SecurityContext: &corev1.SecurityContext{},
+		SecurityContext: assembleContainerSecurityContext(config),
 		Image:           config.Images.CollectorImage,
 		Env:             collectorEnv,
 		LivenessProbe:   &collectorProbe,
 		ReadinessProbe:  &collectorProbe,
+		Lifecycle:       assembleCollectorLifecycle(config),
 		Resources:       resourceRequirements.ToResourceRequirements(),
 		VolumeMounts:    collectorVolumeMounts,
 	}
@@ -1035,6 +1627,13 @@ func ServiceName(namePrefix string) string {
 	return renderName(namePrefix, openTelemetryCollector, "service")
 }
 
+// ServiceBaseUrl returns the OTLP/HTTP base URL of the collector service. The collector is always deployed into the
+// operator namespace, while the workloads it receives telemetry from live in the monitored namespaces, so the URL uses
+// the fully qualified service name including the collector's namespace, not the namespace of the workload.
+func ServiceBaseUrl(namePrefix string, collectorNamespace string) string {
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", ServiceName(namePrefix), collectorNamespace, otlpHttpPort)
+}
+
 func serviceLabels() map[string]string {
 	lbls := labels(false)
 	lbls[appKubernetesIoComponentLabelKey] = daemonSetServiceComponent
@@ -1049,8 +1648,79 @@ func DeploymentName(namePrefix string) string {
 	return renderName(namePrefix, openTelemetryCollectorDeploymentNameSuffix, "deployment")
 }
 
+func GatewayDeploymentName(namePrefix string) string {
+	return renderName(namePrefix, openTelemetryCollectorGatewayNameSuffix)
+}
+
+// renderName joins the name prefix and the given parts. Names longer than maxNameLength are truncated, and a hash of
+// the full name is appended to keep truncated names unique.
 func renderName(prefix string, parts ...string) string {
-	return strings.Join(append([]string{prefix}, parts...), "-")
+	name := strings.Join(append([]string{prefix}, parts...), "-")
+	if len(name) <= maxNameLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	truncated := strings.TrimRight(name[:maxNameLength-nameHashLength-1], "-.")
+	return fmt.Sprintf("%s-%s", truncated, hex.EncodeToString(hash[:])[:nameHashLength])
+}
+
+// NamedResource identifies one of the resources managed by the operator by its kind and name.
+type NamedResource struct {
+	Kind string
+	Name string
+}
+
+// ManagedResourceNames returns the kinds and names of all resources the operator manages for the given name prefix,
+// in the order in which assembleDesiredState returns them. This includes resources which only exist with certain
+// settings, e.g. the cluster metrics collector deployment, which is only created when Kubernetes infrastructure metrics
+// collection is enabled, and the collector gateway deployment, which replaces the daemonset in gateway mode.
+func ManagedResourceNames(namePrefix string) []NamedResource {
+	managedResources := []NamedResource{
+		{Kind: "ServiceAccount", Name: daemonsetServiceAccountName(namePrefix)},
+		{Kind: "ConfigMap", Name: DaemonSetCollectorConfigConfigMapName(namePrefix)},
+		{Kind: "ConfigMap", Name: FilelogReceiverOffsetsConfigMapName(namePrefix)},
+		{Kind: "ClusterRole", Name: DaemonSetClusterRoleName(namePrefix)},
+		{Kind: "ClusterRoleBinding", Name: DaemonSetClusterRoleBindingName(namePrefix)},
+		{Kind: "Role", Name: roleName(namePrefix)},
+		{Kind: "RoleBinding", Name: roleBindingName(namePrefix)},
+		{Kind: "Service", Name: ServiceName(namePrefix)},
+		{Kind: "DaemonSet", Name: DaemonSetName(namePrefix)},
+		{Kind: "Deployment", Name: GatewayDeploymentName(namePrefix)},
+		{Kind: "ServiceAccount", Name: deploymentServiceAccountName(namePrefix)},
+		{Kind: "ClusterRole", Name: DeploymentClusterRoleName(namePrefix)},
+		{Kind: "ClusterRoleBinding", Name: DeploymentClusterRoleBindingName(namePrefix)},
+		{Kind: "ConfigMap", Name: DeploymentCollectorConfigConfigMapName(namePrefix)},
+		{Kind: "Deployment", Name: DeploymentName(namePrefix)},
+	}
+	slices.SortStableFunc(managedResources, compareInApplyOrder)
+	return managedResources
+}
+
+// MaxNamePrefixLength returns the maximum length of a name prefix for which none of the generated resource names
+// need to be truncated.
+func MaxNamePrefixLength() int {
+	maxPrefixLength := maxNameLength
+	for _, managedResource := range ManagedResourceNames("") {
+		maxPrefixLength = min(maxPrefixLength, maxNameLength-len(managedResource.Name))
+	}
+	return maxPrefixLength
+}
+
+// ValidateNamePrefix returns an error if the given name prefix is so long that at least one of the generated resource
+// names needs to be truncated. The operator still works with such a prefix, but the truncated names are harder to
+// relate to the prefix.
+func ValidateNamePrefix(namePrefix string) error {
+	if maxPrefixLength := MaxNamePrefixLength(); len(namePrefix) > maxPrefixLength {
+		return fmt.Errorf(
+			"the collector name prefix \"%s\" is too long (%d characters, at most %d characters are supported), the "+
+				"names of the generated collector resources will be truncated and suffixed with a hash, e.g. %s",
+			namePrefix,
+			len(namePrefix),
+			maxPrefixLength,
+			DaemonSetName(namePrefix),
+		)
+	}
+	return nil
 }
 
 func labels(addOptOutLabel bool) map[string]string {
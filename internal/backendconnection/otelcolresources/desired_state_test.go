@@ -6,9 +6,16 @@ package otelcolresources
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
@@ -46,6 +53,43 @@ var _ = Describe("The desired state of the OpenTelemetry Collector resources", f
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should order the desired state so that resources are created before they are referenced", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		var kinds []string
+		for _, wrapper := range desiredState {
+			kinds = append(kinds, wrapper.object.GetObjectKind().GroupVersionKind().Kind)
+		}
+		Expect(kinds).To(Equal([]string{
+			"ServiceAccount",
+			"ServiceAccount",
+			"ConfigMap",
+			"ConfigMap",
+			"ConfigMap",
+			"ClusterRole",
+			"ClusterRole",
+			"Role",
+			"ClusterRoleBinding",
+			"ClusterRoleBinding",
+			"RoleBinding",
+			"Service",
+			"DaemonSet",
+			"Deployment",
+		}))
+		for i := 1; i < len(desiredState); i++ {
+			if kinds[i-1] == kinds[i] {
+				Expect(desiredState[i-1].object.GetName() < desiredState[i].object.GetName()).To(BeTrue())
+			}
+		}
+	})
+
 	It("should describe the desired state as a set of Kubernetes client objects", func() {
 		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
 			Namespace:  namespace,
@@ -205,6 +249,640 @@ var _ = Describe("The desired state of the OpenTelemetry Collector resources", f
 		Expect(getDeployment(desiredState)).To(BeNil())
 	})
 
+	It("should add file exporters and a volume for their files if the debug file export is enabled", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:          TestImages,
+			DebugFileExport: true,
+		}, nil, &DefaultOTelColResourceSpecs)
+
+		Expect(err).ToNot(HaveOccurred())
+
+		collectorConfigConfigMapContent := getDaemonSetCollectorConfigConfigMapContent(desiredState)
+		Expect(collectorConfigConfigMapContent).To(ContainSubstring("file/traces"))
+		Expect(collectorConfigConfigMapContent).To(ContainSubstring("file/metrics"))
+		Expect(collectorConfigConfigMapContent).To(ContainSubstring("file/logs"))
+		Expect(collectorConfigConfigMapContent).To(ContainSubstring("path: /var/otelcol/debug/traces.jsonl"))
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(6))
+		debugFilesVolume := findVolumeByName(podSpec.Volumes, "opentelemetry-collector-debug-files")
+		Expect(debugFilesVolume).NotTo(BeNil())
+		Expect(debugFilesVolume.VolumeSource.EmptyDir).NotTo(BeNil())
+		Expect(findContainerByName(podSpec.Containers, "opentelemetry-collector").VolumeMounts).To(
+			ContainElement(MatchVolumeMount("opentelemetry-collector-debug-files", "/var/otelcol/debug")))
+
+		deploymentCollectorConfigMapContent :=
+			getConfigMap(desiredState, ExpectedDeploymentCollectorConfigMapName).Data["config.yaml"]
+		Expect(deploymentCollectorConfigMapContent).To(ContainSubstring("file/metrics"))
+		Expect(deploymentCollectorConfigMapContent).NotTo(ContainSubstring("file/traces"))
+		Expect(deploymentCollectorConfigMapContent).NotTo(ContainSubstring("file/logs"))
+
+		podSpec = getDeployment(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(3))
+		Expect(findVolumeByName(podSpec.Volumes, "opentelemetry-collector-debug-files")).NotTo(BeNil())
+		Expect(findContainerByName(podSpec.Containers, "opentelemetry-collector").VolumeMounts).To(
+			ContainElement(MatchVolumeMount("opentelemetry-collector-debug-files", "/var/otelcol/debug")))
+	})
+
+	It("should add a volume for the sending queue if the persistent sending queue is enabled", func() {
+		sizeLimit := resource.MustParse("1Gi")
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+			PersistentSendingQueue: PersistentSendingQueueSettings{
+				Enabled:   true,
+				SizeLimit: &sizeLimit,
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			sendingQueueVolume := findVolumeByName(podSpec.Volumes, "opentelemetry-collector-sending-queue")
+			Expect(sendingQueueVolume).NotTo(BeNil())
+			Expect(sendingQueueVolume.VolumeSource.EmptyDir.SizeLimit.String()).To(Equal("1Gi"))
+			Expect(findContainerByName(podSpec.Containers, "opentelemetry-collector").VolumeMounts).To(
+				ContainElement(MatchVolumeMount("opentelemetry-collector-sending-queue", "/var/otelcol/sending_queue")))
+		}
+	})
+
+	It("should not set proxy environment variables by default", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		collectorContainer := findContainerByName(
+			getDaemonSet(desiredState).Spec.Template.Spec.Containers, "opentelemetry-collector")
+		Expect(findEnvVarByName(collectorContainer.Env, "HTTPS_PROXY")).To(BeNil())
+		Expect(findEnvVarByName(collectorContainer.Env, "NO_PROXY")).To(BeNil())
+	})
+
+	It("should set the proxy environment variables on the collector containers", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+			Proxy: CollectorProxySettings{
+				HttpsProxy: "http://proxy.example.com:3128",
+				NoProxy:    "internal.example.com",
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+			Expect(findEnvVarByName(collectorContainer.Env, "HTTP_PROXY")).To(BeNil())
+			Expect(findEnvVarByName(collectorContainer.Env, "HTTPS_PROXY").Value).To(
+				Equal("http://proxy.example.com:3128"))
+			noProxy := findEnvVarByName(collectorContainer.Env, "NO_PROXY").Value
+			Expect(noProxy).To(HavePrefix("localhost,"))
+			Expect(noProxy).To(ContainSubstring("$(KUBERNETES_SERVICE_HOST)"))
+			Expect(noProxy).To(HaveSuffix(",internal.example.com"))
+			Expect(findEnvVarByName(
+				findContainerByName(podSpec.Containers, "configuration-reloader").Env, "HTTPS_PROXY",
+			)).To(BeNil())
+		}
+	})
+
+	It("should mount extra host paths for the filelog receiver", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				Include:        []string{"/mnt/logs/pods/*/*/*.log"},
+				ExtraHostPaths: []string{"/mnt/logs/pods", "/data/logs"},
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(7))
+		extraHostPathVolume := findVolumeByName(podSpec.Volumes, "filelogreceiver-extra-host-path-0")
+		Expect(extraHostPathVolume).NotTo(BeNil())
+		Expect(extraHostPathVolume.VolumeSource.HostPath.Path).To(Equal("/mnt/logs/pods"))
+		extraHostPathVolume = findVolumeByName(podSpec.Volumes, "filelogreceiver-extra-host-path-1")
+		Expect(extraHostPathVolume).NotTo(BeNil())
+		Expect(extraHostPathVolume.VolumeSource.HostPath.Path).To(Equal("/data/logs"))
+
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		Expect(collectorContainer.VolumeMounts).To(HaveLen(7))
+		Expect(collectorContainer.VolumeMounts).To(
+			ContainElement(MatchVolumeMount("filelogreceiver-extra-host-path-0", "/mnt/logs/pods")))
+		Expect(collectorContainer.VolumeMounts).To(
+			ContainElement(MatchVolumeMount("filelogreceiver-extra-host-path-1", "/data/logs")))
+		Expect(findVolumeMountByName(
+			collectorContainer.VolumeMounts, "filelogreceiver-extra-host-path-0").ReadOnly).To(BeTrue())
+
+		Expect(getDaemonSetCollectorConfigConfigMapContent(desiredState)).To(
+			ContainSubstring("- \"/mnt/logs/pods/*/*/*.log\""))
+	})
+
+	It("should mount extra log host paths and read pod logs from them", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				ExtraHostPaths:    []string{"/var/lib/kubelet/pki", "/mnt/rotated-logs"},
+				ExtraLogHostPaths: []string{"/mnt/rotated-logs/", "/data/pod-logs"},
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(8))
+		extraHostPathVolume := findVolumeByName(podSpec.Volumes, "filelogreceiver-extra-host-path-2")
+		Expect(extraHostPathVolume).NotTo(BeNil())
+		Expect(extraHostPathVolume.VolumeSource.HostPath.Path).To(Equal("/data/pod-logs"))
+		Expect(findVolumeByName(podSpec.Volumes, "filelogreceiver-extra-host-path-3")).To(BeNil())
+
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		Expect(collectorContainer.VolumeMounts).To(HaveLen(8))
+		Expect(collectorContainer.VolumeMounts).To(
+			ContainElement(MatchVolumeMount("filelogreceiver-extra-host-path-1", "/mnt/rotated-logs")))
+		Expect(collectorContainer.VolumeMounts).To(
+			ContainElement(MatchVolumeMount("filelogreceiver-extra-host-path-2", "/data/pod-logs")))
+		Expect(findVolumeMountByName(
+			collectorContainer.VolumeMounts, "filelogreceiver-extra-host-path-2").ReadOnly).To(BeTrue())
+
+		collectorConfig := getDaemonSetCollectorConfigConfigMapContent(desiredState)
+		Expect(collectorConfig).To(ContainSubstring("- \"/var/log/pods/*/*/*.log\""))
+		Expect(collectorConfig).To(ContainSubstring("- \"/mnt/rotated-logs/*/*/*.log\""))
+		Expect(collectorConfig).To(ContainSubstring("- \"/data/pod-logs/*/*/*.log\""))
+		Expect(collectorConfig).To(ContainSubstring("- \"/data/pod-logs/kube-system_*/*/*.log\""))
+	})
+
+	It("should not mount extra log host paths if pod log collection is disabled", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:                namespace,
+			NamePrefix:               namePrefix,
+			Export:                   Dash0ExportWithEndpointAndToken(),
+			Images:                   TestImages,
+			PodLogCollectionDisabled: true,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				ExtraLogHostPaths: []string{"/data/pod-logs"},
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(findVolumeByName(podSpec.Volumes, "filelogreceiver-extra-host-path-0")).To(BeNil())
+	})
+
+	It("should not mount the docker container log directory for other container runtimes", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				ContainerRuntime: ContainerRuntimeCriO,
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(4))
+		Expect(findVolumeByName(podSpec.Volumes, "node-docker-container-logs")).To(BeNil())
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		Expect(collectorContainer.VolumeMounts).To(HaveLen(4))
+		Expect(findVolumeMountByName(collectorContainer.VolumeMounts, "node-docker-container-logs")).To(BeNil())
+	})
+
+	It("should mount a custom container runtime log directory", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				ContainerRuntime:        ContainerRuntimeCriO,
+				ContainerRuntimeLogPath: "/data/containers",
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(HaveLen(5))
+		runtimeLogsVolume := findVolumeByName(podSpec.Volumes, "node-docker-container-logs")
+		Expect(runtimeLogsVolume).NotTo(BeNil())
+		Expect(runtimeLogsVolume.VolumeSource.HostPath.Path).To(Equal("/data/containers"))
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		Expect(collectorContainer.VolumeMounts).To(
+			ContainElement(MatchVolumeMount("node-docker-container-logs", "/data/containers")))
+	})
+
+	It("should restrict the daemonset role to the filelog offsets config map", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		role := findObjectByName(desiredState, ExpectedDaemonSetRoleName).(*rbacv1.Role)
+		Expect(role.Rules).To(HaveLen(1))
+		Expect(role.Rules[0].Resources).To(Equal([]string{"configmaps"}))
+		Expect(role.Rules[0].Verbs).To(Equal([]string{"get", "update", "patch"}))
+		Expect(role.Rules[0].ResourceNames).To(Equal([]string{ExpectedDaemonSetFilelogOffsetSynchConfigMapName}))
+	})
+
+	It("should omit the filelog receiver resources and the role if pod log collection is disabled", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:                namespace,
+			NamePrefix:               namePrefix,
+			Export:                   Dash0ExportWithEndpointAndToken(),
+			Images:                   TestImages,
+			PodLogCollectionDisabled: true,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(desiredState).To(HaveLen(numberOfResourcesWithoutKubernetesInfrastructureMetricsCollectionEnabled - 3))
+		Expect(findObjectByName(desiredState, ExpectedDaemonSetFilelogOffsetSynchConfigMapName)).To(BeNil())
+		Expect(findObjectByName(desiredState, roleName(namePrefix))).To(BeNil())
+		Expect(findObjectByName(desiredState, roleBindingName(namePrefix))).To(BeNil())
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(podSpec.InitContainers).To(BeEmpty())
+		Expect(podSpec.Containers).To(HaveLen(2))
+		Expect(findContainerByName(podSpec.Containers, "filelog-offset-synch")).To(BeNil())
+		Expect(podSpec.Volumes).To(HaveLen(2))
+		Expect(findVolumeByName(podSpec.Volumes, "node-pod-logs")).To(BeNil())
+		Expect(findVolumeByName(podSpec.Volumes, "node-docker-container-logs")).To(BeNil())
+		Expect(findVolumeByName(podSpec.Volumes, "filelogreceiver-offsets")).To(BeNil())
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		Expect(collectorContainer.VolumeMounts).To(HaveLen(2))
+
+		collectorConfigConfigMapContent := getDaemonSetCollectorConfigConfigMapContent(desiredState)
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("filelog/monitored_pods"))
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("file_storage/filelogreceiver_offsets"))
+	})
+
+	It("should only scrape namespaces that collect metrics", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+		}, []dash0v1alpha1.Dash0Monitoring{
+			monitoringResourceCollecting("namespace-1"),
+			monitoringResourceCollecting("namespace-2", dash0v1alpha1.TelemetrySignalLogs),
+		}, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		collectorConfigConfigMapContent := getDaemonSetCollectorConfigConfigMapContent(desiredState)
+		Expect(collectorConfigConfigMapContent).To(ContainSubstring("- namespace-1"))
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("- namespace-2"))
+	})
+
+	It("should only update the config map of the affected collector when the configuration changes", func() {
+		// Configuration changes are picked up by the configuration reloader container, they must neither modify the
+		// pod templates of the collectors (which would trigger a rollout) nor the config map of the other collector.
+		config := &oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+		}
+		desiredStateBefore, err := assembleDesiredStateForUpsert(config, []dash0v1alpha1.Dash0Monitoring{
+			monitoringResourceCollecting("namespace-1"),
+		}, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		desiredStateAfter, err := assembleDesiredStateForUpsert(config, []dash0v1alpha1.Dash0Monitoring{
+			monitoringResourceCollecting("namespace-1"),
+			monitoringResourceCollecting("namespace-2"),
+		}, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getDaemonSetCollectorConfigConfigMapContent(desiredStateAfter)).ToNot(
+			Equal(getDaemonSetCollectorConfigConfigMapContent(desiredStateBefore)))
+		Expect(getConfigMap(desiredStateAfter, ExpectedDeploymentCollectorConfigMapName).Data).To(
+			Equal(getConfigMap(desiredStateBefore, ExpectedDeploymentCollectorConfigMapName).Data))
+		Expect(getDaemonSet(desiredStateAfter).Spec.Template).To(Equal(getDaemonSet(desiredStateBefore).Spec.Template))
+		Expect(getDeployment(desiredStateAfter).Spec.Template).To(
+			Equal(getDeployment(desiredStateBefore).Spec.Template))
+	})
+
+	It("should not use the well-known OTLP ports as host ports", func() {
+		// The host ports are fixed, see the comment on OtlpGrpcHostPort. This guards against them being changed to
+		// ports that are likely used by other OpenTelemetry collector daemonsets in the cluster.
+		Expect(OtlpGrpcHostPort).ToNot(BeElementOf(otlpGrpcPort, otlpHttpPort))
+		Expect(OtlpHttpHostPort).ToNot(BeElementOf(otlpGrpcPort, otlpHttpPort))
+		Expect(OtlpGrpcHostPort).ToNot(Equal(OtlpHttpHostPort))
+
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		collectorContainer :=
+			findContainerByName(getDaemonSet(desiredState).Spec.Template.Spec.Containers, "opentelemetry-collector")
+		var hostPorts []int32
+		for _, port := range collectorContainer.Ports {
+			if port.HostPort != 0 {
+				hostPorts = append(hostPorts, port.HostPort)
+			}
+		}
+		Expect(hostPorts).To(ConsistOf(int32(OtlpGrpcHostPort), int32(OtlpHttpHostPort)))
+	})
+
+	It("should not export the collector configuration by default", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			Images:     TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desiredState).To(HaveLen(numberOfResourcesWithoutKubernetesInfrastructureMetricsCollectionEnabled))
+	})
+
+	It("should export a copy of the collector configuration to an additional config map", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:                          namespace,
+			NamePrefix:                         namePrefix,
+			Export:                             Dash0ExportWithEndpointAndToken(),
+			Images:                             TestImages,
+			CollectorConfigExportConfigMapName: "collector-config-copy",
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desiredState).To(HaveLen(numberOfResourcesWithoutKubernetesInfrastructureMetricsCollectionEnabled + 1))
+
+		exportConfigMap := getConfigMap(desiredState, "collector-config-copy")
+		Expect(exportConfigMap).ToNot(BeNil())
+		Expect(exportConfigMap.Namespace).To(Equal(namespace))
+		Expect(exportConfigMap.Data).To(
+			Equal(getConfigMap(desiredState, ExpectedDaemonSetCollectorConfigMapName).Data))
+		Expect(exportConfigMap.Data["config.yaml"]).To(ContainSubstring("receivers:"))
+		Expect(exportConfigMap.Annotations["argocd.argoproj.io/sync-options"]).To(Equal("Prune=false"))
+	})
+
+	DescribeTable("should reject exporting the collector configuration to a config map managed by the operator",
+		func(exportConfigMapName string) {
+			_, err := assembleDesiredStateForUpsert(&oTelColConfig{
+				Namespace:                          namespace,
+				NamePrefix:                         namePrefix,
+				Export:                             Dash0ExportWithEndpointAndToken(),
+				Images:                             TestImages,
+				CollectorConfigExportConfigMapName: exportConfigMapName,
+			}, nil, &DefaultOTelColResourceSpecs)
+			Expect(err).To(MatchError(ContainSubstring("already manages a config map with this name")))
+		},
+		Entry("daemonset collector config map", ExpectedDaemonSetCollectorConfigMapName),
+		Entry("deployment collector config map", ExpectedDeploymentCollectorConfigMapName),
+		Entry("filelog offsets config map", ExpectedDaemonSetFilelogOffsetSynchConfigMapName),
+	)
+
+	It("should reject exporting the collector configuration to the snippet or template config map", func() {
+		resourceSpecs := DefaultOTelColResourceSpecs
+		resourceSpecs.CollectorConfigSnippetConfigMapName = "collector-config-snippet"
+		resourceSpecs.CollectorConfigTemplateConfigMapName = "collector-config-template"
+		for _, exportConfigMapName := range []string{"collector-config-snippet", "collector-config-template"} {
+			_, err := assembleDesiredStateForUpsert(&oTelColConfig{
+				Namespace:                          namespace,
+				NamePrefix:                         namePrefix,
+				Export:                             Dash0ExportWithEndpointAndToken(),
+				Images:                             TestImages,
+				CollectorConfigExportConfigMapName: exportConfigMapName,
+			}, nil, &resourceSpecs)
+			Expect(err).To(MatchError(ContainSubstring("provides the collector configuration snippet or templates")))
+		}
+	})
+
+	It("should replace the daemonset with a gateway deployment in gateway mode", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:   namespace,
+			NamePrefix:  namePrefix,
+			Export:      Dash0ExportWithEndpointAndToken(),
+			Images:      TestImages,
+			GatewayMode: true,
+			FilelogReceiverPaths: FilelogReceiverPaths{
+				ExtraHostPaths: []string{"/var/lib/custom-logs"},
+			},
+			GatewayReplicas: 3,
+		}, []dash0v1alpha1.Dash0Monitoring{
+			monitoringResourceCollecting("namespace-1"),
+		}, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getDaemonSet(desiredState)).To(BeNil())
+		Expect(findObjectByName(desiredState, ExpectedDaemonSetFilelogOffsetSynchConfigMapName)).To(BeNil())
+		Expect(findObjectByName(desiredState, roleName(namePrefix))).To(BeNil())
+		gatewayDeployment := findObjectByName(desiredState, GatewayDeploymentName(namePrefix)).(*appsv1.Deployment)
+		Expect(*gatewayDeployment.Spec.Replicas).To(Equal(int32(3)))
+		Expect(gatewayDeployment.Spec.Selector.MatchLabels).To(Equal(gatewayMatchLabels))
+		podSpec := gatewayDeployment.Spec.Template.Spec
+		Expect(podSpec.HostNetwork).To(BeFalse())
+		Expect(podSpec.InitContainers).To(BeEmpty())
+		Expect(podSpec.Containers).To(HaveLen(2))
+		Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+		for _, volume := range podSpec.Volumes {
+			Expect(volume.HostPath).To(BeNil())
+		}
+		collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+		for _, port := range collectorContainer.Ports {
+			Expect(port.HostPort).To(BeZero())
+		}
+
+		service := findObjectByName(desiredState, ExpectedDaemonSetServiceName).(*corev1.Service)
+		Expect(service.Spec.Selector).To(Equal(gatewayMatchLabels))
+		Expect(*service.Spec.InternalTrafficPolicy).To(Equal(corev1.ServiceInternalTrafficPolicyCluster))
+
+		collectorConfigConfigMapContent := getDaemonSetCollectorConfigConfigMapContent(desiredState)
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("kubeletstats"))
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("node_from_env_var"))
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("kubernetes-pods"))
+		Expect(collectorConfigConfigMapContent).NotTo(ContainSubstring("filelog/monitored_pods"))
+	})
+
+	It("should use secure security context defaults for all collector pods and containers", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			Expect(*podSpec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(podSpec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+			for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+				securityContext := container.SecurityContext
+				Expect(*securityContext.AllowPrivilegeEscalation).To(BeFalse())
+				Expect(*securityContext.ReadOnlyRootFilesystem).To(BeTrue())
+				Expect(securityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+				Expect(securityContext.Capabilities.Add).To(BeEmpty())
+			}
+		}
+	})
+
+	It("should apply the configured security context settings", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+			SecurityContextSettings: CollectorSecurityContextSettings{
+				RunAsNonRoot:           ptr.To(false),
+				ReadOnlyRootFilesystem: ptr.To(false),
+				SeccompProfileType:     corev1.SeccompProfileTypeUnconfined,
+				AddCapabilities:        []corev1.Capability{"DAC_READ_SEARCH"},
+			},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(*podSpec.SecurityContext.RunAsNonRoot).To(BeFalse())
+		Expect(podSpec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeUnconfined))
+		securityContext := findContainerByName(podSpec.Containers, "opentelemetry-collector").SecurityContext
+		Expect(*securityContext.AllowPrivilegeEscalation).To(BeFalse())
+		Expect(*securityContext.ReadOnlyRootFilesystem).To(BeFalse())
+		Expect(securityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+		Expect(securityContext.Capabilities.Add).To(Equal([]corev1.Capability{"DAC_READ_SEARCH"}))
+	})
+
+	It("should not set a priority class name by default", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images: TestImages,
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getDaemonSet(desiredState).Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+		Expect(getDeployment(desiredState).Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+	})
+
+	It("should set the configured priority class name for the daemonset and deployment pods", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:            TestImages,
+			PriorityClassName: "system-node-critical",
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getDaemonSet(desiredState).Spec.Template.Spec.PriorityClassName).To(Equal("system-node-critical"))
+		Expect(getDeployment(desiredState).Spec.Template.Spec.PriorityClassName).To(Equal("system-node-critical"))
+	})
+
+	It("should set the cluster name environment variable for the collectors", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:      TestImages,
+			ClusterName: "production-eu-west-1",
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+			Expect(findEnvVarByName(collectorContainer.Env, "K8S_CLUSTER_NAME").Value).To(Equal("production-eu-west-1"))
+		}
+	})
+
+	It("should pass the configured check frequency to the configuration reloader", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:                              TestImages,
+			ConfigurationReloaderCheckFrequency: &metav1.Duration{Duration: 250 * time.Millisecond},
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			Expect(findContainerByName(podSpec.Containers, "configuration-reloader").Args).To(Equal([]string{
+				"--pidfile=/etc/otelcol/run/pid.file",
+				"--frequency=250ms",
+				"/etc/otelcol/conf/config.yaml",
+			}))
+		}
+	})
+
+	It("should set the termination grace period and a preStop sleep for the collector", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:                        TestImages,
+			TerminationGracePeriodSeconds: ptr.To(int64(90)),
+			PreStopSleepSeconds:           ptr.To(int64(10)),
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, podSpec := range []corev1.PodSpec{
+			getDaemonSet(desiredState).Spec.Template.Spec,
+			getDeployment(desiredState).Spec.Template.Spec,
+		} {
+			Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(90)))
+			collectorContainer := findContainerByName(podSpec.Containers, "opentelemetry-collector")
+			Expect(collectorContainer.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "10"}))
+			Expect(findContainerByName(podSpec.Containers, "configuration-reloader").Lifecycle).To(BeNil())
+		}
+	})
+
+	It("should not add a preStop hook if the preStop sleep is disabled", func() {
+		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+			Namespace:  namespace,
+			NamePrefix: namePrefix,
+			Export:     Dash0ExportWithEndpointAndToken(),
+			KubernetesInfrastructureMetricsCollectionEnabled: true,
+			Images:                        TestImages,
+			TerminationGracePeriodSeconds: ptr.To(int64(30)),
+			PreStopSleepSeconds:           ptr.To(int64(0)),
+		}, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		podSpec := getDaemonSet(desiredState).Spec.Template.Spec
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(30)))
+		Expect(findContainerByName(podSpec.Containers, "opentelemetry-collector").Lifecycle).To(BeNil())
+	})
+
 	It("should use the authorization token directly if provided", func() {
 		desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
 			Namespace:  namespace,
@@ -307,6 +985,219 @@ var _ = Describe("The desired state of the OpenTelemetry Collector resources", f
 		Expect(selfMonitoringConfiguration.Export.Grpc).To(BeNil())
 		Expect(selfMonitoringConfiguration.Export.Http).To(BeNil())
 	})
+
+	DescribeTable("should not add any self-monitoring settings to the collector pods if self-monitoring is disabled",
+		func(gatewayMode bool) {
+			desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: namePrefix,
+				Export:     Dash0ExportWithEndpointAndToken(),
+				SelfMonitoringAndApiAccessConfiguration: selfmonitoringapiaccess.SelfMonitoringAndApiAccessConfiguration{
+					SelfMonitoringEnabled: false,
+					Export:                Dash0ExportWithEndpointTokenAndInsightsDataset(),
+				},
+				KubernetesInfrastructureMetricsCollectionEnabled: true,
+				GatewayMode:     gatewayMode,
+				GatewayReplicas: 2,
+				Images:          TestImages,
+				// in development mode, enabled self-monitoring would also set OTEL_LOG_LEVEL
+				DevelopmentMode: true,
+			}, nil, &DefaultOTelColResourceSpecs)
+			Expect(err).NotTo(HaveOccurred())
+
+			var podSpecs []corev1.PodSpec
+			for _, wrapper := range desiredState {
+				switch workload := wrapper.object.(type) {
+				case *appsv1.DaemonSet:
+					podSpecs = append(podSpecs, workload.Spec.Template.Spec)
+				case *appsv1.Deployment:
+					podSpecs = append(podSpecs, workload.Spec.Template.Spec)
+				}
+			}
+			Expect(podSpecs).ToNot(BeEmpty())
+			selfMonitoringEnvVarNames := []string{
+				util.SelfMonitoringAndApiAuthTokenEnvVarName,
+				"OTEL_EXPORTER_OTLP_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_PROTOCOL",
+				"OTEL_EXPORTER_OTLP_HEADERS",
+				"OTEL_RESOURCE_ATTRIBUTES",
+				"OTEL_LOG_LEVEL",
+			}
+			for _, podSpec := range podSpecs {
+				for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+					for _, envVarName := range selfMonitoringEnvVarNames {
+						Expect(findEnvVarByName(container.Env, envVarName)).To(
+							BeNil(),
+							"container %s has the self-monitoring env var %s",
+							container.Name,
+							envVarName,
+						)
+					}
+				}
+			}
+		},
+		Entry("in daemonset mode", false),
+		Entry("in gateway mode", true),
+	)
+
+	Describe("signal collection", func() {
+		It("should collect all signals for namespaces without spec.collect", func() {
+			allMonitoringResources := []dash0v1alpha1.Dash0Monitoring{monitoringResourceCollecting("namespace-1")}
+			Expect(collectNamespacesWithoutSignalCollection(allMonitoringResources)).To(BeEmpty())
+			Expect(isPodLogCollectionDisabled(allMonitoringResources)).To(BeFalse())
+		})
+
+		It("should list the namespaces per signal that do not collect it", func() {
+			allMonitoringResources := []dash0v1alpha1.Dash0Monitoring{
+				monitoringResourceCollecting("namespace-1", dash0v1alpha1.TelemetrySignalLogs),
+				monitoringResourceCollecting(
+					"namespace-2",
+					dash0v1alpha1.TelemetrySignalMetrics,
+					dash0v1alpha1.TelemetrySignalTraces,
+				),
+				monitoringResourceCollecting("namespace-3"),
+			}
+			Expect(collectNamespacesWithoutSignalCollection(allMonitoringResources)).To(Equal(
+				map[dash0v1alpha1.TelemetrySignal][]string{
+					dash0v1alpha1.TelemetrySignalTraces:  {"namespace-1"},
+					dash0v1alpha1.TelemetrySignalMetrics: {"namespace-1"},
+					dash0v1alpha1.TelemetrySignalLogs:    {"namespace-2"},
+				},
+			))
+			Expect(isPodLogCollectionDisabled(allMonitoringResources)).To(BeFalse())
+		})
+
+		It("should disable pod log collection if no namespace collects logs", func() {
+			Expect(isPodLogCollectionDisabled([]dash0v1alpha1.Dash0Monitoring{
+				monitoringResourceCollecting("namespace-1", dash0v1alpha1.TelemetrySignalMetrics),
+				monitoringResourceCollecting("namespace-2", dash0v1alpha1.TelemetrySignalTraces),
+			})).To(BeTrue())
+		})
+
+		It("should not disable pod log collection if there are no monitoring resources", func() {
+			Expect(isPodLogCollectionDisabled(nil)).To(BeFalse())
+		})
+	})
+
+	Describe("telemetry filters", func() {
+		It("should map the namespaces with spec.filter to their filter", func() {
+			filter := dash0v1alpha1.Filter{
+				Traces: &dash0v1alpha1.TraceFilter{SpanFilter: []string{`attributes["url.path"] == "/healthz"`}},
+			}
+			withFilter := monitoringResourceCollecting("namespace-1")
+			withFilter.Spec.Filter = &filter
+			Expect(collectFiltersPerNamespace([]dash0v1alpha1.Dash0Monitoring{
+				withFilter,
+				monitoringResourceCollecting("namespace-2"),
+			})).To(Equal(map[string]dash0v1alpha1.Filter{"namespace-1": filter}))
+		})
+	})
+
+	Describe("telemetry transforms", func() {
+		It("should map the namespaces with spec.transform to their transform", func() {
+			transform := dash0v1alpha1.Transform{Logs: []string{`delete_key(attributes, "user.email")`}}
+			withTransform := monitoringResourceCollecting("namespace-1")
+			withTransform.Spec.Transform = &transform
+			Expect(collectTransformsPerNamespace([]dash0v1alpha1.Dash0Monitoring{
+				withTransform,
+				monitoringResourceCollecting("namespace-2"),
+			})).To(Equal(map[string]dash0v1alpha1.Transform{"namespace-1": transform}))
+		})
+	})
+
+	Describe("resource names", func() {
+		It("should not truncate names that are exactly at the length limit", func() {
+			prefix := strings.Repeat("a", maxNameLength-len("-opentelemetry-collector-service"))
+			name := ServiceName(prefix)
+			Expect(name).To(HaveLen(maxNameLength))
+			Expect(name).To(Equal(prefix + "-opentelemetry-collector-service"))
+		})
+
+		It("should truncate names that exceed the length limit and append a hash", func() {
+			prefix := strings.Repeat("a", maxNameLength-len("-opentelemetry-collector-service")+1)
+			name := ServiceName(prefix)
+			Expect(name).To(HaveLen(maxNameLength))
+			Expect(name).To(HavePrefix(prefix + "-opentelemetry-collect-"))
+			Expect(name).To(MatchRegexp("-[0-9a-f]{8}$"))
+		})
+
+		It("should not end the truncated part of the name with a dash", func() {
+			prefix := strings.Repeat("a", 53) + "-b"
+			name := ServiceName(prefix)
+			Expect(name).To(HaveLen(maxNameLength - 1))
+			Expect(name).To(MatchRegexp("^a{53}-[0-9a-f]{8}$"))
+		})
+
+		It("should use the collector namespace in the service base URL", func() {
+			Expect(ServiceBaseUrl("dash0-operator", "observability")).To(
+				Equal("http://dash0-operator-opentelemetry-collector-service.observability.svc.cluster.local:4318"))
+		})
+
+		It("should generate distinct names for long prefixes that only differ after the truncation point", func() {
+			prefix := strings.Repeat("a", 60)
+			Expect(ServiceName(prefix + "-1")).ToNot(Equal(ServiceName(prefix + "-2")))
+			Expect(DaemonSetName(prefix)).ToNot(Equal(DeploymentName(prefix)))
+		})
+
+		It("should keep all generated names within the length limit", func() {
+			desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+				Namespace:  namespace,
+				NamePrefix: strings.Repeat("a", 100),
+				Export:     Dash0ExportWithEndpointAndToken(),
+				KubernetesInfrastructureMetricsCollectionEnabled: true,
+				Images: TestImages,
+			}, nil, &DefaultOTelColResourceSpecs)
+			Expect(err).ToNot(HaveOccurred())
+			for _, wrapper := range desiredState {
+				Expect(len(wrapper.object.GetName())).To(BeNumerically("<=", maxNameLength))
+			}
+		})
+
+		DescribeTable("should list all managed resources in the order in which they are returned",
+			func(gatewayMode bool, numberOfResourcesNotAssembled int) {
+				desiredState, err := assembleDesiredStateForUpsert(&oTelColConfig{
+					Namespace:  namespace,
+					NamePrefix: namePrefix,
+					Export:     Dash0ExportWithEndpointAndToken(),
+					KubernetesInfrastructureMetricsCollectionEnabled: true,
+					Images:          TestImages,
+					GatewayMode:     gatewayMode,
+					GatewayReplicas: 2,
+				}, nil, &DefaultOTelColResourceSpecs)
+				Expect(err).ToNot(HaveOccurred())
+
+				managedResources := ManagedResourceNames(namePrefix)
+				Expect(managedResources).To(HaveLen(len(desiredState) + numberOfResourcesNotAssembled))
+				managedResourceIndex := 0
+				for _, wrapper := range desiredState {
+					assembledResource := NamedResource{
+						Kind: wrapper.object.GetObjectKind().GroupVersionKind().Kind,
+						Name: wrapper.object.GetName(),
+					}
+					for managedResources[managedResourceIndex] != assembledResource {
+						managedResourceIndex++
+						Expect(managedResourceIndex).To(BeNumerically("<", len(managedResources)),
+							"%v is missing in the managed resources or out of order", assembledResource)
+					}
+				}
+			},
+			// the gateway deployment
+			Entry("daemonset mode", false, 1),
+			// the daemonset, the filelog offsets config map, the role and the role binding
+			Entry("gateway mode", true, 4),
+		)
+
+		It("should accept name prefixes up to the maximum length", func() {
+			Expect(ValidateNamePrefix(namePrefix)).To(Succeed())
+			Expect(ValidateNamePrefix(strings.Repeat("a", MaxNamePrefixLength()))).To(Succeed())
+		})
+
+		It("should reject name prefixes that exceed the maximum length", func() {
+			err := ValidateNamePrefix(strings.Repeat("a", MaxNamePrefixLength()+1))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is too long"))
+		})
+	})
 })
 
 func getConfigMap(desiredState []clientObject, name string) *corev1.ConfigMap {
@@ -321,6 +1212,21 @@ func getDaemonSetCollectorConfigConfigMapContent(desiredState []clientObject) st
 	return cm.Data["config.yaml"]
 }
 
+func monitoringResourceCollecting(
+	targetNamespace string,
+	signals ...dash0v1alpha1.TelemetrySignal,
+) dash0v1alpha1.Dash0Monitoring {
+	return dash0v1alpha1.Dash0Monitoring{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MonitoringResourceName,
+			Namespace: targetNamespace,
+		},
+		Spec: dash0v1alpha1.Dash0MonitoringSpec{
+			Collect: signals,
+		},
+	}
+}
+
 func getFileOffsetConfigMapContent(desiredState []clientObject) string {
 	cm := getConfigMap(desiredState, ExpectedDaemonSetFilelogOffsetSynchConfigMapName)
 	return cm.Data["config.yaml"]
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelcolresources
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+// RenderDesiredStateAsYaml assembles the OpenTelemetry collector resources exactly like
+// CreateOrUpdateOpenTelemetryCollectorResources would, but instead of applying them to the cluster, it returns them as
+// a multi-document YAML stream. This can be used to review the resources the operator is going to create, for example
+// in a dry run or as a diff in a pull request.
+//
+// The output is stable: resources are sorted by API version, kind, namespace and name, the keys of each resource are
+// sorted alphabetically, and fields that are only set by the API server (status, creation timestamps) are omitted.
+func (m *OTelColResourceManager) RenderDesiredStateAsYaml(
+	ctx context.Context,
+	namespace string,
+	images util.Images,
+	allMonitoringResources []dash0v1alpha1.Dash0Monitoring,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	logger *logr.Logger,
+) ([]byte, error) {
+	operatorConfigurationResource, err := m.findOperatorConfigurationResource(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	config, err := m.assembleOTelColConfig(
+		ctx,
+		namespace,
+		images,
+		allMonitoringResources,
+		monitoringResource,
+		operatorConfigurationResource,
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	desiredState, err := assembleDesiredStateForUpsert(
+		config,
+		allMonitoringResources,
+		m.OTelColResourceSpecs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return serializeDesiredStateAsYaml(desiredState)
+}
+
+func serializeDesiredStateAsYaml(desiredState []clientObject) ([]byte, error) {
+	sortedObjects := make([]*unstructured.Unstructured, 0, len(desiredState))
+	for _, wrapper := range desiredState {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(wrapper.object)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot convert %s %s/%s: %w",
+				wrapper.object.GetObjectKind().GroupVersionKind().Kind,
+				wrapper.object.GetNamespace(),
+				wrapper.object.GetName(),
+				err,
+			)
+		}
+		object := &unstructured.Unstructured{Object: content}
+		removeServerSideFields(object)
+		sortedObjects = append(sortedObjects, object)
+	}
+	slices.SortStableFunc(sortedObjects, func(a, b *unstructured.Unstructured) int {
+		return cmp.Or(
+			cmp.Compare(a.GetAPIVersion(), b.GetAPIVersion()),
+			cmp.Compare(a.GetKind(), b.GetKind()),
+			cmp.Compare(a.GetNamespace(), b.GetNamespace()),
+			cmp.Compare(a.GetName(), b.GetName()),
+		)
+	})
+
+	var buffer bytes.Buffer
+	for _, object := range sortedObjects {
+		// yaml.Marshal converts the object to JSON first, which sorts the keys of all maps.
+		serialized, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot serialize %s %s/%s: %w",
+				object.GetKind(),
+				object.GetNamespace(),
+				object.GetName(),
+				err,
+			)
+		}
+		buffer.WriteString("---\n")
+		buffer.Write(serialized)
+	}
+	return buffer.Bytes(), nil
+}
+
+func removeServerSideFields(object *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(object.Object, "status")
+	unstructured.RemoveNestedField(object.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(object.Object, "spec", "template", "metadata", "creationTimestamp")
+}
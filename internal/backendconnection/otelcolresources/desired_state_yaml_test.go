@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelcolresources
+
+import (
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+var _ = Describe("Serializing the desired state as YAML", func() {
+
+	config := &oTelColConfig{
+		Namespace:  namespace,
+		NamePrefix: namePrefix,
+		Export:     Dash0ExportWithEndpointAndToken(),
+		KubernetesInfrastructureMetricsCollectionEnabled: true,
+		Images: TestImages,
+	}
+
+	It("should render all resources as a multi-document YAML stream in a stable order", func() {
+		desiredState, err := assembleDesiredStateForUpsert(config, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+
+		serialized, err := serializeDesiredStateAsYaml(desiredState)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(serialized)).To(HavePrefix("---\n"))
+
+		documents := strings.Split(strings.TrimPrefix(string(serialized), "---\n"), "\n---\n")
+		Expect(documents).To(HaveLen(numberOfResourcesWithKubernetesInfrastructureMetricsCollectionEnabled))
+		var sortKeys []string
+		for _, document := range documents {
+			object := &unstructured.Unstructured{}
+			Expect(yaml.Unmarshal([]byte(document), &object.Object)).To(Succeed())
+			Expect(object.Object).ToNot(HaveKey("status"))
+			Expect(document).ToNot(ContainSubstring("creationTimestamp"))
+			sortKeys = append(
+				sortKeys,
+				strings.Join([]string{object.GetAPIVersion(), object.GetKind(), object.GetNamespace(), object.GetName()}, "\x00"),
+			)
+		}
+		Expect(slices.IsSorted(sortKeys)).To(BeTrue())
+	})
+
+	It("should produce identical output for identical input", func() {
+		desiredState1, err := assembleDesiredStateForUpsert(config, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		desiredState2, err := assembleDesiredStateForUpsert(config, nil, &DefaultOTelColResourceSpecs)
+		Expect(err).ToNot(HaveOccurred())
+		// the order in which the resources are assembled must not matter
+		desiredState2[0], desiredState2[len(desiredState2)-1] = desiredState2[len(desiredState2)-1], desiredState2[0]
+
+		serialized1, err := serializeDesiredStateAsYaml(desiredState1)
+		Expect(err).ToNot(HaveOccurred())
+		serialized2, err := serializeDesiredStateAsYaml(desiredState2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(serialized1)).To(Equal(string(serialized2)))
+	})
+})
@@ -5,10 +5,17 @@ package otelcolresources
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/yaml"
 )
 
@@ -25,8 +32,217 @@ type OTelColResourceSpecs struct {
 
 	CollectorDeploymentCollectorContainerResources             ResourceRequirementsWithGoMemLimit `json:"collectorDeploymentCollectorContainerResources,omitempty"`
 	CollectorDeploymentConfigurationReloaderContainerResources ResourceRequirementsWithGoMemLimit `json:"collectorDeploymentConfigurationReloaderContainerResources,omitempty"`
+
+	CollectorDaemonSetFilelogReceiver      FilelogReceiverPaths         `json:"collectorDaemonSetFilelogReceiver,omitempty"`
+	CollectorDaemonSetKubeletStatsReceiver KubeletStatsReceiverSettings `json:"collectorDaemonSetKubeletStatsReceiver,omitempty"`
+
+	CollectorK8sAttributesProcessor K8sAttributesProcessorSettings `json:"collectorK8sAttributesProcessor,omitempty"`
+
+	CollectorSecurityContext CollectorSecurityContextSettings `json:"collectorSecurityContext,omitempty"`
+
+	// CollectorPriorityClassName is the priority class name of the collector daemonset and deployment pods, unset by
+	// default.
+	CollectorPriorityClassName string `json:"collectorPriorityClassName,omitempty"`
+
+	// CollectorTerminationGracePeriodSeconds is the time the collector pods get to flush their sending queues after
+	// being asked to terminate, defaults to 60 seconds.
+	CollectorTerminationGracePeriodSeconds *int64 `json:"collectorTerminationGracePeriodSeconds,omitempty"`
+	// CollectorPreStopSleepSeconds delays the termination of the collector container, so that workloads stop sending
+	// telemetry to the terminating collector pod before it shuts down, defaults to 5 seconds. Setting this to 0 disables
+	// the preStop hook.
+	CollectorPreStopSleepSeconds *int64 `json:"collectorPreStopSleepSeconds,omitempty"`
+
+	CollectorPersistentSendingQueue PersistentSendingQueueSettings `json:"collectorPersistentSendingQueue,omitempty"`
+
+	CollectorProxy CollectorProxySettings `json:"collectorProxy,omitempty"`
+
+	// CollectorMode is either daemonset (the default) or gateway. In gateway mode, the operator does not deploy the
+	// collector daemonset, instead a collector deployment receives telemetry from workloads via the collector service
+	// and forwards it. Pod logs, kubelet metrics and Prometheus metrics cannot be collected in gateway mode, since
+	// collecting them requires a collector on each node.
+	CollectorMode string `json:"collectorMode,omitempty"`
+	// CollectorGatewayReplicas is the number of replicas of the collector deployment in gateway mode, defaults to 2.
+	CollectorGatewayReplicas *int32 `json:"collectorGatewayReplicas,omitempty"`
+
+	// CollectorLogLevel is the level of the collectors' own logs (one of debug, info, warn or error), defaults to info.
+	CollectorLogLevel string `json:"collectorLogLevel,omitempty"`
+
+	// CollectorConfigurationReloaderCheckFrequency is how often the configuration reloader containers check the collector
+	// configuration file for changes. When unset, the default of the configuration reloader (one second) applies.
+	CollectorConfigurationReloaderCheckFrequency *metav1.Duration `json:"collectorConfigurationReloaderCheckFrequency,omitempty"`
+
+	CollectorReconcileRetry CollectorReconcileRetrySettings `json:"collectorReconcileRetry,omitempty"`
+
+	// CollectorConfigExportConfigMapName is the name of an additional config map in the operator namespace, to which
+	// the operator writes a copy of the rendered collector configuration, so that other tools can read it. Unset by
+	// default, which disables the export.
+	CollectorConfigExportConfigMapName string `json:"collectorConfigExportConfigMapName,omitempty"`
+
+	// CollectorConfigSnippetConfigMapName is the name of a config map in the operator namespace that contains a YAML
+	// snippet under the key config.yaml, which is deep-merged into the generated configuration of the daemonset
+	// collector (or the gateway deployment in gateway mode). Values from the snippet take precedence. Unset by default.
+	CollectorConfigSnippetConfigMapName string `json:"collectorConfigSnippetConfigMapName,omitempty"`
+
+	// CollectorConfigTemplateConfigMapName is the name of a config map in the operator namespace that contains Go
+	// text/templates for the collector configuration under the keys daemonset.config.yaml.template and/or
+	// deployment.config.yaml.template. The operator renders them instead of its built-in templates, with the same
+	// values. Unset by default.
+	CollectorConfigTemplateConfigMapName string `json:"collectorConfigTemplateConfigMapName,omitempty"`
+
+	// CollectorNodeCoverageCheckEnabled enables a periodic check whether the collector daemonset has a ready pod on every
+	// node, with the result being reported on the Dash0 operator configuration resource. Disabled by default, it has no
+	// effect in gateway mode.
+	CollectorNodeCoverageCheckEnabled bool `json:"collectorNodeCoverageCheckEnabled,omitempty"`
+}
+
+// CollectorReconcileRetrySettings controls how reconcile requests are requeued after creating or updating the
+// collector resources has failed. The delay between two attempts grows exponentially, starting at one second.
+type CollectorReconcileRetrySettings struct {
+	// MaxDelay caps the delay between two attempts, defaults to 5 minutes.
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+	// Jitter is the maximum fraction by which each delay is extended at random (e.g. 0.1 for up to 10%), so that
+	// retries of several reconcile requests do not hit the API server at the same time, defaults to 0.1.
+	Jitter *float64 `json:"jitter,omitempty"`
 }
 
+// CollectorProxySettings configures a forward proxy for the outgoing connections of the collectors, by setting the
+// standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables in the collector containers.
+type CollectorProxySettings struct {
+	HttpProxy  string `json:"httpProxy,omitempty"`
+	HttpsProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy is a comma-separated list of hosts, domains and CIDR ranges that are not accessed via the proxy. It is
+	// added to a default list that covers in-cluster traffic (localhost, services, the Kubernetes API server and the
+	// kubelet).
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// PersistentSendingQueueSettings configures a file-backed sending queue for the exporters of the collectors, so that
+// telemetry which cannot be exported (e.g. during a short backend outage) survives collector restarts. The queue is
+// stored in an emptyDir volume, hence it does not survive the collector pod being replaced.
+type PersistentSendingQueueSettings struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// SizeLimit is the size limit of the volume backing the queue, defaults to 500Mi.
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+// FilelogReceiverPaths configures which log files the filelog receiver of the collector daemonset reads.
+type FilelogReceiverPaths struct {
+	// Include replaces the default include globs (/var/log/pods/*/*/*.log) if it is not empty.
+	Include []string `json:"include,omitempty"`
+	// Exclude is added to the default exclude globs, which skip logs from kube-system and the operator namespace.
+	Exclude []string `json:"exclude,omitempty"`
+	// ExtraHostPaths lists additional host directories that are mounted read-only into the collector container, at the
+	// same path, so that the filelog receiver can read log files from them.
+	ExtraHostPaths []string `json:"extraHostPaths,omitempty"`
+	// ExtraLogHostPaths lists additional host directories containing pod log files in the directory layout of
+	// /var/log/pods. They are mounted read-only into the collector container, at the same path, and the filelog
+	// receiver reads the log files below them in addition to the include globs.
+	ExtraLogHostPaths []string `json:"extraLogHostPaths,omitempty"`
+	// ContainerRuntime is the container runtime of the cluster's nodes (docker, containerd or cri-o), defaults to
+	// docker. It determines which host directory needs to be mounted in addition to /var/log/pods, because the files in
+	// /var/log/pods are symlinks into that directory.
+	ContainerRuntime string `json:"containerRuntime,omitempty"`
+	// ContainerRuntimeLogPath overrides the host directory the files in /var/log/pods link to, derived from
+	// ContainerRuntime by default.
+	ContainerRuntimeLogPath string `json:"containerRuntimeLogPath,omitempty"`
+	// OptOutAnnotation is the pod annotation that excludes a pod from log collection when it is set to "false",
+	// defaults to dash0.com/log-collection.
+	OptOutAnnotation string `json:"optOutAnnotation,omitempty"`
+}
+
+// KubeletStatsReceiverSettings configures how the kubeletstats receiver of the collector daemonset authenticates
+// against the kubelet.
+type KubeletStatsReceiverSettings struct {
+	// AuthType is the kubeletstats receiver's auth_type (serviceAccount, tls, kubeConfig or none), defaults to
+	// serviceAccount.
+	AuthType string `json:"authType,omitempty"`
+	// InsecureSkipVerify disables the verification of the kubelet's certificate, for clusters where the kubelet uses a
+	// self-signed certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// CAFile, CertFile and KeyFile are the paths of the certificate files for auth type tls. The directory containing
+	// them can be mounted into the collector container via FilelogReceiverPaths.ExtraHostPaths.
+	CAFile   string `json:"caFile,omitempty"`
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// K8sAttributesProcessorSettings configures additional Kubernetes metadata that the k8sattributes processor of the
+// collector daemonset (or the gateway deployment in gateway mode) adds to all telemetry as resource attributes.
+type K8sAttributesProcessorSettings struct {
+	// ExtraMetadata lists metadata fields (e.g. k8s.replicaset.name or k8s.container.name) that are extracted in
+	// addition to the default ones.
+	ExtraMetadata []string `json:"extraMetadata,omitempty"`
+	// Labels lists pod, namespace or node labels that are added as resource attributes.
+	Labels []K8sAttributesFieldExtraction `json:"labels,omitempty"`
+	// Annotations lists pod, namespace or node annotations that are added as resource attributes.
+	Annotations []K8sAttributesFieldExtraction `json:"annotations,omitempty"`
+}
+
+// K8sAttributesFieldExtraction describes a label or annotation that the k8sattributes processor adds as a resource
+// attribute.
+type K8sAttributesFieldExtraction struct {
+	// Key is the key of the label or annotation.
+	Key string `json:"key"`
+	// TagName is the name of the resource attribute, defaults to k8s.<from>.labels.<key> for labels and
+	// k8s.<from>.annotations.<key> for annotations.
+	TagName string `json:"tagName,omitempty"`
+	// From is the object the label or annotation is read from (pod, namespace or node), defaults to pod.
+	From string `json:"from,omitempty"`
+}
+
+// CollectorSecurityContextSettings configures the security contexts of the collector pods and their containers. All
+// settings have secure defaults that satisfy the restricted Pod Security Standard, except for the host path volumes and
+// host ports the collector daemonset requires.
+type CollectorSecurityContextSettings struct {
+	// RunAsNonRoot defaults to true.
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+	// ReadOnlyRootFilesystem defaults to true.
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+	// SeccompProfileType is either RuntimeDefault or Unconfined, defaults to RuntimeDefault.
+	SeccompProfileType corev1.SeccompProfileType `json:"seccompProfileType,omitempty"`
+	// AddCapabilities lists capabilities that are added to all collector containers. All other capabilities are always
+	// dropped.
+	AddCapabilities []corev1.Capability `json:"addCapabilities,omitempty"`
+}
+
+const (
+	KubeletStatsAuthTypeServiceAccount = "serviceAccount"
+	KubeletStatsAuthTypeTls            = "tls"
+	KubeletStatsAuthTypeKubeConfig     = "kubeConfig"
+	KubeletStatsAuthTypeNone           = "none"
+)
+
+const (
+	K8sAttributesFromPod       = "pod"
+	K8sAttributesFromNamespace = "namespace"
+	K8sAttributesFromNode      = "node"
+)
+
+var k8sAttributesTagNameRegex = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+const (
+	kibibyte = 1024
+	mebibyte = 1024 * kibibyte
+)
+
+const (
+	CollectorModeDaemonSet = "daemonset"
+	CollectorModeGateway   = "gateway"
+)
+
+const (
+	CollectorLogLevelDebug = "debug"
+	CollectorLogLevelInfo  = "info"
+	CollectorLogLevelWarn  = "warn"
+	CollectorLogLevelError = "error"
+)
+
+const (
+	ContainerRuntimeDocker     = "docker"
+	ContainerRuntimeContainerd = "containerd"
+	ContainerRuntimeCriO       = "cri-o"
+)
+
 var (
 	DefaultOTelColResourceSpecs = OTelColResourceSpecs{
 		CollectorDaemonSetCollectorContainerResources: ResourceRequirementsWithGoMemLimit{
@@ -74,6 +290,15 @@ var (
 				corev1.ResourceMemory: resource.MustParse("12Mi"),
 			},
 		},
+		CollectorTerminationGracePeriodSeconds: ptr.To(int64(60)),
+		CollectorPreStopSleepSeconds:           ptr.To(int64(5)),
+		CollectorMode:                          CollectorModeDaemonSet,
+		CollectorGatewayReplicas:               ptr.To(int32(2)),
+		CollectorLogLevel:                      CollectorLogLevelInfo,
+		CollectorReconcileRetry: CollectorReconcileRetrySettings{
+			MaxDelay: &metav1.Duration{Duration: 5 * time.Minute},
+			Jitter:   ptr.To(0.1),
+		},
 	}
 )
 
@@ -111,9 +336,223 @@ func ReadOTelColResourcesConfiguration(configurationFile string) (*OTelColResour
 		&DefaultOTelColResourceSpecs.CollectorDeploymentConfigurationReloaderContainerResources,
 	)
 
+	if resourcesSpecs.CollectorTerminationGracePeriodSeconds == nil {
+		resourcesSpecs.CollectorTerminationGracePeriodSeconds = DefaultOTelColResourceSpecs.CollectorTerminationGracePeriodSeconds
+	}
+	if resourcesSpecs.CollectorPreStopSleepSeconds == nil {
+		resourcesSpecs.CollectorPreStopSleepSeconds = DefaultOTelColResourceSpecs.CollectorPreStopSleepSeconds
+	}
+	if *resourcesSpecs.CollectorPreStopSleepSeconds >= *resourcesSpecs.CollectorTerminationGracePeriodSeconds {
+		return nil, fmt.Errorf(
+			"the collector preStop sleep (%d seconds) needs to be shorter than the termination grace period (%d seconds)",
+			*resourcesSpecs.CollectorPreStopSleepSeconds,
+			*resourcesSpecs.CollectorTerminationGracePeriodSeconds,
+		)
+	}
+
+	switch resourcesSpecs.CollectorMode {
+	case "":
+		resourcesSpecs.CollectorMode = DefaultOTelColResourceSpecs.CollectorMode
+	case CollectorModeDaemonSet, CollectorModeGateway:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported collector mode \"%s\", must be one of %s or %s",
+			resourcesSpecs.CollectorMode,
+			CollectorModeDaemonSet,
+			CollectorModeGateway,
+		)
+	}
+	if resourcesSpecs.CollectorGatewayReplicas == nil {
+		resourcesSpecs.CollectorGatewayReplicas = DefaultOTelColResourceSpecs.CollectorGatewayReplicas
+	}
+	if *resourcesSpecs.CollectorGatewayReplicas < 1 {
+		return nil, fmt.Errorf(
+			"the number of collector gateway replicas needs to be at least 1, got %d",
+			*resourcesSpecs.CollectorGatewayReplicas,
+		)
+	}
+
+	switch resourcesSpecs.CollectorLogLevel {
+	case "":
+		resourcesSpecs.CollectorLogLevel = DefaultOTelColResourceSpecs.CollectorLogLevel
+	case CollectorLogLevelDebug, CollectorLogLevelInfo, CollectorLogLevelWarn, CollectorLogLevelError:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported collector log level \"%s\", must be one of %s, %s, %s or %s",
+			resourcesSpecs.CollectorLogLevel,
+			CollectorLogLevelDebug,
+			CollectorLogLevelInfo,
+			CollectorLogLevelWarn,
+			CollectorLogLevelError,
+		)
+	}
+
+	if resourcesSpecs.CollectorConfigurationReloaderCheckFrequency != nil &&
+		resourcesSpecs.CollectorConfigurationReloaderCheckFrequency.Duration < 100*time.Millisecond {
+		return nil, fmt.Errorf(
+			"the check frequency of the collector configuration reloader needs to be at least 100ms, got %s",
+			resourcesSpecs.CollectorConfigurationReloaderCheckFrequency.Duration,
+		)
+	}
+
+	if resourcesSpecs.CollectorReconcileRetry.MaxDelay == nil {
+		resourcesSpecs.CollectorReconcileRetry.MaxDelay = DefaultOTelColResourceSpecs.CollectorReconcileRetry.MaxDelay
+	}
+	if resourcesSpecs.CollectorReconcileRetry.MaxDelay.Duration < time.Second {
+		return nil, fmt.Errorf(
+			"the maximum delay for retrying a failed collector reconcile needs to be at least 1s, got %s",
+			resourcesSpecs.CollectorReconcileRetry.MaxDelay.Duration,
+		)
+	}
+	if resourcesSpecs.CollectorReconcileRetry.Jitter == nil {
+		resourcesSpecs.CollectorReconcileRetry.Jitter = DefaultOTelColResourceSpecs.CollectorReconcileRetry.Jitter
+	}
+	if *resourcesSpecs.CollectorReconcileRetry.Jitter < 0 || *resourcesSpecs.CollectorReconcileRetry.Jitter > 1 {
+		return nil, fmt.Errorf(
+			"the jitter for retrying a failed collector reconcile needs to be between 0 and 1, got %g",
+			*resourcesSpecs.CollectorReconcileRetry.Jitter,
+		)
+	}
+
+	switch resourcesSpecs.CollectorDaemonSetFilelogReceiver.ContainerRuntime {
+	case "", ContainerRuntimeDocker, ContainerRuntimeContainerd, ContainerRuntimeCriO:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported container runtime \"%s\", must be one of %s, %s or %s",
+			resourcesSpecs.CollectorDaemonSetFilelogReceiver.ContainerRuntime,
+			ContainerRuntimeDocker,
+			ContainerRuntimeContainerd,
+			ContainerRuntimeCriO,
+		)
+	}
+
+	switch resourcesSpecs.CollectorSecurityContext.SeccompProfileType {
+	case "", corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported seccomp profile type \"%s\" for the collector security context, must be one of %s or %s",
+			resourcesSpecs.CollectorSecurityContext.SeccompProfileType,
+			corev1.SeccompProfileTypeRuntimeDefault,
+			corev1.SeccompProfileTypeUnconfined,
+		)
+	}
+
+	for _, proxyUrl := range []string{
+		resourcesSpecs.CollectorProxy.HttpProxy,
+		resourcesSpecs.CollectorProxy.HttpsProxy,
+	} {
+		if proxyUrl == "" {
+			continue
+		}
+		if parsedUrl, err := url.Parse(proxyUrl); err != nil || parsedUrl.Scheme == "" || parsedUrl.Host == "" {
+			return nil, fmt.Errorf("invalid collector proxy URL \"%s\", the URL needs to have a scheme and a host", proxyUrl)
+		}
+	}
+
+	if resourcesSpecs.CollectorConfigExportConfigMapName != "" {
+		if errs := validation.IsDNS1123Subdomain(resourcesSpecs.CollectorConfigExportConfigMapName); len(errs) > 0 {
+			return nil, fmt.Errorf(
+				"invalid name \"%s\" for the collector configuration export config map: %s",
+				resourcesSpecs.CollectorConfigExportConfigMapName,
+				strings.Join(errs, ", "),
+			)
+		}
+	}
+	if resourcesSpecs.CollectorConfigSnippetConfigMapName != "" {
+		if errs := validation.IsDNS1123Subdomain(resourcesSpecs.CollectorConfigSnippetConfigMapName); len(errs) > 0 {
+			return nil, fmt.Errorf(
+				"invalid name \"%s\" for the collector configuration snippet config map: %s",
+				resourcesSpecs.CollectorConfigSnippetConfigMapName,
+				strings.Join(errs, ", "),
+			)
+		}
+	}
+	if resourcesSpecs.CollectorConfigTemplateConfigMapName != "" {
+		if errs := validation.IsDNS1123Subdomain(resourcesSpecs.CollectorConfigTemplateConfigMapName); len(errs) > 0 {
+			return nil, fmt.Errorf(
+				"invalid name \"%s\" for the collector configuration template config map: %s",
+				resourcesSpecs.CollectorConfigTemplateConfigMapName,
+				strings.Join(errs, ", "),
+			)
+		}
+	}
+
+	kubeletStatsReceiverSettings := resourcesSpecs.CollectorDaemonSetKubeletStatsReceiver
+	switch kubeletStatsReceiverSettings.AuthType {
+	case "", KubeletStatsAuthTypeServiceAccount, KubeletStatsAuthTypeKubeConfig, KubeletStatsAuthTypeNone:
+	case KubeletStatsAuthTypeTls:
+		if kubeletStatsReceiverSettings.CertFile == "" || kubeletStatsReceiverSettings.KeyFile == "" {
+			return nil, fmt.Errorf("the kubeletstats receiver auth type %s requires a certFile and a keyFile",
+				KubeletStatsAuthTypeTls)
+		}
+	default:
+		return nil, fmt.Errorf(
+			"unsupported kubeletstats receiver auth type \"%s\", must be one of %s, %s, %s or %s",
+			kubeletStatsReceiverSettings.AuthType,
+			KubeletStatsAuthTypeServiceAccount,
+			KubeletStatsAuthTypeTls,
+			KubeletStatsAuthTypeKubeConfig,
+			KubeletStatsAuthTypeNone,
+		)
+	}
+
+	if err = validateK8sAttributesProcessorSettings(resourcesSpecs.CollectorK8sAttributesProcessor); err != nil {
+		return nil, err
+	}
+
 	return resourcesSpecs, nil
 }
 
+func validateK8sAttributesProcessorSettings(settings K8sAttributesProcessorSettings) error {
+	for _, metadata := range settings.ExtraMetadata {
+		if strings.TrimSpace(metadata) == "" {
+			return fmt.Errorf("the extra metadata of the k8sattributes processor must not contain empty entries")
+		}
+	}
+	for _, fieldExtractions := range []struct {
+		fieldType   string
+		extractions []K8sAttributesFieldExtraction
+	}{
+		{fieldType: "label", extractions: settings.Labels},
+		{fieldType: "annotation", extractions: settings.Annotations},
+	} {
+		fieldType := fieldExtractions.fieldType
+		for _, extraction := range fieldExtractions.extractions {
+			if errs := validation.IsQualifiedName(extraction.Key); len(errs) > 0 {
+				return fmt.Errorf(
+					"invalid %s key \"%s\" for the k8sattributes processor: %s",
+					fieldType,
+					extraction.Key,
+					strings.Join(errs, ", "),
+				)
+			}
+			if extraction.TagName != "" && !k8sAttributesTagNameRegex.MatchString(extraction.TagName) {
+				return fmt.Errorf(
+					"invalid tag name \"%s\" for the %s %s of the k8sattributes processor, only letters, digits, "+
+						"'.', '_', '/' and '-' are allowed",
+					extraction.TagName,
+					fieldType,
+					extraction.Key,
+				)
+			}
+			switch extraction.From {
+			case "", K8sAttributesFromPod, K8sAttributesFromNamespace, K8sAttributesFromNode:
+			default:
+				return fmt.Errorf(
+					"unsupported source \"%s\" for the %s %s of the k8sattributes processor, must be one of %s, %s or %s",
+					extraction.From,
+					fieldType,
+					extraction.Key,
+					K8sAttributesFromPod,
+					K8sAttributesFromNamespace,
+					K8sAttributesFromNode,
+				)
+			}
+		}
+	}
+	return nil
+}
+
 func applyDefaults(spec *ResourceRequirementsWithGoMemLimit, defaults *ResourceRequirementsWithGoMemLimit) {
 	if spec.Limits == nil {
 		spec.Limits = make(corev1.ResourceList)
@@ -123,8 +562,7 @@ func applyDefaults(spec *ResourceRequirementsWithGoMemLimit, defaults *ResourceR
 			*defaults.Limits.Memory()
 	}
 	if spec.GoMemLimit == "" {
-		spec.GoMemLimit =
-			defaults.GoMemLimit
+		spec.GoMemLimit = scaleGoMemLimit(*spec.Limits.Memory(), defaults)
 	}
 	if spec.Requests == nil {
 		spec.Requests = make(corev1.ResourceList)
@@ -135,6 +573,30 @@ func applyDefaults(spec *ResourceRequirementsWithGoMemLimit, defaults *ResourceR
 	}
 }
 
+// scaleGoMemLimit derives the GOMEMLIMIT for a container from its memory limit, keeping the same ratio between
+// GOMEMLIMIT and memory limit as the defaults. Without an overridden memory limit, this yields the default GOMEMLIMIT.
+// Without this, raising the memory limit of a container would not let its Go runtime make use of the additional
+// memory, and lowering it would risk the container being OOM-killed before the Go runtime starts to collect garbage
+// more aggressively.
+func scaleGoMemLimit(memoryLimit resource.Quantity, defaults *ResourceRequirementsWithGoMemLimit) string {
+	defaultGoMemLimit, err := resource.ParseQuantity(strings.TrimSuffix(defaults.GoMemLimit, "B"))
+	defaultMemoryLimit := defaults.Limits.Memory()
+	if err != nil || defaultMemoryLimit.IsZero() {
+		return defaults.GoMemLimit
+	}
+	goMemLimitBytes := int64(
+		float64(memoryLimit.Value()) * float64(defaultGoMemLimit.Value()) / float64(defaultMemoryLimit.Value()),
+	)
+	switch {
+	case goMemLimitBytes >= mebibyte:
+		return fmt.Sprintf("%dMiB", goMemLimitBytes/mebibyte)
+	case goMemLimitBytes >= kibibyte:
+		return fmt.Sprintf("%dKiB", goMemLimitBytes/kibibyte)
+	default:
+		return fmt.Sprintf("%dB", goMemLimitBytes)
+	}
+}
+
 func (rr ResourceRequirementsWithGoMemLimit) ToResourceRequirements() corev1.ResourceRequirements {
 	return corev1.ResourceRequirements{
 		Limits:   rr.Limits,
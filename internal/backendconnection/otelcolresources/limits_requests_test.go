@@ -5,6 +5,7 @@ package otelcolresources
 
 import (
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -270,4 +271,438 @@ var _ = Describe("limits and requests for the otelcol resources", func() {
 		Expect(resourceSpec.CollectorDeploymentConfigurationReloaderContainerResources.Requests.Storage().IsZero()).To(BeTrue())
 		Expect(resourceSpec.CollectorDeploymentConfigurationReloaderContainerResources.Requests.StorageEphemeral().IsZero()).To(BeTrue())
 	})
+
+	It("should derive GOMEMLIMIT from an overridden memory limit", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetCollectorContainerResources:
+    limits:
+      memory: 1Gi
+  collectorDaemonSetConfigurationReloaderContainerResources:
+    limits:
+      memory: 24Mi
+  collectorDaemonSetFileLogOffsetSynchContainerResources:
+    limits:
+      memory: 1000Ki
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		// 80% of the memory limit, like the default of 400MiB for a 500Mi limit
+		Expect(resourceSpec.CollectorDaemonSetCollectorContainerResources.GoMemLimit).To(Equal("819MiB"))
+		// two thirds of the memory limit, like the default of 8MiB for a 12Mi limit
+		Expect(resourceSpec.CollectorDaemonSetConfigurationReloaderContainerResources.GoMemLimit).To(Equal("16MiB"))
+		// 75% of the memory limit, like the default of 24MiB for a 32Mi limit
+		Expect(resourceSpec.CollectorDaemonSetFileLogOffsetSynchContainerResources.GoMemLimit).To(Equal("750KiB"))
+		// not overridden
+		Expect(resourceSpec.CollectorDeploymentCollectorContainerResources.GoMemLimit).To(Equal("400MiB"))
+	})
+
+	It("should not derive GOMEMLIMIT from the memory limit if it is set explicitly", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetCollectorContainerResources:
+    limits:
+      memory: 1Gi
+    gomemlimit: 700MiB
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorDaemonSetCollectorContainerResources.GoMemLimit).To(Equal("700MiB"))
+	})
+
+	It("should parse the filelog receiver paths", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetFilelogReceiver:
+    include:
+    - /var/log/pods/*/*/*.log
+    - /mnt/logs/pods/*/*/*.log
+    exclude:
+    - /var/log/pods/noisy-namespace_*/*/*.log
+    extraHostPaths:
+    - /mnt/logs/pods
+    extraLogHostPaths:
+    - /data/pod-logs
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resourceSpec.CollectorDaemonSetFilelogReceiver.Include).To(
+			Equal([]string{"/var/log/pods/*/*/*.log", "/mnt/logs/pods/*/*/*.log"}))
+		Expect(resourceSpec.CollectorDaemonSetFilelogReceiver.Exclude).To(
+			Equal([]string{"/var/log/pods/noisy-namespace_*/*/*.log"}))
+		Expect(resourceSpec.CollectorDaemonSetFilelogReceiver.ExtraHostPaths).To(Equal([]string{"/mnt/logs/pods"}))
+		Expect(resourceSpec.CollectorDaemonSetFilelogReceiver.ExtraLogHostPaths).To(Equal([]string{"/data/pod-logs"}))
+	})
+
+	It("should reject an unsupported container runtime", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetFilelogReceiver:
+    containerRuntime: rkt
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("unsupported container runtime \"rkt\"")))
+	})
+
+	It("should reject an unsupported kubeletstats receiver auth type", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetKubeletStatsReceiver:
+    authType: token
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("unsupported kubeletstats receiver auth type \"token\"")))
+	})
+
+	It("should require certificate files for the kubeletstats receiver auth type tls", func() {
+		_, err := tmpFile.WriteString(`
+  collectorDaemonSetKubeletStatsReceiver:
+    authType: tls
+    insecureSkipVerify: true
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("requires a certFile and a keyFile")))
+	})
+
+	It("should read the k8sattributes processor settings", func() {
+		_, err := tmpFile.WriteString(`
+  collectorK8sAttributesProcessor:
+    extraMetadata:
+    - k8s.container.name
+    labels:
+    - key: example.com/team
+      tagName: team
+    annotations:
+    - key: example.com/cost-center
+      from: namespace
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorK8sAttributesProcessor).To(Equal(K8sAttributesProcessorSettings{
+			ExtraMetadata: []string{"k8s.container.name"},
+			Labels:        []K8sAttributesFieldExtraction{{Key: "example.com/team", TagName: "team"}},
+			Annotations:   []K8sAttributesFieldExtraction{{Key: "example.com/cost-center", From: "namespace"}},
+		}))
+	})
+
+	It("should reject an invalid label key for the k8sattributes processor", func() {
+		_, err := tmpFile.WriteString(`
+  collectorK8sAttributesProcessor:
+    labels:
+    - key: "team name"
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("invalid label key \"team name\" for the k8sattributes processor")))
+	})
+
+	It("should reject an unsupported source for the k8sattributes processor", func() {
+		_, err := tmpFile.WriteString(`
+  collectorK8sAttributesProcessor:
+    annotations:
+    - key: example.com/cost-center
+      from: deployment
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring(
+			"unsupported source \"deployment\" for the annotation example.com/cost-center of the k8sattributes processor")))
+	})
+
+	It("should reject an unsupported seccomp profile type for the collector security context", func() {
+		_, err := tmpFile.WriteString(`
+  collectorSecurityContext:
+    seccompProfileType: Localhost
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("unsupported seccomp profile type \"Localhost\"")))
+	})
+
+	It("should parse the collector priority class name", func() {
+		_, err := tmpFile.WriteString(`
+  collectorPriorityClassName: system-node-critical
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorPriorityClassName).To(Equal("system-node-critical"))
+	})
+
+	It("should apply the default termination grace period and preStop sleep", func() {
+		_, err := tmpFile.WriteString(`
+  collectorPriorityClassName: system-node-critical
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*resourceSpec.CollectorTerminationGracePeriodSeconds).To(Equal(int64(60)))
+		Expect(*resourceSpec.CollectorPreStopSleepSeconds).To(Equal(int64(5)))
+	})
+
+	It("should reject a preStop sleep that is not shorter than the termination grace period", func() {
+		_, err := tmpFile.WriteString(`
+  collectorTerminationGracePeriodSeconds: 10
+  collectorPreStopSleepSeconds: 10
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("needs to be shorter than the termination grace period")))
+	})
+
+	It("should parse the collector proxy settings", func() {
+		_, err := tmpFile.WriteString(`
+  collectorProxy:
+    httpProxy: http://proxy.example.com:3128
+    httpsProxy: http://proxy.example.com:3129
+    noProxy: internal.example.com,10.0.0.0/8
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorProxy).To(Equal(CollectorProxySettings{
+			HttpProxy:  "http://proxy.example.com:3128",
+			HttpsProxy: "http://proxy.example.com:3129",
+			NoProxy:    "internal.example.com,10.0.0.0/8",
+		}))
+	})
+
+	It("should reject an invalid collector proxy URL", func() {
+		_, err := tmpFile.WriteString(`
+  collectorProxy:
+    httpsProxy: proxy.example.com
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("invalid collector proxy URL")))
+	})
+
+	It("should default to the daemonset collector mode", func() {
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorMode).To(Equal(CollectorModeDaemonSet))
+		Expect(*resourceSpec.CollectorGatewayReplicas).To(Equal(int32(2)))
+	})
+
+	It("should parse the gateway collector mode", func() {
+		_, err := tmpFile.WriteString(`
+  collectorMode: gateway
+  collectorGatewayReplicas: 3
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorMode).To(Equal(CollectorModeGateway))
+		Expect(*resourceSpec.CollectorGatewayReplicas).To(Equal(int32(3)))
+	})
+
+	It("should reject an unsupported collector mode", func() {
+		_, err := tmpFile.WriteString(`
+  collectorMode: sidecar
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("unsupported collector mode")))
+	})
+
+	It("should reject less than one collector gateway replica", func() {
+		_, err := tmpFile.WriteString(`
+  collectorGatewayReplicas: 0
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("at least 1")))
+	})
+
+	It("should default to the info collector log level", func() {
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorLogLevel).To(Equal(CollectorLogLevelInfo))
+	})
+
+	It("should parse the collector log level", func() {
+		_, err := tmpFile.WriteString(`
+  collectorLogLevel: debug
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorLogLevel).To(Equal(CollectorLogLevelDebug))
+	})
+
+	It("should reject an unsupported collector log level", func() {
+		_, err := tmpFile.WriteString(`
+  collectorLogLevel: verbose
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("unsupported collector log level")))
+	})
+
+	It("should parse the name of the collector configuration export config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigExportConfigMapName: collector-config-copy
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorConfigExportConfigMapName).To(Equal("collector-config-copy"))
+	})
+
+	It("should reject an invalid name for the collector configuration export config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigExportConfigMapName: Collector_Config
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("invalid name \"Collector_Config\"")))
+	})
+
+	It("should parse the name of the collector configuration snippet config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigSnippetConfigMapName: collector-config-snippet
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorConfigSnippetConfigMapName).To(Equal("collector-config-snippet"))
+	})
+
+	It("should reject an invalid name for the collector configuration snippet config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigSnippetConfigMapName: Collector_Snippet
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("invalid name \"Collector_Snippet\"")))
+	})
+
+	It("should parse the name of the collector configuration template config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigTemplateConfigMapName: collector-config-template
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorConfigTemplateConfigMapName).To(Equal("collector-config-template"))
+	})
+
+	It("should reject an invalid name for the collector configuration template config map", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigTemplateConfigMapName: Collector_Template
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("invalid name \"Collector_Template\"")))
+	})
+
+	It("should parse the node coverage check setting", func() {
+		_, err := tmpFile.WriteString(`
+  collectorNodeCoverageCheckEnabled: true
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorNodeCoverageCheckEnabled).To(BeTrue())
+	})
+
+	It("should not set a check frequency for the configuration reloader by default", func() {
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorConfigurationReloaderCheckFrequency).To(BeNil())
+	})
+
+	It("should parse the check frequency of the configuration reloader", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigurationReloaderCheckFrequency: 5s
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorConfigurationReloaderCheckFrequency.Duration).To(Equal(5 * time.Second))
+	})
+
+	It("should reject a check frequency of the configuration reloader below 100ms", func() {
+		_, err := tmpFile.WriteString(`
+  collectorConfigurationReloaderCheckFrequency: 10ms
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("needs to be at least 100ms")))
+	})
+
+	It("should default the collector reconcile retry settings", func() {
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorReconcileRetry.MaxDelay.Duration).To(Equal(5 * time.Minute))
+		Expect(*resourceSpec.CollectorReconcileRetry.Jitter).To(Equal(0.1))
+	})
+
+	It("should parse the collector reconcile retry settings", func() {
+		_, err := tmpFile.WriteString(`
+  collectorReconcileRetry:
+    maxDelay: 90s
+    jitter: 0
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		resourceSpec, err := ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resourceSpec.CollectorReconcileRetry.MaxDelay.Duration).To(Equal(90 * time.Second))
+		Expect(*resourceSpec.CollectorReconcileRetry.Jitter).To(BeZero())
+	})
+
+	It("should reject a collector reconcile retry max delay below one second", func() {
+		_, err := tmpFile.WriteString(`
+  collectorReconcileRetry:
+    maxDelay: 500ms
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("needs to be at least 1s")))
+	})
+
+	It("should reject a collector reconcile retry jitter outside of [0, 1]", func() {
+		_, err := tmpFile.WriteString(`
+  collectorReconcileRetry:
+    jitter: 1.5
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ReadOTelColResourcesConfiguration(tmpFile.Name())
+		Expect(err).To(MatchError(ContainSubstring("needs to be between 0 and 1")))
+	})
 })
@@ -14,13 +14,16 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
@@ -36,6 +39,7 @@ type OTelColResourceManager struct {
 	OTelColResourceSpecs             *OTelColResourceSpecs
 	IsIPv6Cluster                    bool
 	DevelopmentMode                  bool
+	DebugFileExport                  bool
 	obsoleteResourcesHaveBeenDeleted atomic.Bool
 }
 
@@ -46,6 +50,22 @@ const (
 var (
 	knownIrrelevantPatches = []string{bogusDeploymentPatch}
 
+	// managedResourceKinds lists all kinds of resources that are created by assembleDesiredState.
+	managedResourceKinds = []struct {
+		gvk        schema.GroupVersionKind
+		namespaced bool
+	}{
+		{gvk: corev1.SchemeGroupVersion.WithKind("ServiceAccount"), namespaced: true},
+		{gvk: corev1.SchemeGroupVersion.WithKind("ConfigMap"), namespaced: true},
+		{gvk: corev1.SchemeGroupVersion.WithKind("Service"), namespaced: true},
+		{gvk: rbacv1.SchemeGroupVersion.WithKind("Role"), namespaced: true},
+		{gvk: rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), namespaced: true},
+		{gvk: rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), namespaced: false},
+		{gvk: rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"), namespaced: false},
+		{gvk: appsv1.SchemeGroupVersion.WithKind("DaemonSet"), namespaced: true},
+		{gvk: appsv1.SchemeGroupVersion.WithKind("Deployment"), namespaced: true},
+	}
+
 	dummyImagesForDeletion = util.Images{
 		OperatorImage:              "ghcr.io/dash0hq/operator-controller:latest",
 		InitContainerImage:         "ghcr.io/dash0hq/instrumentation:latest",
@@ -67,22 +87,128 @@ func (m *OTelColResourceManager) CreateOrUpdateOpenTelemetryCollectorResources(
 	if err != nil {
 		return false, false, err
 	}
+	if operatorConfigurationResource != nil && util.IsReconciliationPaused(&operatorConfigurationResource.ObjectMeta) {
+		logger.Info(fmt.Sprintf(
+			"The operator configuration resource has the annotation %s=true, not updating the OpenTelemetry "+
+				"collector resources.",
+			util.PausedAnnotationKey,
+		))
+		return false, false, nil
+	}
+
+	config, err := m.assembleOTelColConfig(
+		ctx,
+		namespace,
+		images,
+		allMonitoringResources,
+		monitoringResource,
+		operatorConfigurationResource,
+		logger,
+	)
+	if err != nil {
+		return false, false, err
+	}
+	desiredState, err := assembleDesiredStateForUpsert(
+		config,
+		allMonitoringResources,
+		m.OTelColResourceSpecs,
+	)
+	if err != nil {
+		return false, false, err
+	}
+	resourcesHaveBeenCreated := false
+	resourcesHaveBeenUpdated := false
+	// A failure to create or update one resource does not stop the others from being reconciled, so that each attempt
+	// makes as much progress as possible. Resources that have already been created in a previous, partially failed
+	// attempt are updated in place by the next attempt.
+	var upsertErrors []error
+	for _, wrapper := range desiredState {
+		desiredResource := wrapper.object
+		isNew, isChanged, err := m.createOrUpdateResource(
+			ctx,
+			desiredResource,
+			logger,
+		)
+		if err != nil {
+			upsertErrors = append(upsertErrors, fmt.Errorf(
+				"failed to create or update %s %s/%s: %w",
+				desiredResource.GetObjectKind().GroupVersionKind().Kind,
+				desiredResource.GetNamespace(),
+				desiredResource.GetName(),
+				err,
+			))
+		} else if isNew {
+			resourcesHaveBeenCreated = true
+		} else if isChanged {
+			resourcesHaveBeenUpdated = true
+		}
+	}
+	if len(upsertErrors) > 0 {
+		// Do not delete obsolete or orphaned resources as long as their replacements could not be created, e.g. when
+		// switching the collector mode.
+		return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, errors.Join(upsertErrors...)
+	}
 
+	if err = m.deleteObsoleteResourcesFromPreviousOperatorVersions(ctx, namespace, logger); err != nil {
+		return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, err
+	}
+	if err = m.deleteOrphanedResources(ctx, namespace, desiredState, logger); err != nil {
+		return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, err
+	}
+
+	return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, nil
+}
+
+// assembleOTelColConfig collects all settings that determine the desired state of the OpenTelemetry collector
+// resources.
+func (m *OTelColResourceManager) assembleOTelColConfig(
+	ctx context.Context,
+	namespace string,
+	images util.Images,
+	allMonitoringResources []dash0v1alpha1.Dash0Monitoring,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	operatorConfigurationResource *dash0v1alpha1.Dash0OperatorConfiguration,
+	logger *logr.Logger,
+) (*oTelColConfig, error) {
 	var export *dash0v1alpha1.Export
 	if monitoringResource != nil {
 		export = monitoringResource.Spec.Export
+		if export != nil && export.Dash0 != nil && !util.IsSecretRefAllowedForMonitoringResource(
+			export.Dash0.Authorization.SecretRef,
+			monitoringResource.Namespace,
+		) {
+			return nil, fmt.Errorf(
+				"the Dash0Monitoring resource %s/%s references a secret in the namespace %s, but monitoring resources "+
+					"can only reference secrets in their own namespace",
+				monitoringResource.Namespace,
+				monitoringResource.Name,
+				export.Dash0.Authorization.SecretRef.Namespace,
+			)
+		}
 	}
 	if export == nil {
 		if operatorConfigurationResource == nil {
-			return false, false, fmt.Errorf("the provided Dash0Monitoring resource does not have an export " +
+			return nil, fmt.Errorf("the provided Dash0Monitoring resource does not have an export " +
 				"configuration and no Dash0OperatorConfiguration resource has been found")
 		} else if operatorConfigurationResource.Spec.Export == nil {
-			return false, false, fmt.Errorf("the provided Dash0Monitoring resource does not have an export " +
+			return nil, fmt.Errorf("the provided Dash0Monitoring resource does not have an export " +
 				"configuration and the Dash0OperatorConfiguration resource does not have one either")
 		} else {
 			export = operatorConfigurationResource.Spec.Export
 		}
 	}
+	var err error
+	export = export.DeepCopy()
+	if export.Dash0 != nil {
+		if export.Dash0.Authorization, err = m.mirrorAuthorizationSecret(
+			ctx,
+			namespace,
+			export.Dash0.Authorization,
+			logger,
+		); err != nil {
+			return nil, err
+		}
+	}
 
 	selfMonitoringConfiguration, err :=
 		selfmonitoringapiaccess.ConvertOperatorConfigurationResourceToSelfMonitoringConfiguration(
@@ -94,54 +220,156 @@ func (m *OTelColResourceManager) CreateOrUpdateOpenTelemetryCollectorResources(
 			SelfMonitoringEnabled: false,
 		}
 	}
+	if selfMonitoringConfiguration.Export.Dash0 != nil {
+		if selfMonitoringConfiguration.Export.Dash0.Authorization, err = m.mirrorAuthorizationSecret(
+			ctx,
+			namespace,
+			selfMonitoringConfiguration.Export.Dash0.Authorization,
+			logger,
+		); err != nil {
+			return nil, err
+		}
+	}
 
 	kubernetesInfrastructureMetricsCollectionEnabled := true
+	kubernetesEventsCollectionEnabled := false
+	clusterName := ""
 	if operatorConfigurationResource != nil {
 		kubernetesInfrastructureMetricsCollectionEnabled =
 			util.ReadBoolPointerWithDefault(operatorConfigurationResource.Spec.KubernetesInfrastructureMetricsCollectionEnabled, true)
+		kubernetesEventsCollectionEnabled =
+			util.ReadBoolPointerWithDefault(operatorConfigurationResource.Spec.KubernetesEventsCollectionEnabled, false)
+		clusterName = operatorConfigurationResource.Spec.ClusterName
 	}
 
-	config := &oTelColConfig{
+	collectorConfigSnippet, err := m.readCollectorConfigSnippet(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	daemonSetCollectorConfigTemplate, deploymentCollectorConfigTemplate, err :=
+		m.readCollectorConfigTemplates(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oTelColConfig{
 		Namespace:                               namespace,
 		NamePrefix:                              m.OTelCollectorNamePrefix,
 		Export:                                  *export,
+		ClusterName:                             clusterName,
 		SelfMonitoringAndApiAccessConfiguration: selfMonitoringConfiguration,
 		KubernetesInfrastructureMetricsCollectionEnabled: kubernetesInfrastructureMetricsCollectionEnabled,
-		Images:          images,
-		IsIPv6Cluster:   m.IsIPv6Cluster,
-		DevelopmentMode: m.DevelopmentMode,
-	}
-	desiredState, err := assembleDesiredStateForUpsert(
-		config,
-		allMonitoringResources,
-		m.OTelColResourceSpecs,
-	)
-	if err != nil {
-		return false, false, err
-	}
-	resourcesHaveBeenCreated := false
-	resourcesHaveBeenUpdated := false
-	for _, wrapper := range desiredState {
-		desiredResource := wrapper.object
-		isNew, isChanged, err := m.createOrUpdateResource(
+		KubernetesEventsCollectionEnabled:                kubernetesEventsCollectionEnabled,
+		DatasetsPerNamespace:                             collectDatasetsPerNamespace(allMonitoringResources),
+		NamespacesWithoutSignalCollection:                collectNamespacesWithoutSignalCollection(allMonitoringResources),
+		FiltersPerNamespace:                              collectFiltersPerNamespace(allMonitoringResources),
+		TransformsPerNamespace:                           collectTransformsPerNamespace(allMonitoringResources),
+		PodLogCollectionDisabled:                         isPodLogCollectionDisabled(allMonitoringResources),
+		Images:                                           images,
+		IsIPv6Cluster:                                    m.IsIPv6Cluster,
+		DevelopmentMode:                                  m.DevelopmentMode,
+		DebugFileExport:                                  m.DebugFileExport,
+		FilelogReceiverPaths:                             m.OTelColResourceSpecs.CollectorDaemonSetFilelogReceiver,
+		KubeletStatsReceiverSettings:                     m.OTelColResourceSpecs.CollectorDaemonSetKubeletStatsReceiver,
+		K8sAttributesProcessorSettings:                   m.OTelColResourceSpecs.CollectorK8sAttributesProcessor,
+		SecurityContextSettings:                          m.OTelColResourceSpecs.CollectorSecurityContext,
+		PriorityClassName:                                m.OTelColResourceSpecs.CollectorPriorityClassName,
+		TerminationGracePeriodSeconds:                    m.OTelColResourceSpecs.CollectorTerminationGracePeriodSeconds,
+		PreStopSleepSeconds:                              m.OTelColResourceSpecs.CollectorPreStopSleepSeconds,
+		PersistentSendingQueue:                           m.OTelColResourceSpecs.CollectorPersistentSendingQueue,
+		Proxy:                                            m.OTelColResourceSpecs.CollectorProxy,
+		GatewayMode:                                      m.OTelColResourceSpecs.CollectorMode == CollectorModeGateway,
+		GatewayReplicas: ptr.Deref(
+			m.OTelColResourceSpecs.CollectorGatewayReplicas,
+			*DefaultOTelColResourceSpecs.CollectorGatewayReplicas,
+		),
+		CollectorLogLevel:                   m.OTelColResourceSpecs.CollectorLogLevel,
+		ConfigurationReloaderCheckFrequency: m.OTelColResourceSpecs.CollectorConfigurationReloaderCheckFrequency,
+		CollectorConfigExportConfigMapName:  m.OTelColResourceSpecs.CollectorConfigExportConfigMapName,
+		CollectorConfigSnippet:              collectorConfigSnippet,
+		DaemonSetCollectorConfigTemplate:    daemonSetCollectorConfigTemplate,
+		DeploymentCollectorConfigTemplate:   deploymentCollectorConfigTemplate,
+	}, nil
+}
+
+// mirrorAuthorizationSecret makes sure that a secret in a different namespace which holds the Dash0 authorization token
+// is mirrored into the collector namespace, and returns the authorization settings that the collector pods need to use.
+func (m *OTelColResourceManager) mirrorAuthorizationSecret(
+	ctx context.Context,
+	namespace string,
+	authorization dash0v1alpha1.Authorization,
+	logger *logr.Logger,
+) (dash0v1alpha1.Authorization, error) {
+	if authorization.Token == nil || *authorization.Token == "" {
+		if err := util.MirrorSecret(
 			ctx,
-			desiredResource,
+			m.Client,
+			m.Scheme,
+			authorization.SecretRef,
+			namespace,
+			m.DeploymentSelfReference,
 			logger,
-		)
-		if err != nil {
-			return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, err
-		} else if isNew {
-			resourcesHaveBeenCreated = true
-		} else if isChanged {
-			resourcesHaveBeenUpdated = true
+		); err != nil {
+			return authorization, err
 		}
 	}
+	return util.ResolveMirroredSecretRef(authorization, namespace), nil
+}
 
-	if err = m.deleteObsoleteResourcesFromPreviousOperatorVersions(ctx, namespace, logger); err != nil {
-		return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, err
+// readCollectorConfigSnippet reads the user-provided collector configuration snippet from the config map referenced by
+// collectorConfigSnippetConfigMapName, if any. A missing config map or key is treated as an error instead of silently
+// falling back to the generated configuration.
+func (m *OTelColResourceManager) readCollectorConfigSnippet(ctx context.Context, namespace string) (string, error) {
+	configMapName := m.OTelColResourceSpecs.CollectorConfigSnippetConfigMapName
+	if configMapName == "" {
+		return "", nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := m.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configMapName}, configMap); err != nil {
+		return "", fmt.Errorf(
+			"cannot read the collector configuration snippet config map %s/%s: %w", namespace, configMapName, err)
+	}
+	snippet, ok := configMap.Data[collectorConfigurationYaml]
+	if !ok {
+		return "", fmt.Errorf(
+			"the collector configuration snippet config map %s/%s does not have the key %s",
+			namespace,
+			configMapName,
+			collectorConfigurationYaml,
+		)
 	}
+	return snippet, nil
+}
 
-	return resourcesHaveBeenCreated, resourcesHaveBeenUpdated, nil
+// readCollectorConfigTemplates reads the user-provided templates for the configuration of the daemonset collector and
+// the deployment collector from the config map referenced by collectorConfigTemplateConfigMapName, if any. An empty
+// string is returned for a template that is not provided, the built-in template is used for that collector then. A
+// missing config map, or a config map without any of the two keys, is treated as an error.
+func (m *OTelColResourceManager) readCollectorConfigTemplates(
+	ctx context.Context,
+	namespace string,
+) (string, string, error) {
+	configMapName := m.OTelColResourceSpecs.CollectorConfigTemplateConfigMapName
+	if configMapName == "" {
+		return "", "", nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := m.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configMapName}, configMap); err != nil {
+		return "", "", fmt.Errorf(
+			"cannot read the collector configuration template config map %s/%s: %w", namespace, configMapName, err)
+	}
+	daemonSetTemplate := configMap.Data[daemonSetCollectorConfigurationTemplateKey]
+	deploymentTemplate := configMap.Data[deploymentCollectorConfigurationTemplateKey]
+	if daemonSetTemplate == "" && deploymentTemplate == "" {
+		return "", "", fmt.Errorf(
+			"the collector configuration template config map %s/%s has neither the key %s nor the key %s",
+			namespace,
+			configMapName,
+			daemonSetCollectorConfigurationTemplateKey,
+			deploymentCollectorConfigurationTemplateKey,
+		)
+	}
+	return daemonSetTemplate, deploymentTemplate, nil
 }
 
 func (m *OTelColResourceManager) findOperatorConfigurationResource(
@@ -179,10 +407,19 @@ func (m *OTelColResourceManager) createOrUpdateResource(
 			return false, false, err
 		}
 		err = m.createResource(ctx, desiredResource, logger)
-		if err != nil {
+		if err == nil {
+			return true, false, nil
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			return false, false, err
+		}
+		// The resource has been created in the meantime, for example by a concurrent reconcile request or by a
+		// previous attempt whose result is not yet visible in the cache; fall back to updating it.
+		if err = m.Client.Get(ctx, client.ObjectKeyFromObject(desiredResource), existingResource); err != nil {
 			return false, false, err
 		}
-		return true, false, nil
+		hasChanged, err := m.updateResource(ctx, existingResource, desiredResource, logger)
+		return false, hasChanged, err
 	} else {
 		// object might need to be updated
 		hasChanged, err := m.updateResource(ctx, existingResource, desiredResource, logger)
@@ -248,6 +485,7 @@ func (m *OTelColResourceManager) updateResource(
 	patchResult, err := patch.DefaultPatchMaker.Calculate(
 		existingResource,
 		desiredResource,
+		patch.IgnoreStatusFields(),
 		patch.IgnoreField("kind"),
 		patch.IgnoreField("apiVersion"),
 	)
@@ -268,12 +506,20 @@ func (m *OTelColResourceManager) updateResource(
 		return false, err
 	}
 
+	// The patch is calculated from our desired object, which lacks all fields that the API server fills in with
+	// defaults. Hence, a non-empty patch does not necessarily mean that the update has actually changed anything. The
+	// API server does not bump the resource version for no-op updates, so comparing the resource version before and
+	// after the update tells us whether the resource has really been changed.
+	if desiredResource.GetResourceVersion() == existingResource.GetResourceVersion() {
+		return false, nil
+	}
+
 	if m.DevelopmentMode {
 		logger.Info(fmt.Sprintf(
 			"resource %s/%s was out of sync and has been reconciled",
 			desiredResource.GetNamespace(),
 			desiredResource.GetName(),
-		))
+		), "patch", string(patchResult.Patch))
 	}
 
 	return hasChanged, nil
@@ -288,8 +534,12 @@ func (m *OTelColResourceManager) setOwnerReference(
 	object client.Object,
 	logger *logr.Logger,
 ) error {
+	// The owner is the operator's own deployment, which lives in the same namespace as the collector resources. The
+	// Dash0Monitoring resources cannot be used as owners, since they live in other namespaces and owner references
+	// across namespaces are not supported.
 	if object.GetNamespace() == "" {
-		// cluster scoped resources like ClusterRole and ClusterRoleBinding cannot have a namespace-scoped owner.
+		// cluster scoped resources like ClusterRole and ClusterRoleBinding cannot have a namespace-scoped owner, they
+		// are cleaned up via their labels in DeleteResources instead.
 		return nil
 	}
 	if err := controllerutil.SetControllerReference(&appsv1.Deployment{
@@ -311,7 +561,8 @@ func (m *OTelColResourceManager) amendDeploymentAndDaemonSetWithSelfReferenceUID
 	if name == DaemonSetName(m.OTelCollectorNamePrefix) {
 		daemonset := desiredResource.(*appsv1.DaemonSet)
 		addSelfReferenceUidToAllContainers(&daemonset.Spec.Template.Spec.Containers, "K8S_DAEMONSET_UID", uid)
-	} else if name == DeploymentName(m.OTelCollectorNamePrefix) {
+	} else if name == DeploymentName(m.OTelCollectorNamePrefix) ||
+		name == GatewayDeploymentName(m.OTelCollectorNamePrefix) {
 		deployment := desiredResource.(*appsv1.Deployment)
 		addSelfReferenceUidToAllContainers(&deployment.Spec.Template.Spec.Containers, "K8S_DEPLOYMENT_UID", uid)
 	}
@@ -354,9 +605,11 @@ func (m *OTelColResourceManager) DeleteResources(
 		Export:                                  dash0v1alpha1.Export{},
 		SelfMonitoringAndApiAccessConfiguration: selfmonitoringapiaccess.SelfMonitoringAndApiAccessConfiguration{SelfMonitoringEnabled: false},
 		KubernetesInfrastructureMetricsCollectionEnabled: kubernetesInfrastructureMetricsCollectionEnabled,
-		Images:          dummyImagesForDeletion,
-		IsIPv6Cluster:   m.IsIPv6Cluster,
-		DevelopmentMode: m.DevelopmentMode,
+		Images:                             dummyImagesForDeletion,
+		IsIPv6Cluster:                      m.IsIPv6Cluster,
+		DevelopmentMode:                    m.DevelopmentMode,
+		DebugFileExport:                    m.DebugFileExport,
+		CollectorConfigExportConfigMapName: m.OTelColResourceSpecs.CollectorConfigExportConfigMapName,
 	}
 	desiredResources, err := assembleDesiredStateForDelete(config, m.OTelColResourceSpecs)
 	if err != nil {
@@ -384,12 +637,85 @@ func (m *OTelColResourceManager) DeleteResources(
 			))
 		}
 	}
+	// Remove all remaining resources that carry our labels, in particular cluster-scoped resources (which have no
+	// owner reference and are hence not garbage-collected by Kubernetes) that have been created with a different name
+	// prefix.
+	if err = m.deleteOrphanedResources(ctx, namespace, nil, logger); err != nil {
+		allErrors = append(allErrors, err)
+	}
 	if len(allErrors) > 0 {
 		return errors.Join(allErrors...)
 	}
 	return nil
 }
 
+// deleteOrphanedResources deletes all resources that carry the labels of the resources managed by the operator, but
+// are not part of the given desired state. This cleans up resources that have been created with a different name
+// prefix (for example after the collector name prefix has been changed) as well as resources that are no longer needed
+// with the current configuration, e.g. the cluster metrics collector deployment after Kubernetes infrastructure
+// metrics collection has been disabled.
+func (m *OTelColResourceManager) deleteOrphanedResources(
+	ctx context.Context,
+	namespace string,
+	desiredState []clientObject,
+	logger *logr.Logger,
+) error {
+	desiredResources := make(map[string]bool, len(desiredState))
+	for _, wrapper := range desiredState {
+		// The type meta of the desired objects might have been cleared when they were created or updated, hence the
+		// kind is looked up from the scheme.
+		gvk, err := apiutil.GVKForObject(wrapper.object, m.Client.Scheme())
+		if err != nil {
+			return err
+		}
+		desiredResources[orphanCheckKey(gvk.Kind, wrapper.object.GetNamespace(), wrapper.object.GetName())] = true
+	}
+
+	var allErrors []error
+	for _, managedKind := range managedResourceKinds {
+		listOptions := []client.ListOption{client.MatchingLabels(labels(false))}
+		if managedKind.namespaced {
+			listOptions = append(listOptions, client.InNamespace(namespace))
+		}
+		existingResources := &metav1.PartialObjectMetadataList{}
+		existingResources.SetGroupVersionKind(managedKind.gvk.GroupVersion().WithKind(managedKind.gvk.Kind + "List"))
+		if err := m.Client.List(ctx, existingResources, listOptions...); err != nil {
+			allErrors = append(allErrors, err)
+			continue
+		}
+		for _, existingResource := range existingResources.Items {
+			if desiredResources[orphanCheckKey(
+				managedKind.gvk.Kind,
+				existingResource.GetNamespace(),
+				existingResource.GetName(),
+			)] || existingResource.GetDeletionTimestamp() != nil {
+				continue
+			}
+			existingResource.SetGroupVersionKind(managedKind.gvk)
+			if err := m.Client.Delete(ctx, &existingResource); err != nil {
+				if !apierrors.IsNotFound(err) {
+					allErrors = append(allErrors, err)
+				}
+			} else {
+				logger.Info(fmt.Sprintf(
+					"deleted orphaned %s %s/%s",
+					managedKind.gvk.Kind,
+					existingResource.GetNamespace(),
+					existingResource.GetName(),
+				))
+			}
+		}
+	}
+	if len(allErrors) > 0 {
+		return errors.Join(allErrors...)
+	}
+	return nil
+}
+
+func orphanCheckKey(kind string, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
 func (m *OTelColResourceManager) deleteObsoleteResourcesFromPreviousOperatorVersions(
 	ctx context.Context,
 	namespace string,
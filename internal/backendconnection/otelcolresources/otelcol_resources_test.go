@@ -10,11 +10,14 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -114,6 +117,34 @@ var _ = Describe("The OpenTelemetry Collector resource manager", Ordered, func()
 			Expect(isChanged).To(BeFalse())
 			verifyObject(ctx, testResource)
 		})
+
+		It("should report that nothing has changed for an object with fields defaulted by the API server", func() {
+			// The desired deployment leaves out all fields that the API server fills in with defaults (strategy,
+			// revision history limit, image pull policy etc.), so the existing deployment differs from the desired one
+			// although updating it does not change anything.
+			err := oTelColResourceManager.createResource(ctx, testDeploymentWithoutDefaults(), &logger)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, testDeploymentWithoutDefaults())).To(Succeed())
+			}()
+			existingResource := &appsv1.Deployment{}
+			Expect(k8sClient.Get(
+				ctx,
+				client.ObjectKeyFromObject(testDeploymentWithoutDefaults()),
+				existingResource,
+			)).To(Succeed())
+			Expect(existingResource.Spec.RevisionHistoryLimit).ToNot(BeNil())
+
+			hasChanged, err := oTelColResourceManager.updateResource(
+				ctx,
+				existingResource,
+				testDeploymentWithoutDefaults(),
+				&logger,
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasChanged).To(BeFalse())
+		})
 	})
 
 	Describe("when creating all OpenTelemetry collector resources", func() {
@@ -161,6 +192,82 @@ var _ = Describe("The OpenTelemetry Collector resource manager", Ordered, func()
 			VerifyCollectorResources(ctx, k8sClient, OperatorNamespace)
 		})
 
+		It("should route telemetry from namespaces with different datasets to separate Dash0 exporters", func() {
+			CreateDefaultOperatorConfigurationResource(
+				ctx,
+				k8sClient,
+			)
+			monitoringResourceTeamA := dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      MonitoringResourceName,
+					Namespace: "team-a-namespace",
+				},
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Dataset: "team-a-dataset",
+				},
+			}
+			monitoringResourceTeamB := dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      MonitoringResourceName,
+					Namespace: "team-b-namespace",
+				},
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Dataset: "team-b-dataset",
+				},
+			}
+
+			_, _, err := oTelColResourceManager.CreateOrUpdateOpenTelemetryCollectorResources(
+				ctx,
+				OperatorNamespace,
+				TestImages,
+				[]dash0v1alpha1.Dash0Monitoring{monitoringResourceTeamA, monitoringResourceTeamB},
+				&monitoringResourceTeamB,
+				&logger,
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: OperatorNamespace,
+				Name:      ExpectedDaemonSetCollectorConfigMapName,
+			}, configMap)).To(Succeed())
+			collectorConfig := parseConfigMapContent(configMap)
+
+			// The default Dash0 exporter must not have been modified by either monitoring resource.
+			Expect(readFromMap(
+				collectorConfig,
+				[]string{"exporters", "otlp/dash0", "headers", util.Dash0DatasetHeaderName},
+			)).To(BeNil())
+			Expect(readFromMap(
+				collectorConfig,
+				[]string{"exporters", "otlp/dash0-dataset-0", "headers", util.Dash0DatasetHeaderName},
+			)).To(Equal("team-a-dataset"))
+			Expect(readFromMap(
+				collectorConfig,
+				[]string{"exporters", "otlp/dash0-dataset-1", "headers", util.Dash0DatasetHeaderName},
+			)).To(Equal("team-b-dataset"))
+
+			pipelines := readPipelines(collectorConfig)
+			for _, signal := range []string{"traces", "metrics", "logs"} {
+				routingConnector := fmt.Sprintf("routing/%s", signal)
+				Expect(readPipelineExporters(pipelines, fmt.Sprintf("%s/downstream", signal))).To(
+					Equal([]interface{}{routingConnector}))
+				Expect(readPipelineExporters(pipelines, fmt.Sprintf("%s/downstream-default", signal))).To(
+					ContainElement("otlp/dash0"))
+				Expect(readPipelineExporters(pipelines, fmt.Sprintf("%s/downstream-dataset-0", signal))).To(
+					ContainElement("otlp/dash0-dataset-0"))
+				Expect(readPipelineExporters(pipelines, fmt.Sprintf("%s/downstream-dataset-1", signal))).To(
+					ContainElement("otlp/dash0-dataset-1"))
+
+				routingTable := readFromMap(collectorConfig, []string{"connectors", routingConnector, "table"}).([]interface{})
+				Expect(routingTable).To(HaveLen(2))
+				Expect(routingTable[0].(map[string]interface{})["statement"]).To(
+					Equal("route() where resource.attributes[\"k8s.namespace.name\"] == \"team-a-namespace\""))
+				Expect(routingTable[1].(map[string]interface{})["statement"]).To(
+					Equal("route() where resource.attributes[\"k8s.namespace.name\"] == \"team-b-namespace\""))
+			}
+		})
+
 		It("should fail if the monitoring resource has no export and there is no operator configuration resource", func() {
 			monitoringResource := dash0v1alpha1.Dash0Monitoring{
 				Spec: dash0v1alpha1.Dash0MonitoringSpec{},
@@ -203,6 +310,38 @@ var _ = Describe("The OpenTelemetry Collector resource manager", Ordered, func()
 			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, OperatorNamespace)
 		})
 
+		It("should fail if the monitoring resource references a secret in a different namespace", func() {
+			monitoringResource := dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: MonitoringResourceDefaultObjectMeta,
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint: EndpointDash0Test,
+							Authorization: dash0v1alpha1.Authorization{
+								SecretRef: &dash0v1alpha1.SecretRef{
+									Name:      "database-credentials",
+									Key:       "password",
+									Namespace: "other-team",
+								},
+							},
+						},
+					},
+				},
+			}
+			_, _, err := oTelColResourceManager.CreateOrUpdateOpenTelemetryCollectorResources(
+				ctx,
+				OperatorNamespace,
+				TestImages,
+				[]dash0v1alpha1.Dash0Monitoring{monitoringResource},
+				&monitoringResource,
+				&logger,
+			)
+			Expect(err).To(MatchError(ContainSubstring(
+				"references a secret in the namespace other-team, but monitoring resources can only reference " +
+					"secrets in their own namespace")))
+			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, OperatorNamespace)
+		})
+
 		It("should delete outdated resources from older operator versions", func() {
 			nameOfOutdatedResources := fmt.Sprintf("%s-opentelemetry-collector-agent", NamePrefix)
 			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
@@ -264,6 +403,82 @@ var _ = Describe("The OpenTelemetry Collector resource manager", Ordered, func()
 		})
 	})
 
+	Describe("when the name prefix has been changed", func() {
+		It("should delete the resources that have been created with the previous name prefix", func() {
+			previousNamePrefix := "previous-prefix"
+			oTelColResourceManagerWithPreviousPrefix := &OTelColResourceManager{
+				Client:                  k8sClient,
+				Scheme:                  k8sClient.Scheme(),
+				DeploymentSelfReference: DeploymentSelfReference,
+				OTelCollectorNamePrefix: previousNamePrefix,
+				OTelColResourceSpecs:    &DefaultOTelColResourceSpecs,
+				DevelopmentMode:         true,
+			}
+			_, _, err :=
+				oTelColResourceManagerWithPreviousPrefix.CreateOrUpdateOpenTelemetryCollectorResources(
+					ctx,
+					OperatorNamespace,
+					TestImages,
+					[]dash0v1alpha1.Dash0Monitoring{*monitoringResource},
+					monitoringResource,
+					&logger,
+				)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Namespace: OperatorNamespace,
+				Name:      DaemonSetName(previousNamePrefix),
+			}, &appsv1.DaemonSet{})).To(Succeed())
+
+			_, _, err =
+				oTelColResourceManager.CreateOrUpdateOpenTelemetryCollectorResources(
+					ctx,
+					OperatorNamespace,
+					TestImages,
+					[]dash0v1alpha1.Dash0Monitoring{*monitoringResource},
+					monitoringResource,
+					&logger,
+				)
+			Expect(err).ToNot(HaveOccurred())
+
+			VerifyCollectorResources(ctx, k8sClient, OperatorNamespace)
+			VerifyResourceDoesNotExist(
+				ctx,
+				k8sClient,
+				OperatorNamespace,
+				DaemonSetCollectorConfigConfigMapName(previousNamePrefix),
+				&corev1.ConfigMap{},
+			)
+			VerifyResourceDoesNotExist(
+				ctx,
+				k8sClient,
+				OperatorNamespace,
+				ServiceName(previousNamePrefix),
+				&corev1.Service{},
+			)
+			VerifyResourceDoesNotExist(
+				ctx,
+				k8sClient,
+				"",
+				DaemonSetClusterRoleName(previousNamePrefix),
+				&rbacv1.ClusterRole{},
+			)
+			VerifyResourceDoesNotExist(
+				ctx,
+				k8sClient,
+				OperatorNamespace,
+				DaemonSetName(previousNamePrefix),
+				&appsv1.DaemonSet{},
+			)
+			VerifyResourceDoesNotExist(
+				ctx,
+				k8sClient,
+				OperatorNamespace,
+				DeploymentName(previousNamePrefix),
+				&appsv1.Deployment{},
+			)
+		})
+	})
+
 	Describe("when OpenTelemetry collector resources have been modified externally", func() {
 		It("should reconcile the resources back into the desired state", func() {
 			_, _, err :=
@@ -436,9 +651,70 @@ var _ = Describe("The OpenTelemetry Collector resource manager", Ordered, func()
 
 			VerifyCollectorResourcesDoNotExist(ctx, k8sClient, OperatorNamespace)
 		})
+
+		It("should delete cluster-scoped resources that have been created with a different name prefix", func() {
+			clusterRoleName := DaemonSetClusterRoleName("previous-prefix")
+			Expect(k8sClient.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   clusterRoleName,
+					Labels: labels(false),
+				},
+			})).To(Succeed())
+			unrelatedClusterRoleName := "unrelated-cluster-role"
+			Expect(k8sClient.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: unrelatedClusterRoleName,
+				},
+			})).To(Succeed())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: unrelatedClusterRoleName},
+				}))).To(Succeed())
+			})
+
+			err := oTelColResourceManager.DeleteResources(
+				ctx,
+				OperatorNamespace,
+				&logger,
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			VerifyResourceDoesNotExist(ctx, k8sClient, "", clusterRoleName, &rbacv1.ClusterRole{})
+			Expect(k8sClient.Get(
+				ctx,
+				client.ObjectKey{Name: unrelatedClusterRoleName},
+				&rbacv1.ClusterRole{},
+			)).To(Succeed())
+		})
 	})
 })
 
+func testDeploymentWithoutDefaults() *appsv1.Deployment {
+	labels := map[string]string{"app": "test-deployment"}
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: OperatorNamespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "test-container",
+						Image: "ubuntu",
+					}},
+				},
+			},
+		},
+	}
+}
+
 func verifyObject(ctx context.Context, testObject *corev1.ConfigMap) {
 	object := &corev1.ConfigMap{}
 	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(testObject), object)
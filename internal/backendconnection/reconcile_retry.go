@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package backendconnection
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
+)
+
+const collectorReconcileRetryBaseDelay = 1 * time.Second
+
+type jitteredRateLimiter struct {
+	workqueue.TypedRateLimiter[reconcile.Request]
+	jitter float64
+}
+
+// NewCollectorReconcileRateLimiter creates the rate limiter for the controllers that reconcile the OpenTelemetry
+// collector resources. When a reconcile request fails, it is requeued with a delay that starts at one second and
+// doubles with each consecutive failure of the same request, up to the configured maximum delay. Each delay is
+// extended by a random fraction of up to the configured jitter.
+func NewCollectorReconcileRateLimiter(
+	settings otelcolresources.CollectorReconcileRetrySettings,
+) workqueue.TypedRateLimiter[reconcile.Request] {
+	defaults := otelcolresources.DefaultOTelColResourceSpecs.CollectorReconcileRetry
+	maxDelay := defaults.MaxDelay.Duration
+	if settings.MaxDelay != nil {
+		maxDelay = settings.MaxDelay.Duration
+	}
+	return &jitteredRateLimiter{
+		TypedRateLimiter: workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](
+			collectorReconcileRetryBaseDelay,
+			maxDelay,
+		),
+		jitter: ptr.Deref(settings.Jitter, *defaults.Jitter),
+	}
+}
+
+func (l *jitteredRateLimiter) When(request reconcile.Request) time.Duration {
+	delay := l.TypedRateLimiter.When(request)
+	if l.jitter <= 0 {
+		// wait.Jitter treats a factor <= 0 as 1.0, so we need to handle "no jitter" explicitly
+		return delay
+	}
+	return wait.Jitter(delay, l.jitter)
+}
+
+// ReconcileRateLimiter returns the rate limiter for reconcile requests that create or update the OpenTelemetry
+// collector resources, according to the collector reconcile retry settings.
+func (m *BackendConnectionManager) ReconcileRateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	if m == nil || m.OTelColResourceManager == nil || m.OTelColResourceSpecs == nil {
+		return NewCollectorReconcileRateLimiter(otelcolresources.CollectorReconcileRetrySettings{})
+	}
+	return NewCollectorReconcileRateLimiter(m.OTelColResourceSpecs.CollectorReconcileRetry)
+}
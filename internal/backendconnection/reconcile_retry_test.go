@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package backendconnection
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The collector reconcile rate limiter", func() {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "namespace", Name: "name"}}
+
+	It("should back off exponentially up to the maximum delay", func() {
+		rateLimiter := NewCollectorReconcileRateLimiter(otelcolresources.CollectorReconcileRetrySettings{
+			MaxDelay: &metav1.Duration{Duration: 5 * time.Second},
+			Jitter:   ptr.To(0.0),
+		})
+		var delays []time.Duration
+		for i := 0; i < 5; i++ {
+			delays = append(delays, rateLimiter.When(request))
+		}
+		Expect(delays).To(Equal([]time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			4 * time.Second,
+			5 * time.Second,
+			5 * time.Second,
+		}))
+		Expect(rateLimiter.NumRequeues(request)).To(Equal(5))
+
+		rateLimiter.Forget(request)
+		Expect(rateLimiter.When(request)).To(Equal(1 * time.Second))
+	})
+
+	It("should add jitter to the delay", func() {
+		rateLimiter := NewCollectorReconcileRateLimiter(otelcolresources.CollectorReconcileRetrySettings{
+			MaxDelay: &metav1.Duration{Duration: 1 * time.Minute},
+			Jitter:   ptr.To(0.5),
+		})
+		delay := rateLimiter.When(request)
+		Expect(delay).To(BeNumerically(">=", 1*time.Second))
+		Expect(delay).To(BeNumerically("<=", 1500*time.Millisecond))
+	})
+
+	It("should use the defaults if no settings are provided", func() {
+		rateLimiter := (&BackendConnectionManager{}).ReconcileRateLimiter()
+		for i := 0; i < 20; i++ {
+			rateLimiter.When(request)
+		}
+		Expect(rateLimiter.When(request)).To(BeNumerically("<=", 330*time.Second))
+	})
+})
@@ -0,0 +1,440 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+// BackendConnectionHealthChecker periodically checks whether the Dash0 API can be reached with the configured
+// authorization and whether the OpenTelemetry collectors managed by the operator are healthy. The results are
+// recorded as the ApiReachable and CollectorHealthy conditions in the status of the Dash0 operator configuration
+// resource. If NodeCoverageCheckEnabled is set, it also checks whether the collector daemonset has a ready pod on every
+// node, and records the result as the CollectorCoversAllNodes condition.
+type BackendConnectionHealthChecker struct {
+	Client                   client.Client
+	Recorder                 record.EventRecorder
+	HttpClient               *http.Client
+	AuthToken                string
+	OperatorNamespace        string
+	OTelCollectorNamePrefix  string
+	CollectorGatewayMode     bool
+	NodeCoverageCheckEnabled bool
+	Interval                 time.Duration
+}
+
+const (
+	// nodeCoverageGracePeriod is the time a new node gets to start a ready collector pod before it is reported as not
+	// covered.
+	nodeCoverageGracePeriod = 3 * time.Minute
+	// maxUncoveredNodesInMessage limits the number of node names that are listed in the CollectorCoversAllNodes
+	// condition and the corresponding event.
+	maxUncoveredNodesInMessage = 10
+)
+
+type healthCheckResult struct {
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+}
+
+// Start runs the health checks every Interval until the context is cancelled. It implements manager.Runnable.
+func (c *BackendConnectionHealthChecker) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.checkHealth(ctx, &logger); err != nil {
+				logger.Error(err, "The backend connection health check has failed.")
+			}
+		}
+	}
+}
+
+func (c *BackendConnectionHealthChecker) checkHealth(ctx context.Context, logger *logr.Logger) error {
+	resource, err := util.FindUniqueOrMostRecentResourceInScope(
+		ctx,
+		c.Client,
+		"", /* cluster-scope, thus no namespace */
+		&dash0v1alpha1.Dash0OperatorConfiguration{},
+		logger,
+	)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		return nil
+	}
+	operatorConfigurationResource := resource.(*dash0v1alpha1.Dash0OperatorConfiguration)
+	if operatorConfigurationResource.IsMarkedForDeletion() {
+		return nil
+	}
+
+	apiResult := c.checkApiReachability(ctx, operatorConfigurationResource)
+	collectorResult := c.checkCollectorHealth(ctx, operatorConfigurationResource)
+	apiConditionChanged := operatorConfigurationResource.SetHealthCondition(
+		dash0v1alpha1.ConditionTypeApiReachable,
+		apiResult.status,
+		apiResult.reason,
+		apiResult.message,
+	)
+	collectorConditionChanged := operatorConfigurationResource.SetHealthCondition(
+		dash0v1alpha1.ConditionTypeCollectorHealthy,
+		collectorResult.status,
+		collectorResult.reason,
+		collectorResult.message,
+	)
+	nodeCoverageConditionChanged := false
+	var nodeCoverageResult *healthCheckResult
+	if c.NodeCoverageCheckEnabled && !c.CollectorGatewayMode {
+		result := c.checkNodeCoverage(ctx)
+		nodeCoverageResult = &result
+		nodeCoverageConditionChanged = operatorConfigurationResource.SetHealthCondition(
+			dash0v1alpha1.ConditionTypeCollectorCoversAllNodes,
+			result.status,
+			result.reason,
+			result.message,
+		)
+	} else {
+		// Remove a stale condition after the check has been disabled or the collector mode has been switched.
+		nodeCoverageConditionChanged = meta.RemoveStatusCondition(
+			&operatorConfigurationResource.Status.Conditions,
+			string(dash0v1alpha1.ConditionTypeCollectorCoversAllNodes),
+		)
+	}
+	if !apiConditionChanged && !collectorConditionChanged && !nodeCoverageConditionChanged {
+		return nil
+	}
+	if err = c.Client.Status().Update(ctx, operatorConfigurationResource); err != nil {
+		return fmt.Errorf("cannot update the health conditions of the Dash0 operator configuration resource: %w", err)
+	}
+	if nodeCoverageConditionChanged && nodeCoverageResult != nil && nodeCoverageResult.status == metav1.ConditionFalse {
+		// The condition only changes when the set of uncovered nodes changes, so this does not emit the same event on
+		// every check.
+		c.Recorder.Event(
+			operatorConfigurationResource,
+			corev1.EventTypeWarning,
+			nodeCoverageResult.reason,
+			nodeCoverageResult.message,
+		)
+	}
+	return nil
+}
+
+func (c *BackendConnectionHealthChecker) checkApiReachability(
+	ctx context.Context,
+	operatorConfigurationResource *dash0v1alpha1.Dash0OperatorConfiguration,
+) healthCheckResult {
+	if !operatorConfigurationResource.HasDash0ApiAccessConfigured() {
+		return healthCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  "ApiAccessNotConfigured",
+			message: "The operator configuration resource has no Dash0 API endpoint and authorization.",
+		}
+	}
+	if c.AuthToken == "" {
+		return healthCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  "AuthTokenNotAvailable",
+			message: "The Dash0 authorization token has not been provided to the operator yet.",
+		}
+	}
+	dataset := operatorConfigurationResource.Spec.Export.Dash0.Dataset
+	if dataset == "" {
+		dataset = util.DatasetDefault
+	}
+	return probeApi(
+		ctx,
+		c.HttpClient,
+		operatorConfigurationResource.Spec.Export.Dash0.ApiEndpoint,
+		dataset,
+		operatorConfigurationResource.Spec.Export.Dash0.ApiHeaders,
+		c.AuthToken,
+	)
+}
+
+// probeApi sends a lightweight authenticated GET request (listing the dashboards of the dataset) to the Dash0 API.
+func probeApi(
+	ctx context.Context,
+	httpClient *http.Client,
+	apiEndpoint string,
+	dataset string,
+	apiHeaders []dash0v1alpha1.Header,
+	authToken string,
+) healthCheckResult {
+	if !strings.HasSuffix(apiEndpoint, "/") {
+		apiEndpoint += "/"
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%sapi/dashboards?dataset=%s", apiEndpoint, url.QueryEscape(dataset)),
+		nil,
+	)
+	if err != nil {
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "InvalidApiEndpoint",
+			message: fmt.Sprintf("Cannot create a request for the Dash0 API: %v", err),
+		}
+	}
+	setApiRequestHeaders(req, apiHeaders, authToken, false)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "ApiRequestFailed",
+			message: fmt.Sprintf("The request to the Dash0 API has failed: %v", err),
+		}
+	}
+	_ = res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "ApiAuthorizationFailed",
+			message: fmt.Sprintf("The Dash0 API has rejected the authorization token (HTTP %d).", res.StatusCode),
+		}
+	case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "ApiRequestFailed",
+			message: fmt.Sprintf("The Dash0 API has responded with HTTP %d.", res.StatusCode),
+		}
+	default:
+		return healthCheckResult{
+			status:  metav1.ConditionTrue,
+			reason:  "ApiReachable",
+			message: "The Dash0 API is reachable with the configured authorization.",
+		}
+	}
+}
+
+func (c *BackendConnectionHealthChecker) checkCollectorHealth(
+	ctx context.Context,
+	operatorConfigurationResource *dash0v1alpha1.Dash0OperatorConfiguration,
+) healthCheckResult {
+	var daemonSet *appsv1.DaemonSet
+	var gatewayDeployment *appsv1.Deployment
+	if c.CollectorGatewayMode {
+		gatewayDeployment = &appsv1.Deployment{}
+		if err := c.Client.Get(ctx, client.ObjectKey{
+			Namespace: c.OperatorNamespace,
+			Name:      otelcolresources.GatewayDeploymentName(c.OTelCollectorNamePrefix),
+		}, gatewayDeployment); err != nil {
+			return collectorLookupFailed("gateway deployment", err)
+		}
+	} else {
+		daemonSet = &appsv1.DaemonSet{}
+		if err := c.Client.Get(ctx, client.ObjectKey{
+			Namespace: c.OperatorNamespace,
+			Name:      otelcolresources.DaemonSetName(c.OTelCollectorNamePrefix),
+		}, daemonSet); err != nil {
+			return collectorLookupFailed("daemonset", err)
+		}
+	}
+	var deployment *appsv1.Deployment
+	if util.ReadBoolPointerWithDefault(
+		operatorConfigurationResource.Spec.KubernetesInfrastructureMetricsCollectionEnabled,
+		true,
+	) {
+		deployment = &appsv1.Deployment{}
+		if err := c.Client.Get(ctx, client.ObjectKey{
+			Namespace: c.OperatorNamespace,
+			Name:      otelcolresources.DeploymentName(c.OTelCollectorNamePrefix),
+		}, deployment); err != nil {
+			return collectorLookupFailed("deployment", err)
+		}
+	}
+	return evaluateCollectorHealth(daemonSet, gatewayDeployment, deployment)
+}
+
+func (c *BackendConnectionHealthChecker) checkNodeCoverage(ctx context.Context) healthCheckResult {
+	daemonSet := &appsv1.DaemonSet{}
+	if err := c.Client.Get(ctx, client.ObjectKey{
+		Namespace: c.OperatorNamespace,
+		Name:      otelcolresources.DaemonSetName(c.OTelCollectorNamePrefix),
+	}, daemonSet); err != nil {
+		return collectorLookupFailed("daemonset", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(daemonSet.Spec.Selector)
+	if err != nil {
+		return healthCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  "CollectorLookupFailed",
+			message: fmt.Sprintf("The OpenTelemetry collector daemonset has an invalid selector: %v", err),
+		}
+	}
+	collectorPods := &corev1.PodList{}
+	if err = c.Client.List(
+		ctx,
+		collectorPods,
+		client.InNamespace(c.OperatorNamespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return healthCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  "CollectorLookupFailed",
+			message: fmt.Sprintf("Cannot list the OpenTelemetry collector daemonset pods: %v", err),
+		}
+	}
+	nodes := &corev1.NodeList{}
+	if err = c.Client.List(ctx, nodes); err != nil {
+		return healthCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  "NodeLookupFailed",
+			message: fmt.Sprintf("Cannot list the nodes of the cluster: %v", err),
+		}
+	}
+	return evaluateNodeCoverage(nodes.Items, collectorPods.Items, time.Now())
+}
+
+// evaluateNodeCoverage reports the nodes that do not have a ready collector daemonset pod, for example because they
+// have a taint the collector does not tolerate. Nodes that are not ready themselves and nodes that have joined the
+// cluster less than nodeCoverageGracePeriod ago are not taken into account.
+func evaluateNodeCoverage(nodes []corev1.Node, collectorPods []corev1.Pod, now time.Time) healthCheckResult {
+	nodesWithReadyCollector := make(map[string]bool, len(collectorPods))
+	for _, pod := range collectorPods {
+		if pod.Spec.NodeName != "" && isPodReady(&pod) {
+			nodesWithReadyCollector[pod.Spec.NodeName] = true
+		}
+	}
+	var uncoveredNodes []string
+	for _, node := range nodes {
+		if !isNodeReady(&node) || now.Sub(node.CreationTimestamp.Time) < nodeCoverageGracePeriod {
+			continue
+		}
+		if !nodesWithReadyCollector[node.Name] {
+			uncoveredNodes = append(uncoveredNodes, node.Name)
+		}
+	}
+	if len(uncoveredNodes) == 0 {
+		return healthCheckResult{
+			status:  metav1.ConditionTrue,
+			reason:  "AllNodesCovered",
+			message: "The OpenTelemetry collector daemonset has a ready pod on every ready node.",
+		}
+	}
+	slices.Sort(uncoveredNodes)
+	listedNodes := strings.Join(uncoveredNodes, ", ")
+	if len(uncoveredNodes) > maxUncoveredNodesInMessage {
+		listedNodes = fmt.Sprintf(
+			"%s and %d more",
+			strings.Join(uncoveredNodes[:maxUncoveredNodesInMessage], ", "),
+			len(uncoveredNodes)-maxUncoveredNodesInMessage,
+		)
+	}
+	return healthCheckResult{
+		status: metav1.ConditionFalse,
+		reason: "NodesNotCovered",
+		message: fmt.Sprintf(
+			"The OpenTelemetry collector daemonset has no ready pod on %d node(s), telemetry from these nodes is "+
+				"missing: %s. Check whether these nodes have taints that the collector does not tolerate.",
+			len(uncoveredNodes),
+			listedNodes,
+		),
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func collectorLookupFailed(kind string, err error) healthCheckResult {
+	if apierrors.IsNotFound(err) {
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "CollectorNotFound",
+			message: fmt.Sprintf("The OpenTelemetry collector %s does not exist.", kind),
+		}
+	}
+	return healthCheckResult{
+		status:  metav1.ConditionUnknown,
+		reason:  "CollectorLookupFailed",
+		message: fmt.Sprintf("Cannot read the OpenTelemetry collector %s: %v", kind, err),
+	}
+}
+
+// evaluateCollectorHealth checks that all collector daemonset pods (if given) are ready, that the collector gateway
+// deployment (if given) has all its replicas available and that the collector deployment (if given) has at least one
+// available replica.
+func evaluateCollectorHealth(
+	daemonSet *appsv1.DaemonSet,
+	gatewayDeployment *appsv1.Deployment,
+	deployment *appsv1.Deployment,
+) healthCheckResult {
+	if daemonSet != nil {
+		desired := daemonSet.Status.DesiredNumberScheduled
+		ready := daemonSet.Status.NumberReady
+		if desired == 0 || ready < desired {
+			return healthCheckResult{
+				status:  metav1.ConditionFalse,
+				reason:  "CollectorNotReady",
+				message: fmt.Sprintf("%d of %d OpenTelemetry collector daemonset pods are ready.", ready, desired),
+			}
+		}
+	}
+	if gatewayDeployment != nil {
+		desired := ptr.Deref(gatewayDeployment.Spec.Replicas, 1)
+		available := gatewayDeployment.Status.AvailableReplicas
+		if desired == 0 || available < desired {
+			return healthCheckResult{
+				status:  metav1.ConditionFalse,
+				reason:  "CollectorNotReady",
+				message: fmt.Sprintf("%d of %d OpenTelemetry collector gateway replicas are available.", available, desired),
+			}
+		}
+	}
+	if deployment != nil && deployment.Status.AvailableReplicas < 1 {
+		return healthCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  "CollectorNotReady",
+			message: "The OpenTelemetry collector deployment has no available replicas.",
+		}
+	}
+	return healthCheckResult{
+		status:  metav1.ConditionTrue,
+		reason:  "CollectorReady",
+		message: "All OpenTelemetry collector pods are ready.",
+	}
+}
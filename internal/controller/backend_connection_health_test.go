@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The backend connection health checker", func() {
+	ctx := context.Background()
+
+	Describe("when probing the Dash0 API", func() {
+		var apiServer *httptest.Server
+		var responseStatus int
+		var receivedRequest *http.Request
+
+		BeforeEach(func() {
+			responseStatus = http.StatusOK
+			receivedRequest = nil
+			apiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedRequest = r
+				w.WriteHeader(responseStatus)
+			}))
+		})
+
+		AfterEach(func() {
+			apiServer.Close()
+		})
+
+		It("should send an authenticated request and report the API as reachable", func() {
+			result := probeApi(ctx, apiServer.Client(), apiServer.URL, "custom-dataset", nil, "test-token")
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+			Expect(receivedRequest).ToNot(BeNil())
+			Expect(receivedRequest.Method).To(Equal(http.MethodGet))
+			Expect(receivedRequest.URL.Path).To(Equal("/api/dashboards"))
+			Expect(receivedRequest.URL.Query().Get("dataset")).To(Equal("custom-dataset"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("Bearer test-token"))
+		})
+
+		It("should send the additional API headers, without overriding the Authorization header", func() {
+			result := probeApi(
+				ctx,
+				apiServer.Client(),
+				apiServer.URL,
+				"default",
+				[]dash0v1alpha1.Header{
+					{Name: "X-Tenant-Id", Value: "tenant-1"},
+					{Name: "Authorization", Value: "Bearer other-token"},
+				},
+				"test-token",
+			)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+			Expect(receivedRequest).ToNot(BeNil())
+			Expect(receivedRequest.Header.Get("X-Tenant-Id")).To(Equal("tenant-1"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("Bearer test-token"))
+		})
+
+		It("should report rejected authorization", func() {
+			responseStatus = http.StatusUnauthorized
+			result := probeApi(ctx, apiServer.Client(), apiServer.URL+"/", "default", nil, "test-token")
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.reason).To(Equal("ApiAuthorizationFailed"))
+		})
+
+		It("should report server errors", func() {
+			responseStatus = http.StatusServiceUnavailable
+			result := probeApi(ctx, apiServer.Client(), apiServer.URL, "default", nil, "test-token")
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.reason).To(Equal("ApiRequestFailed"))
+			Expect(result.message).To(ContainSubstring("503"))
+		})
+
+		It("should report an unreachable API", func() {
+			apiServer.Close()
+			result := probeApi(ctx, apiServer.Client(), apiServer.URL, "default", nil, "test-token")
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.reason).To(Equal("ApiRequestFailed"))
+		})
+	})
+
+	Describe("when evaluating the collector health", func() {
+		It("should report a healthy collector if all pods are ready", func() {
+			result := evaluateCollectorHealth(
+				daemonSetWithStatus(3, 3),
+				nil,
+				&appsv1.Deployment{Status: appsv1.DeploymentStatus{AvailableReplicas: 1}},
+			)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should report a healthy collector without a deployment", func() {
+			result := evaluateCollectorHealth(daemonSetWithStatus(2, 2), nil, nil)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should report an unhealthy collector if not all daemonset pods are ready", func() {
+			result := evaluateCollectorHealth(daemonSetWithStatus(3, 2), nil, nil)
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.message).To(Equal("2 of 3 OpenTelemetry collector daemonset pods are ready."))
+		})
+
+		It("should report an unhealthy collector if no daemonset pods are scheduled", func() {
+			result := evaluateCollectorHealth(daemonSetWithStatus(0, 0), nil, nil)
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should report an unhealthy collector if the deployment has no available replicas", func() {
+			result := evaluateCollectorHealth(daemonSetWithStatus(3, 3), nil, &appsv1.Deployment{})
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should report a healthy collector if all gateway replicas are available", func() {
+			result := evaluateCollectorHealth(nil, gatewayDeploymentWithStatus(2, 2), nil)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should report an unhealthy collector if not all gateway replicas are available", func() {
+			result := evaluateCollectorHealth(nil, gatewayDeploymentWithStatus(2, 1), nil)
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.message).To(Equal("1 of 2 OpenTelemetry collector gateway replicas are available."))
+		})
+	})
+
+	Describe("when evaluating the node coverage", func() {
+		now := time.Now()
+		longAgo := now.Add(-1 * time.Hour)
+
+		It("should report all nodes as covered if every ready node has a ready collector pod", func() {
+			result := evaluateNodeCoverage(
+				[]corev1.Node{node("node-1", true, longAgo), node("node-2", true, longAgo)},
+				[]corev1.Pod{collectorPod("node-1", true), collectorPod("node-2", true)},
+				now,
+			)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+			Expect(result.reason).To(Equal("AllNodesCovered"))
+		})
+
+		It("should report nodes without a ready collector pod", func() {
+			result := evaluateNodeCoverage(
+				[]corev1.Node{node("node-3", true, longAgo), node("node-1", true, longAgo), node("node-2", true, longAgo)},
+				[]corev1.Pod{collectorPod("node-1", true), collectorPod("node-2", false)},
+				now,
+			)
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.reason).To(Equal("NodesNotCovered"))
+			Expect(result.message).To(ContainSubstring("has no ready pod on 2 node(s)"))
+			Expect(result.message).To(ContainSubstring(": node-2, node-3."))
+		})
+
+		It("should ignore nodes that are not ready or have joined the cluster recently", func() {
+			result := evaluateNodeCoverage(
+				[]corev1.Node{node("node-1", false, longAgo), node("node-2", true, now.Add(-1*time.Minute))},
+				nil,
+				now,
+			)
+			Expect(result.status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should limit the number of listed nodes", func() {
+			var nodes []corev1.Node
+			for i := 0; i < 12; i++ {
+				nodes = append(nodes, node(fmt.Sprintf("node-%02d", i), true, longAgo))
+			}
+			result := evaluateNodeCoverage(nodes, nil, now)
+			Expect(result.status).To(Equal(metav1.ConditionFalse))
+			Expect(result.message).To(ContainSubstring("node-08, node-09 and 2 more."))
+			Expect(result.message).ToNot(ContainSubstring("node-10"))
+		})
+	})
+})
+
+func gatewayDeploymentWithStatus(replicas int32, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: available,
+		},
+	}
+}
+
+func daemonSetWithStatus(desired int32, ready int32) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: desired,
+			NumberReady:            ready,
+		},
+	}
+}
+
+func node(name string, ready bool, createdAt time.Time) corev1.Node {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(createdAt),
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: readyStatus}},
+		},
+	}
+}
+
+func collectorPod(nodeName string, ready bool) corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
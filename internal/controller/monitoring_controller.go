@@ -11,16 +11,19 @@ import (
 
 	"github.com/go-logr/logr"
 	otelmetric "go.opentelemetry.io/otel/metric"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
 	"github.com/dash0hq/dash0-operator/internal/backendconnection"
+	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
 	"github.com/dash0hq/dash0-operator/internal/instrumentation"
 	"github.com/dash0hq/dash0-operator/internal/util"
 )
@@ -32,11 +35,17 @@ type MonitoringReconciler struct {
 	BackendConnectionManager *backendconnection.BackendConnectionManager
 	Images                   util.Images
 	OperatorNamespace        string
+	OTelCollectorNamePrefix  string
 	DanglingEventsTimeouts   *util.DanglingEventsTimeouts
+	DatasetChangeHandlers    []DatasetChangeHandler
+	// MaxConcurrentReconciles is the maximum number of monitoring resources (that is, namespaces) that are reconciled
+	// concurrently. If it is not set, monitoring resources are reconciled one at a time.
+	MaxConcurrentReconciles int
 }
 
 const (
 	updateStatusFailedMessageMonitoring = "Failed to update Dash0 monitoring status conditions, requeuing reconcile request."
+	collectorServiceNotFoundReason      = "CollectorServiceNotFound"
 )
 
 var (
@@ -59,6 +68,12 @@ func (r *MonitoringReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dash0v1alpha1.Dash0Monitoring{}).
+		// Reconciling a monitoring resource also reconciles the OpenTelemetry collector resources, so failed reconcile
+		// requests are retried according to the collector reconcile retry settings.
+		WithOptions(controller.Options{
+			RateLimiter:             r.BackendConnectionManager.ReconcileRateLimiter(),
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+		}).
 		Complete(r)
 }
 
@@ -84,7 +99,7 @@ func (r *MonitoringReconciler) InitializeSelfMonitoringMetrics(
 //+kubebuilder:rbac:groups=apps,resources=daemonsets;deployments;replicasets;statefulsets,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;list;patch;update
-//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;delete
 //+kubebuilder:rbac:groups=core,resources=endpoints,verbs=get
 //+kubebuilder:rbac:groups=operator.dash0.com,resources=dash0monitorings,verbs=get;list;watch;create;update;patch;delete;deletecollection
@@ -137,7 +152,7 @@ func (r *MonitoringReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// prometheus scraping) after removing the monitoring resource from this namespace. Or, to be more precise,
 		// when r.reconcileOpenTelemetryCollector runs, the monitoring resource in this namespace is still present, but
 		// it is no longer marked as available.
-		if r.reconcileOpenTelemetryCollector(ctx, nil, &logger) != nil {
+		if err = r.reconcileOpenTelemetryCollector(ctx, nil, &logger); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -150,6 +165,16 @@ func (r *MonitoringReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	monitoringResource := checkResourceResult.Resource.(*dash0v1alpha1.Dash0Monitoring)
+	if util.IsReconciliationPaused(&monitoringResource.ObjectMeta) && monitoringResource.DeletionTimestamp.IsZero() {
+		// Deleting a paused monitoring resource is still processed, otherwise the finalizer would block the deletion.
+		logger.Info(fmt.Sprintf(
+			"The Dash0 monitoring resource has the annotation %s=true, reconciliation is paused. Ignoring the "+
+				"reconcile request.",
+			util.PausedAnnotationKey,
+		))
+		return ctrl.Result{}, nil
+	}
+
 	isFirstReconcile, err := util.InitStatusConditions(
 		ctx,
 		r.Client,
@@ -191,7 +216,7 @@ func (r *MonitoringReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		// check, due to checkResourceResult.ResourceDoesNotExist being true. We still want to handle this case
 		// correctly for good measure (reconcile the otel collector, then stop the reconcile of the monitoring
 		// resource).
-		if r.reconcileOpenTelemetryCollector(ctx, monitoringResource, &logger) != nil {
+		if err = r.reconcileOpenTelemetryCollector(ctx, monitoringResource, &logger); err != nil {
 			return ctrl.Result{}, err
 		}
 		// The Dash0 monitoring resource is slated for deletion, the finalizer has already been removed in the last
@@ -221,6 +246,12 @@ func (r *MonitoringReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	monitoringResource, err = r.manageDatasetChanges(ctx, monitoringResource, isFirstReconcile, &logger)
+	if err != nil {
+		// The error has already been logged in manageDatasetChanges
+		return ctrl.Result{}, err
+	}
+
 	r.scheduleAttachDanglingEvents(ctx, monitoringResource, &logger)
 
 	monitoringResource.EnsureResourceIsMarkedAsAvailable()
@@ -229,13 +260,55 @@ func (r *MonitoringReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	if r.reconcileOpenTelemetryCollector(ctx, monitoringResource, &logger) != nil {
+	if err = r.reconcileOpenTelemetryCollector(ctx, monitoringResource, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err = r.verifyCollectorServiceExists(ctx, monitoringResource, &logger); err != nil {
+		// The error has already been logged in verifyCollectorServiceExists
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// verifyCollectorServiceExists checks that the OpenTelemetry collector service, which the injected
+// DASH0_OTEL_COLLECTOR_BASE_URL points to, exists in the operator namespace. This can only be checked after the
+// collector resources have been reconciled. If the service does not exist, the monitoring resource is marked as
+// degraded, which also stops the webhook from instrumenting workloads in this namespace, and the reconcile request is
+// requeued.
+func (r *MonitoringReconciler) verifyCollectorServiceExists(
+	ctx context.Context,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	logger *logr.Logger,
+) error {
+	serviceName := otelcolresources.ServiceName(r.OTelCollectorNamePrefix)
+	// Read the service directly from the API server, the cache might not contain a service that has just been created.
+	_, err := r.Clientset.CoreV1().Services(r.OperatorNamespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		logger.Error(err, "Cannot verify that the OpenTelemetry collector service exists, requeuing reconcile request.")
+		return err
+	}
+
+	message := fmt.Sprintf(
+		"The OpenTelemetry collector service %s/%s does not exist, instrumented workloads would not be able to send "+
+			"telemetry to %s. Check the operator logs and the Dash0 operator configuration resource.",
+		r.OperatorNamespace,
+		serviceName,
+		otelcolresources.ServiceBaseUrl(r.OTelCollectorNamePrefix, r.OperatorNamespace),
+	)
+	logger.Info(message)
+	monitoringResource.EnsureResourceIsMarkedAsDegraded(collectorServiceNotFoundReason, message)
+	if err = r.Status().Update(ctx, monitoringResource); err != nil {
+		logger.Error(err, updateStatusFailedMessageMonitoring)
+		return err
+	}
+	return errors.New(message)
+}
+
 func (r *MonitoringReconciler) manageInstrumentWorkloadsChanges(
 	ctx context.Context,
 	monitoringResource *dash0v1alpha1.Dash0Monitoring,
@@ -274,6 +347,41 @@ func (r *MonitoringReconciler) manageInstrumentWorkloadsChanges(
 	return monitoringResource, requiredAction, nil
 }
 
+// manageDatasetChanges checks whether spec.dataset has changed since the previous reconcile cycle. If so, all dashboards
+// and check rules in this namespace that have been synchronized via the Dash0 API are deleted from the previous dataset
+// and created in the new dataset.
+func (r *MonitoringReconciler) manageDatasetChanges(
+	ctx context.Context,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	isFirstReconcile bool,
+	logger *logr.Logger,
+) (*dash0v1alpha1.Dash0Monitoring, error) {
+	previous := monitoringResource.Status.PreviousDataset
+	current := monitoringResource.Spec.Dataset
+	if previous == current {
+		return monitoringResource, nil
+	}
+
+	monitoringResource.Status.PreviousDataset = current
+	if err := r.Status().Update(ctx, monitoringResource); err != nil {
+		logger.Error(err, "Failed to update the previous dataset status on the Dash0 monitoring resource, requeuing "+
+			"reconcile request.")
+		return monitoringResource, err
+	}
+
+	if !isFirstReconcile {
+		logger.Info(fmt.Sprintf(
+			"The dataset setting has changed from \"%s\" to \"%s\". Dashboards and check rules in this namespace will "+
+				"be deleted from the previous dataset and created in the new dataset.",
+			previous,
+			current))
+		for _, datasetChangeHandler := range r.DatasetChangeHandlers {
+			datasetChangeHandler.HandleDatasetChange(ctx, monitoringResource.Namespace, previous, logger)
+		}
+	}
+	return monitoringResource, nil
+}
+
 func (r *MonitoringReconciler) runCleanupActions(
 	ctx context.Context,
 	monitoringResource *dash0v1alpha1.Dash0Monitoring,
@@ -14,6 +14,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -26,6 +27,7 @@ import (
 	"github.com/dash0hq/dash0-operator/internal/backendconnection"
 	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
 	"github.com/dash0hq/dash0-operator/internal/instrumentation"
+	"github.com/dash0hq/dash0-operator/internal/util"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -44,6 +46,7 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 	var createdObjects []client.Object
 
 	var reconciler *MonitoringReconciler
+	var datasetChangeHandler *DummyDatasetChangeHandler
 
 	BeforeAll(func() {
 		EnsureTestNamespaceExists(ctx, k8sClient)
@@ -52,6 +55,7 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 
 	BeforeEach(func() {
 		createdObjects = make([]client.Object, 0)
+		datasetChangeHandler = &DummyDatasetChangeHandler{}
 
 		instrumenter := &instrumentation.Instrumenter{
 			Client:               k8sClient,
@@ -79,8 +83,10 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 			Instrumenter:             instrumenter,
 			Images:                   TestImages,
 			OperatorNamespace:        OperatorNamespace,
+			OTelCollectorNamePrefix:  OTelCollectorNamePrefixTest,
 			BackendConnectionManager: backendConnectionManager,
 			DanglingEventsTimeouts:   &DanglingEventsTimeoutsTest,
+			DatasetChangeHandlers:    []DatasetChangeHandler{datasetChangeHandler},
 		}
 	})
 
@@ -126,6 +132,30 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 				VerifyCollectorResources(ctx, k8sClient, operatorNamespace)
 			})
 
+			It("should mark the resource as degraded if the collector service does not exist", func() {
+				reconciler.OTelCollectorNamePrefix = "does-not-exist"
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: MonitoringResourceQualifiedName,
+				})
+				Expect(err).To(MatchError(ContainSubstring(
+					"The OpenTelemetry collector service dash0-system/does-not-exist-opentelemetry-collector-service " +
+						"does not exist")))
+
+				Eventually(func(g Gomega) {
+					available := loadCondition(ctx, MonitoringResourceQualifiedName, dash0v1alpha1.ConditionTypeAvailable)
+					degraded := loadCondition(ctx, MonitoringResourceQualifiedName, dash0v1alpha1.ConditionTypeDegraded)
+					g.Expect(available).NotTo(BeNil())
+					g.Expect(available.Status).To(Equal(metav1.ConditionFalse))
+					g.Expect(degraded).NotTo(BeNil())
+					g.Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+					g.Expect(degraded.Reason).To(Equal("CollectorServiceNotFound"))
+				}, timeout, pollingInterval).Should(Succeed())
+
+				reconciler.OTelCollectorNamePrefix = OTelCollectorNamePrefixTest
+				triggerReconcileRequest(ctx, reconciler, "Reconcile with the correct collector name prefix")
+				verifyMonitoringResourceIsAvailable(ctx)
+			})
+
 			It("should mark only the most recent resource as available and the other ones as degraded when multiple resources exist", func() {
 				firstMonitoringResource := &dash0v1alpha1.Dash0Monitoring{}
 				Expect(k8sClient.Get(ctx, MonitoringResourceQualifiedName, firstMonitoringResource)).To(Succeed())
@@ -705,6 +735,47 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 		)
 	})
 
+	Describe("when the dataset setting changes on an existing Dash0 monitoring resource", Ordered, func() {
+		AfterEach(func() {
+			DeleteMonitoringResource(ctx, k8sClient)
+		})
+
+		It("should not notify the dataset change handlers on the first reconcile", func() {
+			monitoringResource := EnsureMonitoringResourceExists(ctx, k8sClient)
+			monitoringResource.Spec.Dataset = "team-a"
+			Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+
+			triggerReconcileRequest(ctx, reconciler, "")
+
+			Expect(datasetChangeHandler.calls).To(BeEmpty())
+			Expect(LoadMonitoringResourceOrFail(ctx, k8sClient, Default).Status.PreviousDataset).To(Equal("team-a"))
+		})
+
+		It("should notify the dataset change handlers with the previous dataset", func() {
+			EnsureMonitoringResourceExists(ctx, k8sClient)
+			triggerReconcileRequest(ctx, reconciler, "")
+			Expect(datasetChangeHandler.calls).To(BeEmpty())
+
+			updateDataset(ctx, "team-a")
+			triggerReconcileRequest(ctx, reconciler, "")
+			Expect(datasetChangeHandler.calls).To(Equal([]datasetChange{
+				{namespace: TestNamespaceName, previousDataset: ""},
+			}))
+
+			updateDataset(ctx, "team-b")
+			triggerReconcileRequest(ctx, reconciler, "")
+			Expect(datasetChangeHandler.calls).To(Equal([]datasetChange{
+				{namespace: TestNamespaceName, previousDataset: ""},
+				{namespace: TestNamespaceName, previousDataset: "team-a"},
+			}))
+
+			// reconciling again without changing the dataset must not notify the handlers again
+			triggerReconcileRequest(ctx, reconciler, "")
+			Expect(datasetChangeHandler.calls).To(HaveLen(2))
+			Expect(LoadMonitoringResourceOrFail(ctx, k8sClient, Default).Status.PreviousDataset).To(Equal("team-b"))
+		})
+	})
+
 	Describe("when the Dash0 monitoring resource does not exist", func() {
 		It("should not instrument workloads", func() {
 			createdObjects = verifyThatDeploymentIsNotBeingInstrumented(ctx, reconciler, createdObjects)
@@ -788,6 +859,22 @@ var _ = Describe("The monitoring resource controller", Ordered, func() {
 		})
 	})
 
+	Describe("when the Dash0 monitoring resource exists but reconciliation has been paused", Ordered, func() {
+		BeforeAll(func() {
+			monitoringResource := EnsureMonitoringResourceExists(ctx, k8sClient)
+			monitoringResource.Annotations = map[string]string{util.PausedAnnotationKey: "true"}
+			Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			DeleteMonitoringResource(ctx, k8sClient)
+		})
+
+		It("should not instrument workloads", func() {
+			createdObjects = verifyThatDeploymentIsNotBeingInstrumented(ctx, reconciler, createdObjects)
+		})
+	})
+
 	Describe("when deleting the Dash0 monitoring resource and removing the collector resources", func() {
 		BeforeEach(func() {
 			EnsureMonitoringResourceExists(ctx, k8sClient)
@@ -832,7 +919,7 @@ func verifyThatDeploymentIsNotBeingInstrumented(ctx context.Context, reconciler
 
 	triggerReconcileRequest(ctx, reconciler, "")
 
-	VerifyNoEvents(ctx, clientset, namespace)
+	VerifyOptedOutOfInstrumentationEvent(ctx, clientset, namespace, name, "controller")
 	VerifyDeploymentWithOptOutLabel(GetDeployment(ctx, k8sClient, namespace, name))
 
 	return createdObjects
@@ -894,3 +981,27 @@ func verifyCondition(
 	g.Expect(condition.Reason).To(Equal(expectedReason))
 	g.Expect(condition.Message).To(Equal(expectedMessage))
 }
+
+func updateDataset(ctx context.Context, dataset string) {
+	monitoringResource := LoadMonitoringResourceOrFail(ctx, k8sClient, Default)
+	monitoringResource.Spec.Dataset = dataset
+	Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+}
+
+type datasetChange struct {
+	namespace       string
+	previousDataset string
+}
+
+type DummyDatasetChangeHandler struct {
+	calls []datasetChange
+}
+
+func (h *DummyDatasetChangeHandler) HandleDatasetChange(
+	_ context.Context,
+	namespace string,
+	previousDataset string,
+	_ *logr.Logger,
+) {
+	h.calls = append(h.calls, datasetChange{namespace: namespace, previousDataset: previousDataset})
+}
@@ -7,18 +7,23 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 
 	"github.com/go-logr/logr"
 	otelmetric "go.opentelemetry.io/otel/metric"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/backendconnection"
 	"github.com/dash0hq/dash0-operator/internal/selfmonitoringapiaccess"
 	"github.com/dash0hq/dash0-operator/internal/util"
 )
@@ -33,6 +38,11 @@ type OperatorConfigurationReconciler struct {
 	DanglingEventsTimeouts  *util.DanglingEventsTimeouts
 	Images                  util.Images
 	DevelopmentMode         bool
+	// BackendConnectionManager is used to update the OpenTelemetry collector resources when the operator configuration
+	// resource changes. It can be nil, in which case the collector resources are only updated by the backend connection
+	// controller.
+	BackendConnectionManager *backendconnection.BackendConnectionManager
+	OperatorNamespace        string
 }
 
 const (
@@ -52,9 +62,56 @@ func (r *OperatorConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) err
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dash0v1alpha1.Dash0OperatorConfiguration{}).
+		// Only the metadata of secrets is watched, to avoid caching the content of all secrets in the cluster. The
+		// resource version in the metadata changes whenever the content of a secret changes.
+		WatchesMetadata(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.operatorConfigurationResourcesForSecret),
+		).
 		Complete(r)
 }
 
+// operatorConfigurationResourcesForSecret maps a change of a secret that is mirrored into the operator namespace (or of
+// one of the mirrored copies) to reconcile requests for all operator configuration resources, so that the mirrored
+// copies are kept up to date. Changes to all other secrets are ignored.
+func (r *OperatorConfigurationReconciler) operatorConfigurationResourcesForSecret(
+	ctx context.Context,
+	secret client.Object,
+) []reconcile.Request {
+	operatorNamespace := r.DeploymentSelfReference.Namespace
+	isMirroredCopy := secret.GetNamespace() == operatorNamespace &&
+		secret.GetLabels()[util.MirroredSecretLabelKey] == "true"
+	if !isMirroredCopy {
+		referencedSecretRefs, err := r.findReferencedSecretRefs(ctx, nil)
+		if err != nil {
+			logger := log.FromContext(ctx)
+			logger.Error(err, "cannot determine whether the changed secret is mirrored into the operator namespace")
+			return nil
+		}
+		if !slices.ContainsFunc(referencedSecretRefs, func(secretRef dash0v1alpha1.SecretRef) bool {
+			return util.IsCrossNamespaceSecretRef(&secretRef, operatorNamespace) &&
+				secretRef.Namespace == secret.GetNamespace() &&
+				secretRef.Name == secret.GetName()
+		}) {
+			return nil
+		}
+	}
+
+	operatorConfigurationResources := &dash0v1alpha1.Dash0OperatorConfigurationList{}
+	if err := r.Client.List(ctx, operatorConfigurationResources); err != nil {
+		logger := log.FromContext(ctx)
+		logger.Error(err, "cannot list the operator configuration resources")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(operatorConfigurationResources.Items))
+	for _, operatorConfigurationResource := range operatorConfigurationResources.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&operatorConfigurationResource),
+		})
+	}
+	return requests
+}
+
 func (r *OperatorConfigurationReconciler) InitializeSelfMonitoringMetrics(
 	meter otelmetric.Meter,
 	metricNamePrefix string,
@@ -75,8 +132,10 @@ func (r *OperatorConfigurationReconciler) InitializeSelfMonitoringMetrics(
 // when the command <make manifests> is executed.
 // To know more about markers see: https://book.kubebuilder.io/reference/markers.html
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;list;patch;update
-//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,namespace=system,resources=secrets,verbs=create;update;delete
 //+kubebuilder:rbac:groups=operator.dash0.com,resources=dash0operatorconfigurations,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=operator.dash0.com,resources=dash0operatorconfigurations/finalizers,verbs=update
 //+kubebuilder:rbac:groups=operator.dash0.com,resources=dash0operatorconfigurations/status,verbs=get;update;patch
@@ -133,6 +192,14 @@ func (r *OperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctr
 		} else if stopReconcile {
 			return ctrl.Result{}, nil
 		}
+		if util.IsReconciliationPaused(&resource.ObjectMeta) {
+			logger.Info(fmt.Sprintf(
+				"The operator configuration resource has the annotation %s=true, reconciliation is paused. "+
+					"Ignoring the reconcile request.",
+				util.PausedAnnotationKey,
+			), "name", req.Name)
+			return ctrl.Result{}, nil
+		}
 		logger.Info("Reconciling the operator configuration resource", "name", req.Name)
 	}
 
@@ -149,6 +216,10 @@ func (r *OperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctr
 		} else {
 			logger.Info("Self-monitoring of the controller deployment has been disabled")
 		}
+		if err = r.deleteUnreferencedMirroredSecrets(ctx, nil, &logger); err != nil {
+			logger.Error(err, "cannot delete mirrored secrets that are no longer referenced")
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -163,6 +234,27 @@ func (r *OperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, err
 	}
 
+	// The OpenTelemetry collector resources are updated before the API clients, and the API clients are only switched
+	// to the new settings once the collector has been updated successfully. Otherwise, a failed collector update would
+	// leave telemetry export and the synchronization of dashboards and check rules pointing at different endpoints. If
+	// the collector update fails, the reconcile request is requeued and both are updated with the next attempt.
+	if r.BackendConnectionManager != nil {
+		if err = r.BackendConnectionManager.ReconcileOpenTelemetryCollectorForOperatorConfiguration(
+			ctx,
+			r.Images,
+			r.OperatorNamespace,
+		); err != nil {
+			logger.Error(err, "Failed to update the OpenTelemetry collector resources, requeuing reconcile request.")
+			return ctrl.Result{}, err
+		}
+		// Updating the collector resources might have updated the status of the operator configuration resource, reload
+		// it to avoid a conflict when updating its status below.
+		if err = r.Client.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+			logger.Error(err, "Failed to reload the operator configuration resource, requeuing reconcile request.")
+			return ctrl.Result{}, err
+		}
+	}
+
 	if resource.HasDash0ApiAccessConfigured() {
 		dataset := resource.Spec.Export.Dash0.Dataset
 		if dataset == "" {
@@ -172,6 +264,7 @@ func (r *OperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctr
 			apiClient.SetApiEndpointAndDataset(&ApiConfig{
 				Endpoint: resource.Spec.Export.Dash0.ApiEndpoint,
 				Dataset:  dataset,
+				Headers:  resource.Spec.Export.Dash0.ApiHeaders,
 			}, &logger)
 		}
 	} else {
@@ -203,6 +296,26 @@ func (r *OperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctr
 		)
 		return ctrl.Result{}, err
 	}
+	if newSelfMonitoringAndApiAccessConfiguration.Export.Dash0 != nil {
+		if err = r.mirrorAuthorizationSecret(ctx, &newSelfMonitoringAndApiAccessConfiguration, &logger); err != nil {
+			logger.Error(err, "cannot mirror the secret with the Dash0 authorization token into the operator namespace")
+			if statusUpdateErr := r.markAsDegraded(
+				ctx,
+				resource,
+				"CannotMirrorAuthorizationSecret",
+				fmt.Sprintf("Could not mirror the secret with the Dash0 authorization token into the operator "+
+					"namespace: %v", err),
+				&logger,
+			); statusUpdateErr != nil {
+				return ctrl.Result{}, statusUpdateErr
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	if err = r.deleteUnreferencedMirroredSecrets(ctx, resource, &logger); err != nil {
+		logger.Error(err, "cannot delete mirrored secrets that are no longer referenced")
+		return ctrl.Result{}, err
+	}
 
 	controllerDeployment := &appsv1.Deployment{}
 	if err = r.Client.Get(ctx, client.ObjectKeyFromObject(r.DeploymentSelfReference), controllerDeployment); err != nil {
@@ -312,6 +425,103 @@ func (r *OperatorConfigurationReconciler) removeSelfMonitoringAndApiAccessAndUpd
 	return r.Client.Update(ctx, updatedDeployment)
 }
 
+// mirrorAuthorizationSecret mirrors a secret in a different namespace that holds the Dash0 authorization token into
+// the operator namespace, and points the given configuration to the mirrored copy.
+func (r *OperatorConfigurationReconciler) mirrorAuthorizationSecret(
+	ctx context.Context,
+	selfMonitoringAndApiAccessConfiguration *selfmonitoringapiaccess.SelfMonitoringAndApiAccessConfiguration,
+	logger *logr.Logger,
+) error {
+	operatorNamespace := r.DeploymentSelfReference.Namespace
+	dash0Export := selfMonitoringAndApiAccessConfiguration.Export.Dash0
+	if dash0Export.Authorization.Token == nil || *dash0Export.Authorization.Token == "" {
+		if err := util.MirrorSecret(
+			ctx,
+			r.Client,
+			r.Scheme,
+			dash0Export.Authorization.SecretRef,
+			operatorNamespace,
+			r.DeploymentSelfReference,
+			logger,
+		); err != nil {
+			return err
+		}
+	}
+	dash0Export.Authorization = util.ResolveMirroredSecretRef(dash0Export.Authorization, operatorNamespace)
+	return nil
+}
+
+// deleteUnreferencedMirroredSecrets removes mirrored copies of secrets that are neither referenced by the given
+// operator configuration resource (which is nil if it has been deleted) nor by any monitoring resource.
+func (r *OperatorConfigurationReconciler) deleteUnreferencedMirroredSecrets(
+	ctx context.Context,
+	resource *dash0v1alpha1.Dash0OperatorConfiguration,
+	logger *logr.Logger,
+) error {
+	referencedSecretRefs, err := r.findReferencedSecretRefs(ctx, resource)
+	if err != nil {
+		return err
+	}
+	return util.DeleteUnreferencedMirroredSecrets(
+		ctx,
+		r.Client,
+		r.DeploymentSelfReference.Namespace,
+		referencedSecretRefs,
+		logger,
+	)
+}
+
+// findReferencedSecretRefs collects the secret refs of the Dash0 exports (including dedicated self-monitoring exports)
+// of the given operator configuration resource, or of all operator configuration resources if nil is passed, and of all
+// monitoring resources.
+func (r *OperatorConfigurationReconciler) findReferencedSecretRefs(
+	ctx context.Context,
+	resource *dash0v1alpha1.Dash0OperatorConfiguration,
+) ([]dash0v1alpha1.SecretRef, error) {
+	var exports []*dash0v1alpha1.Export
+	if resource != nil {
+		exports = append(exports, resource.Spec.Export, resource.Spec.SelfMonitoring.Export)
+	} else {
+		operatorConfigurationResources := &dash0v1alpha1.Dash0OperatorConfigurationList{}
+		if err := r.Client.List(ctx, operatorConfigurationResources); err != nil {
+			return nil, fmt.Errorf("cannot list the operator configuration resources: %w", err)
+		}
+		for _, operatorConfigurationResource := range operatorConfigurationResources.Items {
+			if operatorConfigurationResource.DeletionTimestamp.IsZero() {
+				exports = append(
+					exports,
+					operatorConfigurationResource.Spec.Export,
+					operatorConfigurationResource.Spec.SelfMonitoring.Export,
+				)
+			}
+		}
+	}
+	monitoringResources := &dash0v1alpha1.Dash0MonitoringList{}
+	if err := r.Client.List(ctx, monitoringResources); err != nil {
+		return nil, fmt.Errorf("cannot list the monitoring resources: %w", err)
+	}
+	for _, monitoringResource := range monitoringResources.Items {
+		export := monitoringResource.Spec.Export
+		if export != nil && export.Dash0 != nil && !util.IsSecretRefAllowedForMonitoringResource(
+			export.Dash0.Authorization.SecretRef,
+			monitoringResource.Namespace,
+		) {
+			// Secrets in other namespaces are never mirrored for monitoring resources, see
+			// util.IsSecretRefAllowedForMonitoringResource.
+			continue
+		}
+		exports = append(exports, export)
+	}
+
+	var secretRefs []dash0v1alpha1.SecretRef
+	for _, export := range exports {
+		if export != nil && export.Dash0 != nil && export.Dash0.Authorization.SecretRef != nil {
+			secretRefs = append(secretRefs, *export.Dash0.Authorization.SecretRef)
+		}
+	}
+	return secretRefs, nil
+}
+
 func (r *OperatorConfigurationReconciler) markAsDegraded(
 	ctx context.Context,
 	resource *dash0v1alpha1.Dash0OperatorConfiguration,
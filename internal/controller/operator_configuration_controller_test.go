@@ -12,10 +12,13 @@ import (
 	json "github.com/json-iterator/go"
 	"github.com/wI2L/jsondiff"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
@@ -890,6 +893,125 @@ var _ = Describe("The operation configuration resource controller", Ordered, fun
 			})
 		})
 	})
+
+	Describe("when the secret ref points to a secret in a different namespace", func() {
+		var sourceSecret *corev1.Secret
+		secretRef := dash0v1alpha1.SecretRef{
+			Name:      "shared-dash0-secret",
+			Key:       "dash0-token",
+			Namespace: TestNamespaceName,
+		}
+		mirroredSecretKey := client.ObjectKey{Namespace: OperatorNamespace, Name: util.MirroredSecretName(secretRef)}
+
+		BeforeEach(func() {
+			sourceSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: secretRef.Namespace,
+					Name:      secretRef.Name,
+				},
+				Data: map[string][]byte{
+					secretRef.Key:   []byte("token-value"),
+					"unrelated-key": []byte("unrelated-value"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, sourceSecret)).To(Succeed())
+
+			controllerDeployment = CreateControllerDeploymentWithoutSelfMonitoringWithoutAuth()
+			EnsureControllerDeploymentExists(ctx, k8sClient, controllerDeployment)
+			reconciler = createReconciler(controllerDeployment)
+
+			CreateOperatorConfigurationResourceWithSpec(
+				ctx,
+				k8sClient,
+				dash0v1alpha1.Dash0OperatorConfigurationSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint:    EndpointDash0Test,
+							ApiEndpoint: ApiEndpointTest,
+							Authorization: dash0v1alpha1.Authorization{
+								SecretRef: &secretRef,
+							},
+						},
+					},
+					SelfMonitoring: dash0v1alpha1.SelfMonitoring{
+						Enabled: ptr.To(false),
+					},
+				},
+			)
+		})
+
+		AfterEach(func() {
+			RemoveOperatorConfigurationResource(ctx, k8sClient)
+			EnsureControllerDeploymentDoesNotExist(ctx, k8sClient, controllerDeployment)
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, sourceSecret))).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: mirroredSecretKey.Namespace, Name: mirroredSecretKey.Name},
+			}))).To(Succeed())
+		})
+
+		It("mirrors the referenced key into the operator namespace and uses the mirrored secret", func() {
+			triggerOperatorConfigurationReconcileRequest(ctx, reconciler)
+			verifyOperatorConfigurationResourceIsAvailable(ctx)
+
+			mirroredSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, mirroredSecretKey, mirroredSecret)).To(Succeed())
+			Expect(mirroredSecret.Data).To(Equal(map[string][]byte{secretRef.Key: []byte("token-value")}))
+			Expect(mirroredSecret.Labels).To(HaveKeyWithValue(util.MirroredSecretLabelKey, "true"))
+			Expect(mirroredSecret.OwnerReferences).To(HaveLen(1))
+			Expect(mirroredSecret.OwnerReferences[0].Name).To(Equal(controllerDeployment.Name))
+
+			Eventually(func(g Gomega) {
+				updatedDeployment := LoadOperatorDeploymentOrFail(ctx, k8sClient, g)
+				selfMonitoringAndApiAccessConfiguration, err :=
+					selfmonitoringapiaccess.GetSelfMonitoringAndApiAccessConfigurationFromControllerDeployment(
+						updatedDeployment,
+						ControllerContainerName,
+					)
+				g.Expect(err).NotTo(HaveOccurred())
+				authorization := selfMonitoringAndApiAccessConfiguration.GetDash0Authorization()
+				g.Expect(authorization.SecretRef).ToNot(BeNil())
+				g.Expect(authorization.SecretRef.Name).To(Equal(mirroredSecretKey.Name))
+				g.Expect(authorization.SecretRef.Key).To(Equal(secretRef.Key))
+			}, timeout, pollingInterval).Should(Succeed())
+		})
+
+		It("updates the mirrored secret when the referenced secret changes", func() {
+			triggerOperatorConfigurationReconcileRequest(ctx, reconciler)
+
+			sourceSecret.Data[secretRef.Key] = []byte("rotated-token-value")
+			Expect(k8sClient.Update(ctx, sourceSecret)).To(Succeed())
+			triggerOperatorConfigurationReconcileRequest(ctx, reconciler)
+
+			mirroredSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, mirroredSecretKey, mirroredSecret)).To(Succeed())
+			Expect(mirroredSecret.Data).To(Equal(map[string][]byte{secretRef.Key: []byte("rotated-token-value")}))
+		})
+
+		It("deletes the mirrored secret when it is no longer referenced", func() {
+			triggerOperatorConfigurationReconcileRequest(ctx, reconciler)
+			Expect(k8sClient.Get(ctx, mirroredSecretKey, &corev1.Secret{})).To(Succeed())
+
+			RemoveOperatorConfigurationResource(ctx, k8sClient)
+			triggerOperatorConfigurationReconcileRequest(ctx, reconciler)
+
+			err := k8sClient.Get(ctx, mirroredSecretKey, &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("marks the resource as degraded if the referenced secret does not exist", func() {
+			Expect(k8sClient.Delete(ctx, sourceSecret)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: OperatorConfigurationResourceName},
+			})
+			Expect(err).To(HaveOccurred())
+
+			resource := LoadOperatorConfigurationResourceOrFail(ctx, k8sClient, Default)
+			degraded := meta.FindStatusCondition(resource.Status.Conditions, string(dash0v1alpha1.ConditionTypeDegraded))
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Reason).To(Equal("CannotMirrorAuthorizationSecret"))
+		})
+	})
 })
 
 func cleanUpDeploymentSpecForDiff(spec *appsv1.DeploymentSpec) {
@@ -912,6 +1034,7 @@ func createReconciler(controllerDeployment *appsv1.Deployment) *OperatorConfigur
 	return &OperatorConfigurationReconciler{
 		Client:    k8sClient,
 		Clientset: clientset,
+		Scheme:    k8sClient.Scheme(),
 		Recorder:  recorder,
 		ApiClients: []ApiClient{
 			apiClient1,
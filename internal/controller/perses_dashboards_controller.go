@@ -23,6 +23,7 @@ import (
 	otelmetric "go.opentelemetry.io/otel/metric"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,11 +32,24 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
 )
 
 type PersesDashboardCrdReconciler struct {
-	Client                    client.Client
-	AuthToken                 string
+	Client                   client.Client
+	AuthToken                string
+	IdempotencyKeyHeaderName string
+	ApiProxyUrl              *url.URL
+	ApiUserAgent             string
+	// ExcludedFields lists fields that are removed from the dashboard payload before it is sent to the Dash0 API, for
+	// example cluster-specific fields in the dashboard spec.
+	ExcludedFields []util.JsonFieldPath
+	// Recorder is optional, if set, it is used to emit events for Perses dashboard resources, for example when their
+	// display name collides with the display name of another dashboard.
+	Recorder record.EventRecorder
+	// HttpClient is optional, if set, it is used for all requests to the Dash0 API instead of a client created from
+	// ApiProxyUrl and ApiUserAgent. This allows tests to stub the responses of the Dash0 API.
+	HttpClient                *http.Client
 	mgr                       ctrl.Manager
 	skipNameValidation        bool
 	persesDashboardReconciler *PersesDashboardReconciler
@@ -49,10 +63,25 @@ type PersesDashboardReconciler struct {
 	apiConfig                  atomic.Pointer[ApiConfig]
 	authToken                  string
 	httpRetryDelay             time.Duration
+	idempotencyKeyHeaderName   string
+	excludedFields             []util.JsonFieldPath
+	recorder                   record.EventRecorder
+	synchronizationCache       synchronizationCache
+	dashboardOrigins           dashboardOrigins
 	controllerStopFunctionLock sync.Mutex
 	controllerStopFunction     *context.CancelFunc
 }
 
+// dashboardOrigins remembers, per Perses dashboard resource (keyed by namespace/name), the full URL (including the API
+// endpoint, the origin and the dataset) at which the dashboard has last been synchronized to Dash0, so that the
+// dashboard can be deleted at that URL when it moves to a different origin or API endpoint. Otherwise, the dashboard
+// would be left behind as a stale duplicate. The URL is also persisted in the synchronization results in the status of
+// the monitoring resource, which is used as a fallback after a restart of the operator manager.
+type dashboardOrigins struct {
+	lock sync.Mutex
+	urls map[string]string
+}
+
 //+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 var (
@@ -68,6 +97,18 @@ func (r *PersesDashboardCrdReconciler) GetAuthToken() string {
 	return r.AuthToken
 }
 
+func (r *PersesDashboardCrdReconciler) GetApiProxyUrl() *url.URL {
+	return r.ApiProxyUrl
+}
+
+func (r *PersesDashboardCrdReconciler) GetApiUserAgent() string {
+	return r.ApiUserAgent
+}
+
+func (r *PersesDashboardCrdReconciler) GetHttpClient() *http.Client {
+	return r.HttpClient
+}
+
 func (r *PersesDashboardCrdReconciler) ClientObject() client.Object {
 	return &persesv1alpha1.PersesDashboard{}
 }
@@ -114,11 +155,14 @@ func (r *PersesDashboardCrdReconciler) CreateResourceReconciler(
 	httpClient *http.Client,
 ) {
 	r.persesDashboardReconciler = &PersesDashboardReconciler{
-		Client:           r.Client,
-		pseudoClusterUid: pseudoClusterUid,
-		authToken:        authToken,
-		httpClient:       httpClient,
-		httpRetryDelay:   1 * time.Second,
+		Client:                   r.Client,
+		pseudoClusterUid:         pseudoClusterUid,
+		authToken:                authToken,
+		httpClient:               httpClient,
+		httpRetryDelay:           1 * time.Second,
+		idempotencyKeyHeaderName: r.IdempotencyKeyHeaderName,
+		excludedFields:           r.ExcludedFields,
+		recorder:                 r.Recorder,
 	}
 }
 
@@ -228,7 +272,15 @@ func (r *PersesDashboardCrdReconciler) SetApiEndpointAndDataset(
 		// hence this nil check is necessary.
 		return
 	}
-	r.persesDashboardReconciler.apiConfig.Store(apiConfig)
+	if previousApiConfig := r.persesDashboardReconciler.apiConfig.Swap(apiConfig); previousApiConfig != nil &&
+		previousApiConfig.hasChanged(apiConfig) {
+		// The API endpoint, the dataset or the headers have changed, do not skip synchronizing unchanged resources.
+		// This method is called on every reconcile of the operator configuration resource, so the cache must not be
+		// cleared if nothing has changed. When the API config is set for the first time (e.g. after the operator
+		// manager has been started), the content hashes persisted in the monitoring resources are still used, to avoid
+		// synchronizing all resources again.
+		r.persesDashboardReconciler.synchronizationCache.clear()
+	}
 	maybeStartWatchingThirdPartyResources(r, false, logger)
 }
 
@@ -239,6 +291,23 @@ func (r *PersesDashboardCrdReconciler) RemoveApiEndpointAndDataset() {
 		return
 	}
 	r.persesDashboardReconciler.apiConfig.Store(nil)
+	r.persesDashboardReconciler.synchronizationCache.clear()
+}
+
+func (r *PersesDashboardCrdReconciler) HandleDatasetChange(
+	ctx context.Context,
+	namespace string,
+	previousDataset string,
+	logger *logr.Logger,
+) {
+	if r.persesDashboardReconciler == nil {
+		// If no auth token has been set via environment variable, we do not even create the persesDashboardReconciler,
+		// hence this nil check is necessary.
+		return
+	}
+	// resynchronizeAfterDatasetChange deletes the dashboards from the previous dataset itself.
+	r.persesDashboardReconciler.dashboardOrigins.removeNamespace(namespace)
+	resynchronizeAfterDatasetChange(ctx, r, namespace, previousDataset, logger)
 }
 
 func (r *PersesDashboardReconciler) InitializeSelfMonitoringMetrics(
@@ -255,6 +324,8 @@ func (r *PersesDashboardReconciler) InitializeSelfMonitoringMetrics(
 	); err != nil {
 		logger.Error(err, "Cannot initialize the metric %s.")
 	}
+
+	initializeSynchronizationMetrics(meter, metricNamePrefix, logger)
 }
 
 func (r *PersesDashboardReconciler) KindDisplayName() string {
@@ -309,6 +380,14 @@ func (r *PersesDashboardReconciler) overrideHttpRetryDelay(delay time.Duration)
 	r.httpRetryDelay = delay
 }
 
+func (r *PersesDashboardReconciler) IdempotencyKeyHeaderName() string {
+	return r.idempotencyKeyHeaderName
+}
+
+func (r *PersesDashboardReconciler) SynchronizationCache() *synchronizationCache {
+	return &r.synchronizationCache
+}
+
 func (r *PersesDashboardReconciler) IsSynchronizationEnabled(monitoringResource *dash0v1alpha1.Dash0Monitoring) bool {
 	if monitoringResource == nil {
 		return false
@@ -320,6 +399,18 @@ func (r *PersesDashboardReconciler) IsSynchronizationEnabled(monitoringResource
 	return *boolPtr
 }
 
+func (r *PersesDashboardReconciler) ListResources(ctx context.Context, namespace string) ([]client.Object, error) {
+	dashboards := &persesv1alpha1.PersesDashboardList{}
+	if err := r.List(ctx, dashboards, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	resources := make([]client.Object, 0, len(dashboards.Items))
+	for i := range dashboards.Items {
+		resources = append(resources, &dashboards.Items[i])
+	}
+	return resources, nil
+}
+
 func (r *PersesDashboardReconciler) Create(
 	ctx context.Context,
 	e event.TypedCreateEvent[client.Object],
@@ -338,7 +429,9 @@ func (r *PersesDashboardReconciler) Create(
 		e.Object.GetName(),
 	)
 
+	r.deleteDashboardAtPreviousOrigin(ctx, e.Object, &logger)
 	upsertViaApi(ctx, r, e.Object, &logger)
+	r.warnAboutDuplicateDisplayName(ctx, e.Object, &logger)
 }
 
 func (r *PersesDashboardReconciler) Update(
@@ -359,7 +452,9 @@ func (r *PersesDashboardReconciler) Update(
 		e.ObjectNew.GetName(),
 	)
 
+	r.deleteDashboardAtPreviousOrigin(ctx, e.ObjectNew, &logger)
 	upsertViaApi(ctx, r, e.ObjectNew, &logger)
+	r.warnAboutDuplicateDisplayName(ctx, e.ObjectNew, &logger)
 }
 
 func (r *PersesDashboardReconciler) Delete(
@@ -380,7 +475,9 @@ func (r *PersesDashboardReconciler) Delete(
 		e.Object.GetName(),
 	)
 
+	r.deleteDashboardAtPreviousOrigin(ctx, e.Object, &logger)
 	deleteViaApi(ctx, r, e.Object, &logger)
+	r.dashboardOrigins.remove(synchronizationCacheKey(e.Object))
 }
 
 func (r *PersesDashboardReconciler) Generic(
@@ -415,7 +512,7 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 	//nolint:ineffassign
 	actionLabel := "?"
 	switch action {
-	case upsert:
+	case upsertAction:
 		actionLabel = "upsert"
 		persesDashboard := preconditionChecksResult.thirdPartyResource.(*persesv1alpha1.PersesDashboard)
 		spec := persesDashboard.Spec
@@ -424,7 +521,7 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 		}
 		if spec.Display.Name == "" {
 			// Let the dashboard name default to the perses dashboard resource's namespace + name, if unset.
-			spec.Display.Name = fmt.Sprintf("%s/%s", preconditionChecksResult.k8sNamespace, preconditionChecksResult.k8sName)
+			spec.Display.Name = dashboardDisplayName(persesDashboard)
 		}
 
 		// Remove all unnecessary metadata (labels & annotations), we basically only need the dashboard spec.
@@ -433,6 +530,13 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 				"kind": "PersesDashboard",
 				"spec": spec,
 			})
+		if len(r.excludedFields) > 0 {
+			if serializedDashboard, err = removeExcludedFields(serializedDashboard, r.excludedFields); err != nil {
+				excludeFieldsErr := fmt.Errorf("unable to remove the excluded fields from the dashboard: %w", err)
+				logger.Error(excludeFieldsErr, "error removing excluded fields")
+				return 1, nil, nil, map[string]string{itemName: excludeFieldsErr.Error()}
+			}
+		}
 		requestPayload := bytes.NewBuffer(serializedDashboard)
 
 		req, err = http.NewRequest(
@@ -440,7 +544,13 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 			dashboardUrl,
 			requestPayload,
 		)
-	case delete:
+		// If the dashboard has been synchronized to a different origin before and has not been deleted there yet, the
+		// previous origin is kept, so that deleting it is attempted again with the next change.
+		cacheKey := synchronizationCacheKey(preconditionChecksResult.thirdPartyResource)
+		if r.dashboardOrigins.get(cacheKey) == "" {
+			r.dashboardOrigins.put(cacheKey, dashboardUrl)
+		}
+	case deleteAction:
 		actionLabel = "delete"
 		req, err = http.NewRequest(
 			http.MethodDelete,
@@ -464,10 +574,12 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 		return 1, nil, nil, map[string]string{itemName: httpError.Error()}
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", preconditionChecksResult.authToken))
-	if action == upsert {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	setApiRequestHeaders(
+		req,
+		preconditionChecksResult.apiHeaders,
+		preconditionChecksResult.authToken,
+		action == upsertAction,
+	)
 
 	return 1, []HttpRequestWithItemName{{
 		ItemName: itemName,
@@ -476,6 +588,15 @@ func (r *PersesDashboardReconciler) MapResourceToHttpRequests(
 }
 
 func (r *PersesDashboardReconciler) renderDashboardUrl(preconditionCheckResult *preconditionValidationResult) string {
+	if !strings.HasSuffix(preconditionCheckResult.apiEndpoint, "/") {
+		preconditionCheckResult.apiEndpoint += "/"
+	}
+	return preconditionCheckResult.apiEndpoint + r.renderDashboardPath(preconditionCheckResult)
+}
+
+// renderDashboardPath renders the path of the dashboard relative to the API endpoint, including the dataset query
+// parameter.
+func (r *PersesDashboardReconciler) renderDashboardPath(preconditionCheckResult *preconditionValidationResult) string {
 	dashboardOrigin := fmt.Sprintf(
 		// we deliberately use _ as the separator, since that is an illegal character in Kubernetes names. This avoids
 		// any potential naming collisions (e.g. namespace="abc" & name="def-ghi" vs. namespace="abc-def" & name="ghi").
@@ -485,17 +606,116 @@ func (r *PersesDashboardReconciler) renderDashboardUrl(preconditionCheckResult *
 		preconditionCheckResult.k8sNamespace,
 		preconditionCheckResult.k8sName,
 	)
-	if !strings.HasSuffix(preconditionCheckResult.apiEndpoint, "/") {
-		preconditionCheckResult.apiEndpoint += "/"
-	}
 	return fmt.Sprintf(
-		"%sapi/dashboards/%s?dataset=%s",
-		preconditionCheckResult.apiEndpoint,
+		"api/dashboards/%s?dataset=%s",
 		dashboardOrigin,
 		url.QueryEscape(preconditionCheckResult.dataset),
 	)
 }
 
+// removeExcludedFields removes the given fields from the serialized dashboard.
+func removeExcludedFields(serializedDashboard []byte, excludedFields []util.JsonFieldPath) ([]byte, error) {
+	var dashboard interface{}
+	if err := json.Unmarshal(serializedDashboard, &dashboard); err != nil {
+		return nil, err
+	}
+	util.RemoveJsonFields(dashboard, excludedFields)
+	return json.Marshal(dashboard)
+}
+
+// deleteDashboardAtPreviousOrigin deletes the dashboard from Dash0 at the URL it has last been synchronized to, if
+// that differs from the URL it would be synchronized to now, e.g. because the operator-wide dataset or the API endpoint
+// has changed. If the deletion fails, the previous URL is kept and deleting it is attempted again with the next change.
+func (r *PersesDashboardReconciler) deleteDashboardAtPreviousOrigin(
+	ctx context.Context,
+	thirdPartyResource client.Object,
+	logger *logr.Logger,
+) {
+	preconditionChecksResult := validatePreconditions(ctx, r, thirdPartyResource, logger)
+	if !preconditionChecksResult.synchronizeResource {
+		return
+	}
+	cacheKey := synchronizationCacheKey(thirdPartyResource)
+	previousDashboardUrl := r.dashboardOrigins.get(cacheKey)
+	if previousDashboardUrl == "" {
+		// After a restart of the operator manager, the in-memory state is empty, the URL persisted in the status of the
+		// monitoring resource is used instead.
+		previousDashboardUrl = preconditionChecksResult.monitoringResource.
+			Status.PersesDashboardSynchronizationResults[cacheKey].DashboardUrl
+		if previousDashboardUrl == "" {
+			return
+		}
+		r.dashboardOrigins.put(cacheKey, previousDashboardUrl)
+	}
+	if previousDashboardUrl == r.renderDashboardUrl(preconditionChecksResult) {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, previousDashboardUrl, nil)
+	if err != nil {
+		logger.Error(err, "unable to create a new HTTP request to delete the dashboard at its previous origin")
+		return
+	}
+	setApiRequestHeaders(req, preconditionChecksResult.apiHeaders, preconditionChecksResult.authToken, false)
+	if _, httpErrors := executeAllHttpRequests(
+		r,
+		[]HttpRequestWithItemName{{
+			ItemName: preconditionChecksResult.k8sName,
+			Request:  req,
+		}},
+		"Deleting",
+		logger,
+	); len(httpErrors) > 0 {
+		logger.Info(
+			fmt.Sprintf(
+				"Failed to delete %s %s/%s at its previous origin, will try again with the next change: %v",
+				r.KindDisplayName(),
+				thirdPartyResource.GetNamespace(),
+				thirdPartyResource.GetName(),
+				httpErrors,
+			))
+		return
+	}
+	r.dashboardOrigins.remove(cacheKey)
+}
+
+func (o *dashboardOrigins) get(key string) string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.urls[key]
+}
+
+func (o *dashboardOrigins) put(key string, dashboardUrl string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.urls == nil {
+		o.urls = make(map[string]string)
+	}
+	o.urls[key] = dashboardUrl
+}
+
+func (o *dashboardOrigins) remove(key string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.urls, key)
+}
+
+func (o *dashboardOrigins) removeNamespace(namespace string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for key := range o.urls {
+		if strings.HasPrefix(key, namespace+"/") {
+			delete(o.urls, key)
+		}
+	}
+}
+
+func (o *dashboardOrigins) clear() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.urls = nil
+}
+
 func (r *PersesDashboardReconciler) UpdateSynchronizationResultsInStatus(
 	monitoringResource *dash0v1alpha1.Dash0Monitoring,
 	qualifiedName string,
@@ -504,6 +724,7 @@ func (r *PersesDashboardReconciler) UpdateSynchronizationResultsInStatus(
 	_ []string,
 	synchronizationErrors map[string]string,
 	validationIssuesMap map[string][]string,
+	contentHash string,
 ) interface{} {
 	previousResults := monitoringResource.Status.PersesDashboardSynchronizationResults
 	if previousResults == nil {
@@ -516,6 +737,10 @@ func (r *PersesDashboardReconciler) UpdateSynchronizationResultsInStatus(
 	result := dash0v1alpha1.PersesDashboardSynchronizationResults{
 		SynchronizedAt:        metav1.Time{Time: time.Now()},
 		SynchronizationStatus: status,
+		ContentHash:           contentHash,
+		// Persist the URL at which the dashboard exists in Dash0, so that it can still be deleted there after a restart
+		// when the origin or the API endpoint has changed in the meantime.
+		DashboardUrl: r.dashboardOrigins.get(qualifiedName),
 	}
 	if len(synchronizationErrors) > 0 {
 		// there can only be at most one synchronization error for a Perses dashboard resource
@@ -528,3 +753,10 @@ func (r *PersesDashboardReconciler) UpdateSynchronizationResultsInStatus(
 	previousResults[qualifiedName] = result
 	return result
 }
+
+func (r *PersesDashboardReconciler) PersistedContentHash(
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	qualifiedName string,
+) string {
+	return monitoringResource.Status.PersesDashboardSynchronizationResults[qualifiedName].ContentHash
+}
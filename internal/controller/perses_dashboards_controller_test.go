@@ -4,22 +4,36 @@
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"time"
 
 	persesv1alpha1 "github.com/perses/perses-operator/api/v1alpha1"
+	persesv1 "github.com/perses/perses/pkg/model/api/v1"
+	persescommon "github.com/perses/perses/pkg/model/api/v1/common"
+	persesdashboard "github.com/perses/perses/pkg/model/api/v1/dashboard"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
 
 	"github.com/h2non/gock"
 	. "github.com/onsi/ginkgo/v2"
@@ -70,6 +84,38 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 			Expect(persesDashboardCrdReconciler.persesDashboardReconciler).To(BeNil())
 		})
 
+		It("retries reading the kube-system namespace if the API server is not available yet", func() {
+			originalBackoff := kubeSystemNamespaceBackoff
+			DeferCleanup(func() {
+				kubeSystemNamespaceBackoff = originalBackoff
+			})
+			kubeSystemNamespaceBackoff.Duration = 10 * time.Millisecond
+
+			watchingClient, err := client.NewWithWatch(cfg, client.Options{Scheme: k8sClient.Scheme()})
+			Expect(err).ToNot(HaveOccurred())
+			failedAttempts := 0
+			flakyClient := interceptor.NewClient(watchingClient, interceptor.Funcs{
+				Get: func(
+					ctx context.Context,
+					c client.WithWatch,
+					key client.ObjectKey,
+					obj client.Object,
+					opts ...client.GetOption,
+				) error {
+					if _, isNamespace := obj.(*corev1.Namespace); isNamespace && failedAttempts < 2 {
+						failedAttempts++
+						return apierrors.NewServiceUnavailable("the API server is starting up")
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			})
+
+			createPersesDashboardCrdReconcilerWithAuthToken()
+			Expect(persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, flakyClient, &logger)).To(Succeed())
+			Expect(failedAttempts).To(Equal(2))
+			Expect(persesDashboardCrdReconciler.persesDashboardReconciler).ToNot(BeNil())
+		})
+
 		It("does not start watching Perses dashboards if the CRD does not exist and the API endpoint has not been provided", func() {
 			createPersesDashboardCrdReconcilerWithAuthToken()
 			Expect(persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, k8sClient, &logger)).To(Succeed())
@@ -105,6 +151,40 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 			Expect(isWatchingPersesDashboardResources()).To(BeTrue())
 		})
 
+		It("starts watching Perses dashboards when elected as leader and stops watching when leadership is lost", func() {
+			createPersesDashboardCrdReconcilerWithAuthToken()
+			ensurePersesDashboardCrdExists(ctx)
+			Expect(persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, k8sClient, &logger)).To(Succeed())
+			// provide the API endpoint without triggering the watch
+			persesDashboardCrdReconciler.persesDashboardReconciler.apiConfig.Store(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+				Dataset:  DatasetTest,
+			})
+			Expect(isWatchingPersesDashboardResources()).To(BeFalse())
+
+			leaderElectionRunnable := &thirdPartyResourceWatchLeaderElectionRunnable{
+				crdReconciler: persesDashboardCrdReconciler,
+				logger:        &logger,
+			}
+			Expect(leaderElectionRunnable.NeedLeaderElection()).To(BeTrue())
+			leaderCtx, loseLeadership := context.WithCancel(ctx)
+			runnableHasReturned := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(runnableHasReturned)
+				Expect(leaderElectionRunnable.Start(leaderCtx)).To(Succeed())
+			}()
+			Eventually(func(g Gomega) {
+				g.Expect(isWatchingPersesDashboardResources()).To(BeTrue())
+			}).Should(Succeed())
+
+			loseLeadership()
+			Eventually(runnableHasReturned).Should(BeClosed())
+			Eventually(func(g Gomega) {
+				g.Expect(isWatchingPersesDashboardResources()).To(BeFalse())
+			}).Should(Succeed())
+		})
+
 		It("starts watching Perses dashboards if API endpoint is provided and the CRD is created later on", func() {
 			createPersesDashboardCrdReconcilerWithAuthToken()
 			Expect(persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, k8sClient, &logger)).To(Succeed())
@@ -225,6 +305,9 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 			persesDashboardReconciler = persesDashboardCrdReconciler.persesDashboardReconciler
 			// to make tests that involve http retries faster, we do not want to wait for one second for each retry
 			persesDashboardReconciler.overrideHttpRetryDelay(20 * time.Millisecond)
+			// tests synchronize the same dashboard resource repeatedly, start each test without a cached content hash
+			persesDashboardReconciler.SynchronizationCache().clear()
+			persesDashboardReconciler.dashboardOrigins.clear()
 		})
 
 		AfterEach(func() {
@@ -316,17 +399,94 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 			Expect(gock.IsDone()).To(BeTrue())
 		})
 
-		It("updates a dashboard", func() {
+		It("sends the additional API headers from the operator configuration", func() {
 			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+				Dataset:  DatasetTest,
+				Headers: []dash0v1alpha1.Header{
+					{Name: "X-Tenant-Id", Value: "tenant-1"},
+				},
+			}, &logger)
 
-			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				MatchHeader("X-Tenant-Id", "tenant-1").
+				MatchHeader("Authorization", "Bearer "+AuthorizationTokenTest).
+				Reply(200).
+				JSON(map[string]string{})
 			defer gock.Off()
 
 			dashboardResource := createDashboardResource()
-			persesDashboardReconciler.Update(
+			persesDashboardReconciler.Create(
 				ctx,
-				event.TypedUpdateEvent[client.Object]{
-					ObjectNew: dashboardResource,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("sends the same idempotency key header when a dashboard is synchronized repeatedly", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			var idempotencyKeys []string
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				AddMatcher(recordHeader(DefaultIdempotencyKeyHeaderName, &idempotencyKeys)).
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for i := 0; i < 2; i++ {
+				// make sure the second create event is not skipped as unchanged
+				persesDashboardReconciler.SynchronizationCache().remove(synchronizationCacheKey(dashboardResource))
+				persesDashboardReconciler.Create(
+					ctx,
+					event.TypedCreateEvent[client.Object]{
+						Object: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(idempotencyKeys).To(HaveLen(2))
+			Expect(idempotencyKeys[0]).To(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(idempotencyKeys[1]).To(Equal(idempotencyKeys[0]))
+		})
+
+		It("sends the same idempotency key header when retrying a failed request", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			var idempotencyKeys []string
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				AddMatcher(recordHeader(DefaultIdempotencyKeyHeaderName, &idempotencyKeys)).
+				Times(1).
+				Reply(503).
+				JSON(map[string]string{})
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				AddMatcher(recordHeader(DefaultIdempotencyKeyHeaderName, &idempotencyKeys)).
+				Times(1).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
 				},
 				&controllertest.TypedQueue[reconcile.Request]{},
 			)
@@ -337,23 +497,100 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 				defaultExpectedPersesSyncResult,
 			)
 			Expect(gock.IsDone()).To(BeTrue())
+			Expect(idempotencyKeys).To(HaveLen(2))
+			Expect(idempotencyKeys[0]).To(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(idempotencyKeys[1]).To(Equal(idempotencyKeys[0]))
 		})
 
-		It("deletes a dashboard", func() {
+		It("uses a custom idempotency key header name", func() {
 			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
-
-			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			customHeaderName := "X-Custom-Idempotency-Key"
+			persesDashboardReconciler.idempotencyKeyHeaderName = customHeaderName
+			defer func() {
+				persesDashboardReconciler.idempotencyKeyHeaderName = ""
+			}()
+
+			var idempotencyKeys []string
+			var defaultHeaderValues []string
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				AddMatcher(recordHeader(customHeaderName, &idempotencyKeys)).
+				AddMatcher(recordHeader(DefaultIdempotencyKeyHeaderName, &defaultHeaderValues)).
+				Times(1).
+				Reply(200).
+				JSON(map[string]string{})
 			defer gock.Off()
 
 			dashboardResource := createDashboardResource()
-			persesDashboardReconciler.Delete(
+			persesDashboardReconciler.Create(
 				ctx,
-				event.TypedDeleteEvent[client.Object]{
+				event.TypedCreateEvent[client.Object]{
 					Object: dashboardResource,
 				},
 				&controllertest.TypedQueue[reconcile.Request]{},
 			)
 
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(idempotencyKeys).To(HaveLen(1))
+			Expect(idempotencyKeys[0]).To(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(defaultHeaderValues).To(Equal([]string{""}))
+		})
+
+		It("derives the same idempotency key for the same dashboard content", func() {
+			key1 := computeIdempotencyKey("/api/dashboards/dashboard-1", []byte(`{"spec":{}}`))
+			key2 := computeIdempotencyKey("/api/dashboards/dashboard-1", []byte(`{"spec":{}}`))
+			key3 := computeIdempotencyKey("/api/dashboards/dashboard-1", []byte(`{"spec":{"display":{}}}`))
+			key4 := computeIdempotencyKey("/api/dashboards/dashboard-2", []byte(`{"spec":{}}`))
+			Expect(key1).To(Equal(key2))
+			Expect(key1).ToNot(Equal(key3))
+			Expect(key1).ToNot(Equal(key4))
+		})
+
+		It("skips synchronizing a dashboard that has not changed since the last successful synchronization", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request, the second update event must not trigger another API call
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for i := 0; i < 2; i++ {
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPersesSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("synchronizes a dashboard only once for duplicate create events", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request, the duplicate create event must not trigger another API call
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for i := 0; i < 2; i++ {
+				persesDashboardReconciler.Create(
+					ctx,
+					event.TypedCreateEvent[client.Object]{
+						Object: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
 			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
 				ctx,
 				k8sClient,
@@ -362,15 +599,117 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 			Expect(gock.IsDone()).To(BeTrue())
 		})
 
-		It("reports http errors when synchronizing a dashboard", func() {
+		It("skips synchronizing an unchanged dashboard after a restart based on the persisted content hash", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request, the update event after the simulated restart must not trigger another API call
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PersesDashboardSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-dashboard")]
+				g.Expect(result.ContentHash).To(MatchRegexp("^[0-9a-f]{64}$"))
+			}).Should(Succeed())
+
+			// simulate a restart of the operator manager, which starts with an empty in-memory cache
+			persesDashboardReconciler.synchronizationCache = synchronizationCache{}
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+		})
+
+		It("still uses the persisted content hash after the cache has been cleared", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request, clearing the cache must not stop the persisted content hash from being used for
+			// good
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PersesDashboardSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-dashboard")]
+				g.Expect(result.ContentHash).To(MatchRegexp("^[0-9a-f]{64}$"))
+			}).Should(Succeed())
+
+			persesDashboardReconciler.SynchronizationCache().clear()
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+		})
+
+		It("does not persist the content hash when the synchronization has failed", func() {
 			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
 
 			gock.New(ApiEndpointTest).
 				Put(defaultExpectedPathDashboard).
 				MatchParam("dataset", DatasetTest).
-				Times(3).
-				Reply(503).
+				Times(1).
+				Reply(400).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: createDashboardResource(),
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PersesDashboardSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-dashboard")]
+				g.Expect(result.SynchronizationStatus).To(Equal(dash0v1alpha1.Failed))
+				g.Expect(result.ContentHash).To(BeEmpty())
+			}).Should(Succeed())
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("synchronizes an unchanged dashboard again after it has been deleted", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(2).
+				Reply(200).
 				JSON(map[string]string{})
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
 			defer gock.Off()
 
 			dashboardResource := createDashboardResource()
@@ -381,59 +720,835 @@ var _ = Describe("The Perses dashboard controller", Ordered, func() {
 				},
 				&controllertest.TypedQueue[reconcile.Request]{},
 			)
-
-			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+			persesDashboardReconciler.Delete(
 				ctx,
-				k8sClient,
-				dash0v1alpha1.PersesDashboardSynchronizationResults{
-					SynchronizationStatus: dash0v1alpha1.Failed,
-					SynchronizationError:  "^unexpected status code 503 when updating/creating/deleting the dashboard \"test-dashboard\" at https://api.dash0.com/api/dashboards/dash0-operator_.*_test-dataset_test-namespace_test-dashboard\\?dataset=test-dataset, response body is {}\n$",
-					ValidationIssues:      nil,
+				event.TypedDeleteEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
 				},
+				&controllertest.TypedQueue[reconcile.Request]{},
 			)
+
 			Expect(gock.IsDone()).To(BeTrue())
 		})
-	})
-})
 
-func createPersesDashboardCrdReconcilerWithoutAuthToken() {
-	persesDashboardCrdReconciler = &PersesDashboardCrdReconciler{
-		Client: k8sClient,
+		It("removes the excluded fields from the dashboard before sending it", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			persesDashboardReconciler.excludedFields = []util.JsonFieldPath{{"spec", "display"}, {"spec", "missing"}}
+			defer func() {
+				persesDashboardReconciler.excludedFields = nil
+			}()
+
+			preconditionChecksResult := validatePreconditions(ctx, persesDashboardReconciler, createDashboardResource(), &logger)
+			_, httpRequests, _, synchronizationErrors :=
+				persesDashboardReconciler.MapResourceToHttpRequests(preconditionChecksResult, upsertAction, &logger)
+
+			Expect(synchronizationErrors).To(BeEmpty())
+			Expect(httpRequests).To(HaveLen(1))
+			payload, err := readRequestPayload(httpRequests[0].Request)
+			Expect(err).ToNot(HaveOccurred())
+			var dashboard map[string]interface{}
+			Expect(json.Unmarshal(payload, &dashboard)).To(Succeed())
+			Expect(dashboard).To(HaveKeyWithValue("kind", "PersesDashboard"))
+			Expect(dashboard).To(HaveKey("spec"))
+			Expect(dashboard["spec"]).ToNot(HaveKey("display"))
+		})
 
-		// We create the controller multiple times in tests, this option is required, otherwise the controller
-		// runtime will complain.
-		skipNameValidation: true,
-	}
-}
+		It("emits a warning event if another dashboard has the same display name in the same dataset", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			existingDashboard := createDashboardResourceInCluster(ctx)
+			defer func() {
+				Expect(k8sClient.Delete(ctx, existingDashboard)).To(Succeed())
+			}()
+			recorder := record.NewFakeRecorder(10)
+			persesDashboardReconciler.recorder = recorder
+			defer func() {
+				persesDashboardReconciler.recorder = nil
+			}()
+
+			otherDashboard := createDashboardResource()
+			otherDashboard.Name = "other-dashboard"
+			otherDashboard.Spec.Display = &persescommon.Display{Name: "test-namespace/test-dashboard"}
+			persesDashboardReconciler.warnAboutDuplicateDisplayName(ctx, otherDashboard, &logger)
+
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(duplicateDashboardDisplayNameReason),
+				ContainSubstring("test-namespace/test-dashboard"),
+			)))
+		})
 
-func createPersesDashboardCrdReconcilerWithAuthToken() {
-	persesDashboardCrdReconciler = &PersesDashboardCrdReconciler{
-		Client:    k8sClient,
-		AuthToken: AuthorizationTokenTest,
+		It("does not emit a warning event if the display names differ", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			existingDashboard := createDashboardResourceInCluster(ctx)
+			defer func() {
+				Expect(k8sClient.Delete(ctx, existingDashboard)).To(Succeed())
+			}()
+			recorder := record.NewFakeRecorder(10)
+			persesDashboardReconciler.recorder = recorder
+			defer func() {
+				persesDashboardReconciler.recorder = nil
+			}()
+
+			otherDashboard := createDashboardResource()
+			otherDashboard.Name = "other-dashboard"
+			persesDashboardReconciler.warnAboutDuplicateDisplayName(ctx, otherDashboard, &logger)
+
+			Expect(recorder.Events).ToNot(Receive())
+		})
 
-		// We create the controller multiple times in tests, this option is required, otherwise the controller
-		// runtime will complain.
-		skipNameValidation: true,
-	}
-}
+		It("falls back to the default dataset if neither the API config nor the monitoring resource set a dataset", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+			}, &logger)
 
-func expectDashboardPutRequest(expectedPath string) {
-	gock.New(ApiEndpointTest).
-		Put(expectedPath).
-		MatchParam("dataset", DatasetTest).
-		Times(1).
-		Reply(200).
-		JSON(map[string]string{})
-}
+			preconditionChecksResult := validatePreconditions(ctx, persesDashboardReconciler, createDashboardResource(), &logger)
 
-func expectDashboardDeleteRequest(expectedPath string) {
-	gock.New(ApiEndpointTest).
-		Delete(expectedPath).
-		MatchParam("dataset", DatasetTest).
-		Times(1).
-		Reply(200).
-		JSON(map[string]string{})
-}
+			Expect(preconditionChecksResult.synchronizeResource).To(BeTrue())
+			Expect(preconditionChecksResult.dataset).To(Equal(util.DatasetDefault))
+		})
+
+		It("uses the dataset from the Dash0 monitoring resource", func() {
+			monitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			monitoringResource.Spec.Dataset = "team-dataset"
+			Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+
+			gock.New(ApiEndpointTest).
+				Put(fmt.Sprintf("%s.*%s", dashboardApiBasePath, "dash0-operator_.*_team-dataset_test-namespace_test-dashboard")).
+				MatchParam("dataset", "team-dataset").
+				Times(1).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("moves dashboards to the new dataset when the dataset of the Dash0 monitoring resource changes", func() {
+			monitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			dashboardResource := createDashboardResourceInCluster(ctx)
+			defer func() {
+				Expect(k8sClient.Delete(ctx, dashboardResource)).To(Succeed())
+			}()
+
+			monitoringResource.Spec.Dataset = "team-dataset"
+			Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Put(fmt.Sprintf("%s.*%s", dashboardApiBasePath, "dash0-operator_.*_team-dataset_test-namespace_test-dashboard")).
+				MatchParam("dataset", "team-dataset").
+				Times(1).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			// the previous spec.dataset was empty, that is, the dashboard has been synchronized to the dataset from the
+			// API config
+			persesDashboardCrdReconciler.HandleDatasetChange(ctx, TestNamespaceName, "", &logger)
+
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("deletes a dashboard at its previous origin when the operator-wide dataset changes", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Put(fmt.Sprintf("%s.*%s", dashboardApiBasePath, "dash0-operator_.*_other-dataset_test-namespace_test-dashboard")).
+				MatchParam("dataset", "other-dataset").
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+				Dataset:  "other-dataset",
+			}, &logger)
+			// the dashboard must only be deleted at its previous origin once
+			for i := 0; i < 2; i++ {
+				// make sure the second update event is not skipped as unchanged
+				persesDashboardReconciler.SynchronizationCache().remove(synchronizationCacheKey(dashboardResource))
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("deletes a dashboard at its previous API endpoint after a restart", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			otherApiEndpoint := "https://api.eu-west-1.aws.dash0.com"
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			gock.New(otherApiEndpoint).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(1).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PersesDashboardSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-dashboard")]
+				g.Expect(result.DashboardUrl).To(HavePrefix(ApiEndpointTest + dashboardApiBasePath))
+			}).Should(Succeed())
+
+			// simulate a restart of the operator manager with a different API endpoint, the previous URL of the
+			// dashboard is only available in the status of the monitoring resource
+			persesDashboardReconciler.synchronizationCache = synchronizationCache{}
+			persesDashboardReconciler.dashboardOrigins = dashboardOrigins{}
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: otherApiEndpoint,
+				Dataset:  DatasetTest,
+			}, &logger)
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+			Expect(gock.IsDone()).To(BeTrue())
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PersesDashboardSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-dashboard")]
+				g.Expect(result.DashboardUrl).To(HavePrefix(otherApiEndpoint + dashboardApiBasePath))
+			}).Should(Succeed())
+		})
+
+		It("deletes a dashboard at its previous origin again if the first attempt has failed", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Delete(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(1).
+				Reply(400).
+				JSON(map[string]string{})
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Put(fmt.Sprintf("%s.*%s", dashboardApiBasePath, "dash0-operator_.*_other-dataset_test-namespace_test-dashboard")).
+				MatchParam("dataset", "other-dataset").
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+				Dataset:  "other-dataset",
+			}, &logger)
+			for i := 0; i < 2; i++ {
+				persesDashboardReconciler.SynchronizationCache().remove(synchronizationCacheKey(dashboardResource))
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("does not delete dashboards when the previous and the new effective dataset are the same", func() {
+			monitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			dashboardResource := createDashboardResourceInCluster(ctx)
+			defer func() {
+				Expect(k8sClient.Delete(ctx, dashboardResource)).To(Succeed())
+			}()
+
+			// spec.dataset is set explicitly to the dataset that was used anyway via the API config
+			monitoringResource.Spec.Dataset = DatasetTest
+			Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			persesDashboardCrdReconciler.HandleDatasetChange(ctx, TestNamespaceName, "", &logger)
+
+			// only the PUT request has been executed, the DELETE request is still pending
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+			Expect(gock.IsPending()).To(BeTrue())
+			Expect(gock.Pending()).To(HaveLen(1))
+			Expect(gock.Pending()[0].Request().Method).To(Equal(http.MethodDelete))
+		})
+
+		It("synchronizes an unchanged dashboard again after the monitoring resource has been recreated", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			DeleteMonitoringResourceIfItExists(ctx, k8sClient)
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPersesSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("synchronizes an unchanged dashboard again after synchronization has been disabled and re-enabled", func() {
+			monitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for _, synchronizationEnabled := range []bool{true, false, true} {
+				monitoringResource.Spec.SynchronizePersesDashboards = ptr.To(synchronizationEnabled)
+				Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("does not synchronize an unchanged dashboard again when the same API config is set again", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request, setting an identical API config must not clear the synchronization cache
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for i := 0; i < 2; i++ {
+				persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+					Endpoint: ApiEndpointTest,
+					Dataset:  DatasetTest,
+				}, &logger)
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+		})
+
+		It("synchronizes an unchanged dashboard again after the API config has been changed", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(2).
+				Reply(200).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			for i := 0; i < 2; i++ {
+				persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+					Endpoint: ApiEndpointTest,
+					Dataset:  DatasetTest,
+					Headers: []dash0v1alpha1.Header{
+						{Name: "X-Tenant-Id", Value: fmt.Sprintf("tenant-%d", i)},
+					},
+				}, &logger)
+				persesDashboardReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: dashboardResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("derives a different content hash when the API config, the auth token or the monitoring resource changes", func() {
+			request, err := http.NewRequest(
+				http.MethodPut,
+				"https://api.dash0.com/api/dashboards/dashboard-1?dataset=default",
+				bytes.NewBufferString(`{"spec":{}}`),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			httpRequests := []HttpRequestWithItemName{{ItemName: "dashboard-1", Request: request}}
+			monitoringResource := &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: metav1.ObjectMeta{UID: "monitoring-resource-uid-1"},
+			}
+			recreatedMonitoringResource := &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: metav1.ObjectMeta{UID: "monitoring-resource-uid-2"},
+			}
+
+			hash := computeSynchronizationContentHash(
+				&preconditionValidationResult{authToken: "token-1", monitoringResource: monitoringResource},
+				httpRequests,
+				&logger,
+			)
+			Expect(hash).To(Equal(computeSynchronizationContentHash(
+				&preconditionValidationResult{authToken: "token-1", monitoringResource: monitoringResource},
+				httpRequests,
+				&logger,
+			)))
+			Expect(hash).ToNot(Equal(computeSynchronizationContentHash(
+				&preconditionValidationResult{authToken: "token-2", monitoringResource: monitoringResource},
+				httpRequests,
+				&logger,
+			)))
+			Expect(hash).ToNot(Equal(computeSynchronizationContentHash(
+				&preconditionValidationResult{authToken: "token-1", monitoringResource: recreatedMonitoringResource},
+				httpRequests,
+				&logger,
+			)))
+			Expect(hash).ToNot(Equal(computeSynchronizationContentHash(
+				&preconditionValidationResult{
+					authToken:          "token-1",
+					monitoringResource: monitoringResource,
+					apiHeaders:         []dash0v1alpha1.Header{{Name: "X-Tenant-Id", Value: "tenant-1"}},
+				},
+				httpRequests,
+				&logger,
+			)))
+			Expect(hash).ToNot(Equal(computeSynchronizationContentHash(
+				&preconditionValidationResult{
+					authToken:          "token-1",
+					monitoringResource: monitoringResource,
+					apiEndpoint:        "https://api.eu-west-1.aws.dash0.com",
+					dataset:            "default",
+				},
+				httpRequests,
+				&logger,
+			)))
+		})
+
+		It("updates a dashboard", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPersesSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("deletes a dashboard", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			expectDashboardDeleteRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Delete(
+				ctx,
+				event.TypedDeleteEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPersesSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("reports http errors when synchronizing a dashboard", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(3).
+				Reply(503).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				dash0v1alpha1.PersesDashboardSynchronizationResults{
+					SynchronizationStatus: dash0v1alpha1.Failed,
+					SynchronizationError:  "^unexpected status code 503 when updating/creating/deleting the dashboard \"test-dashboard\" at https://api.dash0.com/api/dashboards/dash0-operator_.*_test-dataset_test-namespace_test-dashboard\\?dataset=test-dataset, response body is {}\n$",
+					ValidationIssues:      nil,
+				},
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("records the synchronization results in the self-monitoring metric", func() {
+			metricReader := sdkmetric.NewManualReader()
+			meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(metricReader))
+			previousSynchronizationResultMetric := synchronizationResultMetric
+			var err error
+			synchronizationResultMetric, err = meterProvider.Meter("test").Int64Counter("synchronization_results")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				synchronizationResultMetric = previousSynchronizationResultMetric
+			}()
+
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Times(3).
+				Reply(503).
+				JSON(map[string]string{})
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			persesDashboardReconciler.SynchronizationCache().remove(synchronizationCacheKey(dashboardResource))
+			persesDashboardReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+
+			Expect(readSynchronizationResultCounts(ctx, metricReader)).To(Equal(map[string]int64{
+				"dashboard/success":    1,
+				"dashboard/http-error": 1,
+			}))
+		})
+
+		It("records the duration of API requests in the self-monitoring metric", func() {
+			metricReader := sdkmetric.NewManualReader()
+			meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(metricReader))
+			previousApiRequestDurationMetric := apiRequestDurationMetric
+			var err error
+			apiRequestDurationMetric, err = meterProvider.Meter("test").Float64Histogram("api.request.duration")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				apiRequestDurationMetric = previousApiRequestDurationMetric
+			}()
+
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			gock.New(ApiEndpointTest).
+				Put(defaultExpectedPathDashboard).
+				MatchParam("dataset", DatasetTest).
+				Reply(503).
+				JSON(map[string]string{})
+			expectDashboardPutRequest(defaultExpectedPathDashboard)
+			defer gock.Off()
+
+			dashboardResource := createDashboardResource()
+			persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: dashboardResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+
+			var resourceMetrics metricdata.ResourceMetrics
+			Expect(metricReader.Collect(ctx, &resourceMetrics)).To(Succeed())
+			Expect(resourceMetrics.ScopeMetrics).To(HaveLen(1))
+			Expect(resourceMetrics.ScopeMetrics[0].Metrics).To(HaveLen(1))
+			histogram, ok := resourceMetrics.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+			Expect(ok).To(BeTrue())
+			requestCounts := make(map[string]uint64)
+			for _, dataPoint := range histogram.DataPoints {
+				method, _ := dataPoint.Attributes.Value("http.request.method")
+				statusCodeClass, _ := dataPoint.Attributes.Value("http.response.status_code_class")
+				requestCounts[fmt.Sprintf("%s/%s", method.AsString(), statusCodeClass.AsString())] += dataPoint.Count
+			}
+			Expect(requestCounts).To(Equal(map[string]uint64{
+				"PUT/5xx": 1,
+				"PUT/2xx": 1,
+			}))
+		})
+	})
+
+	Describe("with an injected HTTP client", func() {
+		var roundTripper *stubRoundTripper
+
+		BeforeEach(func() {
+			roundTripper = &stubRoundTripper{}
+			persesDashboardCrdReconciler = &PersesDashboardCrdReconciler{
+				Client:     k8sClient,
+				AuthToken:  AuthorizationTokenTest,
+				HttpClient: &http.Client{Transport: roundTripper},
+				// We create the controller multiple times in tests, this option is required, otherwise the controller
+				// runtime will complain.
+				skipNameValidation: true,
+			}
+			ensurePersesDashboardCrdExists(ctx)
+			Expect(persesDashboardCrdReconciler.SetupWithManager(ctx, mgr, k8sClient, &logger)).To(Succeed())
+			persesDashboardCrdReconciler.SetApiEndpointAndDataset(&ApiConfig{
+				Endpoint: ApiEndpointTest,
+				Dataset:  DatasetTest,
+			}, &logger)
+			persesDashboardCrdReconciler.persesDashboardReconciler.overrideHttpRetryDelay(20 * time.Millisecond)
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+		})
+
+		AfterEach(func() {
+			DeleteMonitoringResourceIfItExists(ctx, k8sClient)
+			deletePersesDashboardCrdIfItExists(ctx)
+		})
+
+		It("sends requests via the injected client and retries server errors", func() {
+			roundTripper.statusCodes = []int{503, 200}
+
+			persesDashboardCrdReconciler.persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: createDashboardResource(),
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPersesSyncResult,
+			)
+			Expect(roundTripper.requests).To(HaveLen(2))
+			Expect(roundTripper.requests[0].Method).To(Equal(http.MethodPut))
+			Expect(roundTripper.requests[0].URL.Path).To(MatchRegexp(defaultExpectedPathDashboard))
+		})
+
+		It("does not retry client errors", func() {
+			roundTripper.statusCodes = []int{400}
+
+			persesDashboardCrdReconciler.persesDashboardReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: createDashboardResource(),
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				dash0v1alpha1.PersesDashboardSynchronizationResults{
+					SynchronizationStatus: dash0v1alpha1.Failed,
+					SynchronizationError:  "^unexpected status code 400 when updating/creating/deleting the dashboard",
+					ValidationIssues:      nil,
+				},
+			)
+			Expect(roundTripper.requests).To(HaveLen(1))
+		})
+	})
+})
+
+// readSynchronizationResultCounts collects the synchronization result metric from the given reader and returns the
+// counts by "kind/outcome".
+func readSynchronizationResultCounts(ctx context.Context, metricReader sdkmetric.Reader) map[string]int64 {
+	var resourceMetrics metricdata.ResourceMetrics
+	Expect(metricReader.Collect(ctx, &resourceMetrics)).To(Succeed())
+	counts := make(map[string]int64)
+	for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			Expect(ok).To(BeTrue())
+			for _, dataPoint := range sum.DataPoints {
+				kind, _ := dataPoint.Attributes.Value("kind")
+				outcome, _ := dataPoint.Attributes.Value("outcome")
+				counts[fmt.Sprintf("%s/%s", kind.AsString(), outcome.AsString())] += dataPoint.Value
+			}
+		}
+	}
+	return counts
+}
+
+func createPersesDashboardCrdReconcilerWithoutAuthToken() {
+	persesDashboardCrdReconciler = &PersesDashboardCrdReconciler{
+		Client: k8sClient,
+
+		// We create the controller multiple times in tests, this option is required, otherwise the controller
+		// runtime will complain.
+		skipNameValidation: true,
+	}
+}
+
+func createPersesDashboardCrdReconcilerWithAuthToken() {
+	persesDashboardCrdReconciler = &PersesDashboardCrdReconciler{
+		Client:    k8sClient,
+		AuthToken: AuthorizationTokenTest,
+
+		// We create the controller multiple times in tests, this option is required, otherwise the controller
+		// runtime will complain.
+		skipNameValidation: true,
+	}
+}
+
+// stubRoundTripper answers requests with the given status codes in order, repeating the last one, and records all
+// requests.
+type stubRoundTripper struct {
+	statusCodes []int
+	requests    []*http.Request
+}
+
+func (t *stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	statusCode := http.StatusOK
+	if len(t.statusCodes) > 0 {
+		statusCode = t.statusCodes[min(len(t.requests), len(t.statusCodes))-1]
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+// recordHeader returns a gock matcher that matches every request and records the value of the given header.
+func recordHeader(headerName string, recordedValues *[]string) gock.MatchFunc {
+	return func(req *http.Request, _ *gock.Request) (bool, error) {
+		*recordedValues = append(*recordedValues, req.Header.Get(headerName))
+		return true, nil
+	}
+}
+
+func expectDashboardPutRequest(expectedPath string) {
+	gock.New(ApiEndpointTest).
+		Put(expectedPath).
+		MatchParam("dataset", DatasetTest).
+		Times(1).
+		Reply(200).
+		JSON(map[string]string{})
+}
+
+func expectDashboardDeleteRequest(expectedPath string) {
+	gock.New(ApiEndpointTest).
+		Delete(expectedPath).
+		MatchParam("dataset", DatasetTest).
+		Times(1).
+		Reply(200).
+		JSON(map[string]string{})
+}
 
 func createDashboardResource() *persesv1alpha1.PersesDashboard {
 	return &persesv1alpha1.PersesDashboard{
@@ -449,6 +1564,16 @@ func createDashboardResource() *persesv1alpha1.PersesDashboard {
 	}
 }
 
+// createDashboardResourceInCluster creates the dashboard resource from createDashboardResource in the test namespace,
+// with the minimal spec required by the Perses dashboard CRD.
+func createDashboardResourceInCluster(ctx context.Context) *persesv1alpha1.PersesDashboard {
+	dashboardResource := createDashboardResource()
+	dashboardResource.Spec.Panels = map[string]*persesv1.Panel{}
+	dashboardResource.Spec.Layouts = []persesdashboard.Layout{}
+	Expect(k8sClient.Create(ctx, dashboardResource)).To(Succeed())
+	return dashboardResource
+}
+
 func ensurePersesDashboardCrdExists(ctx context.Context) {
 	persesDashboardCrd = EnsurePersesDashboardCrdExists(
 		ctx,
@@ -505,6 +1630,8 @@ func verifyPersesDashboardSynchronizationResultHasBeenWrittenToMonitoringResourc
 
 		// we do not verify the exact timestamp
 		expectedResult.SynchronizedAt = result.SynchronizedAt
+		// the content hash is verified separately by the tests that care about it
+		expectedResult.ContentHash = result.ContentHash
 
 		g.Expect(result).To(Equal(expectedResult))
 	}).Should(Succeed())
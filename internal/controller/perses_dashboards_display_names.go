@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	persesv1alpha1 "github.com/perses/perses-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+const (
+	duplicateDashboardDisplayNameReason = "DuplicateDashboardDisplayName"
+)
+
+// namespaceSynchronizationTarget records whether Perses dashboards in a namespace are synchronized, and to which
+// dataset.
+type namespaceSynchronizationTarget struct {
+	synchronized bool
+	dataset      string
+}
+
+// dashboardDisplayName returns the name with which the dashboard is displayed in Dash0, that is, the display name from
+// the dashboard spec, or the namespace and name of the Perses dashboard resource if the spec has no display name.
+func dashboardDisplayName(persesDashboard *persesv1alpha1.PersesDashboard) string {
+	if persesDashboard.Spec.Display != nil && persesDashboard.Spec.Display.Name != "" {
+		return persesDashboard.Spec.Display.Name
+	}
+	return fmt.Sprintf("%s/%s", persesDashboard.Namespace, persesDashboard.Name)
+}
+
+// warnAboutDuplicateDisplayName checks whether other synchronized Perses dashboard resources, in any namespace, have
+// the same display name as the given dashboard and are synchronized to the same dataset. Such dashboards do not
+// overwrite each other in Dash0, since the origin of a dashboard is derived from the namespace and name of the resource,
+// but they show up as seemingly identical dashboards in the Dash0 UI. If there are such dashboards, a warning is logged
+// and an event is emitted for the given dashboard.
+func (r *PersesDashboardReconciler) warnAboutDuplicateDisplayName(
+	ctx context.Context,
+	thirdPartyResource client.Object,
+	logger *logr.Logger,
+) {
+	persesDashboard, ok := thirdPartyResource.(*persesv1alpha1.PersesDashboard)
+	if !ok {
+		return
+	}
+	apiConfig := r.apiConfig.Load()
+	if !isValidApiConfig(apiConfig) || r.authToken == "" {
+		// dashboards are not synchronized at all
+		return
+	}
+	targetsByNamespace := make(map[string]namespaceSynchronizationTarget)
+	target := r.namespaceSynchronizationTarget(ctx, persesDashboard.Namespace, apiConfig, targetsByNamespace, logger)
+	if !target.synchronized {
+		return
+	}
+
+	allDashboards := &persesv1alpha1.PersesDashboardList{}
+	if err := r.List(ctx, allDashboards); err != nil {
+		logger.Error(err, "cannot list the Perses dashboard resources to check for duplicate display names")
+		return
+	}
+	displayName := dashboardDisplayName(persesDashboard)
+	var duplicates []string
+	for i := range allDashboards.Items {
+		otherDashboard := &allDashboards.Items[i]
+		if otherDashboard.Namespace == persesDashboard.Namespace && otherDashboard.Name == persesDashboard.Name {
+			continue
+		}
+		if !otherDashboard.DeletionTimestamp.IsZero() || dashboardDisplayName(otherDashboard) != displayName {
+			continue
+		}
+		otherTarget :=
+			r.namespaceSynchronizationTarget(ctx, otherDashboard.Namespace, apiConfig, targetsByNamespace, logger)
+		if !otherTarget.synchronized || otherTarget.dataset != target.dataset {
+			continue
+		}
+		duplicates = append(duplicates, fmt.Sprintf("%s/%s", otherDashboard.Namespace, otherDashboard.Name))
+	}
+	if len(duplicates) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"The display name \"%s\" is also used by the Perses dashboard resource(s) %s in the Dash0 dataset %s, the "+
+			"dashboards will appear as duplicates in Dash0. Set distinct display names via spec.display.name to tell "+
+			"them apart.",
+		displayName,
+		strings.Join(duplicates, ", "),
+		target.dataset,
+	)
+	logger.Info(message, "namespace", persesDashboard.Namespace, "name", persesDashboard.Name)
+	if r.recorder != nil {
+		r.recorder.Event(persesDashboard, corev1.EventTypeWarning, duplicateDashboardDisplayNameReason, message)
+	}
+}
+
+// namespaceSynchronizationTarget determines whether Perses dashboards in the given namespace are synchronized, and to
+// which dataset. Results are memoized in targetsByNamespace.
+func (r *PersesDashboardReconciler) namespaceSynchronizationTarget(
+	ctx context.Context,
+	namespace string,
+	apiConfig *ApiConfig,
+	targetsByNamespace map[string]namespaceSynchronizationTarget,
+	logger *logr.Logger,
+) namespaceSynchronizationTarget {
+	if target, ok := targetsByNamespace[namespace]; ok {
+		return target
+	}
+	target := namespaceSynchronizationTarget{}
+	monitoringResource, err := util.FindUniqueOrMostRecentResourceInScope(
+		ctx,
+		r.Client,
+		namespace,
+		&dash0v1alpha1.Dash0Monitoring{},
+		logger,
+	)
+	if err == nil && monitoringResource != nil {
+		typedMonitoringResource := monitoringResource.(*dash0v1alpha1.Dash0Monitoring)
+		if r.IsSynchronizationEnabled(typedMonitoringResource) {
+			target.synchronized = true
+			target.dataset = effectiveDataset(apiConfig, typedMonitoringResource)
+		}
+	}
+	targetsByNamespace[namespace] = target
+	return target
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+const (
+	// ImportedFromDash0DashboardIdAnnotation is set on Perses dashboard resources that have been created by
+	// ImportPersesDashboards, it contains the ID of the dashboard in Dash0 the resource has been created from.
+	ImportedFromDash0DashboardIdAnnotation = "dash0.com/imported-from-dashboard-id"
+
+	// operatorManagedDashboardOriginPrefix is the prefix of the origin of all dashboards that the operator has
+	// created from Perses dashboard resources, see PersesDashboardReconciler#renderDashboardUrl.
+	operatorManagedDashboardOriginPrefix = "dash0-operator_"
+
+	maxKubernetesResourceNameLength = 253
+)
+
+var (
+	persesDashboardGroupVersionKind = schema.GroupVersionKind{
+		Group:   "perses.dev",
+		Version: "v1alpha1",
+		Kind:    "PersesDashboard",
+	}
+
+	invalidResourceNameCharacters    = regexp.MustCompile(`[^a-z0-9.-]+`)
+	leadingOrTrailingNonAlphaNumeric = regexp.MustCompile(`^[^a-z0-9]+|[^a-z0-9]+$`)
+)
+
+// PersesDashboardImportConfig describes where to import dashboards from and where to put the resulting Perses
+// dashboard resources.
+type PersesDashboardImportConfig struct {
+	ApiEndpoint string
+	Dataset     string
+	AuthToken   string
+	Namespace   string
+}
+
+type PersesDashboardImportResult struct {
+	// Created lists the names of the Perses dashboard resources that have been created.
+	Created []string
+	// Skipped lists the names of the Perses dashboard resources that already existed and have not been modified.
+	Skipped []string
+}
+
+type dash0DashboardListItem struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Origin string `json:"origin"`
+}
+
+type dash0Dashboard struct {
+	Spec map[string]interface{} `json:"spec"`
+}
+
+// ImportPersesDashboards lists the dashboards in the given Dash0 dataset via the Dash0 API and creates a Perses
+// dashboard resource in the given namespace for each of them. This is the reverse direction of the synchronization
+// done by the PersesDashboardReconciler and is meant as a one-time bootstrap for moving dashboards that have been
+// created in the Dash0 UI under version control.
+//
+// Dashboards that have been created by the operator from Perses dashboard resources are not imported, neither are
+// dashboards for which a Perses dashboard resource with the same name already exists. Note that once the imported
+// Perses dashboard resources are synchronized to Dash0 (that is, when the namespace is monitored and has dashboard
+// synchronization enabled), the dashboards that have been imported exist twice in Dash0, the originals should be
+// deleted in Dash0 after the import.
+//
+// The operator itself never calls this function, and its service account is not allowed to create Perses dashboard
+// resources; the given client needs to be authorized to do that in the target namespace.
+func ImportPersesDashboards(
+	ctx context.Context,
+	k8sClient client.Client,
+	httpClient *http.Client,
+	config PersesDashboardImportConfig,
+	logger *logr.Logger,
+) (*PersesDashboardImportResult, error) {
+	dataset := config.Dataset
+	if dataset == "" {
+		dataset = util.DatasetDefault
+	}
+	apiEndpoint := config.ApiEndpoint
+	if !strings.HasSuffix(apiEndpoint, "/") {
+		apiEndpoint += "/"
+	}
+
+	var dashboards []dash0DashboardListItem
+	if err := fetchFromDash0Api(
+		ctx,
+		httpClient,
+		fmt.Sprintf("%sapi/dashboards?dataset=%s", apiEndpoint, url.QueryEscape(dataset)),
+		config.AuthToken,
+		&dashboards,
+	); err != nil {
+		return nil, fmt.Errorf("cannot list the dashboards in dataset %s: %w", dataset, err)
+	}
+
+	result := &PersesDashboardImportResult{}
+	for _, dashboardListItem := range dashboards {
+		if strings.HasPrefix(dashboardListItem.Origin, operatorManagedDashboardOriginPrefix) {
+			continue
+		}
+
+		var dashboard dash0Dashboard
+		if err := fetchFromDash0Api(
+			ctx,
+			httpClient,
+			fmt.Sprintf(
+				"%sapi/dashboards/%s?dataset=%s",
+				apiEndpoint,
+				urlEncodePathSegment(dashboardListItem.Id),
+				url.QueryEscape(dataset),
+			),
+			config.AuthToken,
+			&dashboard,
+		); err != nil {
+			return result, fmt.Errorf("cannot fetch the dashboard %s: %w", dashboardListItem.Id, err)
+		}
+
+		name := dashboardResourceName(dashboardListItem)
+		persesDashboard := &unstructured.Unstructured{}
+		persesDashboard.SetGroupVersionKind(persesDashboardGroupVersionKind)
+		persesDashboard.SetNamespace(config.Namespace)
+		persesDashboard.SetName(name)
+		persesDashboard.SetAnnotations(map[string]string{
+			ImportedFromDash0DashboardIdAnnotation: dashboardListItem.Id,
+		})
+		if dashboard.Spec != nil {
+			persesDashboard.Object["spec"] = dashboard.Spec
+		}
+
+		if err := k8sClient.Create(ctx, persesDashboard); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				logger.Info(
+					"A Perses dashboard resource with this name already exists, skipping the import of the dashboard.",
+					"namespace", config.Namespace,
+					"name", name,
+					"dashboard id", dashboardListItem.Id,
+				)
+				result.Skipped = append(result.Skipped, name)
+				continue
+			}
+			return result, fmt.Errorf("cannot create the Perses dashboard resource %s/%s: %w", config.Namespace, name, err)
+		}
+		logger.Info(
+			"Imported a dashboard from Dash0 as a Perses dashboard resource.",
+			"namespace", config.Namespace,
+			"name", name,
+			"dashboard id", dashboardListItem.Id,
+		)
+		result.Created = append(result.Created, name)
+	}
+	return result, nil
+}
+
+func fetchFromDash0Api(
+	ctx context.Context,
+	httpClient *http.Client,
+	requestUrl string,
+	authToken string,
+	target interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create a new HTTP request to %s: %w", requestUrl, err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	req.Header.Set("Accept", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("the request to %s has failed: %w", requestUrl, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read the response of %s: %w", requestUrl, err)
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d when requesting %s: %s", res.StatusCode, requestUrl, string(body))
+	}
+	if err = json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("cannot parse the response of %s: %w", requestUrl, err)
+	}
+	return nil
+}
+
+// dashboardResourceName derives a valid Kubernetes resource name from the name of the dashboard in Dash0, falling
+// back to the dashboard ID if the name does not contain any usable characters.
+func dashboardResourceName(dashboard dash0DashboardListItem) string {
+	for _, candidate := range []string{dashboard.Name, dashboard.Id} {
+		name := invalidResourceNameCharacters.ReplaceAllString(strings.ToLower(candidate), "-")
+		if len(name) > maxKubernetesResourceNameLength {
+			name = name[:maxKubernetesResourceNameLength]
+		}
+		name = leadingOrTrailingNonAlphaNumeric.ReplaceAllString(name, "")
+		if name != "" {
+			return name
+		}
+	}
+	return "imported-dashboard"
+}
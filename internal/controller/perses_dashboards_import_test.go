@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	persesv1alpha1 "github.com/perses/perses-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/h2non/gock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+var _ = Describe("Importing dashboards from Dash0", Ordered, func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	importConfig := PersesDashboardImportConfig{
+		ApiEndpoint: ApiEndpointTest,
+		Dataset:     DatasetTest,
+		AuthToken:   AuthorizationTokenTest,
+		Namespace:   TestNamespaceName,
+	}
+
+	BeforeAll(func() {
+		EnsureTestNamespaceExists(ctx, k8sClient)
+		ensurePersesDashboardCrdExists(ctx)
+	})
+
+	AfterEach(func() {
+		Expect(
+			k8sClient.DeleteAllOf(ctx, &persesv1alpha1.PersesDashboard{}, client.InNamespace(TestNamespaceName)),
+		).To(Succeed())
+	})
+
+	AfterAll(func() {
+		deletePersesDashboardCrdIfItExists(ctx)
+	})
+
+	It("creates Perses dashboard resources for dashboards that are not managed by the operator", func() {
+		gock.New(ApiEndpointTest).
+			Get("/api/dashboards").
+			MatchParam("dataset", DatasetTest).
+			MatchHeader("Authorization", "Bearer "+AuthorizationTokenTest).
+			Reply(200).
+			JSON([]map[string]string{
+				{"id": "dashboard-1", "name": "Service Overview"},
+				{"id": "dashboard-2", "name": "managed", "origin": "dash0-operator_cluster_dataset_namespace_name"},
+			})
+		gock.New(ApiEndpointTest).
+			Get("/api/dashboards/dashboard-1").
+			MatchParam("dataset", DatasetTest).
+			Reply(200).
+			JSON(map[string]interface{}{
+				"kind": "Dashboard",
+				"spec": map[string]interface{}{
+					"display":  map[string]interface{}{"name": "Service Overview"},
+					"duration": "30m",
+				},
+			})
+		defer gock.Off()
+
+		result, err := ImportPersesDashboards(ctx, k8sClient, &http.Client{}, importConfig, &logger)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gock.IsDone()).To(BeTrue())
+		Expect(result.Created).To(ConsistOf("service-overview"))
+		Expect(result.Skipped).To(BeEmpty())
+
+		persesDashboard := &persesv1alpha1.PersesDashboard{}
+		Expect(k8sClient.Get(
+			ctx,
+			types.NamespacedName{Namespace: TestNamespaceName, Name: "service-overview"},
+			persesDashboard,
+		)).To(Succeed())
+		Expect(persesDashboard.Annotations).To(HaveKeyWithValue(ImportedFromDash0DashboardIdAnnotation, "dashboard-1"))
+		Expect(persesDashboard.Spec.Display.Name).To(Equal("Service Overview"))
+	})
+
+	It("skips dashboards for which a Perses dashboard resource already exists", func() {
+		for i := 0; i < 2; i++ {
+			gock.New(ApiEndpointTest).
+				Get("/api/dashboards").
+				Reply(200).
+				JSON([]map[string]string{{"id": "dashboard-1", "name": "Service Overview"}})
+			gock.New(ApiEndpointTest).
+				Get("/api/dashboards/dashboard-1").
+				Reply(200).
+				JSON(map[string]interface{}{"spec": map[string]interface{}{"duration": "30m"}})
+		}
+		defer gock.Off()
+
+		_, err := ImportPersesDashboards(ctx, k8sClient, &http.Client{}, importConfig, &logger)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := ImportPersesDashboards(ctx, k8sClient, &http.Client{}, importConfig, &logger)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Created).To(BeEmpty())
+		Expect(result.Skipped).To(ConsistOf("service-overview"))
+	})
+
+	It("returns an error if the dashboards cannot be listed", func() {
+		gock.New(ApiEndpointTest).
+			Get("/api/dashboards").
+			Reply(401)
+		defer gock.Off()
+
+		_, err := ImportPersesDashboards(ctx, k8sClient, &http.Client{}, importConfig, &logger)
+		Expect(err).To(MatchError(ContainSubstring("unexpected status code 401")))
+	})
+
+	DescribeTable("derives valid resource names from dashboard names",
+		func(name string, id string, expected string) {
+			Expect(dashboardResourceName(dash0DashboardListItem{Id: id, Name: name})).To(Equal(expected))
+		},
+		Entry("lowercase", "Service Overview", "id", "service-overview"),
+		Entry("special characters", "  HTTP / gRPC (p99)! ", "id", "http-grpc-p99"),
+		Entry("fall back to the id", "!!!", "Dashboard_ID", "dashboard-id"),
+		Entry("nothing usable", "", "", "imported-dashboard"),
+	)
+})
@@ -35,6 +35,12 @@ import (
 type PrometheusRuleCrdReconciler struct {
 	Client                   client.Client
 	AuthToken                string
+	IdempotencyKeyHeaderName string
+	ApiProxyUrl              *url.URL
+	ApiUserAgent             string
+	// HttpClient is optional, if set, it is used for all requests to the Dash0 API instead of a client created from
+	// ApiProxyUrl and ApiUserAgent. This allows tests to stub the responses of the Dash0 API.
+	HttpClient               *http.Client
 	mgr                      ctrl.Manager
 	skipNameValidation       bool
 	prometheusRuleReconciler *PrometheusRuleReconciler
@@ -48,6 +54,8 @@ type PrometheusRuleReconciler struct {
 	apiConfig                  atomic.Pointer[ApiConfig]
 	authToken                  string
 	httpRetryDelay             time.Duration
+	idempotencyKeyHeaderName   string
+	synchronizationCache       synchronizationCache
 	controllerStopFunctionLock sync.Mutex
 	controllerStopFunction     *context.CancelFunc
 }
@@ -85,6 +93,18 @@ func (r *PrometheusRuleCrdReconciler) GetAuthToken() string {
 	return r.AuthToken
 }
 
+func (r *PrometheusRuleCrdReconciler) GetApiProxyUrl() *url.URL {
+	return r.ApiProxyUrl
+}
+
+func (r *PrometheusRuleCrdReconciler) GetApiUserAgent() string {
+	return r.ApiUserAgent
+}
+
+func (r *PrometheusRuleCrdReconciler) GetHttpClient() *http.Client {
+	return r.HttpClient
+}
+
 func (r *PrometheusRuleCrdReconciler) ClientObject() client.Object {
 	return &prometheusv1.PrometheusRule{}
 }
@@ -131,11 +151,12 @@ func (r *PrometheusRuleCrdReconciler) CreateResourceReconciler(
 	httpClient *http.Client,
 ) {
 	r.prometheusRuleReconciler = &PrometheusRuleReconciler{
-		Client:           r.Client,
-		pseudoClusterUid: pseudoClusterUid,
-		authToken:        authToken,
-		httpClient:       httpClient,
-		httpRetryDelay:   1 * time.Second,
+		Client:                   r.Client,
+		pseudoClusterUid:         pseudoClusterUid,
+		authToken:                authToken,
+		httpClient:               httpClient,
+		httpRetryDelay:           1 * time.Second,
+		idempotencyKeyHeaderName: r.IdempotencyKeyHeaderName,
 	}
 }
 
@@ -245,7 +266,15 @@ func (r *PrometheusRuleCrdReconciler) SetApiEndpointAndDataset(
 		// hence this nil check is necessary.
 		return
 	}
-	r.prometheusRuleReconciler.apiConfig.Store(apiConfig)
+	if previousApiConfig := r.prometheusRuleReconciler.apiConfig.Swap(apiConfig); previousApiConfig != nil &&
+		previousApiConfig.hasChanged(apiConfig) {
+		// The API endpoint, the dataset or the headers have changed, do not skip synchronizing unchanged resources.
+		// This method is called on every reconcile of the operator configuration resource, so the cache must not be
+		// cleared if nothing has changed. When the API config is set for the first time (e.g. after the operator
+		// manager has been started), the content hashes persisted in the monitoring resources are still used, to avoid
+		// synchronizing all resources again.
+		r.prometheusRuleReconciler.synchronizationCache.clear()
+	}
 	maybeStartWatchingThirdPartyResources(r, false, logger)
 }
 
@@ -256,6 +285,21 @@ func (r *PrometheusRuleCrdReconciler) RemoveApiEndpointAndDataset() {
 		return
 	}
 	r.prometheusRuleReconciler.apiConfig.Store(nil)
+	r.prometheusRuleReconciler.synchronizationCache.clear()
+}
+
+func (r *PrometheusRuleCrdReconciler) HandleDatasetChange(
+	ctx context.Context,
+	namespace string,
+	previousDataset string,
+	logger *logr.Logger,
+) {
+	if r.prometheusRuleReconciler == nil {
+		// If no auth token has been set via environment variable, we do not even create the prometheusRuleReconciler,
+		// hence this nil check is necessary.
+		return
+	}
+	resynchronizeAfterDatasetChange(ctx, r, namespace, previousDataset, logger)
 }
 
 func (r *PrometheusRuleReconciler) InitializeSelfMonitoringMetrics(
@@ -272,6 +316,8 @@ func (r *PrometheusRuleReconciler) InitializeSelfMonitoringMetrics(
 	); err != nil {
 		logger.Error(err, "Cannot initialize the metric %s.")
 	}
+
+	initializeSynchronizationMetrics(meter, metricNamePrefix, logger)
 }
 
 func (r *PrometheusRuleReconciler) KindDisplayName() string {
@@ -326,6 +372,14 @@ func (r *PrometheusRuleReconciler) overrideHttpRetryDelay(delay time.Duration) {
 	r.httpRetryDelay = delay
 }
 
+func (r *PrometheusRuleReconciler) IdempotencyKeyHeaderName() string {
+	return r.idempotencyKeyHeaderName
+}
+
+func (r *PrometheusRuleReconciler) SynchronizationCache() *synchronizationCache {
+	return &r.synchronizationCache
+}
+
 func (r *PrometheusRuleReconciler) IsSynchronizationEnabled(monitoringResource *dash0v1alpha1.Dash0Monitoring) bool {
 	if monitoringResource == nil {
 		return false
@@ -337,6 +391,18 @@ func (r *PrometheusRuleReconciler) IsSynchronizationEnabled(monitoringResource *
 	return *boolPtr
 }
 
+func (r *PrometheusRuleReconciler) ListResources(ctx context.Context, namespace string) ([]client.Object, error) {
+	rules := &prometheusv1.PrometheusRuleList{}
+	if err := r.List(ctx, rules, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	resources := make([]client.Object, 0, len(rules.Items))
+	for _, rule := range rules.Items {
+		resources = append(resources, rule)
+	}
+	return resources, nil
+}
+
 func (r *PrometheusRuleReconciler) Create(
 	ctx context.Context,
 	e event.TypedCreateEvent[client.Object],
@@ -528,7 +594,7 @@ func convertRuleToRequest(
 	//nolint:ineffassign
 	actionLabel := "?"
 	switch action {
-	case upsert:
+	case upsertAction:
 		actionLabel = "upsert"
 		serializedCheckRule, _ := json.Marshal(checkRule)
 		requestPayload := bytes.NewBuffer(serializedCheckRule)
@@ -537,7 +603,7 @@ func convertRuleToRequest(
 			checkRuleUrl,
 			requestPayload,
 		)
-	case delete:
+	case deleteAction:
 		actionLabel = "delete"
 		req, err = http.NewRequest(
 			http.MethodDelete,
@@ -561,10 +627,12 @@ func convertRuleToRequest(
 		return nil, nil, httpError, false
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", preconditionCheckResult.authToken))
-	if action == upsert {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	setApiRequestHeaders(
+		req,
+		preconditionCheckResult.apiHeaders,
+		preconditionCheckResult.authToken,
+		action == upsertAction,
+	)
 
 	return req, nil, nil, true
 }
@@ -591,7 +659,7 @@ func convertRuleToCheckRule(
 		return nil, []string{"rule has neither the alert nor the record attribute"}, false
 	}
 
-	if action == delete {
+	if action == deleteAction {
 		// When deleting a rule, we do not need an actual payload, but do need to skip rules with rule.Record or without
 		// rule.Alert (that is why we still call convertRuleToCheckRule for deletions).
 		return &CheckRule{}, nil, true
@@ -716,6 +784,7 @@ func (r *PrometheusRuleReconciler) UpdateSynchronizationResultsInStatus(
 	succesfullySynchronized []string,
 	synchronizationErrorsPerItem map[string]string,
 	validationIssuesPerItem map[string][]string,
+	contentHash string,
 ) interface{} {
 	previousResults := monitoringResource.Status.PrometheusRuleSynchronizationResults
 	if previousResults == nil {
@@ -732,7 +801,15 @@ func (r *PrometheusRuleReconciler) UpdateSynchronizationResultsInStatus(
 		SynchronizationErrors:      synchronizationErrorsPerItem,
 		InvalidRulesTotal:          len(validationIssuesPerItem),
 		InvalidRules:               validationIssuesPerItem,
+		ContentHash:                contentHash,
 	}
 	previousResults[qualifiedName] = result
 	return result
 }
+
+func (r *PrometheusRuleReconciler) PersistedContentHash(
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	qualifiedName string,
+) string {
+	return monitoringResource.Status.PrometheusRuleSynchronizationResults[qualifiedName].ContentHash
+}
@@ -4,8 +4,14 @@
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	prometheusv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -238,6 +244,8 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 			prometheusRuleReconciler = prometheusRuleCrdReconciler.prometheusRuleReconciler
 			// to make tests that involve http retries faster, we do not want to wait for one second for each retry
 			prometheusRuleReconciler.overrideHttpRetryDelay(20 * time.Millisecond)
+			// tests synchronize the same rule resource repeatedly, start each test without a cached content hash
+			prometheusRuleReconciler.SynchronizationCache().clear()
 		})
 
 		AfterEach(func() {
@@ -329,6 +337,165 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 			Expect(gock.IsDone()).To(BeTrue())
 		})
 
+		It("sends the same idempotency key header for each check rule when synchronizing repeatedly", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			idempotencyKeysPerPath := make([][]string, len(defaultExpectedPathsCheckRules))
+			for i, expectedPath := range defaultExpectedPathsCheckRules {
+				gock.New(ApiEndpointTest).
+					Put(expectedPath).
+					MatchParam("dataset", DatasetTest).
+					AddMatcher(recordHeader(DefaultIdempotencyKeyHeaderName, &idempotencyKeysPerPath[i])).
+					Times(2).
+					Reply(200).
+					JSON(map[string]string{})
+			}
+			defer gock.Off()
+
+			ruleResource := createDefaultRuleResource()
+			for i := 0; i < 2; i++ {
+				// make sure the second create event is not skipped as unchanged
+				prometheusRuleReconciler.SynchronizationCache().remove(synchronizationCacheKey(ruleResource))
+				prometheusRuleReconciler.Create(
+					ctx,
+					event.TypedCreateEvent[client.Object]{
+						Object: ruleResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			Expect(gock.IsDone()).To(BeTrue())
+			var firstKeys []string
+			for _, idempotencyKeys := range idempotencyKeysPerPath {
+				Expect(idempotencyKeys).To(HaveLen(2))
+				Expect(idempotencyKeys[0]).To(MatchRegexp("^[0-9a-f]{64}$"))
+				Expect(idempotencyKeys[1]).To(Equal(idempotencyKeys[0]))
+				firstKeys = append(firstKeys, idempotencyKeys[0])
+			}
+			// each check rule has its own origin and payload, hence its own idempotency key
+			Expect(slices.Compact(slices.Sorted(slices.Values(firstKeys)))).To(HaveLen(len(firstKeys)))
+		})
+
+		It("does not synchronize unchanged check rules again", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request per check rule, the second update event must not trigger more API calls
+			expectRulePutRequests(defaultExpectedPathsCheckRules)
+			defer gock.Off()
+
+			ruleResource := createDefaultRuleResource()
+			for i := 0; i < 2; i++ {
+				prometheusRuleReconciler.Update(
+					ctx,
+					event.TypedUpdateEvent[client.Object]{
+						ObjectNew: ruleResource,
+					},
+					&controllertest.TypedQueue[reconcile.Request]{},
+				)
+			}
+
+			verifyPrometheusRuleSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPrometheusSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("does not synchronize unchanged check rules again after a restart", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			// only expect one request per check rule, the update event after the simulated restart must not trigger
+			// more API calls
+			expectRulePutRequests(defaultExpectedPathsCheckRules)
+			defer gock.Off()
+
+			ruleResource := createDefaultRuleResource()
+			prometheusRuleReconciler.Create(
+				ctx,
+				event.TypedCreateEvent[client.Object]{
+					Object: ruleResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+			Eventually(func(g Gomega) {
+				monRes := LoadMonitoringResourceOrFail(ctx, k8sClient, g)
+				results := monRes.Status.PrometheusRuleSynchronizationResults
+				result := results[fmt.Sprintf("%s/%s", TestNamespaceName, "test-rule")]
+				g.Expect(result.ContentHash).To(MatchRegexp("^[0-9a-f]{64}$"))
+			}).Should(Succeed())
+
+			// simulate a restart of the operator manager, which starts with an empty in-memory cache
+			prometheusRuleReconciler.synchronizationCache = synchronizationCache{}
+			prometheusRuleReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: ruleResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(gock.GetUnmatchedRequests()).To(BeEmpty())
+		})
+
+		It("synchronizes check rules again when a rule group has changed", func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+
+			var changedRuleExpressions []string
+			for _, expectedPath := range defaultExpectedPathsCheckRules {
+				mock := gock.New(ApiEndpointTest).
+					Put(expectedPath).
+					MatchParam("dataset", DatasetTest).
+					Times(2)
+				if strings.HasSuffix(expectedPath, "group_1_0") {
+					mock.AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+						checkRule := &CheckRule{}
+						body, err := io.ReadAll(req.Body)
+						if err != nil {
+							return false, err
+						}
+						req.Body = io.NopCloser(bytes.NewReader(body))
+						if err = json.Unmarshal(body, checkRule); err != nil {
+							return false, err
+						}
+						changedRuleExpressions = append(changedRuleExpressions, checkRule.Expression)
+						return true, nil
+					})
+				}
+				mock.Reply(200).JSON(map[string]string{})
+			}
+			defer gock.Off()
+
+			ruleResource := createDefaultRuleResource()
+			prometheusRuleReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: ruleResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			changedRuleResource := createDefaultRuleResource()
+			changedRuleResource.Spec.Groups[0].Rules[0].Expr = intstr.FromString("vector(2)")
+			prometheusRuleReconciler.Update(
+				ctx,
+				event.TypedUpdateEvent[client.Object]{
+					ObjectNew: changedRuleResource,
+				},
+				&controllertest.TypedQueue[reconcile.Request]{},
+			)
+
+			verifyPrometheusRuleSynchronizationResultHasBeenWrittenToMonitoringResourceStatus(
+				ctx,
+				k8sClient,
+				defaultExpectedPrometheusSyncResult,
+			)
+			Expect(gock.IsDone()).To(BeTrue())
+			Expect(changedRuleExpressions).To(Equal([]string{"vector(1)", "vector(2)"}))
+		})
+
 		It("updates check rules", func() {
 			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
 
@@ -584,7 +751,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 					Record: "record",
 					Alert:  "alert",
 				},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -598,7 +765,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should treat an empty rule as invalid", func() {
 			rule, validationIssues, ok := convertRuleToCheckRule(
 				prometheusv1.Rule{},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -617,7 +784,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 					For:           ptr.To(prometheusv1.Duration("10s")),
 					KeepFiringFor: ptr.To(prometheusv1.NonEmptyDuration("10s")),
 				},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -634,7 +801,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 				prometheusv1.Rule{
 					Alert: "alert",
 				},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -666,7 +833,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 						"label2": "label value 2",
 					},
 				},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -692,7 +859,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 					Alert: "alert",
 					Expr:  intstr.FromInt32(123),
 				},
-				upsert,
+				upsertAction,
 				"group",
 				ptr.To(prometheusv1.Duration("10m")),
 				&logger,
@@ -718,7 +885,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 						Expr:        intstr.FromString("foobar $__threshold baz"),
 						Annotations: config.annotations,
 					},
-					upsert,
+					upsertAction,
 					"group",
 					ptr.To(prometheusv1.Duration("10m")),
 					&logger,
@@ -807,7 +974,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should ignore/skip a record rule", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{
 					Record: "record",
 					Alert:  "alert",
@@ -827,7 +994,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should treat an empty rule as invalid", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{},
 				&preconditionValidationResult{},
 				"group",
@@ -845,7 +1012,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should treat a rule without alert or record as invalid", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{
 					Expr:          intstr.FromString("expr"),
 					For:           ptr.To(prometheusv1.Duration("10s")),
@@ -867,7 +1034,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should treat a rule with empty expression as invalid", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{
 					Alert: "alert",
 					Expr:  intstr.FromString(""),
@@ -888,7 +1055,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should convert an almost empty rule", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{
 					Alert: "alert",
 				},
@@ -908,7 +1075,7 @@ var _ = Describe("The Prometheus rule controller", Ordered, func() {
 		It("should convert a rule with all attributes", func() {
 			req, validationIssues, syncError, ok := convertRuleToRequest(
 				"https://api.dash0.com/alerting/check-rules/rule-id",
-				upsert,
+				upsertAction,
 				prometheusv1.Rule{
 					Alert:         "alert",
 					Expr:          intstr.FromString("expr"),
@@ -1096,6 +1263,8 @@ func verifyPrometheusRuleSynchronizationResultHasBeenWrittenToMonitoringResource
 
 		// we do not verify the exact timestamp
 		expectedResult.SynchronizedAt = result.SynchronizedAt
+		// the content hash is verified separately by the tests that care about it
+		expectedResult.ContentHash = result.ContentHash
 
 		g.Expect(result).To(Equal(expectedResult))
 	}).Should(Succeed())
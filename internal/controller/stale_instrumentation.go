@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+// staleInstrumentationListPageSize is the page size for listing instrumented workloads in a namespace.
+const staleInstrumentationListPageSize = 500
+
+// StaleInstrumentationChecker periodically looks for workloads that still carry the Dash0 instrumentation in namespaces
+// in which workload instrumentation has been disabled (spec.instrumentWorkloads=none), for example because they wait
+// for their next rollout under the lazy uninstrumentation policy. The results are recorded in the status of the
+// respective Dash0 monitoring resource (status.staleInstrumentationWorkloads and the UninstrumentationComplete
+// condition), as a completion signal for removing Dash0 from a namespace.
+//
+// Jobs and pods are not taken into account, since they are immutable and cannot be uninstrumented. Replica sets that
+// are managed by a deployment are not taken into account either, they are uninstrumented via their owner.
+type StaleInstrumentationChecker struct {
+	Client    client.Client
+	Clientset *kubernetes.Clientset
+	Interval  time.Duration
+}
+
+// Start runs the check every Interval until the context is cancelled. It implements manager.Runnable.
+func (c *StaleInstrumentationChecker) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.checkStaleInstrumentation(ctx, &logger); err != nil {
+				logger.Error(err, "The check for workloads with stale Dash0 instrumentation has failed.")
+			}
+		}
+	}
+}
+
+func (c *StaleInstrumentationChecker) checkStaleInstrumentation(ctx context.Context, logger *logr.Logger) error {
+	monitoringResources := &dash0v1alpha1.Dash0MonitoringList{}
+	if err := c.Client.List(ctx, monitoringResources); err != nil {
+		return fmt.Errorf("cannot list Dash0 monitoring resources: %w", err)
+	}
+	for i := range monitoringResources.Items {
+		monitoringResource := &monitoringResources.Items[i]
+		if monitoringResource.IsMarkedForDeletion() {
+			continue
+		}
+		if err := c.updateStaleInstrumentation(ctx, monitoringResource); err != nil {
+			// Continue with the other namespaces, this namespace will be checked again with the next tick.
+			logger.Error(
+				err,
+				"Cannot update the stale instrumentation status of the Dash0 monitoring resource.",
+				"namespace",
+				monitoringResource.Namespace,
+			)
+		}
+	}
+	return nil
+}
+
+func (c *StaleInstrumentationChecker) updateStaleInstrumentation(
+	ctx context.Context,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+) error {
+	var changed bool
+	if monitoringResource.ReadInstrumentWorkloadsSetting() != dash0v1alpha1.None {
+		changed = monitoringResource.ClearStaleInstrumentation()
+	} else {
+		workloads, err := c.findInstrumentedWorkloads(ctx, monitoringResource.Namespace)
+		if err != nil {
+			return err
+		}
+		changed = monitoringResource.SetStaleInstrumentation(workloads)
+	}
+	if !changed {
+		return nil
+	}
+	return c.Client.Status().Update(ctx, monitoringResource)
+}
+
+// findInstrumentedWorkloads returns the workloads in the given namespace that carry the label
+// dash0.com/instrumented=true, in the form kind/name.
+func (c *StaleInstrumentationChecker) findInstrumentedWorkloads(ctx context.Context, namespace string) ([]string, error) {
+	var workloads []string
+	add := func(kind string, objectMeta metav1.ObjectMeta) {
+		workloads = append(workloads, fmt.Sprintf("%s/%s", kind, objectMeta.Name))
+	}
+	if err := listInstrumentedInPages(
+		ctx,
+		c.Clientset.BatchV1().CronJobs(namespace).List,
+		func(list *batchv1.CronJobList) {
+			for _, cronJob := range list.Items {
+				add("CronJob", cronJob.ObjectMeta)
+			}
+		},
+	); err != nil {
+		return nil, fmt.Errorf("error when querying instrumented cron jobs: %w", err)
+	}
+	if err := listInstrumentedInPages(
+		ctx,
+		c.Clientset.AppsV1().DaemonSets(namespace).List,
+		func(list *appsv1.DaemonSetList) {
+			for _, daemonSet := range list.Items {
+				add("DaemonSet", daemonSet.ObjectMeta)
+			}
+		},
+	); err != nil {
+		return nil, fmt.Errorf("error when querying instrumented daemon sets: %w", err)
+	}
+	if err := listInstrumentedInPages(
+		ctx,
+		c.Clientset.AppsV1().Deployments(namespace).List,
+		func(list *appsv1.DeploymentList) {
+			for _, deployment := range list.Items {
+				add("Deployment", deployment.ObjectMeta)
+			}
+		},
+	); err != nil {
+		return nil, fmt.Errorf("error when querying instrumented deployments: %w", err)
+	}
+	if err := listInstrumentedInPages(
+		ctx,
+		c.Clientset.AppsV1().ReplicaSets(namespace).List,
+		func(list *appsv1.ReplicaSetList) {
+			for _, replicaSet := range list.Items {
+				if util.FindHigherOrderOwner(&replicaSet.ObjectMeta) == nil {
+					add("ReplicaSet", replicaSet.ObjectMeta)
+				}
+			}
+		},
+	); err != nil {
+		return nil, fmt.Errorf("error when querying instrumented replica sets: %w", err)
+	}
+	if err := listInstrumentedInPages(
+		ctx,
+		c.Clientset.AppsV1().StatefulSets(namespace).List,
+		func(list *appsv1.StatefulSetList) {
+			for _, statefulSet := range list.Items {
+				add("StatefulSet", statefulSet.ObjectMeta)
+			}
+		},
+	); err != nil {
+		return nil, fmt.Errorf("error when querying instrumented stateful sets: %w", err)
+	}
+	return workloads, nil
+}
+
+func listInstrumentedInPages[L metav1.ListInterface](
+	ctx context.Context,
+	list func(context.Context, metav1.ListOptions) (L, error),
+	process func(L),
+) error {
+	listOptions := util.WorkloadsWithSuccessfulDash0InstrumentationFilter
+	listOptions.Limit = staleInstrumentationListPageSize
+	for {
+		page, err := list(ctx, listOptions)
+		if err != nil {
+			return err
+		}
+		process(page)
+		if page.GetContinue() == "" {
+			return nil
+		}
+		listOptions.Continue = page.GetContinue()
+	}
+}
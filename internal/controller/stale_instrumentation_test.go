@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+const staleInstrumentationTestNamespace = "stale-instrumentation-test"
+
+var _ = Describe("The stale instrumentation checker", Ordered, func() {
+	ctx := context.Background()
+	var createdObjects []client.Object
+	var checker *StaleInstrumentationChecker
+	monitoringResourceName := types.NamespacedName{
+		Namespace: staleInstrumentationTestNamespace,
+		Name:      MonitoringResourceName,
+	}
+
+	BeforeAll(func() {
+		EnsureNamespaceExists(ctx, k8sClient, staleInstrumentationTestNamespace)
+	})
+
+	BeforeEach(func() {
+		createdObjects = make([]client.Object, 0)
+		checker = &StaleInstrumentationChecker{
+			Client:    k8sClient,
+			Clientset: clientset,
+		}
+	})
+
+	AfterEach(func() {
+		createdObjects = DeleteAllCreatedObjects(ctx, k8sClient, createdObjects)
+		DeleteMonitoringResourceByName(ctx, k8sClient, monitoringResourceName, true)
+	})
+
+	createMonitoringResource := func(instrumentWorkloads dash0v1alpha1.InstrumentWorkloadsMode) {
+		spec := MonitoringResourceDefaultSpec
+		spec.InstrumentWorkloads = instrumentWorkloads
+		Expect(k8sClient.Create(ctx, &dash0v1alpha1.Dash0Monitoring{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: monitoringResourceName.Namespace,
+				Name:      monitoringResourceName.Name,
+			},
+			Spec: spec,
+		})).To(Succeed())
+	}
+
+	runCheck := func() *dash0v1alpha1.Dash0Monitoring {
+		monitoringResource := &dash0v1alpha1.Dash0Monitoring{}
+		Expect(k8sClient.Get(ctx, monitoringResourceName, monitoringResource)).To(Succeed())
+		Expect(checker.updateStaleInstrumentation(ctx, monitoringResource)).To(Succeed())
+		Expect(k8sClient.Get(ctx, monitoringResourceName, monitoringResource)).To(Succeed())
+		return monitoringResource
+	}
+
+	It("should list instrumented workloads if workload instrumentation is disabled", func() {
+		createMonitoringResource(dash0v1alpha1.None)
+		instrumentedName := UniqueName(DeploymentNamePrefix)
+		createdObjects = append(
+			createdObjects,
+			CreateInstrumentedDeployment(ctx, k8sClient, staleInstrumentationTestNamespace, instrumentedName),
+			CreateBasicDeployment(ctx, k8sClient, staleInstrumentationTestNamespace, UniqueName(DeploymentNamePrefix)),
+		)
+
+		monitoringResource := runCheck()
+
+		Expect(monitoringResource.Status.StaleInstrumentationWorkloads).To(
+			Equal([]string{"Deployment/" + instrumentedName}))
+		Expect(monitoringResource.Status.StaleInstrumentationWorkloadCount).To(Equal(1))
+		condition := meta.FindStatusCondition(
+			monitoringResource.Status.Conditions,
+			string(dash0v1alpha1.ConditionTypeUninstrumentationComplete),
+		)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("StaleInstrumentation"))
+	})
+
+	It("should report completion when no instrumented workloads are left", func() {
+		createMonitoringResource(dash0v1alpha1.None)
+		createdObjects = append(
+			createdObjects,
+			CreateBasicDeployment(ctx, k8sClient, staleInstrumentationTestNamespace, UniqueName(DeploymentNamePrefix)),
+		)
+
+		monitoringResource := runCheck()
+
+		Expect(monitoringResource.Status.StaleInstrumentationWorkloads).To(BeEmpty())
+		Expect(monitoringResource.Status.StaleInstrumentationWorkloadCount).To(Equal(0))
+		condition := meta.FindStatusCondition(
+			monitoringResource.Status.Conditions,
+			string(dash0v1alpha1.ConditionTypeUninstrumentationComplete),
+		)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should not list instrumented workloads if workload instrumentation is enabled", func() {
+		createMonitoringResource(dash0v1alpha1.All)
+		createdObjects = append(
+			createdObjects,
+			CreateInstrumentedDeployment(
+				ctx,
+				k8sClient,
+				staleInstrumentationTestNamespace,
+				UniqueName(DeploymentNamePrefix),
+			),
+		)
+
+		monitoringResource := runCheck()
+
+		Expect(monitoringResource.Status.StaleInstrumentationWorkloads).To(BeEmpty())
+		Expect(meta.FindStatusCondition(
+			monitoringResource.Status.Conditions,
+			string(dash0v1alpha1.ConditionTypeUninstrumentationComplete),
+		)).To(BeNil())
+	})
+})
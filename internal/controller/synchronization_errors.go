@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AuthError is returned when the Dash0 API rejects a synchronization request because of missing or invalid
+// authorization (HTTP status 401 or 403). Retrying the request does not help, the auth token needs to be fixed.
+type AuthError struct {
+	StatusCode int
+	err        error
+}
+
+// ValidationError is returned when the Dash0 API rejects a synchronization request with an HTTP 4xx status other than
+// 401, 403 and 429, usually because the payload is not valid. Retrying the same request does not help.
+type ValidationError struct {
+	StatusCode int
+	err        error
+}
+
+// TransientError is returned when a synchronization request has failed in a way that might resolve itself, that is,
+// network errors, timeouts, HTTP 429 and HTTP 5xx responses. StatusCode is zero if no response has been received.
+// Transient errors are retried.
+type TransientError struct {
+	StatusCode int
+	err        error
+}
+
+func (e *AuthError) Error() string {
+	return e.err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.err
+}
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+func (e *TransientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.err
+}
+
+// classifyStatusCodeError wraps the error for a non-2xx response into the error type matching the status code.
+func classifyStatusCodeError(statusCode int, err error) error {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return &AuthError{StatusCode: statusCode, err: err}
+	case statusCode == http.StatusTooManyRequests:
+		return &TransientError{StatusCode: statusCode, err: err}
+	case statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError:
+		return &ValidationError{StatusCode: statusCode, err: err}
+	default:
+		return &TransientError{StatusCode: statusCode, err: err}
+	}
+}
+
+func isTransientError(err error) bool {
+	var transientErr *TransientError
+	return errors.As(err, &transientErr)
+}
+
+// synchronizationOutcomeForError maps an error returned from the HTTP execution path to the outcome attribute of the
+// synchronization result metric.
+func synchronizationOutcomeForError(err error) string {
+	var authErr *AuthError
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &authErr):
+		return synchronizationOutcomeAuthError
+	case errors.As(err, &validationErr):
+		return synchronizationOutcomeValidationError
+	default:
+		return synchronizationOutcomeHttpError
+	}
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The synchronization error types", func() {
+
+	DescribeTable("should classify non-2xx status codes",
+		func(statusCode int, expectTransient bool, expectedOutcome string) {
+			err := classifyStatusCodeError(statusCode, fmt.Errorf("unexpected status code %d", statusCode))
+			Expect(isTransientError(err)).To(Equal(expectTransient))
+			Expect(synchronizationOutcomeForError(err)).To(Equal(expectedOutcome))
+			Expect(err.Error()).To(Equal(fmt.Sprintf("unexpected status code %d", statusCode)))
+		},
+		Entry("400", http.StatusBadRequest, false, synchronizationOutcomeValidationError),
+		Entry("401", http.StatusUnauthorized, false, synchronizationOutcomeAuthError),
+		Entry("403", http.StatusForbidden, false, synchronizationOutcomeAuthError),
+		Entry("404", http.StatusNotFound, false, synchronizationOutcomeValidationError),
+		Entry("429", http.StatusTooManyRequests, true, synchronizationOutcomeHttpError),
+		Entry("500", http.StatusInternalServerError, true, synchronizationOutcomeHttpError),
+		Entry("503", http.StatusServiceUnavailable, true, synchronizationOutcomeHttpError),
+	)
+
+	It("should allow matching the typed errors with errors.As", func() {
+		var authErr *AuthError
+		err := fmt.Errorf("wrapped: %w", classifyStatusCodeError(http.StatusUnauthorized, errors.New("unauthorized")))
+		Expect(errors.As(err, &authErr)).To(BeTrue())
+		Expect(authErr.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should treat network errors as transient", func() {
+		err := &TransientError{err: errors.New("connection refused")}
+		Expect(isTransientError(err)).To(BeTrue())
+		Expect(synchronizationOutcomeForError(err)).To(Equal(synchronizationOutcomeHttpError))
+	})
+})
@@ -5,18 +5,22 @@ package controller
 
 import (
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -41,6 +45,16 @@ import (
 type ApiConfig struct {
 	Endpoint string
 	Dataset  string
+	Headers  []dash0v1alpha1.Header
+}
+
+// hasChanged returns true if the other API config differs from this one in any of the settings that affect the
+// synchronization of third-party resources.
+func (c *ApiConfig) hasChanged(other *ApiConfig) bool {
+	if other == nil {
+		return true
+	}
+	return c.Endpoint != other.Endpoint || c.Dataset != other.Dataset || !slices.Equal(c.Headers, other.Headers)
 }
 
 type ApiClient interface {
@@ -48,12 +62,21 @@ type ApiClient interface {
 	RemoveApiEndpointAndDataset()
 }
 
+// DatasetChangeHandler is notified by the monitoring controller when the spec.dataset setting of a Dash0 monitoring
+// resource has changed, so that resources which have been synchronized to the previous dataset can be moved.
+type DatasetChangeHandler interface {
+	HandleDatasetChange(ctx context.Context, namespace string, previousDataset string, logger *logr.Logger)
+}
+
 type ThirdPartyCrdReconciler interface {
 	handler.TypedEventHandler[client.Object, reconcile.Request]
 	reconcile.TypedReconciler[reconcile.Request]
 
 	Manager() ctrl.Manager
 	GetAuthToken() string
+	GetApiProxyUrl() *url.URL
+	GetApiUserAgent() string
+	GetHttpClient() *http.Client
 	ClientObject() client.Object
 	KindDisplayName() string
 	Group() string
@@ -84,7 +107,10 @@ type ThirdPartyResourceReconciler interface {
 	K8sClient() client.Client
 	HttpClient() *http.Client
 	GetHttpRetryDelay() time.Duration
+	IdempotencyKeyHeaderName() string
+	SynchronizationCache() *synchronizationCache
 	IsSynchronizationEnabled(*dash0v1alpha1.Dash0Monitoring) bool
+	ListResources(context.Context, string) ([]client.Object, error)
 
 	// MapResourceToHttpRequests converts a third-party resource object to a list of HTTP requests that can be sent to
 	// the Dash0 API. It returns:
@@ -110,7 +136,13 @@ type ThirdPartyResourceReconciler interface {
 		succesfullySynchronized []string,
 		synchronizationErrorsPerItem map[string]string,
 		validationIssuesPerItem map[string][]string,
+		contentHash string,
 	) interface{}
+
+	// PersistedContentHash returns the content hash of the last successful synchronization of the third-party resource
+	// with the given qualified name (namespace/name), as recorded in the status of the monitoring resource, or an empty
+	// string if there is none.
+	PersistedContentHash(monitoringResource *dash0v1alpha1.Dash0Monitoring, qualifiedName string) string
 }
 
 type HttpRequestWithItemName struct {
@@ -121,28 +153,65 @@ type HttpRequestWithItemName struct {
 type apiAction int
 
 const (
-	upsert apiAction = iota
-	delete
+	upsertAction apiAction = iota
+	deleteAction
+)
+
+const (
+	DefaultIdempotencyKeyHeaderName = "Idempotency-Key"
+)
+
+const (
+	synchronizationOutcomeSuccess         = "success"
+	synchronizationOutcomeValidationError = "validation-error"
+	synchronizationOutcomeAuthError       = "auth-error"
+	synchronizationOutcomeHttpError       = "http-error"
+)
+
+var (
+	// The synchronization metrics are shared by all third-party resource reconcilers.
+
+	// synchronizationResultMetric counts synchronized items (dashboards, check rules) by kind and outcome.
+	synchronizationResultMetric otelmetric.Int64Counter
+	// apiRequestDurationMetric records the duration of individual HTTP requests to the Dash0 API by HTTP method and
+	// status code class.
+	apiRequestDurationMetric             otelmetric.Float64Histogram
+	initializeSynchronizationMetricsOnce sync.Once
 )
 
+// synchronizationCache keeps track of the content hash of the last successful synchronization per third-party
+// resource, so that repeated reconcile requests for an unchanged resource do not trigger redundant API calls. The
+// content hash is also recorded in the synchronization results in the status of the monitoring resource. When the
+// in-memory cache has no entry for a resource (e.g. after the operator manager has been restarted), the hash from the
+// status is used instead, so that a restart does not trigger a re-synchronization of all resources. Removing the entry
+// for a resource also stops the persisted hash for that resource from being used, until the next successful
+// synchronization. The persisted hashes do not need to be invalidated when the API config changes, since the API
+// config is part of the hash (see computeSynchronizationContentHash). The zero value is ready to use.
+type synchronizationCache struct {
+	lock   sync.Mutex
+	hashes map[string]string
+	// removed holds the keys of entries that have been removed explicitly.
+	removed map[string]bool
+}
+
 type preconditionValidationResult struct {
 	synchronizeResource bool
 	thirdPartyResource  client.Object
 	monitoringResource  *dash0v1alpha1.Dash0Monitoring
 	authToken           string
 	apiEndpoint         string
+	apiHeaders          []dash0v1alpha1.Header
 	dataset             string
 	k8sNamespace        string
 	k8sName             string
 }
 
-type retryableError struct {
-	err       error
-	retryable bool
-}
-
-func (e *retryableError) Error() string {
-	return e.err.Error()
+var kubeSystemNamespaceBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+	Steps:    6,
+	Cap:      8 * time.Second,
 }
 
 func SetupThirdPartyCrdReconcilerWithManager(
@@ -159,16 +228,30 @@ func SetupThirdPartyCrdReconcilerWithManager(
 	}
 
 	kubeSystemNamespace := &corev1.Namespace{}
-	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "kube-system"}, kubeSystemNamespace); err != nil {
+	// The API server might not be fully available yet when the operator manager starts (e.g. on a cluster cold start),
+	// so a failure to read the kube-system namespace is retried a couple of times before giving up.
+	if err := util.RetryWithCustomBackoff(
+		"reading the kube-system namespace",
+		func() error {
+			return k8sClient.Get(ctx, client.ObjectKey{Name: "kube-system"}, kubeSystemNamespace)
+		},
+		kubeSystemNamespaceBackoff,
+		true,
+		logger,
+	); err != nil {
 		msg := "unable to get the kube-system namespace uid"
 		logger.Error(err, msg)
 		return fmt.Errorf("%s: %w", msg, err)
 	}
 
+	httpClient := crdReconciler.GetHttpClient()
+	if httpClient == nil {
+		httpClient = util.NewApiHttpClient(crdReconciler.GetApiProxyUrl(), crdReconciler.GetApiUserAgent(), 0)
+	}
 	crdReconciler.CreateResourceReconciler(
 		kubeSystemNamespace.UID,
 		authToken,
-		&http.Client{},
+		httpClient,
 	)
 
 	if err := k8sClient.Get(ctx, client.ObjectKey{
@@ -183,7 +266,21 @@ func SetupThirdPartyCrdReconcilerWithManager(
 		}
 	} else {
 		crdReconciler.SetCrdExists(true)
-		maybeStartWatchingThirdPartyResources(crdReconciler, true, logger)
+	}
+
+	// The watch for third-party resources is not started right away, but only once this operator manager replica has
+	// been elected as leader. Otherwise, when running multiple replicas, each replica would synchronize the same
+	// resources with the Dash0 API.
+	if err := crdReconciler.Manager().Add(&thirdPartyResourceWatchLeaderElectionRunnable{
+		crdReconciler: crdReconciler,
+		logger:        logger,
+	}); err != nil {
+		logger.Error(err,
+			fmt.Sprintf(
+				"unable to add the leader election runnable for the %s CRD reconciler",
+				crdReconciler.KindDisplayName(),
+			))
+		return err
 	}
 
 	controllerBuilder := ctrl.NewControllerManagedBy(crdReconciler.Manager()).
@@ -216,6 +313,32 @@ func SetupThirdPartyCrdReconcilerWithManager(
 	return nil
 }
 
+// thirdPartyResourceWatchLeaderElectionRunnable starts watching third-party resources when this operator manager replica
+// acquires leadership and stops watching them when leadership is lost (or the manager is shut down). All other code
+// paths that start or stop the watch (CRD create/delete events, operator configuration changes) are invoked from
+// controllers managed by the controller manager, which also only run on the leader.
+type thirdPartyResourceWatchLeaderElectionRunnable struct {
+	crdReconciler ThirdPartyCrdReconciler
+	logger        *logr.Logger
+}
+
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface.
+func (r *thirdPartyResourceWatchLeaderElectionRunnable) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements the manager.Runnable interface. It is called by the manager once this replica has been elected as
+// leader and blocks until the given context is cancelled.
+func (r *thirdPartyResourceWatchLeaderElectionRunnable) Start(ctx context.Context) error {
+	maybeStartWatchingThirdPartyResources(r.crdReconciler, true, r.logger)
+	<-ctx.Done()
+	if r.crdReconciler.ResourceReconciler().IsWatching() {
+		// The context has already been cancelled at this point, hence we cannot use it for stopping the watch.
+		stopWatchingThirdPartyResources(context.Background(), r.crdReconciler, r.logger)
+	}
+	return nil
+}
+
 func makeFilterPredicate(group string, kind string) predicate.Funcs {
 	return predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
@@ -374,6 +497,8 @@ func stopWatchingThirdPartyResources(
 	logger.Info(fmt.Sprintf("stopping the controller %s now", resourceReconciler.ControllerName()))
 	(*cancelFunc)()
 	resourceReconciler.SetControllerStopFunction(nil)
+	// Resources will be synchronized from scratch when the watch is started again.
+	resourceReconciler.SynchronizationCache().clear()
 }
 
 func isValidApiConfig(apiConfig *ApiConfig) bool {
@@ -399,7 +524,7 @@ func upsertViaApi(
 		ctx,
 		resourceReconciler,
 		thirdPartyResource,
-		upsert,
+		upsertAction,
 		"Creating/updating",
 		logger,
 	)
@@ -415,12 +540,96 @@ func deleteViaApi(
 		ctx,
 		resourceReconciler,
 		thirdPartyResource,
-		delete,
+		deleteAction,
 		"Deleting",
 		logger,
 	)
 }
 
+// resynchronizeAfterDatasetChange moves all third-party resources of one type in the given namespace from the previous
+// dataset to the dataset that is currently configured for the namespace: each resource is first deleted from the
+// previous dataset and then created in the current dataset. Failing to delete a resource from the previous dataset is
+// logged but does not prevent creating it in the current dataset.
+func resynchronizeAfterDatasetChange(
+	ctx context.Context,
+	crdReconciler ThirdPartyCrdReconciler,
+	namespace string,
+	previousDataset string,
+	logger *logr.Logger,
+) {
+	if !crdReconciler.DoesCrdExist().Load() {
+		return
+	}
+	resourceReconciler := crdReconciler.ResourceReconciler()
+	if !resourceReconciler.IsWatching() {
+		return
+	}
+	thirdPartyResources, err := resourceReconciler.ListResources(ctx, namespace)
+	if err != nil {
+		logger.Error(err,
+			fmt.Sprintf(
+				"Cannot list the %s resources in namespace %s, they will not be moved to the new dataset.",
+				crdReconciler.KindDisplayName(),
+				namespace,
+			))
+		return
+	}
+	for _, thirdPartyResource := range thirdPartyResources {
+		deleteFromPreviousDatasetViaApi(ctx, resourceReconciler, thirdPartyResource, previousDataset, logger)
+		upsertViaApi(ctx, resourceReconciler, thirdPartyResource, logger)
+	}
+}
+
+func deleteFromPreviousDatasetViaApi(
+	ctx context.Context,
+	resourceReconciler ThirdPartyResourceReconciler,
+	thirdPartyResource client.Object,
+	previousDataset string,
+	logger *logr.Logger,
+) {
+	preconditionChecksResult := validatePreconditions(
+		ctx,
+		resourceReconciler,
+		thirdPartyResource,
+		logger,
+	)
+	// Whatever has been synchronized before has been synchronized to the previous dataset.
+	resourceReconciler.SynchronizationCache().remove(synchronizationCacheKey(thirdPartyResource))
+	if !preconditionChecksResult.synchronizeResource {
+		return
+	}
+	if previousDataset == "" {
+		// The namespace did not have its own dataset before, so the resource has been synchronized to the operator-wide
+		// dataset.
+		previousDataset = resourceReconciler.GetApiConfig().Load().Dataset
+	}
+	if previousDataset == "" {
+		previousDataset = util.DatasetDefault
+	}
+	if previousDataset == preconditionChecksResult.dataset {
+		return
+	}
+
+	previousDatasetPreconditionChecksResult := *preconditionChecksResult
+	previousDatasetPreconditionChecksResult.dataset = previousDataset
+	_, httpRequests, _, _ :=
+		resourceReconciler.MapResourceToHttpRequests(&previousDatasetPreconditionChecksResult, deleteAction, logger)
+	if len(httpRequests) == 0 {
+		return
+	}
+	successfullyDeleted, httpErrors := executeAllHttpRequests(resourceReconciler, httpRequests, "Deleting", logger)
+	logger.Info(
+		fmt.Sprintf("Deleting %s %s/%s from the previous dataset %s: %d %s(s) successfully deleted, errors: %v",
+			resourceReconciler.KindDisplayName(),
+			thirdPartyResource.GetNamespace(),
+			thirdPartyResource.GetName(),
+			previousDataset,
+			len(successfullyDeleted),
+			resourceReconciler.ShortName(),
+			httpErrors,
+		))
+}
+
 func synchronizeViaApi(
 	ctx context.Context,
 	resourceReconciler ThirdPartyResourceReconciler,
@@ -435,12 +644,20 @@ func synchronizeViaApi(
 		thirdPartyResource,
 		logger,
 	)
+	cacheKey := synchronizationCacheKey(thirdPartyResource)
 	if !preconditionChecksResult.synchronizeResource {
+		// Forget the last successful synchronization, so that the resource is synchronized again (and its
+		// synchronization result is written to the status of the monitoring resource) once the preconditions are met
+		// again, e.g. after synchronization has been re-enabled or the monitoring resource has been recreated.
+		resourceReconciler.SynchronizationCache().remove(cacheKey)
 		return
 	}
 
 	itemsTotal, httpRequests, validationIssues, synchronizationErrors :=
 		resourceReconciler.MapResourceToHttpRequests(preconditionChecksResult, action, logger)
+	recordSynchronizationResults(resourceReconciler, synchronizationOutcomeValidationError, len(validationIssues))
+	// Errors at this stage occur when creating the HTTP request for an item, hence they are counted as HTTP errors.
+	recordSynchronizationResults(resourceReconciler, synchronizationOutcomeHttpError, len(synchronizationErrors))
 
 	if len(httpRequests) == 0 && len(validationIssues) == 0 && len(synchronizationErrors) == 0 {
 		logger.Info(
@@ -453,6 +670,31 @@ func synchronizeViaApi(
 			))
 	}
 
+	var contentHash string
+	if action == upsertAction {
+		addIdempotencyKeyHeaders(resourceReconciler, httpRequests, logger)
+		if len(validationIssues) == 0 && len(synchronizationErrors) == 0 {
+			contentHash = computeSynchronizationContentHash(preconditionChecksResult, httpRequests, logger)
+			if contentHash != "" && isUnchangedSinceLastSynchronization(
+				resourceReconciler,
+				preconditionChecksResult.monitoringResource,
+				cacheKey,
+				contentHash,
+			) {
+				logger.V(1).Info(
+					fmt.Sprintf(
+						"%s %s/%s has not changed since the last successful synchronization, skipping.",
+						resourceReconciler.KindDisplayName(),
+						thirdPartyResource.GetNamespace(),
+						thirdPartyResource.GetName(),
+					))
+				return
+			}
+		}
+	} else {
+		resourceReconciler.SynchronizationCache().remove(cacheKey)
+	}
+
 	var successfullySynchronized []string
 	var httpErrors map[string]string
 	if len(httpRequests) > 0 {
@@ -468,6 +710,16 @@ func synchronizeViaApi(
 		// are never converted to requests, so the two maps are disjoint.
 		maps.Copy(synchronizationErrors, httpErrors)
 	}
+	if contentHash != "" {
+		if len(synchronizationErrors) == 0 && len(successfullySynchronized) == len(httpRequests) {
+			resourceReconciler.SynchronizationCache().put(cacheKey, contentHash)
+		} else {
+			resourceReconciler.SynchronizationCache().remove(cacheKey)
+			// Do not persist the hash of a failed or partially failed synchronization, so the resource is synchronized
+			// again on the next reconcile request.
+			contentHash = ""
+		}
+	}
 	logger.Info(
 		fmt.Sprintf("%s %s %s/%s: %d %s(s), %d successfully synchronized, validation issues: %v, synchronization errors: %v",
 			actionLabel,
@@ -489,10 +741,34 @@ func synchronizeViaApi(
 		successfullySynchronized,
 		validationIssues,
 		synchronizationErrors,
+		contentHash,
 		logger,
 	)
 }
 
+// isUnchangedSinceLastSynchronization checks whether the given content hash matches the hash of the last successful
+// synchronization of the resource. The in-memory cache is consulted first; if it has no entry for the resource, the
+// hash persisted in the status of the monitoring resource is used, and is copied to the in-memory cache on a match.
+func isUnchangedSinceLastSynchronization(
+	resourceReconciler ThirdPartyResourceReconciler,
+	monitoringResource *dash0v1alpha1.Dash0Monitoring,
+	cacheKey string,
+	contentHash string,
+) bool {
+	cache := resourceReconciler.SynchronizationCache()
+	if cachedHash := cache.get(cacheKey); cachedHash != "" {
+		return cachedHash == contentHash
+	}
+	if monitoringResource == nil || !cache.mayUsePersistedHash(cacheKey) {
+		return false
+	}
+	if resourceReconciler.PersistedContentHash(monitoringResource, cacheKey) != contentHash {
+		return false
+	}
+	cache.put(cacheKey, contentHash)
+	return true
+}
+
 func validatePreconditions(
 	ctx context.Context,
 	resourceReconciler ThirdPartyResourceReconciler,
@@ -578,10 +854,7 @@ func validatePreconditions(
 		}
 	}
 
-	dataset := apiConfig.Dataset
-	if dataset == "" {
-		dataset = util.DatasetDefault
-	}
+	dataset := effectiveDataset(apiConfig, monitoringResource)
 
 	return &preconditionValidationResult{
 		synchronizeResource: true,
@@ -589,12 +862,190 @@ func validatePreconditions(
 		monitoringResource:  monitoringResource,
 		authToken:           authToken,
 		apiEndpoint:         apiConfig.Endpoint,
+		apiHeaders:          apiConfig.Headers,
 		dataset:             dataset,
 		k8sNamespace:        namespace,
 		k8sName:             name,
 	}
 }
 
+// effectiveDataset returns the dataset to which the third-party resources in the namespace of the given monitoring
+// resource are synchronized.
+func effectiveDataset(apiConfig *ApiConfig, monitoringResource *dash0v1alpha1.Dash0Monitoring) string {
+	dataset := apiConfig.Dataset
+	if monitoringResource.Spec.Dataset != "" {
+		// the dataset configured for the namespace takes precedence over the operator-wide dataset
+		dataset = monitoringResource.Spec.Dataset
+	}
+	if dataset == "" {
+		dataset = util.DatasetDefault
+	}
+	return dataset
+}
+
+// setApiRequestHeaders sets the additional headers configured for the Dash0 API on the given request, followed by the
+// Authorization header (and the Content-Type header for requests with a JSON payload), so the latter always take
+// precedence over additional headers with the same name.
+func setApiRequestHeaders(req *http.Request, apiHeaders []dash0v1alpha1.Header, authToken string, hasJsonPayload bool) {
+	for _, header := range apiHeaders {
+		req.Header.Set(header.Name, header.Value)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
+	if hasJsonPayload {
+		req.Header.Set("Content-Type", "application/json")
+	}
+}
+
+// addIdempotencyKeyHeaders sets a deterministic idempotency key header on all given requests. The key is derived from
+// the request path (which contains the origin of the synchronized item) and the request payload, so that re-sending
+// the same content for the same item always produces the same key, across retries as well as across reconciles. This
+// allows the Dash0 API to deduplicate requests that have been retried.
+func addIdempotencyKeyHeaders(
+	resourceReconciler ThirdPartyResourceReconciler,
+	httpRequests []HttpRequestWithItemName,
+	logger *logr.Logger,
+) {
+	headerName := resourceReconciler.IdempotencyKeyHeaderName()
+	if headerName == "" {
+		headerName = DefaultIdempotencyKeyHeaderName
+	}
+	for _, req := range httpRequests {
+		payload, err := readRequestPayload(req.Request)
+		if err != nil {
+			logger.Error(err,
+				fmt.Sprintf(
+					"unable to read the request payload for %s \"%s\", cannot set the idempotency key header",
+					resourceReconciler.ShortName(),
+					req.ItemName,
+				))
+			continue
+		}
+		req.Request.Header.Set(headerName, computeIdempotencyKey(req.Request.URL.Path, payload))
+	}
+}
+
+// readRequestPayload returns a copy of the request body, without consuming the body of the request itself.
+func readRequestPayload(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return io.ReadAll(body)
+}
+
+// computeIdempotencyKey returns a hex encoded SHA-256 hash over the given origin and payload.
+func computeIdempotencyKey(origin string, payload []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(origin))
+	// separate origin and payload, to avoid collisions between different origin/payload combinations with the same
+	// concatenation
+	hash.Write([]byte{0})
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// computeSynchronizationContentHash returns a hex encoded SHA-256 hash over the method, URL and payload of all given
+// requests, as well as over the API config (endpoint, dataset and headers), the auth token and the UID of the
+// monitoring resource. Including the API config ties persisted hashes to the API config they have been computed for,
+// so that resources are synchronized again after the API config has changed. Including the auth token makes sure that
+// resources are synchronized again when the token is changed to one for a different organization, including the UID
+// of the monitoring resource makes sure that the synchronization result is written to a recreated monitoring resource.
+// An empty string is returned if any of the payloads cannot be read; this disables skipping unchanged resources for
+// this synchronization.
+func computeSynchronizationContentHash(
+	preconditionChecksResult *preconditionValidationResult,
+	httpRequests []HttpRequestWithItemName,
+	logger *logr.Logger,
+) string {
+	if len(httpRequests) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	hash.Write([]byte(preconditionChecksResult.apiEndpoint))
+	hash.Write([]byte{0})
+	hash.Write([]byte(preconditionChecksResult.dataset))
+	hash.Write([]byte{0})
+	for _, header := range preconditionChecksResult.apiHeaders {
+		hash.Write([]byte(header.Name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(header.Value))
+		hash.Write([]byte{0})
+	}
+	hash.Write([]byte(preconditionChecksResult.authToken))
+	hash.Write([]byte{0})
+	if preconditionChecksResult.monitoringResource != nil {
+		hash.Write([]byte(preconditionChecksResult.monitoringResource.UID))
+	}
+	hash.Write([]byte{0})
+	for _, req := range httpRequests {
+		payload, err := readRequestPayload(req.Request)
+		if err != nil {
+			logger.Error(err, "unable to read the request payload, cannot compute the content hash")
+			return ""
+		}
+		hash.Write([]byte(req.Request.Method))
+		hash.Write([]byte{0})
+		hash.Write([]byte(req.Request.URL.String()))
+		hash.Write([]byte{0})
+		hash.Write(payload)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func synchronizationCacheKey(thirdPartyResource client.Object) string {
+	return fmt.Sprintf("%s/%s", thirdPartyResource.GetNamespace(), thirdPartyResource.GetName())
+}
+
+func (c *synchronizationCache) get(key string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hashes[key]
+}
+
+func (c *synchronizationCache) put(key string, hash string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.hashes == nil {
+		c.hashes = make(map[string]string)
+	}
+	c.hashes[key] = hash
+	delete(c.removed, key)
+}
+
+func (c *synchronizationCache) remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.hashes, key)
+	if c.removed == nil {
+		c.removed = make(map[string]bool)
+	}
+	c.removed[key] = true
+}
+
+// clear drops all entries. This does not affect the use of persisted hashes; those are invalidated by a change of the
+// API config anyway, since the API config is part of the hash.
+func (c *synchronizationCache) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.hashes = nil
+	c.removed = nil
+}
+
+// mayUsePersistedHash returns true if the content hash persisted in the status of the monitoring resource may be used
+// for the given key, that is, if the entry for the key has not been removed explicitly.
+func (c *synchronizationCache) mayUsePersistedHash(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return !c.removed[key]
+}
+
 // executeAllHttpRequests executes all HTTP requests in the given list and returns the names of the items that were
 // successfully synchronized, as well as a map of name to error message for items that were rejected by the Dash0 API.
 func executeAllHttpRequests(
@@ -605,19 +1056,81 @@ func executeAllHttpRequests(
 ) ([]string, map[string]string) {
 	successfullySynchronized := make([]string, 0)
 	httpErrors := make(map[string]string)
+	errorCountsPerOutcome := make(map[string]int)
 	for _, req := range allRequests {
 		if err := executeSingleHttpRequestWithRetry(resourceReconciler, &req, actionLabel, logger); err != nil {
 			httpErrors[req.ItemName] = err.Error()
+			errorCountsPerOutcome[synchronizationOutcomeForError(err)]++
 		} else {
 			successfullySynchronized = append(successfullySynchronized, req.ItemName)
 		}
 	}
+	if errorCountsPerOutcome[synchronizationOutcomeAuthError] > 0 {
+		logger.Info(
+			fmt.Sprintf(
+				"The Dash0 API has rejected the authorization for %d %s(s). Check that the Dash0 auth token is valid "+
+					"and is allowed to manage %ss in the configured dataset.",
+				errorCountsPerOutcome[synchronizationOutcomeAuthError],
+				resourceReconciler.ShortName(),
+				resourceReconciler.ShortName(),
+			))
+	}
+	recordSynchronizationResults(resourceReconciler, synchronizationOutcomeSuccess, len(successfullySynchronized))
+	for outcome, count := range errorCountsPerOutcome {
+		recordSynchronizationResults(resourceReconciler, outcome, count)
+	}
 	if len(successfullySynchronized) == 0 {
 		successfullySynchronized = nil
 	}
 	return successfullySynchronized, httpErrors
 }
 
+// initializeSynchronizationMetrics creates the metrics for synchronizing resources with the Dash0 API. Since the
+// metrics are shared by all third-party resource reconcilers, they are only created once.
+func initializeSynchronizationMetrics(
+	meter otelmetric.Meter,
+	metricNamePrefix string,
+	logger *logr.Logger,
+) {
+	initializeSynchronizationMetricsOnce.Do(func() {
+		synchronizationResultMetricName :=
+			fmt.Sprintf("%s%s", metricNamePrefix, "thirdpartyresource.synchronization_results")
+		var err error
+		if synchronizationResultMetric, err = meter.Int64Counter(
+			synchronizationResultMetricName,
+			otelmetric.WithUnit("1"),
+			otelmetric.WithDescription(
+				"Counter for items synchronized with the Dash0 API, by kind and outcome "+
+					"(success, validation-error, auth-error, http-error)"),
+		); err != nil {
+			logger.Error(err, fmt.Sprintf("Cannot initialize the metric %s.", synchronizationResultMetricName))
+		}
+
+		apiRequestDurationMetricName := fmt.Sprintf("%s%s", metricNamePrefix, "api.request.duration")
+		if apiRequestDurationMetric, err = meter.Float64Histogram(
+			apiRequestDurationMetricName,
+			otelmetric.WithUnit("s"),
+			otelmetric.WithDescription("Histogram of how long HTTP requests to the Dash0 API take"),
+		); err != nil {
+			logger.Error(err, fmt.Sprintf("Cannot initialize the metric %s.", apiRequestDurationMetricName))
+		}
+	})
+}
+
+func recordSynchronizationResults(resourceReconciler ThirdPartyResourceReconciler, outcome string, count int) {
+	if synchronizationResultMetric == nil || count == 0 {
+		return
+	}
+	synchronizationResultMetric.Add(
+		context.Background(),
+		int64(count),
+		otelmetric.WithAttributes(
+			attribute.String("kind", resourceReconciler.ShortName()),
+			attribute.String("outcome", outcome),
+		),
+	)
+}
+
 func executeSingleHttpRequestWithRetry(
 	resourceReconciler ThirdPartyResourceReconciler,
 	req *HttpRequestWithItemName,
@@ -639,13 +1152,7 @@ func executeSingleHttpRequestWithRetry(
 			Duration: resourceReconciler.GetHttpRetryDelay(),
 			Factor:   1.5,
 		},
-		func(err error) bool {
-			var retryErr *retryableError
-			if errors.As(err, &retryErr) {
-				return retryErr.retryable
-			}
-			return false
-		},
+		isTransientError,
 		func() error {
 			return executeSingleHttpRequest(
 				resourceReconciler,
@@ -661,7 +1168,9 @@ func executeSingleHttpRequest(
 	req *HttpRequestWithItemName,
 	logger *logr.Logger,
 ) error {
+	start := time.Now()
 	res, err := resourceReconciler.HttpClient().Do(req.Request)
+	recordApiRequestDuration(req.Request, res, time.Since(start))
 	if err != nil {
 		logger.Error(err,
 			fmt.Sprintf(
@@ -670,31 +1179,22 @@ func executeSingleHttpRequest(
 				req.ItemName,
 				req.Request.URL.String(),
 			))
-		return &retryableError{
-			err:       err,
-			retryable: true,
-		}
+		return &TransientError{err: err}
 	}
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
 		// HTTP status is not 2xx, treat this as an error
 		// convertNon2xxStatusCodeToError will also consume and close the response body
-		statusCodeError := convertNon2xxStatusCodeToError(resourceReconciler, req, res)
-		retryableStatusCodeError := &retryableError{
-			err: statusCodeError,
-		}
-
-		if res.StatusCode >= http.StatusBadRequest && res.StatusCode < http.StatusInternalServerError {
-			// HTTP 4xx status codes are not retryable
-			retryableStatusCodeError.retryable = false
-			logger.Error(statusCodeError, "unexpected status code")
-			return retryableStatusCodeError
-		} else {
-			// everything else, in particular HTTP 5xx status codes can be retried
-			retryableStatusCodeError.retryable = true
+		statusCodeError := classifyStatusCodeError(
+			res.StatusCode,
+			convertNon2xxStatusCodeToError(resourceReconciler, req, res),
+		)
+		if isTransientError(statusCodeError) {
 			logger.Error(statusCodeError, "unexpected status code, request might be retried")
-			return retryableStatusCodeError
+		} else {
+			logger.Error(statusCodeError, "unexpected status code")
 		}
+		return statusCodeError
 	}
 
 	// HTTP status code was 2xx, discard the response body and close it
@@ -705,6 +1205,26 @@ func executeSingleHttpRequest(
 	return nil
 }
 
+func recordApiRequestDuration(req *http.Request, res *http.Response, elapsed time.Duration) {
+	if apiRequestDurationMetric == nil {
+		return
+	}
+	// Requests that did not receive a response at all (e.g. due to network errors or timeouts) are recorded with the
+	// status code class "error".
+	statusCodeClass := "error"
+	if res != nil {
+		statusCodeClass = fmt.Sprintf("%dxx", res.StatusCode/100)
+	}
+	apiRequestDurationMetric.Record(
+		req.Context(),
+		elapsed.Seconds(),
+		otelmetric.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("http.response.status_code_class", statusCodeClass),
+		),
+	)
+}
+
 func convertNon2xxStatusCodeToError(
 	resourceReconciler ThirdPartyResourceReconciler,
 	req *HttpRequestWithItemName,
@@ -745,6 +1265,7 @@ func writeSynchronizationResult(
 	succesfullySynchronized []string,
 	validationIssuesPerItem map[string][]string,
 	synchronizationErrorsPerItem map[string]string,
+	contentHash string,
 	logger *logr.Logger,
 ) {
 	qualifiedName := fmt.Sprintf("%s/%s", thirdPartyResource.GetNamespace(), thirdPartyResource.GetName())
@@ -799,6 +1320,7 @@ func writeSynchronizationResult(
 				succesfullySynchronized,
 				synchronizationErrorsPerItem,
 				validationIssuesPerItem,
+				contentHash,
 			)
 			if err := resourceReconciler.K8sClient().Status().Update(ctx, monitoringResource); err != nil {
 				logger.Error(
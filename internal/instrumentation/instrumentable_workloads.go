@@ -19,11 +19,11 @@ type instrumentableWorkload interface {
 	getKind() string
 	asRuntimeObject() runtime.Object
 	asClientObject() client.Object
-	instrument(images util.Images, oTelCollectorBaseUrl string, isIPv6Cluster bool, logger *logr.Logger) bool
-	// Strictly speaking, for reverting we do not need the images nor the isIPv6Cluster setting, but for symmetry with
-	// the instrument method and to make sure any WorkloadModifier instance we create actually has valid values, the
-	// revert method accepts them as arguments as well.
-	revert(images util.Images, oTelCollectorBaseUrl string, isIPv6Cluster bool, logger *logr.Logger) bool
+	instrument(instrumentationMetadata util.InstrumentationMetadata, logger *logr.Logger) bool
+	// Strictly speaking, for reverting we do not need the instrumentation metadata, but for symmetry with the instrument
+	// method and to make sure any WorkloadModifier instance we create actually has valid values, the revert method
+	// accepts it as an argument as well.
+	revert(instrumentationMetadata util.InstrumentationMetadata, logger *logr.Logger) bool
 }
 
 type cronJobWorkload struct {
@@ -35,20 +35,16 @@ func (w *cronJobWorkload) getKind() string                   { return "CronJob"
 func (w *cronJobWorkload) asRuntimeObject() runtime.Object   { return w.cronJob }
 func (w *cronJobWorkload) asClientObject() client.Object     { return w.cronJob }
 func (w *cronJobWorkload) instrument(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).ModifyCronJob(w.cronJob)
+	return newWorkloadModifier(instrumentationMetadata, logger).ModifyCronJob(w.cronJob)
 }
 func (w *cronJobWorkload) revert(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).RevertCronJob(w.cronJob)
+	return newWorkloadModifier(instrumentationMetadata, logger).RevertCronJob(w.cronJob)
 }
 
 type daemonSetWorkload struct {
@@ -60,20 +56,16 @@ func (w *daemonSetWorkload) getKind() string                   { return "DaemonS
 func (w *daemonSetWorkload) asRuntimeObject() runtime.Object   { return w.daemonSet }
 func (w *daemonSetWorkload) asClientObject() client.Object     { return w.daemonSet }
 func (w *daemonSetWorkload) instrument(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).ModifyDaemonSet(w.daemonSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).ModifyDaemonSet(w.daemonSet)
 }
 func (w *daemonSetWorkload) revert(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).RevertDaemonSet(w.daemonSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).RevertDaemonSet(w.daemonSet)
 }
 
 type deploymentWorkload struct {
@@ -85,20 +77,16 @@ func (w *deploymentWorkload) getKind() string                   { return "Deploy
 func (w *deploymentWorkload) asRuntimeObject() runtime.Object   { return w.deployment }
 func (w *deploymentWorkload) asClientObject() client.Object     { return w.deployment }
 func (w *deploymentWorkload) instrument(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).ModifyDeployment(w.deployment)
+	return newWorkloadModifier(instrumentationMetadata, logger).ModifyDeployment(w.deployment)
 }
 func (w *deploymentWorkload) revert(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).RevertDeployment(w.deployment)
+	return newWorkloadModifier(instrumentationMetadata, logger).RevertDeployment(w.deployment)
 }
 
 type replicaSetWorkload struct {
@@ -110,20 +98,16 @@ func (w *replicaSetWorkload) getKind() string                   { return "Replic
 func (w *replicaSetWorkload) asRuntimeObject() runtime.Object   { return w.replicaSet }
 func (w *replicaSetWorkload) asClientObject() client.Object     { return w.replicaSet }
 func (w *replicaSetWorkload) instrument(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).ModifyReplicaSet(w.replicaSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).ModifyReplicaSet(w.replicaSet)
 }
 func (w *replicaSetWorkload) revert(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).RevertReplicaSet(w.replicaSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).RevertReplicaSet(w.replicaSet)
 }
 
 type statefulSetWorkload struct {
@@ -135,18 +119,14 @@ func (w *statefulSetWorkload) getKind() string                   { return "State
 func (w *statefulSetWorkload) asRuntimeObject() runtime.Object   { return w.statefulSet }
 func (w *statefulSetWorkload) asClientObject() client.Object     { return w.statefulSet }
 func (w *statefulSetWorkload) instrument(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).ModifyStatefulSet(w.statefulSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).ModifyStatefulSet(w.statefulSet)
 }
 func (w *statefulSetWorkload) revert(
-	images util.Images,
-	oTelCollectorBaseUrl string,
-	isIPv6Cluster bool,
+	instrumentationMetadata util.InstrumentationMetadata,
 	logger *logr.Logger,
 ) bool {
-	return newWorkloadModifier(images, oTelCollectorBaseUrl, isIPv6Cluster, logger).RevertStatefulSet(w.statefulSet)
+	return newWorkloadModifier(instrumentationMetadata, logger).RevertStatefulSet(w.statefulSet)
 }
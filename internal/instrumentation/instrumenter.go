@@ -27,11 +27,16 @@ import (
 
 type Instrumenter struct {
 	client.Client
-	Clientset            *kubernetes.Clientset
-	Recorder             record.EventRecorder
-	Images               util.Images
-	OTelCollectorBaseUrl string
-	IsIPv6Cluster        bool
+	Clientset                    *kubernetes.Clientset
+	Recorder                     record.EventRecorder
+	Images                       util.Images
+	OTelCollectorBaseUrl         string
+	IsIPv6Cluster                bool
+	CollectorGatewayMode         bool
+	InitContainerSecurityContext util.InitContainerSecurityContext
+	InitContainerResources       corev1.ResourceRequirements
+	PropagatedWorkloadLabels     []string
+	AuditLog                     util.InstrumentationAuditLog
 }
 
 type ImmutableWorkloadError struct {
@@ -46,6 +51,8 @@ const (
 	workloadNameLabel      = "workload name"
 
 	updateStatusFailedMessage = "Failed to update Dash0 monitoring status conditions, requeuing reconcile request."
+
+	workloadListPageSize = 500
 )
 
 var (
@@ -158,6 +165,22 @@ func (i *Instrumenter) CheckSettingsAndInstrumentExistingWorkloads(
 		)
 		return nil
 	}
+	namespaceExcluded, err := util.IsNamespaceExcludedFromInstrumentation(
+		ctx,
+		i.Client,
+		dash0MonitoringResource.Namespace,
+	)
+	if err != nil {
+		logger.Error(err, "Cannot read the namespace to check whether it is excluded from instrumentation.")
+		return err
+	}
+	if namespaceExcluded {
+		logger.Info(
+			"The namespace has the label dash0.com/enable=false, existing workloads in this namespace will not be " +
+				"modified to send telemetry to Dash0.",
+		)
+		return nil
+	}
 
 	logger.Info("Now instrumenting existing workloads in namespace so they send telemetry to Dash0.")
 	if err := i.instrumentAllWorkloads(ctx, dash0MonitoringResource, logger); err != nil {
@@ -174,13 +197,43 @@ func (i *Instrumenter) instrumentAllWorkloads(
 	logger *logr.Logger,
 ) error {
 	namespace := dash0MonitoringResource.Namespace
+	policy := dash0MonitoringResource.ReadInstrumentationPolicy()
 
-	errCronJobs := i.findAndInstrumentCronJobs(ctx, namespace, logger)
-	errDaemonSets := i.findAndInstrumentyDaemonSets(ctx, namespace, logger)
-	errDeployments := i.findAndInstrumentDeployments(ctx, namespace, logger)
-	errJobs := i.findAndAddLabelsToImmutableJobsOnInstrumentation(ctx, namespace, logger)
-	errReplicaSets := i.findAndInstrumentReplicaSets(ctx, namespace, logger)
-	errStatefulSets := i.findAndInstrumentStatefulSets(ctx, namespace, logger)
+	excludedWorkloadKinds, err := util.FindExcludedWorkloadKinds(ctx, i.Client, logger)
+	if err != nil {
+		return fmt.Errorf("cannot read the excluded workload kinds from the Dash0 operator configuration: %w", err)
+	}
+	instrumentUnlessExcluded := func(
+		kind dash0v1alpha1.WorkloadKind,
+		findAndInstrument func(context.Context, string, dash0v1alpha1.InstrumentationPolicy, *logr.Logger) error,
+	) error {
+		if slices.Contains(excludedWorkloadKinds, kind) {
+			logger.Info(fmt.Sprintf(
+				"Instrumenting workloads of kind %s has been disabled in the Dash0 operator configuration resource, "+
+					"existing workloads of this kind will not be instrumented.", kind))
+			return nil
+		}
+		return findAndInstrument(ctx, namespace, policy, logger)
+	}
+
+	errCronJobs := instrumentUnlessExcluded(dash0v1alpha1.WorkloadKindCronJob, i.findAndInstrumentCronJobs)
+	errDaemonSets := instrumentUnlessExcluded(dash0v1alpha1.WorkloadKindDaemonSet, i.findAndInstrumentyDaemonSets)
+	errDeployments := instrumentUnlessExcluded(dash0v1alpha1.WorkloadKindDeployment, i.findAndInstrumentDeployments)
+	instrumentOwningCronJobs := !slices.Contains(excludedWorkloadKinds, dash0v1alpha1.WorkloadKindCronJob)
+	errJobs := instrumentUnlessExcluded(
+		dash0v1alpha1.WorkloadKindJob,
+		func(ctx context.Context, namespace string, policy dash0v1alpha1.InstrumentationPolicy, logger *logr.Logger) error {
+			return i.findAndAddLabelsToImmutableJobsOnInstrumentation(
+				ctx,
+				namespace,
+				instrumentOwningCronJobs,
+				policy,
+				logger,
+			)
+		},
+	)
+	errReplicaSets := instrumentUnlessExcluded(dash0v1alpha1.WorkloadKindReplicaSet, i.findAndInstrumentReplicaSets)
+	errStatefulSets := instrumentUnlessExcluded(dash0v1alpha1.WorkloadKindStatefulSet, i.findAndInstrumentStatefulSets)
 	combinedErrors := errors.Join(
 		errCronJobs,
 		errDaemonSets,
@@ -198,101 +251,116 @@ func (i *Instrumenter) instrumentAllWorkloads(
 func (i *Instrumenter) findAndInstrumentCronJobs(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.BatchV1().CronJobs(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.BatchV1().CronJobs(namespace).List,
+		util.EmptyListOptions,
+		func(list *batchv1.CronJobList) []batchv1.CronJob { return list.Items },
+		func(resource batchv1.CronJob) { i.instrumentCronJob(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying cron jobs: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.instrumentCronJob(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) instrumentCronJob(
 	ctx context.Context,
 	cronJob batchv1.CronJob,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.instrumentWorkload(ctx, &cronJobWorkload{
 		cronJob: &cronJob,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) findAndInstrumentyDaemonSets(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().DaemonSets(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().DaemonSets(namespace).List,
+		util.EmptyListOptions,
+		func(list *appsv1.DaemonSetList) []appsv1.DaemonSet { return list.Items },
+		func(resource appsv1.DaemonSet) { i.instrumentDaemonSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying daemon sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.instrumentDaemonSet(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) instrumentDaemonSet(
 	ctx context.Context,
 	daemonSet appsv1.DaemonSet,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.instrumentWorkload(ctx, &daemonSetWorkload{
 		daemonSet: &daemonSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) findAndInstrumentDeployments(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().Deployments(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().Deployments(namespace).List,
+		util.EmptyListOptions,
+		func(list *appsv1.DeploymentList) []appsv1.Deployment { return list.Items },
+		func(resource appsv1.Deployment) { i.instrumentDeployment(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying deployments: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.instrumentDeployment(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) instrumentDeployment(
 	ctx context.Context,
 	deployment appsv1.Deployment,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.instrumentWorkload(ctx, &deploymentWorkload{
 		deployment: &deployment,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) findAndAddLabelsToImmutableJobsOnInstrumentation(
 	ctx context.Context,
 	namespace string,
+	instrumentOwningCronJobs bool,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.BatchV1().Jobs(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.BatchV1().Jobs(namespace).List,
+		util.EmptyListOptions,
+		func(list *batchv1.JobList) []batchv1.Job { return list.Items },
+		func(job batchv1.Job) {
+			i.handleJobJobOnInstrumentation(ctx, job, instrumentOwningCronJobs, policy, logger)
+		},
+	); err != nil {
 		return fmt.Errorf("error when querying jobs: %w", err)
 	}
-
-	for _, job := range matchingWorkloadsInNamespace.Items {
-		i.handleJobJobOnInstrumentation(ctx, job, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) handleJobJobOnInstrumentation(
 	ctx context.Context,
 	job batchv1.Job,
+	instrumentOwningCronJobs bool,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	logger := reconcileLogger.WithValues(
@@ -313,22 +381,22 @@ func (i *Instrumenter) handleJobJobOnInstrumentation(
 	var requiredAction util.ModificationMode
 	modifyLabels := true
 	createImmutableWorkloadsError := true
-	if util.HasOptedOutOfInstrumentation(objectMeta) && util.InstrumentationAttemptHasFailed(objectMeta) {
+	if util.HasOptedOutOfInstrumentation(objectMeta, policy) && util.InstrumentationAttemptHasFailed(objectMeta) {
 		// There has been an unsuccessful attempt to instrument this job before, but now the user has added the opt-out
 		// label, so we can remove the labels left over from that earlier attempt.
 		// "requiredAction = Instrumentation" in the context of immutable jobs means "remove Dash0 labels from the job",
 		// no other modification will take place.
 		requiredAction = util.ModificationModeUninstrumentation
 		createImmutableWorkloadsError = false
-	} else if util.HasOptedOutOfInstrumentation(objectMeta) && util.HasBeenInstrumentedSuccessfully(objectMeta) {
+	} else if util.HasOptedOutOfInstrumentation(objectMeta, policy) && util.HasBeenInstrumentedSuccessfully(objectMeta) {
 		// This job has been instrumented successfully, presumably by the webhook. Since then, the opt-out label has
 		// been added. The correct action would be to uninstrument it, but since it is immutable, we cannot do that.
 		// We will not actually modify this job at all, but create a log message and a corresponding event.
 		modifyLabels = false
 		requiredAction = util.ModificationModeUninstrumentation
-	} else if util.HasOptedOutOfInstrumentation(objectMeta) {
+	} else if util.HasOptedOutOfInstrumentation(objectMeta, policy) {
 		// has opt-out label and there has been no previous instrumentation attempt
-		logger.Info("not instrumenting this workload due to dash0.com/enable=false")
+		i.postProcessOptOut(&job, objectMeta, &logger)
 		return
 	} else if util.HasBeenInstrumentedSuccessfully(objectMeta) || util.InstrumentationAttemptHasFailed(objectMeta) {
 		// We already have instrumented this job (via the webhook) or have failed to instrument it, in either case,
@@ -357,9 +425,9 @@ func (i *Instrumenter) handleJobJobOnInstrumentation(
 		hasBeenModified := false
 		switch requiredAction {
 		case util.ModificationModeInstrumentation:
-			hasBeenModified = newWorkloadModifier(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger).AddLabelsToImmutableJob(&job)
+			hasBeenModified = newWorkloadModifier(i.instrumentationMetadata(), &logger).AddLabelsToImmutableJob(&job)
 		case util.ModificationModeUninstrumentation:
-			hasBeenModified = newWorkloadModifier(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger).RemoveLabelsFromImmutableJob(&job)
+			hasBeenModified = newWorkloadModifier(i.instrumentationMetadata(), &logger).RemoveLabelsFromImmutableJob(&job)
 		}
 
 		if hasBeenModified {
@@ -375,6 +443,13 @@ func (i *Instrumenter) handleJobJobOnInstrumentation(
 	}
 	if retryErr != nil {
 		postProcess(&job, false, retryErr, &logger)
+	} else if createImmutableWorkloadsError &&
+		requiredAction == util.ModificationModeInstrumentation &&
+		instrumentOwningCronJobs &&
+		i.instrumentOwningCronJob(ctx, &job, policy, reconcileLogger, &logger) {
+		// The job itself cannot be instrumented, but the cron job that owns it has been instrumented (or has already
+		// been instrumented before), so all jobs it creates from now on will be instrumented.
+		return
 	} else if createImmutableWorkloadsError {
 		// One way or another we are in a situation were we would have wanted to instrument/uninstrument the job, but
 		// could not. Passing an ImmutableWorkloadError to postProcess will make sure we write a corresponding log
@@ -389,30 +464,68 @@ func (i *Instrumenter) handleJobJobOnInstrumentation(
 	}
 }
 
+// instrumentOwningCronJob instruments the cron job that owns the given job, if there is one. Existing jobs are
+// immutable and cannot be instrumented, but instrumenting the job template of the cron job makes sure that all jobs it
+// creates from now on are instrumented. It returns true if the owning cron job is instrumented, in which case an
+// informative event is recorded for the job.
+func (i *Instrumenter) instrumentOwningCronJob(
+	ctx context.Context,
+	job *batchv1.Job,
+	policy dash0v1alpha1.InstrumentationPolicy,
+	reconcileLogger *logr.Logger,
+	logger *logr.Logger,
+) bool {
+	ownerIdx := slices.IndexFunc(job.GetOwnerReferences(), func(ownerReference metav1.OwnerReference) bool {
+		return ownerReference.Kind == "CronJob" && ownerReference.APIVersion == batchv1.SchemeGroupVersion.String()
+	})
+	if ownerIdx < 0 {
+		return false
+	}
+	cronJobName := job.GetOwnerReferences()[ownerIdx].Name
+
+	cronJob := &batchv1.CronJob{}
+	if err := i.Client.Get(ctx, client.ObjectKey{Namespace: job.GetNamespace(), Name: cronJobName}, cronJob); err != nil {
+		logger.Error(err, "Cannot fetch the cron job that owns this job.", "cron job", cronJobName)
+		return false
+	}
+	i.instrumentWorkload(ctx, &cronJobWorkload{cronJob: cronJob}, policy, reconcileLogger)
+	if !util.HasBeenInstrumentedSuccessfully(&cronJob.ObjectMeta) || util.HasOptedOutOfInstrumentation(&cronJob.ObjectMeta, policy) {
+		return false
+	}
+
+	logger.Info(fmt.Sprintf("This job is immutable and cannot be instrumented, the cron job %s that owns it has been "+
+		"instrumented instead.", cronJobName))
+	util.QueueOwnerInstrumentedEvent(i.Recorder, job, "cron job", cronJobName, "controller")
+	return true
+}
+
 func (i *Instrumenter) findAndInstrumentReplicaSets(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().ReplicaSets(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().ReplicaSets(namespace).List,
+		util.EmptyListOptions,
+		func(list *appsv1.ReplicaSetList) []appsv1.ReplicaSet { return list.Items },
+		func(resource appsv1.ReplicaSet) { i.instrumentReplicaSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying replica sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.instrumentReplicaSet(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) instrumentReplicaSet(
 	ctx context.Context,
 	replicaSet appsv1.ReplicaSet,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	hasBeenUpdated := i.instrumentWorkload(ctx, &replicaSetWorkload{
 		replicaSet: &replicaSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 
 	if hasBeenUpdated {
 		i.restartPodsOfReplicaSet(ctx, replicaSet, reconcileLogger)
@@ -422,31 +535,36 @@ func (i *Instrumenter) instrumentReplicaSet(
 func (i *Instrumenter) findAndInstrumentStatefulSets(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err := i.Clientset.AppsV1().StatefulSets(namespace).List(ctx, util.EmptyListOptions)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().StatefulSets(namespace).List,
+		util.EmptyListOptions,
+		func(list *appsv1.StatefulSetList) []appsv1.StatefulSet { return list.Items },
+		func(resource appsv1.StatefulSet) { i.instrumentStatefulSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying stateful sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.instrumentStatefulSet(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) instrumentStatefulSet(
 	ctx context.Context,
 	statefulSet appsv1.StatefulSet,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.instrumentWorkload(ctx, &statefulSetWorkload{
 		statefulSet: &statefulSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) instrumentWorkload(
 	ctx context.Context,
 	workload instrumentableWorkload,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) bool {
 	objectMeta := workload.getObjectMeta()
@@ -466,7 +584,7 @@ func (i *Instrumenter) instrumentWorkload(
 	}
 
 	var requiredAction util.ModificationMode
-	if util.WasInstrumentedButHasOptedOutNow(objectMeta) {
+	if util.WasInstrumentedButHasOptedOutNow(objectMeta, policy) {
 		requiredAction = util.ModificationModeUninstrumentation
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(objectMeta, i.Images) {
 		// No change necessary, this workload has already been instrumented and an opt-out label (which would need to
@@ -474,12 +592,26 @@ func (i *Instrumenter) instrumentWorkload(
 		logger.Info("not updating the existing instrumentation for this workload, it has already been successfully " +
 			"instrumented by the same operator version")
 		return false
-	} else if util.HasOptedOutOfInstrumentationAndIsUninstrumented(workload.getObjectMeta()) {
-		logger.Info("not instrumenting this workload due to dash0.com/enable=false")
+	} else if util.HasOptedOutOfInstrumentationAndIsUninstrumented(workload.getObjectMeta(), policy) {
+		i.postProcessOptOut(workload.asRuntimeObject(), objectMeta, &logger)
+		return false
+	} else if owner := util.FindHigherOrderOwner(objectMeta); kind == "ReplicaSet" && owner != nil {
+		logger.Info(fmt.Sprintf("not instrumenting this workload, since it is managed by the %s %s, which will be "+
+			"instrumented instead", owner.Kind, owner.Name))
+		util.QueueOwnedByHigherOrderWorkloadEvent(
+			i.Recorder,
+			workload.asRuntimeObject(),
+			owner.Kind,
+			owner.Name,
+			"controller",
+		)
 		return false
 	} else {
 		requiredAction = util.ModificationModeInstrumentation
 	}
+	// At this point, a workload that has been instrumented successfully before has been instrumented by a different
+	// operator version (otherwise we would have returned early), so instrumenting it again updates the instrumentation.
+	isReinstrumentation := util.HasBeenInstrumentedSuccessfully(objectMeta)
 
 	hasBeenModified := false
 	retryErr := util.Retry(fmt.Sprintf("instrumenting %s", kind), func() error {
@@ -498,9 +630,9 @@ func (i *Instrumenter) instrumentWorkload(
 
 		switch requiredAction {
 		case util.ModificationModeInstrumentation:
-			hasBeenModified = workload.instrument(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger)
+			hasBeenModified = workload.instrument(i.instrumentationMetadata(), &logger)
 		case util.ModificationModeUninstrumentation:
-			hasBeenModified = workload.revert(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger)
+			hasBeenModified = workload.revert(i.instrumentationMetadata(), &logger)
 		}
 
 		if hasBeenModified {
@@ -512,9 +644,26 @@ func (i *Instrumenter) instrumentWorkload(
 
 	switch requiredAction {
 	case util.ModificationModeInstrumentation:
-		return i.postProcessInstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger)
+		if isReinstrumentation && hasBeenModified && retryErr == nil {
+			logger.Info("The controller has updated the Dash0 instrumentation of the workload after an operator version " +
+				"change.")
+			util.QueueReinstrumentedAfterUpgradeEvent(i.Recorder, workload.asRuntimeObject(), "controller")
+			i.reportUninstrumentableContainers(workload.asRuntimeObject(), &logger)
+			i.recordAudit(kind, objectMeta, requiredAction, util.ReasonReinstrumentedAfterUpgrade, &logger)
+			return true
+		}
+		if !i.postProcessInstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger) {
+			return false
+		}
+		i.reportUninstrumentableContainers(workload.asRuntimeObject(), &logger)
+		i.recordAudit(kind, objectMeta, requiredAction, util.ReasonSuccessfulInstrumentation, &logger)
+		return true
 	case util.ModificationModeUninstrumentation:
-		return i.postProcessUninstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger)
+		if !i.postProcessUninstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger) {
+			return false
+		}
+		i.recordAudit(kind, objectMeta, requiredAction, util.ReasonSuccessfulUninstrumentation, &logger)
+		return true
 	}
 	return false
 }
@@ -535,11 +684,8 @@ func (i *Instrumenter) postProcessInstrumentation(
 		util.QueueFailedInstrumentationEvent(i.Recorder, resource, "controller", retryErr)
 		return false
 	} else if !hasBeenModified {
-		// TODO This also happens for replica sets owned by a deployment and the log message as well as the message on
-		// the event are unspecific, would be better if we could differentiate between the two cases.
-		// (Also for revert maybe.)
-		logger.Info("Dash0 instrumentation was already present on this workload, or the workload is part of a higher " +
-			"order workload that will be instrumented, no modification by the controller is necessary.")
+		logger.Info("Dash0 instrumentation was already present on this workload, no modification by the controller " +
+			"is necessary.")
 		util.QueueNoInstrumentationNecessaryEvent(i.Recorder, resource, "controller")
 		return false
 	} else {
@@ -549,6 +695,26 @@ func (i *Instrumenter) postProcessInstrumentation(
 	}
 }
 
+// reportUninstrumentableContainers queues a warning event for the given workload if some of its containers could not
+// be instrumented, see workloads.FindUninstrumentableContainers.
+func (i *Instrumenter) postProcessOptOut(resource runtime.Object, objectMeta *metav1.ObjectMeta, logger *logr.Logger) {
+	if util.HasExplicitlyOptedOutOfInstrumentation(objectMeta) {
+		logger.Info("not instrumenting this workload due to dash0.com/enable=false")
+		util.QueueOptedOutOfInstrumentationEvent(i.Recorder, resource, "controller")
+		return
+	}
+	logger.Info("not instrumenting this workload, the instrumentation policy of this namespace is opt-in and the " +
+		"workload does not have the label dash0.com/enable=true")
+	util.QueueNotOptedInToInstrumentationEvent(i.Recorder, resource, "controller")
+}
+
+func (i *Instrumenter) reportUninstrumentableContainers(resource runtime.Object, logger *logr.Logger) {
+	if containerNames := workloads.FindUninstrumentableContainers(resource); len(containerNames) > 0 {
+		logger.Info("Some containers of the workload could not be instrumented.", "containers", containerNames)
+		util.QueueContainersNotInstrumentedEvent(i.Recorder, resource, containerNames, "controller")
+	}
+}
+
 // UninstrumentWorkloadsIfAvailable is the main uninstrumentation function that is called in the controller's reconcile
 // loop. It checks whether the Dash0 monitoring resource is marked as available; if it is, it uninstruments existing
 // workloads.
@@ -576,13 +742,14 @@ func (i *Instrumenter) uninstrumentWorkloads(
 	logger *logr.Logger,
 ) error {
 	namespace := dash0MonitoringResource.Namespace
+	policy := dash0MonitoringResource.ReadUninstrumentationPolicy()
 
-	errCronJobs := i.findAndUninstrumentCronJobs(ctx, namespace, logger)
-	errDaemonSets := i.findAndUninstrumentDaemonSets(ctx, namespace, logger)
-	errDeployments := i.findAndUninstrumentDeployments(ctx, namespace, logger)
+	errCronJobs := i.findAndUninstrumentCronJobs(ctx, namespace, policy, logger)
+	errDaemonSets := i.findAndUninstrumentDaemonSets(ctx, namespace, policy, logger)
+	errDeployments := i.findAndUninstrumentDeployments(ctx, namespace, policy, logger)
 	errJobs := i.findAndHandleJobOnUninstrumentation(ctx, namespace, logger)
-	errReplicaSets := i.findAndUninstrumentReplicaSets(ctx, namespace, logger)
-	errStatefulSets := i.findAndUninstrumentStatefulSets(ctx, namespace, logger)
+	errReplicaSets := i.findAndUninstrumentReplicaSets(ctx, namespace, policy, logger)
+	errStatefulSets := i.findAndUninstrumentStatefulSets(ctx, namespace, policy, logger)
 	combinedErrors := errors.Join(
 		errCronJobs,
 		errDaemonSets,
@@ -600,75 +767,88 @@ func (i *Instrumenter) uninstrumentWorkloads(
 func (i *Instrumenter) findAndUninstrumentCronJobs(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.BatchV1().CronJobs(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.BatchV1().CronJobs(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *batchv1.CronJobList) []batchv1.CronJob { return list.Items },
+		func(resource batchv1.CronJob) { i.uninstrumentCronJob(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented cron jobs: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.uninstrumentCronJob(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) uninstrumentCronJob(
 	ctx context.Context,
 	cronJob batchv1.CronJob,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.revertWorkloadInstrumentation(ctx, &cronJobWorkload{
 		cronJob: &cronJob,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
-func (i *Instrumenter) findAndUninstrumentDaemonSets(ctx context.Context, namespace string, logger *logr.Logger) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().DaemonSets(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+func (i *Instrumenter) findAndUninstrumentDaemonSets(
+	ctx context.Context,
+	namespace string,
+	policy dash0v1alpha1.UninstrumentationPolicy,
+	logger *logr.Logger,
+) error {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().DaemonSets(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *appsv1.DaemonSetList) []appsv1.DaemonSet { return list.Items },
+		func(resource appsv1.DaemonSet) { i.uninstrumentDaemonSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented daemon sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.uninstrumentDaemonSet(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) uninstrumentDaemonSet(
 	ctx context.Context,
 	daemonSet appsv1.DaemonSet,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.revertWorkloadInstrumentation(ctx, &daemonSetWorkload{
 		daemonSet: &daemonSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) findAndUninstrumentDeployments(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().Deployments(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().Deployments(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *appsv1.DeploymentList) []appsv1.Deployment { return list.Items },
+		func(resource appsv1.Deployment) { i.uninstrumentDeployment(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented deployments: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.uninstrumentDeployment(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) uninstrumentDeployment(
 	ctx context.Context,
 	deployment appsv1.Deployment,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.revertWorkloadInstrumentation(ctx, &deploymentWorkload{
 		deployment: &deployment,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
 func (i *Instrumenter) findAndHandleJobOnUninstrumentation(
@@ -676,14 +856,15 @@ func (i *Instrumenter) findAndHandleJobOnUninstrumentation(
 	namespace string,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err := i.Clientset.BatchV1().Jobs(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.BatchV1().Jobs(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *batchv1.JobList) []batchv1.Job { return list.Items },
+		func(job batchv1.Job) { i.handleJobOnUninstrumentation(ctx, job, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented jobs: %w", err)
 	}
-
-	for _, job := range matchingWorkloadsInNamespace.Items {
-		i.handleJobOnUninstrumentation(ctx, job, logger)
-	}
 	return nil
 }
 
@@ -727,7 +908,7 @@ func (i *Instrumenter) handleJobOnUninstrumentation(ctx context.Context, job bat
 		} else if util.InstrumentationAttemptHasFailed(&job.ObjectMeta) {
 			// There was an attempt to instrument this job (probably by the controller), which has not been successful.
 			// We only need remove the labels from that instrumentation attempt to clean up.
-			newWorkloadModifier(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger).RemoveLabelsFromImmutableJob(&job)
+			newWorkloadModifier(i.instrumentationMetadata(), &logger).RemoveLabelsFromImmutableJob(&job)
 
 			// Apparently for jobs we do not need to set the "dash0.com/webhook-ignore-once" label, since changing their
 			// labels does not trigger a new admission request.
@@ -757,23 +938,30 @@ func (i *Instrumenter) handleJobOnUninstrumentation(ctx context.Context, job bat
 func (i *Instrumenter) findAndUninstrumentReplicaSets(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().ReplicaSets(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().ReplicaSets(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *appsv1.ReplicaSetList) []appsv1.ReplicaSet { return list.Items },
+		func(resource appsv1.ReplicaSet) { i.uninstrumentReplicaSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented replica sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.uninstrumentReplicaSet(ctx, resource, logger)
-	}
 	return nil
 }
 
-func (i *Instrumenter) uninstrumentReplicaSet(ctx context.Context, replicaSet appsv1.ReplicaSet, reconcileLogger *logr.Logger) {
+func (i *Instrumenter) uninstrumentReplicaSet(
+	ctx context.Context,
+	replicaSet appsv1.ReplicaSet,
+	policy dash0v1alpha1.UninstrumentationPolicy,
+	reconcileLogger *logr.Logger,
+) {
 	hasBeenUpdated := i.revertWorkloadInstrumentation(ctx, &replicaSetWorkload{
 		replicaSet: &replicaSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 
 	if hasBeenUpdated {
 		i.restartPodsOfReplicaSet(ctx, replicaSet, reconcileLogger)
@@ -783,32 +971,39 @@ func (i *Instrumenter) uninstrumentReplicaSet(ctx context.Context, replicaSet ap
 func (i *Instrumenter) findAndUninstrumentStatefulSets(
 	ctx context.Context,
 	namespace string,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	logger *logr.Logger,
 ) error {
-	matchingWorkloadsInNamespace, err :=
-		i.Clientset.AppsV1().StatefulSets(namespace).List(ctx, util.WorkloadsWithDash0InstrumentedLabelFilter)
-	if err != nil {
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.AppsV1().StatefulSets(namespace).List,
+		util.WorkloadsWithDash0InstrumentedLabelFilter,
+		func(list *appsv1.StatefulSetList) []appsv1.StatefulSet { return list.Items },
+		func(resource appsv1.StatefulSet) { i.uninstrumentStatefulSet(ctx, resource, policy, logger) },
+	); err != nil {
 		return fmt.Errorf("error when querying instrumented stateful sets: %w", err)
 	}
-	for _, resource := range matchingWorkloadsInNamespace.Items {
-		i.uninstrumentStatefulSet(ctx, resource, logger)
-	}
 	return nil
 }
 
 func (i *Instrumenter) uninstrumentStatefulSet(
 	ctx context.Context,
 	statefulSet appsv1.StatefulSet,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) {
 	i.revertWorkloadInstrumentation(ctx, &statefulSetWorkload{
 		statefulSet: &statefulSet,
-	}, reconcileLogger)
+	}, policy, reconcileLogger)
 }
 
+// revertWorkloadInstrumentation removes the instrumentation from the given workload, or, under the lazy
+// uninstrumentation policy, only marks the workload so that the instrumentation is removed by the webhook with the next
+// change of its pod template. It returns true if the instrumentation has been removed.
 func (i *Instrumenter) revertWorkloadInstrumentation(
 	ctx context.Context,
 	workload instrumentableWorkload,
+	policy dash0v1alpha1.UninstrumentationPolicy,
 	reconcileLogger *logr.Logger,
 ) bool {
 	objectMeta := workload.getObjectMeta()
@@ -827,6 +1022,11 @@ func (i *Instrumenter) revertWorkloadInstrumentation(
 		return false
 	}
 
+	if policy == dash0v1alpha1.Lazy {
+		i.markUninstrumentationPending(ctx, workload, &logger)
+		return false
+	}
+
 	// Note: In contrast to the instrumentation logic, there is no need to check for dash0.com/enable=false here:
 	// If it is set, the workload would not have been instrumented in the first place, hence the label selector filter
 	// looking for dash0.com/instrumented=true would not have matched. Or if the workload is actually instrumented,
@@ -847,7 +1047,7 @@ func (i *Instrumenter) revertWorkloadInstrumentation(
 				err,
 			)
 		}
-		hasBeenModified = workload.revert(i.Images, i.OTelCollectorBaseUrl, i.IsIPv6Cluster, &logger)
+		hasBeenModified = workload.revert(i.instrumentationMetadata(), &logger)
 		if hasBeenModified {
 			// Changing the workload spec sometimes triggers a new admission request, which would re-instrument the
 			// workload via the webhook immediately. To prevent this, we add a label that the webhook can check to
@@ -859,7 +1059,61 @@ func (i *Instrumenter) revertWorkloadInstrumentation(
 		}
 	}, &logger)
 
-	return i.postProcessUninstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger)
+	if !i.postProcessUninstrumentation(workload.asRuntimeObject(), hasBeenModified, retryErr, &logger) {
+		return false
+	}
+	i.recordAudit(
+		kind,
+		objectMeta,
+		util.ModificationModeUninstrumentation,
+		util.ReasonSuccessfulUninstrumentation,
+		&logger,
+	)
+	return true
+}
+
+// markUninstrumentationPending adds the label dash0.com/uninstrumentation-pending to the metadata of the workload. The
+// pod template is left untouched, so marking the workload does not restart its pods.
+func (i *Instrumenter) markUninstrumentationPending(
+	ctx context.Context,
+	workload instrumentableWorkload,
+	logger *logr.Logger,
+) {
+	objectMeta := workload.getObjectMeta()
+	kind := workload.getKind()
+	if owner := util.FindHigherOrderOwner(objectMeta); kind == "ReplicaSet" && owner != nil {
+		// replica sets owned by a deployment are handled via their deployment
+		return
+	}
+	hasBeenMarked := false
+	retryErr := util.Retry(fmt.Sprintf("marking %s for uninstrumentation", kind), func() error {
+		if err := i.Client.Get(ctx, client.ObjectKey{
+			Namespace: objectMeta.GetNamespace(),
+			Name:      objectMeta.GetName(),
+		}, workload.asClientObject()); err != nil {
+			return fmt.Errorf(
+				"error when fetching %s %s/%s: %w",
+				kind,
+				objectMeta.GetNamespace(),
+				objectMeta.GetName(),
+				err,
+			)
+		}
+		if util.IsUninstrumentationPending(objectMeta) {
+			return nil
+		}
+		util.MarkUninstrumentationPending(objectMeta)
+		hasBeenMarked = true
+		return i.Client.Update(ctx, workload.asClientObject())
+	}, logger)
+
+	if retryErr != nil {
+		logger.Error(retryErr, "Marking the workload for uninstrumentation has not been successful.")
+		util.QueueFailedUninstrumentationEvent(i.Recorder, workload.asRuntimeObject(), "controller", retryErr)
+	} else if hasBeenMarked {
+		logger.Info("The controller has marked the workload for removing the Dash0 instrumentation with the next " +
+			"change of its pod template.")
+	}
 }
 
 func (i *Instrumenter) postProcessUninstrumentation(
@@ -889,16 +1143,42 @@ func (i *Instrumenter) postProcessUninstrumentation(
 	}
 }
 
-func newWorkloadModifier(images util.Images, oTelCollectorBaseUrl string, isIPv6Cluster bool, logger *logr.Logger) *workloads.ResourceModifier {
-	return workloads.NewResourceModifier(
-		util.InstrumentationMetadata{
-			Images:               images,
-			InstrumentedBy:       "controller",
-			OTelCollectorBaseUrl: oTelCollectorBaseUrl,
-			IsIPv6Cluster:        isIPv6Cluster,
-		},
-		logger,
-	)
+func (i *Instrumenter) recordAudit(
+	kind string,
+	objectMeta *metav1.ObjectMeta,
+	action util.ModificationMode,
+	reason util.Reason,
+	logger *logr.Logger,
+) {
+	util.RecordInstrumentationAudit(i.AuditLog, util.InstrumentationAuditRecord{
+		Action:          action,
+		Reason:          reason,
+		InstrumentedBy:  "controller",
+		Kind:            kind,
+		Namespace:       objectMeta.GetNamespace(),
+		Name:            objectMeta.GetName(),
+		OperatorVersion: i.Images.GetOperatorVersion(),
+	}, logger)
+}
+
+func (i *Instrumenter) instrumentationMetadata() util.InstrumentationMetadata {
+	return util.InstrumentationMetadata{
+		Images:                       i.Images,
+		InstrumentedBy:               "controller",
+		OTelCollectorBaseUrl:         i.OTelCollectorBaseUrl,
+		IsIPv6Cluster:                i.IsIPv6Cluster,
+		CollectorGatewayMode:         i.CollectorGatewayMode,
+		InitContainerSecurityContext: i.InitContainerSecurityContext,
+		InitContainerResources:       i.InitContainerResources,
+		PropagatedWorkloadLabels:     i.PropagatedWorkloadLabels,
+	}
+}
+
+func newWorkloadModifier(
+	instrumentationMetadata util.InstrumentationMetadata,
+	logger *logr.Logger,
+) *workloads.ResourceModifier {
+	return workloads.NewResourceModifier(instrumentationMetadata, logger)
 }
 
 func (i *Instrumenter) restartPodsOfReplicaSet(
@@ -909,14 +1189,20 @@ func (i *Instrumenter) restartPodsOfReplicaSet(
 	// Note: ReplicaSet pods are not restarted automatically by Kubernetes when their spec is changed (for other
 	// resource types like deployments or daemonsets this is managed by Kubernetes automatically). Therefore, we
 	// find all pods owned by the replica set and explicitly delete them to trigger a restart.
-	allPodsInNamespace, err :=
-		i.Clientset.
-			CoreV1().
-			Pods(replicaSet.Namespace).
-			List(ctx, metav1.ListOptions{
-				TimeoutSeconds: &timeoutForListingPods,
-			})
-	if err != nil {
+	var podsOfReplicaSet []corev1.Pod
+	if err := listAndProcessInPages(
+		ctx,
+		i.Clientset.CoreV1().Pods(replicaSet.Namespace).List,
+		metav1.ListOptions{
+			TimeoutSeconds: &timeoutForListingPods,
+		},
+		func(list *corev1.PodList) []corev1.Pod { return list.Items },
+		func(pod corev1.Pod) {
+			if isOwnedByReplicaSet(pod, replicaSet) {
+				podsOfReplicaSet = append(podsOfReplicaSet, pod)
+			}
+		},
+	); err != nil {
 		logger.Error(
 			err,
 			fmt.Sprintf(
@@ -929,18 +1215,6 @@ func (i *Instrumenter) restartPodsOfReplicaSet(
 		return
 	}
 
-	podsOfReplicaSet := slices.DeleteFunc(allPodsInNamespace.Items, func(pod corev1.Pod) bool {
-		ownerReferences := pod.GetOwnerReferences()
-		for _, ownerReference := range ownerReferences {
-			if ownerReference.Kind == "ReplicaSet" &&
-				ownerReference.Name == replicaSet.Name &&
-				ownerReference.UID == replicaSet.UID {
-				return false
-			}
-		}
-		return true
-	})
-
 	for _, pod := range podsOfReplicaSet {
 		err := i.Client.Delete(ctx, &pod)
 		if err != nil {
@@ -955,3 +1229,40 @@ func (i *Instrumenter) restartPodsOfReplicaSet(
 		}
 	}
 }
+
+func isOwnedByReplicaSet(pod corev1.Pod, replicaSet appsv1.ReplicaSet) bool {
+	for _, ownerReference := range pod.GetOwnerReferences() {
+		if ownerReference.Kind == "ReplicaSet" &&
+			ownerReference.Name == replicaSet.Name &&
+			ownerReference.UID == replicaSet.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// listAndProcessInPages lists resources in chunks of at most workloadListPageSize items and hands each item to the
+// process function before fetching the next chunk. This avoids holding all workloads of a large namespace in memory
+// at once, and keeps the individual list requests to the API server small.
+func listAndProcessInPages[L metav1.ListInterface, I any](
+	ctx context.Context,
+	list func(context.Context, metav1.ListOptions) (L, error),
+	listOptions metav1.ListOptions,
+	items func(L) []I,
+	process func(I),
+) error {
+	listOptions.Limit = workloadListPageSize
+	for {
+		page, err := list(ctx, listOptions)
+		if err != nil {
+			return err
+		}
+		for _, item := range items(page) {
+			process(item)
+		}
+		if page.GetContinue() == "" {
+			return nil
+		}
+		listOptions.Continue = page.GetContinue()
+	}
+}
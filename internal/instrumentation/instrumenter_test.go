@@ -4,16 +4,20 @@
 package instrumentation
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -135,6 +139,51 @@ var _ = Describe("The instrumenter", Ordered, func() {
 				VerifyImmutableJobCouldNotBeModified(GetJob(ctx, k8sClient, namespace, name))
 			})
 
+			It("should instrument the owning cron job instead of an existing job", func() {
+				cronJobName := UniqueName(CronJobNamePrefix)
+				cronJob := CreateBasicCronJob(ctx, k8sClient, namespace, cronJobName)
+				createdObjects = append(createdObjects, cronJob)
+				name := UniqueName(JobNamePrefix)
+				job := CreateJobOwnedByCronJob(ctx, k8sClient, namespace, name, cronJobName)
+				createdObjects = append(createdObjects, job)
+
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
+
+				VerifyModifiedCronJob(GetCronJob(ctx, k8sClient, namespace, cronJobName), BasicInstrumentedPodSpecExpectations())
+				VerifyOwnerInstrumentedEvent(
+					ctx,
+					clientset,
+					namespace,
+					name,
+					fmt.Sprintf("This workload is immutable and cannot be instrumented by the controller. Its owner, "+
+						"the cron job %s, has been instrumented instead, workloads it creates from now on will send "+
+						"telemetry to Dash0.", cronJobName),
+				)
+				VerifyImmutableJobCouldNotBeModified(GetJob(ctx, k8sClient, namespace, name))
+			})
+
+			It("should record a failure event for an existing job if the owning cron job has opted out", func() {
+				cronJobName := UniqueName(CronJobNamePrefix)
+				cronJob := CreateCronJobWithOptOutLabel(ctx, k8sClient, namespace, cronJobName)
+				createdObjects = append(createdObjects, cronJob)
+				name := UniqueName(JobNamePrefix)
+				job := CreateJobOwnedByCronJob(ctx, k8sClient, namespace, name, cronJobName)
+				createdObjects = append(createdObjects, job)
+
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
+
+				VerifyCronJobWithOptOutLabel(GetCronJob(ctx, k8sClient, namespace, cronJobName))
+				VerifyFailedInstrumentationEvent(
+					ctx,
+					clientset,
+					namespace,
+					name,
+					fmt.Sprintf("Dash0 instrumentation of this workload by the controller has not been successful. Error message: "+
+						"Dash0 cannot instrument the existing job test-namespace/%s, since this type of workload "+
+						"is immutable.", name),
+				)
+			})
+
 			It("should not instrument an existing ownerless pod", func() {
 				name := UniqueName(PodNamePrefix)
 				By("Inititalize a pod")
@@ -167,10 +216,69 @@ var _ = Describe("The instrumenter", Ordered, func() {
 				replicaSet := CreateReplicaSetOwnedByDeployment(ctx, k8sClient, namespace, name)
 				createdObjects = append(createdObjects, replicaSet)
 
-				//expect(Instrumenter.CheckSettingsAndInstrumentExistingWorkloads(ctx, dash0MonitoringResource, &logger); err != nil {
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
 
+				VerifyOwnedByHigherOrderWorkloadEvent(ctx, clientset, namespace, name, "Deployment", "deployment", "controller")
 				VerifyUnmodifiedReplicaSet(GetReplicaSet(ctx, k8sClient, namespace, name))
 			})
+
+			It("should not instrument existing workloads of a kind that is excluded in the operator configuration", func() {
+				operatorConfigurationSpec := *OperatorConfigurationResourceDefaultSpec.DeepCopy()
+				operatorConfigurationSpec.ExcludedWorkloadKinds = []dash0v1alpha1.WorkloadKind{
+					dash0v1alpha1.WorkloadKindStatefulSet,
+				}
+				CreateOperatorConfigurationResourceWithSpec(ctx, k8sClient, operatorConfigurationSpec)
+				defer DeleteAllOperatorConfigurationResources(ctx, k8sClient)
+
+				statefulSetName := UniqueName(StatefulSetNamePrefix)
+				statefulSet := CreateBasicStatefulSet(ctx, k8sClient, namespace, statefulSetName)
+				createdObjects = append(createdObjects, statefulSet)
+				deploymentName := UniqueName(DeploymentNamePrefix)
+				deployment := CreateBasicDeployment(ctx, k8sClient, namespace, deploymentName)
+				createdObjects = append(createdObjects, deployment)
+
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
+
+				VerifyUnmodifiedStatefulSet(GetStatefulSet(ctx, k8sClient, namespace, statefulSetName))
+				VerifyModifiedDeployment(
+					GetDeployment(ctx, k8sClient, namespace, deploymentName),
+					BasicInstrumentedPodSpecExpectations(),
+				)
+				VerifySuccessfulInstrumentationEvent(ctx, clientset, namespace, deploymentName, "controller")
+			})
+
+			It("should not instrument existing workloads in a namespace that has opted out of instrumentation", func() {
+				SetNamespaceOptOutLabel(ctx, k8sClient, namespace, true)
+				defer SetNamespaceOptOutLabel(ctx, k8sClient, namespace, false)
+
+				name := UniqueName(DeploymentNamePrefix)
+				deployment := CreateBasicDeployment(ctx, k8sClient, namespace, name)
+				createdObjects = append(createdObjects, deployment)
+
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
+
+				VerifyUnmodifiedDeployment(GetDeployment(ctx, k8sClient, namespace, name))
+				VerifyNoEvents(ctx, clientset, namespace)
+			})
+
+			It("should write an audit record when instrumenting an existing workload", func() {
+				var auditLogBuffer bytes.Buffer
+				instrumenter.AuditLog = util.NewInstrumentationAuditLog(&auditLogBuffer)
+				name := UniqueName(DeploymentNamePrefix)
+				deployment := CreateBasicDeployment(ctx, k8sClient, namespace, name)
+				createdObjects = append(createdObjects, deployment)
+
+				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
+
+				var auditRecord util.InstrumentationAuditRecord
+				Expect(json.Unmarshal(auditLogBuffer.Bytes(), &auditRecord)).To(Succeed())
+				Expect(auditRecord.Action).To(Equal(util.ModificationModeInstrumentation))
+				Expect(auditRecord.Reason).To(Equal(util.ReasonSuccessfulInstrumentation))
+				Expect(auditRecord.InstrumentedBy).To(Equal("controller"))
+				Expect(auditRecord.Kind).To(Equal("Deployment"))
+				Expect(auditRecord.Namespace).To(Equal(namespace))
+				Expect(auditRecord.Name).To(Equal(name))
+			})
 		})
 
 		DescribeTable("when existing workloads have the opt-out label", func(config WorkloadTestConfig) {
@@ -180,7 +288,7 @@ var _ = Describe("The instrumenter", Ordered, func() {
 
 			checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
 
-			VerifyNoEvents(ctx, clientset, namespace)
+			VerifyOptedOutOfInstrumentationEvent(ctx, clientset, namespace, name, "controller")
 			config.VerifyFn(config.GetFn(ctx, k8sClient, namespace, name))
 		}, Entry("should not instrument an existing cron job with the opt-out label", WorkloadTestConfig{
 			WorkloadNamePrefix: CronJobNamePrefix,
@@ -228,7 +336,7 @@ var _ = Describe("The instrumenter", Ordered, func() {
 
 				checkSettingsAndInstrumentExistingWorkloads(ctx, instrumenter, dash0MonitoringResource, &logger)
 
-				VerifyNoEvents(ctx, clientset, namespace)
+				VerifyOptedOutOfInstrumentationEvent(ctx, clientset, namespace, name, "controller")
 				VerifyJobWithOptOutLabel(GetJob(ctx, k8sClient, namespace, name))
 			})
 		})
@@ -625,7 +733,7 @@ var _ = Describe("The instrumenter", Ordered, func() {
 		instrumenter.InstrumentAtStartup(ctx, k8sClient, &logger)
 
 		config.VerifyFn(config.GetFn(ctx, k8sClient, TestNamespaceName, name))
-		VerifySuccessfulInstrumentationEvent(ctx, clientset, namespace, name, "controller")
+		VerifyReinstrumentedAfterUpgradeEvent(ctx, clientset, namespace, name, "controller")
 	}, Entry("should override outdated instrumentation settings for a cron job at startup", WorkloadTestConfig{
 		WorkloadNamePrefix: CronJobNamePrefix,
 		CreateFn:           WrapCronJobFnAsTestableWorkload(CreateInstrumentedCronJob),
@@ -685,6 +793,66 @@ var _ = Describe("The instrumenter", Ordered, func() {
 	})
 })
 
+var _ = Describe("Listing workloads in pages", func() {
+	ctx := context.Background()
+
+	It("should request all pages and process all items", func() {
+		pages := map[string]*appsv1.DeploymentList{
+			"": {
+				ListMeta: metav1.ListMeta{Continue: "page-2"},
+				Items:    []appsv1.Deployment{deploymentWithName("d1"), deploymentWithName("d2")},
+			},
+			"page-2": {
+				ListMeta: metav1.ListMeta{Continue: "page-3"},
+				Items:    []appsv1.Deployment{deploymentWithName("d3")},
+			},
+			"page-3": {
+				Items: []appsv1.Deployment{deploymentWithName("d4")},
+			},
+		}
+		var requestedLimits []int64
+		var processed []string
+		Expect(listAndProcessInPages(
+			ctx,
+			func(_ context.Context, listOptions metav1.ListOptions) (*appsv1.DeploymentList, error) {
+				requestedLimits = append(requestedLimits, listOptions.Limit)
+				return pages[listOptions.Continue], nil
+			},
+			metav1.ListOptions{},
+			func(list *appsv1.DeploymentList) []appsv1.Deployment { return list.Items },
+			func(deployment appsv1.Deployment) { processed = append(processed, deployment.Name) },
+		)).To(Succeed())
+
+		Expect(processed).To(Equal([]string{"d1", "d2", "d3", "d4"}))
+		Expect(requestedLimits).To(Equal([]int64{workloadListPageSize, workloadListPageSize, workloadListPageSize}))
+	})
+
+	It("should stop at the first error", func() {
+		var processed int
+		err := listAndProcessInPages(
+			ctx,
+			func(_ context.Context, listOptions metav1.ListOptions) (*appsv1.DeploymentList, error) {
+				if listOptions.Continue != "" {
+					return nil, fmt.Errorf("the continue token has expired")
+				}
+				return &appsv1.DeploymentList{
+					ListMeta: metav1.ListMeta{Continue: "page-2"},
+					Items:    []appsv1.Deployment{deploymentWithName("d1")},
+				}, nil
+			},
+			metav1.ListOptions{},
+			func(list *appsv1.DeploymentList) []appsv1.Deployment { return list.Items },
+			func(appsv1.Deployment) { processed++ },
+		)
+		Expect(err).To(MatchError("the continue token has expired"))
+		Expect(processed).To(Equal(1))
+	})
+})
+
+func deploymentWithName(name string) appsv1.Deployment {
+	return appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
 func checkSettingsAndInstrumentExistingWorkloads(
 	ctx context.Context,
 	instrumenter *Instrumenter,
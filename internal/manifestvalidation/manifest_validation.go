@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestvalidation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
+	"github.com/dash0hq/dash0-operator/internal/webhooks"
+)
+
+const (
+	kindMonitoring            = "Dash0Monitoring"
+	kindOperatorConfiguration = "Dash0OperatorConfiguration"
+)
+
+// Result is the outcome of validating one Dash0 resource from a manifest.
+type Result struct {
+	// Document is the index of the YAML document in the manifest that contains the resource, starting at 0.
+	Document int
+	Kind     string
+	Name     string
+	// Problems lists the reasons why the resource is invalid, it is empty if the resource is valid.
+	Problems []string
+}
+
+func (r Result) IsValid() bool {
+	return len(r.Problems) == 0
+}
+
+// ValidateManifest validates all Dash0 monitoring resources and Dash0 operator configuration resources in the given
+// manifest, which can consist of multiple YAML documents. Documents with other kinds of resources are ignored. The
+// resources are validated with the checks of the validation webhooks that do not require access to the cluster, and
+// their export settings are checked in the same way as when the operator creates the OpenTelemetry collector resources
+// for them. Unknown fields are reported as problems as well, since the Kubernetes API server would drop them silently.
+// An error is only returned if the manifest cannot be read or is not valid YAML.
+func ValidateManifest(manifest io.Reader) ([]Result, error) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(manifest))
+	var results []Result
+	for document := 0; ; document++ {
+		content, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("cannot read YAML document %d: %w", document, err)
+		}
+		result, isDash0Resource, err := validateDocument(content)
+		if err != nil {
+			return results, fmt.Errorf("cannot parse YAML document %d: %w", document, err)
+		}
+		if isDash0Resource {
+			result.Document = document
+			results = append(results, result)
+		}
+	}
+}
+
+func validateDocument(content []byte) (Result, bool, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return Result{}, false, nil
+	}
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(content, &typeMeta); err != nil {
+		return Result{}, false, err
+	}
+	if typeMeta.APIVersion != dash0v1alpha1.GroupVersion.String() {
+		return Result{}, false, nil
+	}
+
+	switch typeMeta.Kind {
+	case kindMonitoring:
+		monitoringResource := &dash0v1alpha1.Dash0Monitoring{}
+		if err := yaml.UnmarshalStrict(content, monitoringResource); err != nil {
+			return decodingFailed(typeMeta.Kind, content, err), true, nil
+		}
+		return Result{
+			Kind:     typeMeta.Kind,
+			Name:     monitoringResource.Name,
+			Problems: validateMonitoringResource(monitoringResource),
+		}, true, nil
+	case kindOperatorConfiguration:
+		operatorConfigurationResource := &dash0v1alpha1.Dash0OperatorConfiguration{}
+		if err := yaml.UnmarshalStrict(content, operatorConfigurationResource); err != nil {
+			return decodingFailed(typeMeta.Kind, content, err), true, nil
+		}
+		return Result{
+			Kind:     typeMeta.Kind,
+			Name:     operatorConfigurationResource.Name,
+			Problems: validateOperatorConfigurationResource(operatorConfigurationResource),
+		}, true, nil
+	default:
+		return Result{}, false, nil
+	}
+}
+
+// decodingFailed reports a resource that does not match the API types, for example because of an unknown field or a
+// value of the wrong type. The name is read separately, since the resource itself could not be decoded.
+func decodingFailed(kind string, content []byte, err error) Result {
+	var objectMeta struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}
+	_ = yaml.Unmarshal(content, &objectMeta)
+	return Result{
+		Kind:     kind,
+		Name:     objectMeta.Metadata.Name,
+		Problems: []string{fmt.Sprintf("The resource does not match the %s schema: %v", kind, err)},
+	}
+}
+
+func validateMonitoringResource(monitoringResource *dash0v1alpha1.Dash0Monitoring) []string {
+	var problems []string
+	if validationErr := webhooks.ValidateMonitoringResource(monitoringResource); validationErr != "" {
+		problems = append(problems, validationErr)
+	}
+	problems = appendExportSettingsProblem(problems, monitoringResource.Spec.Export, "spec.export")
+	return problems
+}
+
+func validateOperatorConfigurationResource(
+	operatorConfigurationResource *dash0v1alpha1.Dash0OperatorConfiguration,
+) []string {
+	var problems []string
+	if validationErr :=
+		webhooks.ValidateOperatorConfigurationResource(operatorConfigurationResource); validationErr != "" {
+		problems = append(problems, validationErr)
+	}
+	spec := operatorConfigurationResource.Spec
+	problems = appendExportSettingsProblem(problems, spec.Export, "spec.export")
+	problems = appendExportSettingsProblem(problems, spec.SelfMonitoring.Export, "spec.selfMonitoring.export")
+	return problems
+}
+
+func appendExportSettingsProblem(problems []string, export *dash0v1alpha1.Export, path string) []string {
+	if export == nil {
+		return problems
+	}
+	if err := otelcolresources.ValidateExportSettings(*export); err != nil {
+		return append(problems, fmt.Sprintf("The export settings in %s are invalid: %v.", path, err))
+	}
+	return problems
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestvalidation
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	validMonitoringResource = `apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0Monitoring
+metadata:
+  name: dash0-monitoring-resource
+  namespace: test-namespace
+spec:
+  export:
+    dash0:
+      endpoint: ingress.dash0.com:4317
+      authorization:
+        token: dash0-token
+`
+	validOperatorConfigurationResource = `apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0OperatorConfiguration
+metadata:
+  name: dash0-operator-configuration
+spec:
+  export:
+    dash0:
+      endpoint: ingress.dash0.com:4317
+      authorization:
+        token: dash0-token
+`
+)
+
+var _ = Describe("Validating manifests", func() {
+
+	It("should accept valid Dash0 resources", func() {
+		results, err := ValidateManifest(strings.NewReader(
+			validMonitoringResource + "---\n" + validOperatorConfigurationResource))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(Equal([]Result{
+			{Document: 0, Kind: "Dash0Monitoring", Name: "dash0-monitoring-resource"},
+			{Document: 1, Kind: "Dash0OperatorConfiguration", Name: "dash0-operator-configuration"},
+		}))
+	})
+
+	It("should ignore other resources and empty documents", func() {
+		results, err := ValidateManifest(strings.NewReader(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: some-config-map
+data:
+  unknown: field
+---
+# only a comment
+---
+` + validMonitoringResource))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Document).To(Equal(2))
+		Expect(results[0].IsValid()).To(BeTrue())
+	})
+
+	It("should report invalid export settings", func() {
+		results, err := ValidateManifest(strings.NewReader(`apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0Monitoring
+metadata:
+  name: dash0-monitoring-resource
+spec:
+  export:
+    http:
+      endpoint: https://otlp.example.com
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Problems).To(ConsistOf(
+			ContainSubstring("The export settings in spec.export are invalid: no encoding provided for the HTTP exporter"),
+		))
+	})
+
+	It("should report unknown fields", func() {
+		results, err := ValidateManifest(strings.NewReader(`apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0Monitoring
+metadata:
+  name: dash0-monitoring-resource
+spec:
+  export:
+    dash0:
+      endpiont: ingress.dash0.com:4317
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Name).To(Equal("dash0-monitoring-resource"))
+		Expect(results[0].Problems).To(ConsistOf(ContainSubstring("unknown field \"endpiont\"")))
+	})
+
+	It("should report the problems found by the validation webhooks", func() {
+		results, err := ValidateManifest(strings.NewReader(`apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0Monitoring
+metadata:
+  name: dash0-monitoring-resource
+spec:
+  transform:
+    logs:
+    - " "
+---
+apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0OperatorConfiguration
+metadata:
+  name: dash0-operator-configuration
+spec:
+  selfMonitoring:
+    enabled: true
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Problems).To(ConsistOf(ContainSubstring("empty statement at index 0 in spec.transform.logs")))
+		Expect(results[1].Problems).To(ConsistOf(
+			ContainSubstring("has self-monitoring enabled, but it does not have an export configuration"),
+		))
+	})
+
+	It("should report a monitoring resource that references a secret in a different namespace", func() {
+		results, err := ValidateManifest(strings.NewReader(`apiVersion: operator.dash0.com/v1alpha1
+kind: Dash0Monitoring
+metadata:
+  name: dash0-monitoring-resource
+  namespace: test-namespace
+spec:
+  export:
+    dash0:
+      endpoint: ingress.dash0.com:4317
+      authorization:
+        secretRef:
+          name: database-credentials
+          key: password
+          namespace: other-team
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Problems).To(ConsistOf(
+			ContainSubstring("can only reference secrets in its own namespace (test-namespace)"),
+		))
+	})
+
+	It("should validate the dedicated self-monitoring export", func() {
+		results, err := ValidateManifest(strings.NewReader(validOperatorConfigurationResource + `  selfMonitoring:
+    export:
+      grpc:
+        endpoint: ""
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Problems).To(ConsistOf(
+			ContainSubstring("The export settings in spec.selfMonitoring.export are invalid"),
+		))
+	})
+
+	It("should return an error for a manifest that is not valid YAML", func() {
+		_, err := ValidateManifest(strings.NewReader("apiVersion: [operator.dash0.com/v1alpha1\n"))
+		Expect(err).To(MatchError(ContainSubstring("cannot parse YAML document 0")))
+	})
+})
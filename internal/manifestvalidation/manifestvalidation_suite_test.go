@@ -0,0 +1,16 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package manifestvalidation
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestManifestValidation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Manifest Validation Suite")
+}
@@ -110,6 +110,7 @@ func (r *OperatorPreDeleteHandler) findAllAndRequestDeletion(ctx context.Context
 
 	for _, dash0MonitoringResource := range allDash0MonitoringResources.Items {
 		namespace := dash0MonitoringResource.Namespace
+		r.forceImmediateUninstrumentation(ctx, &dash0MonitoringResource)
 		// You would think that the following call without the "client.InNamespace(namespace)" would delete all
 		// resources across all namespaces in one go, but instead it fails with "the server could not find the requested
 		// resource". Same for the dynamic client.
@@ -126,6 +127,34 @@ func (r *OperatorPreDeleteHandler) findAllAndRequestDeletion(ctx context.Context
 	return len(allDash0MonitoringResources.Items), nil
 }
 
+// forceImmediateUninstrumentation switches the uninstrumentation policy of the given Dash0 monitoring resource from
+// lazy to immediate. When the operator is uninstalled, the lazy policy would leave the instrumentation in place after
+// the operator and its webhook are gone, and nothing would ever remove it. Hence, the instrumentation is always removed
+// immediately when the monitoring resources are deleted by the pre-delete handler, regardless of the configured
+// policy.
+func (r *OperatorPreDeleteHandler) forceImmediateUninstrumentation(
+	ctx context.Context,
+	dash0MonitoringResource *dash0v1alpha1.Dash0Monitoring,
+) {
+	if dash0MonitoringResource.ReadUninstrumentationPolicy() != dash0v1alpha1.Lazy {
+		return
+	}
+	namespace := dash0MonitoringResource.Namespace
+	patch := client.MergeFrom(dash0MonitoringResource.DeepCopy())
+	dash0MonitoringResource.Spec.UninstrumentationPolicy = dash0v1alpha1.Immediate
+	if err := r.client.Patch(ctx, dash0MonitoringResource, patch); err != nil {
+		r.logger.Error(err, fmt.Sprintf(
+			"Failed to switch the uninstrumentation policy of the Dash0 monitoring resource in namespace %s to "+
+				"immediate, the instrumentation might not be removed from all workloads in this namespace.",
+			namespace))
+	} else {
+		r.logger.Info(fmt.Sprintf(
+			"Switched the uninstrumentation policy of the Dash0 monitoring resource in namespace %s from lazy to "+
+				"immediate, since the operator is being uninstalled.",
+			namespace))
+	}
+}
+
 func (r *OperatorPreDeleteHandler) waitForAllDash0MonitoringResourcesToBeFinalizedAndDeleted(
 	ctx context.Context,
 	totalNumberOfDash0MonitoringResources int,
@@ -13,7 +13,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
 	"github.com/dash0hq/dash0-operator/internal/controller"
+	"github.com/dash0hq/dash0-operator/internal/util"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -131,6 +133,41 @@ var _ = Describe("Uninstalling the Dash0 Kubernetes operator", Ordered, func() {
 			VerifyWebhookIgnoreOnceLabelIsPresentEventually(g, &deployment2.ObjectMeta)
 		}, testTimeout, pollingInterval).Should(Succeed())
 	})
+
+	It("should uninstrument workloads immediately even if the uninstrumentation policy is lazy", func() {
+		monitoringResource := LoadMonitoringResourceByNameOrFail(ctx, k8sClient, Default, dash0MonitoringResourceName1)
+		monitoringResource.Spec.UninstrumentationPolicy = dash0v1alpha1.Lazy
+		Expect(k8sClient.Update(ctx, monitoringResource)).To(Succeed())
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(preDeleteHandler.DeleteAllMonitoringResources()).To(Succeed())
+		}()
+
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(500 * time.Millisecond)
+			triggerReconcileRequestForName(
+				ctx,
+				reconciler,
+				dash0MonitoringResourceName1,
+			)
+			triggerReconcileRequestForName(
+				ctx,
+				reconciler,
+				dash0MonitoringResourceName2,
+			)
+		}()
+
+		Eventually(func(g Gomega) {
+			VerifyMonitoringResourceByNameDoesNotExist(ctx, k8sClient, g, dash0MonitoringResourceName1)
+
+			VerifySuccessfulUninstrumentationEventEventually(ctx, clientset, g, deployment1.Namespace, deployment1.Name, "controller")
+			deployment1 := GetDeploymentEventually(ctx, k8sClient, g, deployment1.Namespace, deployment1.Name)
+			VerifyUnmodifiedDeploymentEventually(g, deployment1)
+			g.Expect(util.IsUninstrumentationPending(&deployment1.ObjectMeta)).To(BeFalse())
+		}, testTimeout, pollingInterval).Should(Succeed())
+	})
 })
 
 func setupNamespaceWithDash0MonitoringResourceAndWorkload(
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusexport
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var (
+	invalidMetricNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelNameCharacters  = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// Collector exposes the metrics recorded with the OpenTelemetry meter provider of the operator in the Prometheus
+// format. It reads the current values via an OpenTelemetry manual reader whenever it is scraped, so the same
+// instruments that are exported via OTLP for self-monitoring can also be scraped from the /metrics endpoint of the
+// operator manager.
+type Collector struct {
+	reader *sdkmetric.ManualReader
+}
+
+// NewCollector creates a Collector. The returned reader needs to be registered with the OpenTelemetry meter provider,
+// the collector needs to be registered with a Prometheus registry.
+func NewCollector() (*Collector, sdkmetric.Reader) {
+	reader := sdkmetric.NewManualReader()
+	return &Collector{reader: reader}, reader
+}
+
+// Describe implements prometheus.Collector. It does not send any descriptors, which makes this an unchecked
+// collector, since the set of metrics is only known once the instruments have been created.
+func (c *Collector) Describe(chan<- *prometheus.Desc) {
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
+	resourceMetrics := metricdata.ResourceMetrics{}
+	if err := c.reader.Collect(context.Background(), &resourceMetrics); err != nil {
+		return
+	}
+	for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+		for _, metric := range scopeMetrics.Metrics {
+			convertMetric(metric, metrics)
+		}
+	}
+}
+
+func convertMetric(metric metricdata.Metrics, metrics chan<- prometheus.Metric) {
+	name := sanitizeName(metric.Name, invalidMetricNameCharacters)
+	switch data := metric.Data.(type) {
+	case metricdata.Sum[int64]:
+		convertSum(name, metric.Description, data, metrics)
+	case metricdata.Sum[float64]:
+		convertSum(name, metric.Description, data, metrics)
+	case metricdata.Gauge[int64]:
+		convertGauge(name, metric.Description, data, metrics)
+	case metricdata.Gauge[float64]:
+		convertGauge(name, metric.Description, data, metrics)
+	case metricdata.Histogram[int64]:
+		convertHistogram(name, metric.Description, data, metrics)
+	case metricdata.Histogram[float64]:
+		convertHistogram(name, metric.Description, data, metrics)
+	}
+}
+
+func convertSum[N int64 | float64](
+	name string,
+	description string,
+	sum metricdata.Sum[N],
+	metrics chan<- prometheus.Metric,
+) {
+	valueType := prometheus.GaugeValue
+	if sum.IsMonotonic {
+		valueType = prometheus.CounterValue
+		name += "_total"
+	}
+	labelNames := collectLabelNames(sum.DataPoints, func(dataPoint *metricdata.DataPoint[N]) *attribute.Set {
+		return &dataPoint.Attributes
+	})
+	desc := prometheus.NewDesc(name, description, labelNames, nil)
+	for _, dataPoint := range sum.DataPoints {
+		metric, err := prometheus.NewConstMetric(
+			desc,
+			valueType,
+			float64(dataPoint.Value),
+			labelValues(labelNames, &dataPoint.Attributes)...,
+		)
+		if err == nil {
+			metrics <- metric
+		}
+	}
+}
+
+func convertGauge[N int64 | float64](
+	name string,
+	description string,
+	gauge metricdata.Gauge[N],
+	metrics chan<- prometheus.Metric,
+) {
+	labelNames := collectLabelNames(gauge.DataPoints, func(dataPoint *metricdata.DataPoint[N]) *attribute.Set {
+		return &dataPoint.Attributes
+	})
+	desc := prometheus.NewDesc(name, description, labelNames, nil)
+	for _, dataPoint := range gauge.DataPoints {
+		metric, err := prometheus.NewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			float64(dataPoint.Value),
+			labelValues(labelNames, &dataPoint.Attributes)...,
+		)
+		if err == nil {
+			metrics <- metric
+		}
+	}
+}
+
+func convertHistogram[N int64 | float64](
+	name string,
+	description string,
+	histogram metricdata.Histogram[N],
+	metrics chan<- prometheus.Metric,
+) {
+	labelNames := collectLabelNames(
+		histogram.DataPoints,
+		func(dataPoint *metricdata.HistogramDataPoint[N]) *attribute.Set {
+			return &dataPoint.Attributes
+		},
+	)
+	desc := prometheus.NewDesc(name, description, labelNames, nil)
+	for _, dataPoint := range histogram.DataPoints {
+		// OpenTelemetry bucket counts are per bucket, Prometheus buckets are cumulative.
+		buckets := make(map[float64]uint64, len(dataPoint.Bounds))
+		var cumulativeCount uint64
+		for i, bound := range dataPoint.Bounds {
+			cumulativeCount += dataPoint.BucketCounts[i]
+			buckets[bound] = cumulativeCount
+		}
+		metric, err := prometheus.NewConstHistogram(
+			desc,
+			dataPoint.Count,
+			float64(dataPoint.Sum),
+			buckets,
+			labelValues(labelNames, &dataPoint.Attributes)...,
+		)
+		if err == nil {
+			metrics <- metric
+		}
+	}
+}
+
+// collectLabelNames returns the union of the attribute keys of all data points, since all metrics in a Prometheus
+// metric family need to have the same label names.
+func collectLabelNames[D any](dataPoints []D, attributes func(*D) *attribute.Set) []string {
+	var labelNames []string
+	for i := range dataPoints {
+		for _, keyValue := range attributes(&dataPoints[i]).ToSlice() {
+			labelName := sanitizeName(string(keyValue.Key), invalidLabelNameCharacters)
+			if !slices.Contains(labelNames, labelName) {
+				labelNames = append(labelNames, labelName)
+			}
+		}
+	}
+	slices.Sort(labelNames)
+	return labelNames
+}
+
+func labelValues(labelNames []string, attributes *attribute.Set) []string {
+	values := make([]string, len(labelNames))
+	for _, keyValue := range attributes.ToSlice() {
+		if i := slices.Index(labelNames, sanitizeName(string(keyValue.Key), invalidLabelNameCharacters)); i >= 0 {
+			values[i] = keyValue.Value.Emit()
+		}
+	}
+	return values
+}
+
+// sanitizeName converts an OpenTelemetry metric name or attribute key (e.g. dash0.operator.manager.reconcile_requests)
+// into a valid Prometheus metric or label name (e.g. dash0_operator_manager_reconcile_requests).
+func sanitizeName(name string, invalidCharacters *regexp.Regexp) string {
+	sanitized := invalidCharacters.ReplaceAllString(name, "_")
+	if sanitized != "" && sanitized[0] >= '0' && sanitized[0] <= '9' {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusexport
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The Prometheus export of the operator's metrics", func() {
+	ctx := context.Background()
+
+	var meter otelmetric.Meter
+	var registry *prometheus.Registry
+
+	BeforeEach(func() {
+		collector, reader := NewCollector()
+		meter = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("dash0.operator.manager")
+		registry = prometheus.NewRegistry()
+		Expect(registry.Register(collector)).To(Succeed())
+	})
+
+	It("should expose counters with sanitized names and labels", func() {
+		counter, err := meter.Int64Counter("dash0.operator.manager.monitoring.reconcile_requests")
+		Expect(err).ToNot(HaveOccurred())
+		counter.Add(ctx, 2)
+		counter.Add(ctx, 3, otelmetric.WithAttributes(attribute.String("dash0.resource.kind", "dashboard")))
+
+		metricFamily := gatherMetricFamily(registry, "dash0_operator_manager_monitoring_reconcile_requests_total")
+		Expect(metricFamily.GetType()).To(Equal(dto.MetricType_COUNTER))
+		Expect(metricFamily.GetMetric()).To(HaveLen(2))
+		values := map[string]float64{}
+		for _, metric := range metricFamily.GetMetric() {
+			Expect(metric.GetLabel()).To(HaveLen(1))
+			Expect(metric.GetLabel()[0].GetName()).To(Equal("dash0_resource_kind"))
+			values[metric.GetLabel()[0].GetValue()] = metric.GetCounter().GetValue()
+		}
+		Expect(values).To(Equal(map[string]float64{"": 2, "dashboard": 3}))
+	})
+
+	It("should expose up-down counters as gauges", func() {
+		upDownCounter, err := meter.Int64UpDownCounter("dash0.operator.manager.in_flight")
+		Expect(err).ToNot(HaveOccurred())
+		upDownCounter.Add(ctx, 5)
+		upDownCounter.Add(ctx, -2)
+
+		metricFamily := gatherMetricFamily(registry, "dash0_operator_manager_in_flight")
+		Expect(metricFamily.GetType()).To(Equal(dto.MetricType_GAUGE))
+		Expect(metricFamily.GetMetric()[0].GetGauge().GetValue()).To(Equal(float64(3)))
+	})
+
+	It("should expose histograms with cumulative buckets", func() {
+		histogram, err := meter.Float64Histogram(
+			"dash0.operator.manager.api_request.duration",
+			otelmetric.WithExplicitBucketBoundaries(1, 10),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		histogram.Record(ctx, 0.5)
+		histogram.Record(ctx, 5)
+		histogram.Record(ctx, 50)
+
+		metricFamily := gatherMetricFamily(registry, "dash0_operator_manager_api_request_duration")
+		Expect(metricFamily.GetType()).To(Equal(dto.MetricType_HISTOGRAM))
+		prometheusHistogram := metricFamily.GetMetric()[0].GetHistogram()
+		Expect(prometheusHistogram.GetSampleCount()).To(Equal(uint64(3)))
+		Expect(prometheusHistogram.GetSampleSum()).To(Equal(55.5))
+		Expect(prometheusHistogram.GetBucket()).To(HaveLen(2))
+		Expect(prometheusHistogram.GetBucket()[0].GetCumulativeCount()).To(Equal(uint64(1)))
+		Expect(prometheusHistogram.GetBucket()[1].GetCumulativeCount()).To(Equal(uint64(2)))
+	})
+})
+
+func gatherMetricFamily(registry *prometheus.Registry, name string) *dto.MetricFamily {
+	metricFamilies, err := registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() == name {
+			return metricFamily
+		}
+	}
+	Fail("metric family " + name + " not found")
+	return nil
+}
@@ -63,6 +63,11 @@ func ConvertOperatorConfigurationResourceToSelfMonitoringConfiguration(
 		return SelfMonitoringAndApiAccessConfiguration{}, nil
 	}
 
+	if util.ReadBoolPointerWithDefault(resource.Spec.SelfMonitoring.Enabled, true) &&
+		resource.Spec.SelfMonitoring.Export != nil {
+		return convertDedicatedSelfMonitoringExport(resource.Spec.SelfMonitoring.Export, resource.Spec.Export, logger)
+	}
+
 	export := resource.Spec.Export
 	if export == nil {
 		logger.Info("Invalid configuration of Dash0OperatorConfiguration resource: Self-monitoring is enabled but no " +
@@ -94,6 +99,41 @@ func ConvertOperatorConfigurationResourceToSelfMonitoringConfiguration(
 		fmt.Errorf("no export configuration for self-monitoring has been provided, no self-monitoring telemetry will be sent")
 }
 
+// convertDedicatedSelfMonitoringExport creates the self-monitoring configuration for a dedicated self-monitoring export
+// (spec.selfMonitoring.export). The Dash0 API is still accessed with the API endpoint of the regular export; the
+// validation webhook makes sure that both use the same authorization, since the operator manager only has one auth
+// token for self-monitoring and API access.
+func convertDedicatedSelfMonitoringExport(
+	selfMonitoringExport *dash0v1alpha1.Export,
+	export *dash0v1alpha1.Export,
+	logger *logr.Logger,
+) (SelfMonitoringAndApiAccessConfiguration, error) {
+	if selfMonitoringExport.Dash0 != nil {
+		selfMonitoringConfiguration, err := convertResourceToDash0ExportConfiguration(selfMonitoringExport, true, logger)
+		if err != nil {
+			return SelfMonitoringAndApiAccessConfiguration{}, err
+		}
+		dash0Export := selfMonitoringConfiguration.Export.Dash0
+		if selfMonitoringExport.Dash0.Dataset != "" {
+			dash0Export.Dataset = selfMonitoringExport.Dash0.Dataset
+		}
+		dash0Export.ApiEndpoint = ""
+		if export != nil && export.Dash0 != nil {
+			dash0Export.ApiEndpoint = export.Dash0.ApiEndpoint
+		}
+		return selfMonitoringConfiguration, nil
+	}
+	if selfMonitoringExport.Grpc != nil {
+		return convertResourceToGrpcExportConfiguration(selfMonitoringExport, true, logger)
+	}
+	if selfMonitoringExport.Http != nil {
+		return convertResourceToHttpExportConfiguration(selfMonitoringExport, true)
+	}
+	return SelfMonitoringAndApiAccessConfiguration{},
+		fmt.Errorf("the dedicated self-monitoring export configuration does not define any exporter, no " +
+			"self-monitoring telemetry will be sent")
+}
+
 func convertResourceToDash0ExportConfiguration(
 	export *dash0v1alpha1.Export,
 	selfMonitoringEnabled bool,
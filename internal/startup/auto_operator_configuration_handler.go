@@ -33,6 +33,8 @@ type OperatorConfigurationValues struct {
 	ApiEndpoint                                      string
 	SelfMonitoringEnabled                            bool
 	KubernetesInfrastructureMetricsCollectionEnabled bool
+	KubernetesEventsCollectionEnabled                bool
+	ClusterName                                      string
 }
 
 type AutoOperatorConfigurationResourceHandler struct {
@@ -232,6 +234,8 @@ func (r *AutoOperatorConfigurationResourceHandler) createOperatorConfigurationRe
 			},
 			Export: &dash0Export,
 			KubernetesInfrastructureMetricsCollectionEnabled: ptr.To(operatorConfiguration.KubernetesInfrastructureMetricsCollectionEnabled),
+			KubernetesEventsCollectionEnabled:                ptr.To(operatorConfiguration.KubernetesEventsCollectionEnabled),
+			ClusterName:                                      operatorConfiguration.ClusterName,
 		},
 	}
 	if err := r.Create(ctx, &operatorConfigurationResource); err != nil {
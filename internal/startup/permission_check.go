@@ -0,0 +1,262 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package startup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+// PermissionChecker verifies once at startup that the operator's service account holds the key permissions the operator
+// needs, by issuing a SelfSubjectAccessReview for each of them. Missing permissions are logged as one consolidated
+// report, and the result is recorded as the PermissionsSufficient condition on the Dash0 operator configuration
+// resource. It implements manager.Runnable.
+type PermissionChecker struct {
+	Client            client.Client
+	Clientset         kubernetes.Interface
+	OperatorNamespace string
+
+	// conditionRetryInterval and conditionRetryTimeout control how long the checker waits for an operator
+	// configuration resource to appear, which might be created asynchronously at startup.
+	conditionRetryInterval time.Duration
+	conditionRetryTimeout  time.Duration
+}
+
+type requiredPermission struct {
+	group       string
+	resource    string
+	subresource string
+	verb        string
+	// namespaced permissions are checked in the operator namespace, all others cluster-wide.
+	namespaced bool
+	purpose    string
+}
+
+const (
+	defaultConditionRetryInterval = 5 * time.Second
+	defaultConditionRetryTimeout  = 2 * time.Minute
+
+	permissionsSufficientReason   = "AllPermissionsGranted"
+	permissionsInsufficientReason = "PermissionsMissing"
+	permissionCheckFailedReason   = "PermissionCheckFailed"
+)
+
+var requiredPermissions = []requiredPermission{
+	{group: "apiextensions.k8s.io", resource: "customresourcedefinitions", verb: "list",
+		purpose: "detecting third-party CRDs (Perses dashboards, Prometheus rules)"},
+	{group: "", resource: "namespaces", verb: "get",
+		purpose: "reading the kube-system namespace to determine the cluster ID"},
+	{group: "operator.dash0.com", resource: "dash0monitorings", verb: "list",
+		purpose: "watching Dash0 monitoring resources"},
+	{group: "operator.dash0.com", resource: "dash0monitorings", subresource: "status", verb: "update",
+		purpose: "updating the status of Dash0 monitoring resources"},
+	{group: "operator.dash0.com", resource: "dash0operatorconfigurations", verb: "list",
+		purpose: "watching the Dash0 operator configuration resource"},
+	{group: "operator.dash0.com", resource: "dash0operatorconfigurations", subresource: "status", verb: "update",
+		purpose: "updating the status of the Dash0 operator configuration resource"},
+	{group: "apps", resource: "deployments", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "apps", resource: "daemonsets", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "apps", resource: "statefulsets", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "apps", resource: "replicasets", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "batch", resource: "cronjobs", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "batch", resource: "jobs", verb: "patch", purpose: "instrumenting workloads"},
+	{group: "", resource: "pods", verb: "delete",
+		purpose: "restarting pods of workloads that cannot be updated in place"},
+	{group: "", resource: "events", verb: "create", purpose: "reporting instrumentation results as events"},
+	{group: "", resource: "configmaps", verb: "create", namespaced: true,
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "", resource: "services", verb: "create", namespaced: true,
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "", resource: "serviceaccounts", verb: "create", namespaced: true,
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "apps", resource: "daemonsets", verb: "create", namespaced: true,
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "apps", resource: "deployments", verb: "create", namespaced: true,
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "rbac.authorization.k8s.io", resource: "clusterroles", verb: "create",
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "rbac.authorization.k8s.io", resource: "clusterrolebindings", verb: "create",
+		purpose: "managing the OpenTelemetry collector resources"},
+	{group: "", resource: "secrets", verb: "get", namespaced: true,
+		purpose: "reading the Dash0 authorization token from a secret"},
+}
+
+// Start runs the permission check once and then returns. It implements manager.Runnable.
+func (c *PermissionChecker) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	result := c.checkPermissions(ctx, &logger)
+	c.recordCondition(ctx, result, &logger)
+	return nil
+}
+
+// NeedLeaderElection returns false, so the check runs on every operator replica, since every replica uses the same
+// service account and would be affected by missing permissions alike.
+func (c *PermissionChecker) NeedLeaderElection() bool {
+	return false
+}
+
+type permissionCheckResult struct {
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+}
+
+func (c *PermissionChecker) checkPermissions(ctx context.Context, logger *logr.Logger) permissionCheckResult {
+	var missing []string
+	var failed []string
+	for _, permission := range requiredPermissions {
+		allowed, err := c.isAllowed(ctx, permission)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", permission.describe(c.OperatorNamespace), err))
+			continue
+		}
+		if !allowed {
+			missing = append(missing, fmt.Sprintf("%s, required for %s",
+				permission.describe(c.OperatorNamespace), permission.purpose))
+		}
+	}
+
+	if len(missing) > 0 {
+		message := fmt.Sprintf(
+			"The service account of the Dash0 operator is missing %d permission(s), the operator will not work "+
+				"correctly until these are granted: %s.",
+			len(missing),
+			strings.Join(missing, "; "),
+		)
+		logger.Error(fmt.Errorf("insufficient permissions"), message)
+		return permissionCheckResult{
+			status:  metav1.ConditionFalse,
+			reason:  permissionsInsufficientReason,
+			message: message,
+		}
+	}
+	if len(failed) > 0 {
+		message := fmt.Sprintf(
+			"The Dash0 operator could not verify %d of its permissions: %s.",
+			len(failed),
+			strings.Join(failed, "; "),
+		)
+		logger.Error(fmt.Errorf("permission check failed"), message)
+		return permissionCheckResult{
+			status:  metav1.ConditionUnknown,
+			reason:  permissionCheckFailedReason,
+			message: message,
+		}
+	}
+	logger.Info("The service account of the Dash0 operator has all required permissions.")
+	return permissionCheckResult{
+		status:  metav1.ConditionTrue,
+		reason:  permissionsSufficientReason,
+		message: "The service account of the Dash0 operator has all required permissions.",
+	}
+}
+
+func (c *PermissionChecker) isAllowed(ctx context.Context, permission requiredPermission) (bool, error) {
+	resourceAttributes := &authorizationv1.ResourceAttributes{
+		Group:       permission.group,
+		Resource:    permission.resource,
+		Subresource: permission.subresource,
+		Verb:        permission.verb,
+	}
+	if permission.namespaced {
+		resourceAttributes.Namespace = c.OperatorNamespace
+	}
+	review, err := c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(
+		ctx,
+		&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: resourceAttributes,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// recordCondition sets the PermissionsSufficient condition on the operator configuration resource. Since the operator
+// configuration resource might be created concurrently at startup, this waits for it to show up for a limited time.
+func (c *PermissionChecker) recordCondition(
+	ctx context.Context,
+	result permissionCheckResult,
+	logger *logr.Logger,
+) {
+	retryInterval := c.conditionRetryInterval
+	if retryInterval == 0 {
+		retryInterval = defaultConditionRetryInterval
+	}
+	retryTimeout := c.conditionRetryTimeout
+	if retryTimeout == 0 {
+		retryTimeout = defaultConditionRetryTimeout
+	}
+	if err := wait.PollUntilContextTimeout(
+		ctx,
+		retryInterval,
+		retryTimeout,
+		true,
+		func(ctx context.Context) (bool, error) {
+			resource, err := util.FindUniqueOrMostRecentResourceInScope(
+				ctx,
+				c.Client,
+				"", /* cluster-scope, thus no namespace */
+				&dash0v1alpha1.Dash0OperatorConfiguration{},
+				logger,
+			)
+			if err != nil || resource == nil {
+				return false, nil
+			}
+			operatorConfigurationResource := resource.(*dash0v1alpha1.Dash0OperatorConfiguration)
+			if operatorConfigurationResource.IsMarkedForDeletion() {
+				return true, nil
+			}
+			if !operatorConfigurationResource.SetHealthCondition(
+				dash0v1alpha1.ConditionTypePermissionsSufficient,
+				result.status,
+				result.reason,
+				result.message,
+			) {
+				return true, nil
+			}
+			if err = c.Client.Status().Update(ctx, operatorConfigurationResource); err != nil {
+				logger.Info(fmt.Sprintf(
+					"Cannot record the permission check result in the Dash0 operator configuration resource, will "+
+						"retry: %v", err))
+				return false, nil
+			}
+			return true, nil
+		},
+	); err != nil {
+		logger.Info("There is no Dash0 operator configuration resource to record the permission check result in, " +
+			"the result is only available in the log.")
+	}
+}
+
+func (p requiredPermission) describe(operatorNamespace string) string {
+	resource := p.resource
+	if p.subresource != "" {
+		resource = fmt.Sprintf("%s/%s", resource, p.subresource)
+	}
+	if p.group != "" {
+		resource = fmt.Sprintf("%s.%s", resource, p.group)
+	}
+	if p.namespaced {
+		return fmt.Sprintf("%s %s in namespace %s", p.verb, resource, operatorNamespace)
+	}
+	return fmt.Sprintf("%s %s (cluster-wide)", p.verb, resource)
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package startup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+var _ = Describe("The permission check at startup", Ordered, func() {
+
+	ctx := context.Background()
+
+	BeforeAll(func() {
+		EnsureOperatorNamespaceExists(ctx, k8sClient)
+	})
+
+	BeforeEach(func() {
+		CreateDefaultOperatorConfigurationResource(ctx, k8sClient)
+	})
+
+	AfterEach(func() {
+		DeleteAllOperatorConfigurationResources(ctx, k8sClient)
+	})
+
+	It("should set the condition to true if all permissions are granted", func() {
+		checker := createPermissionChecker(func(*authorizationv1.ResourceAttributes) bool {
+			return true
+		})
+		Expect(checker.Start(ctx)).To(Succeed())
+
+		condition := loadPermissionsSufficientCondition(ctx)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(permissionsSufficientReason))
+	})
+
+	It("should list all missing permissions in the condition", func() {
+		checker := createPermissionChecker(func(attributes *authorizationv1.ResourceAttributes) bool {
+			return attributes.Resource != "clusterroles" &&
+				!(attributes.Resource == "configmaps" && attributes.Verb == "create")
+		})
+		Expect(checker.Start(ctx)).To(Succeed())
+
+		condition := loadPermissionsSufficientCondition(ctx)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(permissionsInsufficientReason))
+		Expect(condition.Message).To(ContainSubstring("is missing 2 permission(s)"))
+		Expect(condition.Message).To(ContainSubstring("create clusterroles.rbac.authorization.k8s.io (cluster-wide)"))
+		Expect(condition.Message).To(ContainSubstring(
+			fmt.Sprintf("create configmaps in namespace %s", OperatorNamespace)))
+	})
+
+	It("should set the condition to unknown if the access reviews fail", func() {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor(
+			"create",
+			"selfsubjectaccessreviews",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, fmt.Errorf("connection refused")
+			},
+		)
+		checker := &PermissionChecker{
+			Client:                 k8sClient,
+			Clientset:              clientset,
+			OperatorNamespace:      OperatorNamespace,
+			conditionRetryInterval: 10 * time.Millisecond,
+			conditionRetryTimeout:  time.Second,
+		}
+		Expect(checker.Start(ctx)).To(Succeed())
+
+		condition := loadPermissionsSufficientCondition(ctx)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(permissionCheckFailedReason))
+		Expect(condition.Message).To(ContainSubstring("connection refused"))
+	})
+})
+
+func createPermissionChecker(isAllowed func(*authorizationv1.ResourceAttributes) bool) *PermissionChecker {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor(
+		"create",
+		"selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = isAllowed(review.Spec.ResourceAttributes)
+			return true, review, nil
+		},
+	)
+	return &PermissionChecker{
+		Client:                 k8sClient,
+		Clientset:              clientset,
+		OperatorNamespace:      OperatorNamespace,
+		conditionRetryInterval: 10 * time.Millisecond,
+		conditionRetryTimeout:  time.Second,
+	}
+}
+
+func loadPermissionsSufficientCondition(ctx context.Context) *metav1.Condition {
+	resource := LoadOperatorConfigurationResourceOrFail(ctx, k8sClient, Default)
+	return meta.FindStatusCondition(
+		resource.Status.Conditions,
+		string(dash0v1alpha1.ConditionTypePermissionsSufficient),
+	)
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PausedAnnotationKey can be set to "true" on a Dash0Monitoring resource or on the Dash0OperatorConfiguration
+	// resource to stop the operator from reconciling it, for example while debugging an incident.
+	PausedAnnotationKey = "dash0.com/paused"
+
+	// ServiceNameAnnotationKey can be set on a workload (or on the pod template of a workload) to override the service
+	// name the operator sets via OTEL_SERVICE_NAME when instrumenting the workload. If it is not set, the name of the
+	// workload is used as the service name.
+	ServiceNameAnnotationKey = "dash0.com/service-name"
+
+	// InitContainerImageAnnotationKey can be set on a workload (or on the pod template of a workload) to use a
+	// different Dash0 instrumentation init container image for this workload than the operator-wide default, e.g. to
+	// try out a new version of the instrumentation on a few workloads first. The image needs to be from one of the
+	// allowed registries, see Images.InitContainerImageAllowedRegistries.
+	InitContainerImageAnnotationKey = "dash0.com/init-container-image"
+
+	defaultImageRegistry = "docker.io"
+)
+
+// IsReconciliationPaused returns true if the resource has the annotation dash0.com/paused=true.
+func IsReconciliationPaused(meta *metav1.ObjectMeta) bool {
+	if meta.Annotations == nil {
+		return false
+	}
+	value, isSet := meta.Annotations[PausedAnnotationKey]
+	return isSet && strings.EqualFold(strings.TrimSpace(value), "true")
+}
+
+// InitContainerImageForWorkload returns the init container image to use for the given workload, that is, the image from
+// the annotation dash0.com/init-container-image on the workload or on its pod template (the workload taking precedence),
+// or the operator-wide default image if the annotation is not set. If the image from the annotation is not from one of
+// the allowed registries, the default image is returned together with an error.
+func InitContainerImageForWorkload(images Images, meta *metav1.ObjectMeta, podTemplateMeta *metav1.ObjectMeta) (
+	string,
+	error,
+) {
+	override := ""
+	if podTemplateMeta != nil {
+		override = strings.TrimSpace(podTemplateMeta.Annotations[InitContainerImageAnnotationKey])
+	}
+	if workloadOverride := strings.TrimSpace(meta.Annotations[InitContainerImageAnnotationKey]); workloadOverride != "" {
+		override = workloadOverride
+	}
+	if override == "" {
+		return images.InitContainerImage, nil
+	}
+	allowedRegistries := images.InitContainerImageAllowedRegistries
+	if len(allowedRegistries) == 0 {
+		allowedRegistries = []string{imageRegistry(images.InitContainerImage)}
+	}
+	if !slices.ContainsFunc(allowedRegistries, func(allowedRegistry string) bool {
+		return isImageFromRegistry(override, allowedRegistry)
+	}) {
+		return images.InitContainerImage, fmt.Errorf(
+			"the init container image %s from the annotation %s is not from one of the allowed registries (%s), using "+
+				"the default image %s instead",
+			override,
+			InitContainerImageAnnotationKey,
+			strings.Join(allowedRegistries, ", "),
+			images.InitContainerImage,
+		)
+	}
+	return override, nil
+}
+
+// imageRegistry returns the registry part of the given image reference, following the conventions of container image
+// references: The first path component is the registry if it contains a dot or a colon or is localhost, otherwise the
+// image is from Docker Hub.
+func imageRegistry(image string) string {
+	firstComponent, _, hasPath := strings.Cut(image, "/")
+	if hasPath &&
+		(strings.ContainsAny(firstComponent, ".:") || firstComponent == "localhost") {
+		return firstComponent
+	}
+	return defaultImageRegistry
+}
+
+// isImageFromRegistry checks whether the given image reference is from the given registry, which may include a
+// repository path prefix (like ghcr.io/dash0hq).
+func isImageFromRegistry(image string, registry string) bool {
+	registry = strings.TrimSuffix(strings.TrimSpace(registry), "/")
+	if registry == "" {
+		return false
+	}
+	if imageRegistry(image) == defaultImageRegistry && !strings.HasPrefix(image, defaultImageRegistry+"/") {
+		image = defaultImageRegistry + "/" + image
+	}
+	return strings.HasPrefix(image, registry+"/")
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Annotations", func() {
+
+	DescribeTable("should determine whether reconciliation is paused",
+		func(annotations map[string]string, expected bool) {
+			Expect(IsReconciliationPaused(&metav1.ObjectMeta{Annotations: annotations})).To(Equal(expected))
+		},
+		Entry("no annotations", nil, false),
+		Entry("unrelated annotation", map[string]string{"some": "annotation"}, false),
+		Entry("paused=true", map[string]string{PausedAnnotationKey: "true"}, true),
+		Entry("paused=TRUE", map[string]string{PausedAnnotationKey: "TRUE"}, true),
+		Entry("paused=false", map[string]string{PausedAnnotationKey: "false"}, false),
+		Entry("paused with empty value", map[string]string{PausedAnnotationKey: ""}, false),
+	)
+
+	images := Images{InitContainerImage: "ghcr.io/dash0hq/instrumentation:1.0.0"}
+	imagesWithAllowedRegistries := Images{
+		InitContainerImage:                  "ghcr.io/dash0hq/instrumentation:1.0.0",
+		InitContainerImageAllowedRegistries: []string{"registry.example.com/dash0", "docker.io"},
+	}
+
+	DescribeTable("should determine the init container image for a workload",
+		func(
+			images Images,
+			annotations map[string]string,
+			podTemplateAnnotations map[string]string,
+			expectedImage string,
+			expectError bool,
+		) {
+			image, err := InitContainerImageForWorkload(
+				images,
+				&metav1.ObjectMeta{Annotations: annotations},
+				&metav1.ObjectMeta{Annotations: podTemplateAnnotations},
+			)
+			Expect(image).To(Equal(expectedImage))
+			if expectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+		},
+		Entry("no annotation", images, nil, nil, "ghcr.io/dash0hq/instrumentation:1.0.0", false),
+		Entry("override from the registry of the default image", images,
+			map[string]string{InitContainerImageAnnotationKey: "ghcr.io/dash0hq/instrumentation:1.1.0-rc.1"}, nil,
+			"ghcr.io/dash0hq/instrumentation:1.1.0-rc.1", false),
+		Entry("override on the pod template", images,
+			nil, map[string]string{InitContainerImageAnnotationKey: "ghcr.io/dash0hq/instrumentation:1.1.0"},
+			"ghcr.io/dash0hq/instrumentation:1.1.0", false),
+		Entry("override on the workload takes precedence", images,
+			map[string]string{InitContainerImageAnnotationKey: "ghcr.io/dash0hq/instrumentation:1.2.0"},
+			map[string]string{InitContainerImageAnnotationKey: "ghcr.io/dash0hq/instrumentation:1.1.0"},
+			"ghcr.io/dash0hq/instrumentation:1.2.0", false),
+		Entry("override from a registry that is not allowed", images,
+			map[string]string{InitContainerImageAnnotationKey: "evil.example.com/instrumentation:1.1.0"}, nil,
+			"ghcr.io/dash0hq/instrumentation:1.0.0", true),
+		Entry("override from a registry with a similar prefix", images,
+			map[string]string{InitContainerImageAnnotationKey: "ghcr.io.example.com/instrumentation:1.1.0"}, nil,
+			"ghcr.io/dash0hq/instrumentation:1.0.0", true),
+		Entry("override from an allowed registry and repository path", imagesWithAllowedRegistries,
+			map[string]string{InitContainerImageAnnotationKey: "registry.example.com/dash0/instrumentation:1.1.0"}, nil,
+			"registry.example.com/dash0/instrumentation:1.1.0", false),
+		Entry("override from an allowed registry but another repository path", imagesWithAllowedRegistries,
+			map[string]string{InitContainerImageAnnotationKey: "registry.example.com/other/instrumentation:1.1.0"}, nil,
+			"ghcr.io/dash0hq/instrumentation:1.0.0", true),
+		Entry("override from Docker Hub without explicit registry", imagesWithAllowedRegistries,
+			map[string]string{InitContainerImageAnnotationKey: "dash0hq/instrumentation:1.1.0"}, nil,
+			"dash0hq/instrumentation:1.1.0", false),
+		Entry("default registry is not allowed if allowed registries are configured", imagesWithAllowedRegistries,
+			map[string]string{InitContainerImageAnnotationKey: "ghcr.io/dash0hq/instrumentation:1.1.0"}, nil,
+			"ghcr.io/dash0hq/instrumentation:1.0.0", true),
+	)
+})
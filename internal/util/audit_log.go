@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const InstrumentationAuditLogStdout = "stdout"
+
+// InstrumentationAuditRecord describes one modification the operator has made to a workload to add, update or remove
+// the Dash0 instrumentation. In contrast to the operator's regular logs and to Kubernetes events, audit records are
+// written to a dedicated sink and are not subject to log levels or event expiry, so they can serve as a complete
+// record of the changes the operator has made to workloads.
+type InstrumentationAuditRecord struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Action    ModificationMode `json:"action"`
+	Reason    Reason           `json:"reason"`
+	// InstrumentedBy is either "webhook" or "controller", see InstrumentationMetadata.InstrumentedBy.
+	InstrumentedBy string `json:"instrumentedBy"`
+	// User is the user (or service account) whose request to the Kubernetes API server has triggered the modification.
+	// It is only known for modifications by the webhook, the controller modifies workloads on its own behalf.
+	User      string `json:"user,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// GenerateName is only set if the workload does not have a name yet, which happens for pods that are instrumented
+	// by the webhook when they are created.
+	GenerateName    string `json:"generateName,omitempty"`
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+}
+
+// InstrumentationAuditLog receives an audit record for each workload modification by the webhook and the controller.
+type InstrumentationAuditLog interface {
+	Record(record InstrumentationAuditRecord) error
+}
+
+type jsonLinesInstrumentationAuditLog struct {
+	writer io.Writer
+	lock   sync.Mutex
+}
+
+// NewInstrumentationAuditLog creates an audit log that writes each record as a JSON object on a separate line to the
+// given writer.
+func NewInstrumentationAuditLog(writer io.Writer) InstrumentationAuditLog {
+	return &jsonLinesInstrumentationAuditLog{writer: writer}
+}
+
+// OpenInstrumentationAuditLog creates the audit log for the given target, which is either "stdout" (the operator's
+// regular logs are written to stderr) or the path of a file, to which records are appended. An empty target disables
+// the audit log, in which case nil is returned.
+func OpenInstrumentationAuditLog(target string) (InstrumentationAuditLog, error) {
+	switch target {
+	case "":
+		return nil, nil
+	case InstrumentationAuditLogStdout:
+		return NewInstrumentationAuditLog(os.Stdout), nil
+	default:
+		file, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the instrumentation audit log file %s: %w", target, err)
+		}
+		return NewInstrumentationAuditLog(file), nil
+	}
+}
+
+func (l *jsonLinesInstrumentationAuditLog) Record(record InstrumentationAuditRecord) error {
+	serialized, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cannot serialize the instrumentation audit record: %w", err)
+	}
+	serialized = append(serialized, '\n')
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, err = l.writer.Write(serialized); err != nil {
+		return fmt.Errorf("cannot write the instrumentation audit record: %w", err)
+	}
+	return nil
+}
+
+// RecordInstrumentationAudit writes the given audit record, with the current time as its timestamp. It does nothing if
+// the audit log is disabled (nil). Failing to write the record is logged, but does not affect the modification itself.
+func RecordInstrumentationAudit(
+	auditLog InstrumentationAuditLog,
+	record InstrumentationAuditRecord,
+	logger *logr.Logger,
+) {
+	if auditLog == nil {
+		return
+	}
+	record.Timestamp = time.Now().UTC()
+	if err := auditLog.Record(record); err != nil {
+		logger.Error(err, "Failed to write the instrumentation audit record.")
+	}
+}
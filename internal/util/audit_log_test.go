@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The instrumentation audit log", func() {
+	logger := logr.Discard()
+
+	It("should write each record as a JSON object on a separate line", func() {
+		var buffer bytes.Buffer
+		auditLog := NewInstrumentationAuditLog(&buffer)
+
+		RecordInstrumentationAudit(auditLog, InstrumentationAuditRecord{
+			Action:          ModificationModeInstrumentation,
+			Reason:          ReasonSuccessfulInstrumentation,
+			InstrumentedBy:  "webhook",
+			User:            "system:serviceaccount:kube-system:replicaset-controller",
+			Kind:            "Pod",
+			Namespace:       "namespace",
+			GenerateName:    "pod-",
+			OperatorVersion: "1.2.3",
+		}, &logger)
+		RecordInstrumentationAudit(auditLog, InstrumentationAuditRecord{
+			Action:         ModificationModeUninstrumentation,
+			Reason:         ReasonSuccessfulUninstrumentation,
+			InstrumentedBy: "controller",
+			Kind:           "Deployment",
+			Namespace:      "namespace",
+			Name:           "deployment",
+		}, &logger)
+
+		lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var first map[string]interface{}
+		Expect(json.Unmarshal([]byte(lines[0]), &first)).To(Succeed())
+		Expect(first).To(HaveKeyWithValue("action", "instrumentation"))
+		Expect(first).To(HaveKeyWithValue("reason", "SuccessfulInstrumentation"))
+		Expect(first).To(HaveKeyWithValue("instrumentedBy", "webhook"))
+		Expect(first).To(HaveKeyWithValue("user", "system:serviceaccount:kube-system:replicaset-controller"))
+		Expect(first).To(HaveKeyWithValue("kind", "Pod"))
+		Expect(first).To(HaveKeyWithValue("generateName", "pod-"))
+		Expect(first).To(HaveKeyWithValue("operatorVersion", "1.2.3"))
+		timestamp, err := time.Parse(time.RFC3339Nano, first["timestamp"].(string))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timestamp).To(BeTemporally("~", time.Now(), time.Minute))
+
+		var second map[string]interface{}
+		Expect(json.Unmarshal([]byte(lines[1]), &second)).To(Succeed())
+		Expect(second).To(HaveKeyWithValue("action", "uninstrumentation"))
+		Expect(second).To(HaveKeyWithValue("name", "deployment"))
+		Expect(second).ToNot(HaveKey("user"))
+		Expect(second).ToNot(HaveKey("generateName"))
+	})
+
+	It("should do nothing if the audit log is disabled", func() {
+		auditLog, err := OpenInstrumentationAuditLog("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auditLog).To(BeNil())
+		RecordInstrumentationAudit(auditLog, InstrumentationAuditRecord{}, &logger)
+	})
+
+	It("should append records to a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "audit.log")
+		Expect(os.WriteFile(path, []byte("{}\n"), 0600)).To(Succeed())
+
+		auditLog, err := OpenInstrumentationAuditLog(path)
+		Expect(err).ToNot(HaveOccurred())
+		RecordInstrumentationAudit(auditLog, InstrumentationAuditRecord{Kind: "Deployment"}, &logger)
+
+		content, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(ContainSubstring(`"kind":"Deployment"`))
+	})
+})
@@ -278,6 +278,47 @@ func FindUniqueOrMostRecentResourceInScope(
 	return findMostRecentResource(resourcePrototype, allResourcesInScope), nil
 }
 
+// FindExcludedWorkloadKinds returns the workload kinds that must not be instrumented according to the operator
+// configuration resource. If no operator configuration resource exists, no workload kinds are excluded.
+func FindExcludedWorkloadKinds(
+	ctx context.Context,
+	k8sClient client.Client,
+	logger *logr.Logger,
+) ([]dash0v1alpha1.WorkloadKind, error) {
+	operatorConfigurationResource, err := FindUniqueOrMostRecentResourceInScope(
+		ctx,
+		k8sClient,
+		"", /* cluster-scope, thus no namespace */
+		&dash0v1alpha1.Dash0OperatorConfiguration{},
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if operatorConfigurationResource == nil {
+		return nil, nil
+	}
+	return operatorConfigurationResource.(*dash0v1alpha1.Dash0OperatorConfiguration).Spec.ExcludedWorkloadKinds, nil
+}
+
+// IsNamespaceExcludedFromInstrumentation checks whether the given namespace carries the label dash0.com/enable=false,
+// which excludes all workloads in the namespace from instrumentation. The namespace is read via the given client, that
+// is, from the informer cache when using the manager's client.
+func IsNamespaceExcludedFromInstrumentation(
+	ctx context.Context,
+	k8sClient client.Client,
+	namespace string,
+) (bool, error) {
+	namespaceResource := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: namespace}, namespaceResource); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return HasExplicitlyOptedOutOfInstrumentation(&namespaceResource.ObjectMeta), nil
+}
+
 func findMostRecentResource(
 	resourcePrototype dash0common.Dash0Resource,
 	allResourcesInScope client.ObjectList,
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const apiUserAgentPrefix = "dash0-operator"
+
+// NewApiHttpClient creates the HTTP client the operator uses for requests to the Dash0 API. If proxyUrl is not nil,
+// all requests are sent via that proxy, independent of the proxy settings of the OpenTelemetry collectors. Otherwise,
+// requests go through http.DefaultTransport, to which the standard proxy environment variables (HTTP_PROXY,
+// HTTPS_PROXY, NO_PROXY) of the operator manager apply. If userAgent is not empty, it is sent as the User-Agent header
+// with every request that does not set a User-Agent header itself.
+func NewApiHttpClient(proxyUrl *url.URL, userAgent string, timeout time.Duration) *http.Client {
+	// A nil round tripper resolves to http.DefaultTransport when a request is sent, not when the client is created.
+	var roundTripper http.RoundTripper
+	if proxyUrl != nil {
+		transport := &http.Transport{}
+		if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+			transport = defaultTransport.Clone()
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+		roundTripper = transport
+	}
+	if userAgent != "" {
+		roundTripper = &userAgentRoundTripper{
+			userAgent: userAgent,
+			next:      roundTripper,
+		}
+	}
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   timeout,
+	}
+}
+
+// ApiUserAgent returns the User-Agent header value for requests to the Dash0 API, for example
+// "dash0-operator/0.45.1".
+func ApiUserAgent(images Images) string {
+	operatorVersion := images.GetOperatorVersion()
+	if operatorVersion == "" {
+		return apiUserAgentPrefix
+	}
+	return fmt.Sprintf("%s/%s", apiUserAgentPrefix, operatorVersion)
+}
+
+type userAgentRoundTripper struct {
+	userAgent string
+	// next is the round tripper the request is passed on to, nil means http.DefaultTransport.
+	next http.RoundTripper
+}
+
+func (t *userAgentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if req.Header.Get("User-Agent") != "" {
+		return next.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the original request, hence the header is set on a copy.
+	reqWithUserAgent := req.Clone(req.Context())
+	reqWithUserAgent.Header.Set("User-Agent", t.userAgent)
+	return next.RoundTrip(reqWithUserAgent)
+}
+
+// ParseApiProxyUrl validates the proxy URL for requests to the Dash0 API. An empty string yields nil, that is, no
+// dedicated proxy.
+func ParseApiProxyUrl(rawProxyUrl string) (*url.URL, error) {
+	if rawProxyUrl == "" {
+		return nil, nil
+	}
+	proxyUrl, err := url.Parse(rawProxyUrl)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the Dash0 API proxy URL \"%s\": %w", rawProxyUrl, err)
+	}
+	if proxyUrl.Scheme != "http" && proxyUrl.Scheme != "https" && proxyUrl.Scheme != "socks5" {
+		return nil, fmt.Errorf(
+			"the Dash0 API proxy URL \"%s\" has an unsupported scheme, it must start with http://, https:// or "+
+				"socks5://", rawProxyUrl)
+	}
+	if proxyUrl.Host == "" {
+		return nil, fmt.Errorf("the Dash0 API proxy URL \"%s\" has no host", rawProxyUrl)
+	}
+	return proxyUrl, nil
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The Dash0 API HTTP client", func() {
+
+	Describe("parsing the proxy URL", func() {
+		It("should return nil for an empty value", func() {
+			proxyUrl, err := ParseApiProxyUrl("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(proxyUrl).To(BeNil())
+		})
+
+		It("should accept an http proxy URL", func() {
+			proxyUrl, err := ParseApiProxyUrl("http://proxy.example.com:3128")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(proxyUrl.Host).To(Equal("proxy.example.com:3128"))
+		})
+
+		It("should reject a URL without a scheme", func() {
+			_, err := ParseApiProxyUrl("proxy.example.com:3128")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject a URL with an unsupported scheme", func() {
+			_, err := ParseApiProxyUrl("ftp://proxy.example.com")
+			Expect(err).To(MatchError(ContainSubstring("unsupported scheme")))
+		})
+	})
+
+	Describe("creating the client", func() {
+		It("should send requests via the explicitly configured proxy", func() {
+			proxyUrl, err := ParseApiProxyUrl("http://proxy.example.com:3128")
+			Expect(err).ToNot(HaveOccurred())
+			httpClient := NewApiHttpClient(proxyUrl, "", 5*time.Second)
+			Expect(httpClient.Timeout).To(Equal(5 * time.Second))
+
+			req, err := http.NewRequest(http.MethodGet, "https://api.dash0.com/api/dashboards", nil)
+			Expect(err).ToNot(HaveOccurred())
+			usedProxy, err := httpClient.Transport.(*http.Transport).Proxy(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usedProxy.String()).To(Equal("http://proxy.example.com:3128"))
+		})
+
+		It("should use the default transport without an explicit proxy", func() {
+			// http.DefaultTransport honors the proxy environment variables, and can be replaced in tests.
+			httpClient := NewApiHttpClient(nil, "", 0)
+			Expect(httpClient.Transport).To(BeNil())
+		})
+
+		It("should send the User-Agent header", func() {
+			var receivedUserAgent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedUserAgent = r.Header.Get("User-Agent")
+			}))
+			defer server.Close()
+
+			httpClient := NewApiHttpClient(nil, "dash0-operator/1.2.3", 0)
+			res, err := httpClient.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			_ = res.Body.Close()
+			Expect(receivedUserAgent).To(Equal("dash0-operator/1.2.3"))
+		})
+
+		It("should not override a User-Agent header set on the request", func() {
+			var receivedUserAgent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedUserAgent = r.Header.Get("User-Agent")
+			}))
+			defer server.Close()
+
+			httpClient := NewApiHttpClient(nil, "dash0-operator/1.2.3", 0)
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("User-Agent", "custom-agent")
+			res, err := httpClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			_ = res.Body.Close()
+			Expect(receivedUserAgent).To(Equal("custom-agent"))
+		})
+	})
+
+	Describe("assembling the User-Agent", func() {
+		It("should include the operator version", func() {
+			Expect(ApiUserAgent(Images{OperatorImage: "ghcr.io/dash0hq/operator-controller:0.45.1"})).To(
+				Equal("dash0-operator/0.45.1"))
+		})
+
+		It("should omit the version if the operator image has no tag", func() {
+			Expect(ApiUserAgent(Images{OperatorImage: "operator-controller"})).To(Equal("dash0-operator"))
+		})
+	})
+})
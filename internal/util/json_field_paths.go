@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const jsonFieldPathWildcard = "*"
+
+// JsonFieldPath is a parsed path to a field in a JSON document, for example spec.datasources or
+// spec.panels.*.spec.queries. Each segment is either a field name, the index of an array element, or the wildcard *,
+// which matches all fields of an object or all elements of an array.
+type JsonFieldPath []string
+
+func (p JsonFieldPath) String() string {
+	return strings.Join(p, ".")
+}
+
+// ParseJsonFieldPaths parses a comma-separated list of JSON field paths. Each path is a dot-separated list of segments,
+// optionally prefixed with "$.". An empty string yields no paths.
+func ParseJsonFieldPaths(rawPaths string) ([]JsonFieldPath, error) {
+	var paths []JsonFieldPath
+	for _, rawPath := range strings.Split(rawPaths, ",") {
+		rawPath = strings.TrimSpace(rawPath)
+		if rawPath == "" {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(rawPath, "$."), ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return nil, fmt.Errorf("the JSON field path \"%s\" contains an empty segment", rawPath)
+			}
+		}
+		if len(segments) == 1 && segments[0] == jsonFieldPathWildcard {
+			return nil, fmt.Errorf("the JSON field path \"%s\" would remove the whole document", rawPath)
+		}
+		paths = append(paths, segments)
+	}
+	return paths, nil
+}
+
+// RemoveJsonFields removes all fields matching one of the given paths from the given JSON document, that is, a value
+// produced by unmarshalling JSON into an interface{}. Paths that do not match any field are ignored. Array elements
+// are never removed, a path whose last segment addresses array elements has no effect.
+func RemoveJsonFields(document interface{}, paths []JsonFieldPath) {
+	for _, path := range paths {
+		removeJsonField(document, path)
+	}
+}
+
+func removeJsonField(node interface{}, path JsonFieldPath) {
+	if len(path) == 0 {
+		return
+	}
+	segment := path[0]
+	switch typedNode := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			if segment == jsonFieldPathWildcard {
+				clear(typedNode)
+			} else {
+				delete(typedNode, segment)
+			}
+			return
+		}
+		if segment == jsonFieldPathWildcard {
+			for _, child := range typedNode {
+				removeJsonField(child, path[1:])
+			}
+		} else if child, ok := typedNode[segment]; ok {
+			removeJsonField(child, path[1:])
+		}
+	case []interface{}:
+		if len(path) == 1 {
+			return
+		}
+		if segment == jsonFieldPathWildcard {
+			for _, child := range typedNode {
+				removeJsonField(child, path[1:])
+			}
+		} else if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(typedNode) {
+			removeJsonField(typedNode[index], path[1:])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON field paths", func() {
+
+	Describe("parsing JSON field paths", func() {
+		It("should return no paths for an empty value", func() {
+			paths, err := ParseJsonFieldPaths("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(paths).To(BeEmpty())
+		})
+
+		It("should parse a comma-separated list of paths", func() {
+			paths, err := ParseJsonFieldPaths("spec.datasources, $.spec.panels.*.spec.links,")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(paths).To(Equal([]JsonFieldPath{
+				{"spec", "datasources"},
+				{"spec", "panels", "*", "spec", "links"},
+			}))
+		})
+
+		It("should reject a path with an empty segment", func() {
+			_, err := ParseJsonFieldPaths("spec..datasources")
+			Expect(err).To(MatchError(ContainSubstring("empty segment")))
+		})
+
+		It("should reject a path that would remove the whole document", func() {
+			_, err := ParseJsonFieldPaths("$.*")
+			Expect(err).To(MatchError(ContainSubstring("whole document")))
+		})
+	})
+
+	DescribeTable("removing fields from a JSON document", func(rawPaths string, document string, expected string) {
+		paths, err := ParseJsonFieldPaths(rawPaths)
+		Expect(err).ToNot(HaveOccurred())
+		var parsed interface{}
+		Expect(json.Unmarshal([]byte(document), &parsed)).To(Succeed())
+
+		RemoveJsonFields(parsed, paths)
+
+		actual, err := json.Marshal(parsed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(MatchJSON(expected))
+	},
+		Entry("should remove a nested field",
+			"spec.datasources",
+			`{"kind":"PersesDashboard","spec":{"datasources":{"prom":{}},"panels":{}}}`,
+			`{"kind":"PersesDashboard","spec":{"panels":{}}}`,
+		),
+		Entry("should ignore paths that do not match",
+			"spec.variables,spec.panels.missing.spec",
+			`{"spec":{"panels":{"a":{}}}}`,
+			`{"spec":{"panels":{"a":{}}}}`,
+		),
+		Entry("should remove a field from all children of an object",
+			"spec.panels.*.spec.plugin.spec.datasource",
+			`{"spec":{"panels":{"a":{"spec":{"plugin":{"spec":{"datasource":"x","y":1}}}},"b":{"spec":{}}}}}`,
+			`{"spec":{"panels":{"a":{"spec":{"plugin":{"spec":{"y":1}}}},"b":{"spec":{}}}}}`,
+		),
+		Entry("should remove a field from all elements of an array",
+			"spec.queries.*.datasource",
+			`{"spec":{"queries":[{"datasource":"x","q":"up"},{"q":"down"}]}}`,
+			`{"spec":{"queries":[{"q":"up"},{"q":"down"}]}}`,
+		),
+		Entry("should remove a field from a single array element",
+			"spec.queries.1.datasource",
+			`{"spec":{"queries":[{"datasource":"x"},{"datasource":"y"}]}}`,
+			`{"spec":{"queries":[{"datasource":"x"},{}]}}`,
+		),
+		Entry("should remove all fields of an object",
+			"spec.datasources.*",
+			`{"spec":{"datasources":{"a":{},"b":{}}}}`,
+			`{"spec":{"datasources":{}}}`,
+		),
+		Entry("should not remove array elements",
+			"spec.queries.*",
+			`{"spec":{"queries":[1,2]}}`,
+			`{"spec":{"queries":[1,2]}}`,
+		),
+	)
+})
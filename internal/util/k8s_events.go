@@ -6,6 +6,7 @@ package util
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,13 +25,60 @@ func QueueSuccessfulInstrumentationEvent(eventRecorder record.EventRecorder, res
 	)
 }
 
+func QueueReinstrumentedAfterUpgradeEvent(eventRecorder record.EventRecorder, resource runtime.Object, eventSource string) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeNormal,
+		string(ReasonReinstrumentedAfterUpgrade),
+		fmt.Sprintf("The %s has updated the Dash0 instrumentation of this workload, since it had been instrumented by "+
+			"a different version of the operator.", eventSource),
+	)
+}
+
 func QueueNoInstrumentationNecessaryEvent(eventRecorder record.EventRecorder, resource runtime.Object, eventSource string) {
 	eventRecorder.Event(
 		resource,
 		corev1.EventTypeNormal,
 		string(ReasonNoInstrumentationNecessary),
-		fmt.Sprintf("Dash0 instrumentation was already present on this workload, or the workload is part of a higher "+
-			"order workload that will be instrumented, no modification by the %s is necessary.", eventSource),
+		fmt.Sprintf("Dash0 instrumentation was already present on this workload, no modification by the %s is "+
+			"necessary.", eventSource),
+	)
+}
+
+func QueueOptedOutOfInstrumentationEvent(eventRecorder record.EventRecorder, resource runtime.Object, eventSource string) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeNormal,
+		string(ReasonOptedOutOfInstrumentation),
+		fmt.Sprintf("This workload has opted out of Dash0 instrumentation via the label dash0.com/enable=false, it "+
+			"has not been modified by the %s.", eventSource),
+	)
+}
+
+func QueueNotOptedInToInstrumentationEvent(eventRecorder record.EventRecorder, resource runtime.Object, eventSource string) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeNormal,
+		string(ReasonNotOptedInToInstrumentation),
+		fmt.Sprintf("This workload has not opted in to Dash0 instrumentation via the label dash0.com/enable=true, "+
+			"which is required by the opt-in instrumentation policy of this namespace, it has not been modified by "+
+			"the %s.", eventSource),
+	)
+}
+
+func QueueOwnedByHigherOrderWorkloadEvent(
+	eventRecorder record.EventRecorder,
+	resource runtime.Object,
+	ownerKind string,
+	ownerName string,
+	eventSource string,
+) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeNormal,
+		string(ReasonOwnedByHigherOrderWorkload),
+		fmt.Sprintf("This workload is managed by the %s %s, it has not been modified by the %s. Dash0 "+
+			"instrumentation is applied to the %s instead.", ownerKind, ownerName, eventSource, ownerKind),
 	)
 }
 
@@ -43,6 +91,55 @@ func QueueFailedInstrumentationEvent(eventRecorder record.EventRecorder, resourc
 	)
 }
 
+func QueueContainersNotInstrumentedEvent(
+	eventRecorder record.EventRecorder,
+	resource runtime.Object,
+	containerNames []string,
+	eventSource string,
+) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeWarning,
+		string(ReasonContainersNotInstrumented),
+		fmt.Sprintf("The %s could not instrument the container(s) %s of this workload, since they set the "+
+			"environment variable LD_PRELOAD via valueFrom, which Dash0 cannot extend. These containers will not "+
+			"send telemetry to Dash0.", eventSource, strings.Join(containerNames, ", ")),
+	)
+}
+
+func QueueWorkloadTooLargeForWebhookEvent(
+	eventRecorder record.EventRecorder,
+	resource runtime.Object,
+	size int,
+	maxSize int64,
+) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeWarning,
+		string(ReasonWorkloadTooLargeForWebhook),
+		fmt.Sprintf("The webhook has skipped this workload, since the size of its manifest (%d bytes) exceeds the "+
+			"configured maximum of %d bytes. The webhook has neither instrumented nor uninstrumented it.",
+			size, maxSize),
+	)
+}
+
+func QueueOwnerInstrumentedEvent(
+	eventRecorder record.EventRecorder,
+	resource runtime.Object,
+	ownerKind string,
+	ownerName string,
+	eventSource string,
+) {
+	eventRecorder.Event(
+		resource,
+		corev1.EventTypeNormal,
+		string(ReasonOwnerInstrumented),
+		fmt.Sprintf("This workload is immutable and cannot be instrumented by the %s. Its owner, the %s %s, has "+
+			"been instrumented instead, workloads it creates from now on will send telemetry to Dash0.",
+			eventSource, ownerKind, ownerName),
+	)
+}
+
 func QueueSuccessfulUninstrumentationEvent(eventRecorder record.EventRecorder, resource runtime.Object, eventSource string) {
 	eventRecorder.Event(
 		resource,
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
 )
 
 const (
@@ -28,6 +30,14 @@ const (
 	initContainerImageLabelKey = "dash0.com/init-container-image"
 	instrumentedByLabelKey     = "dash0.com/instrumented-by"
 	webhookIgnoreOnceLabelKey  = "dash0.com/webhook-ignore-once"
+
+	// uninstrumentationPendingLabelKey is added to the metadata (but not to the pod template) of instrumented workloads
+	// by the controller under the lazy uninstrumentation policy, to mark them for removing the instrumentation with the
+	// next change of their pod template.
+	uninstrumentationPendingLabelKey = "dash0.com/uninstrumentation-pending"
+
+	managedByLabelKey           = "app.kubernetes.io/managed-by"
+	managedByDash0OperatorValue = "dash0-operator"
 )
 
 var (
@@ -35,6 +45,9 @@ var (
 	WorkloadsWithDash0InstrumentedLabelFilter = metav1.ListOptions{
 		LabelSelector: instrumentedLabelKey,
 	}
+	WorkloadsWithSuccessfulDash0InstrumentationFilter = metav1.ListOptions{
+		LabelSelector: instrumentedLabelKey + "=" + string(instrumentedLabelValueSuccessful),
+	}
 )
 
 type instrumentedState string
@@ -70,6 +83,26 @@ func RemoveInstrumentationLabels(meta *metav1.ObjectMeta) {
 	removeLabel(meta, operatorImageLabelKey)
 	removeLabel(meta, initContainerImageLabelKey)
 	removeLabel(meta, instrumentedByLabelKey)
+	removeLabel(meta, uninstrumentationPendingLabelKey)
+}
+
+func MarkUninstrumentationPending(meta *metav1.ObjectMeta) {
+	addLabel(meta, uninstrumentationPendingLabelKey, "true")
+}
+
+func IsUninstrumentationPending(meta *metav1.ObjectMeta) bool {
+	value, isSet := readLabel(meta, uninstrumentationPendingLabelKey)
+	return isSet && value == "true"
+}
+
+// RemoveUninstrumentationPendingLabel removes the mark for lazy uninstrumentation, and returns true if the label was
+// present.
+func RemoveUninstrumentationPendingLabel(meta *metav1.ObjectMeta) bool {
+	if _, isSet := readLabel(meta, uninstrumentationPendingLabelKey); !isSet {
+		return false
+	}
+	removeLabel(meta, uninstrumentationPendingLabelKey)
+	return true
 }
 
 func removeLabel(meta *metav1.ObjectMeta, key string) {
@@ -84,7 +117,9 @@ func HasBeenInstrumentedSuccessfullyByThisVersion(
 	meta *metav1.ObjectMeta,
 	images Images,
 ) bool {
-	if !HasBeenInstrumentedSuccessfully(meta) {
+	if !HasBeenInstrumentedSuccessfully(meta) || IsUninstrumentationPending(meta) {
+		// A workload that is marked for lazy uninstrumentation needs to be processed again when it is instrumented, so
+		// the mark gets removed.
 		return false
 	}
 	operatorImageValue, operatorImageIsSet := readLabel(meta, operatorImageLabelKey)
@@ -93,7 +128,11 @@ func HasBeenInstrumentedSuccessfullyByThisVersion(
 		return false
 	}
 	expectedOperatorImageLabel := ImageNameToLabel(images.OperatorImage)
-	expectedInitContainerImageLabel := ImageNameToLabel(images.InitContainerImage)
+	// A workload can override the init container image via an annotation, the label records the image that has
+	// actually been used. Annotations on the pod template are not taken into account here, workloads which only have
+	// the annotation on their pod template are instrumented again, which does not change them.
+	expectedInitContainerImage, _ := InitContainerImageForWorkload(images, meta, nil)
+	expectedInitContainerImageLabel := ImageNameToLabel(expectedInitContainerImage)
 	return operatorImageValue == expectedOperatorImageLabel && initContainerImageValue == expectedInitContainerImageLabel
 }
 
@@ -116,23 +155,54 @@ func readInstrumentationState(meta *metav1.ObjectMeta) instrumentedState {
 	}
 }
 
-func HasOptedOutOfInstrumentation(meta *metav1.ObjectMeta) bool {
-	return hasOptedOutOfInstrumentation(meta)
+// HasOptedOutOfInstrumentation checks whether the workload is excluded from instrumentation, either explicitly via the
+// label dash0.com/enable=false, or, under the opt-in instrumentation policy, by not having the label
+// dash0.com/enable=true.
+func HasOptedOutOfInstrumentation(meta *metav1.ObjectMeta, policy dash0v1alpha1.InstrumentationPolicy) bool {
+	return hasOptedOutOfInstrumentation(meta, policy)
 }
 
-func HasOptedOutOfInstrumentationAndIsUninstrumented(meta *metav1.ObjectMeta) bool {
-	return hasOptedOutOfInstrumentation(meta) && !HasBeenInstrumentedSuccessfully(meta)
+func HasOptedOutOfInstrumentationAndIsUninstrumented(
+	meta *metav1.ObjectMeta,
+	policy dash0v1alpha1.InstrumentationPolicy,
+) bool {
+	return hasOptedOutOfInstrumentation(meta, policy) && !HasBeenInstrumentedSuccessfully(meta)
 }
 
-func WasInstrumentedButHasOptedOutNow(meta *metav1.ObjectMeta) bool {
-	return HasBeenInstrumentedSuccessfully(meta) && hasOptedOutOfInstrumentation(meta)
+func WasInstrumentedButHasOptedOutNow(meta *metav1.ObjectMeta, policy dash0v1alpha1.InstrumentationPolicy) bool {
+	return HasBeenInstrumentedSuccessfully(meta) && hasOptedOutOfInstrumentation(meta, policy)
 }
 
-func hasOptedOutOfInstrumentation(meta *metav1.ObjectMeta) bool {
+// HasExplicitlyOptedOutOfInstrumentation checks whether the workload (or namespace) has the label
+// dash0.com/enable=false.
+func HasExplicitlyOptedOutOfInstrumentation(meta *metav1.ObjectMeta) bool {
 	dash0EnabledValue, isSet := readLabel(meta, dash0EnableLabelKey)
 	return isSet && dash0EnabledValue == "false"
 }
 
+// IsManagedByDash0Operator checks whether the workload has the label app.kubernetes.io/managed-by=dash0-operator, that
+// is, whether it is one of the operator's own resources (like the OpenTelemetry collectors).
+func IsManagedByDash0Operator(meta *metav1.ObjectMeta) bool {
+	managedBy, isSet := readLabel(meta, managedByLabelKey)
+	return isSet && managedBy == managedByDash0OperatorValue
+}
+
+func hasOptedOutOfInstrumentation(meta *metav1.ObjectMeta, policy dash0v1alpha1.InstrumentationPolicy) bool {
+	if HasExplicitlyOptedOutOfInstrumentation(meta) {
+		return true
+	}
+	if policy != dash0v1alpha1.OptIn {
+		return false
+	}
+	if dash0EnabledValue, isSet := readLabel(meta, dash0EnableLabelKey); isSet && dash0EnabledValue == "true" {
+		return false
+	}
+	// Workloads that are managed by another workload (e.g. the replica sets of a deployment or the jobs of a cron job)
+	// inherit their labels from the owner's pod template. If they carry the instrumentation labels from that template,
+	// the owner has opted in and has been instrumented, hence they count as opted in as well.
+	return len(meta.OwnerReferences) == 0 || !HasBeenInstrumentedSuccessfully(meta)
+}
+
 func CheckAndDeleteIgnoreOnceLabel(meta *metav1.ObjectMeta) bool {
 	if meta.Labels == nil {
 		return false
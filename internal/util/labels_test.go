@@ -4,6 +4,10 @@
 package util
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -47,4 +51,59 @@ var _ = Describe("Dash0 Utils", func() {
 			Expect(labelFromLongImageName).To(HaveLen(63))
 		})
 	})
+
+	DescribeTable("checking whether a workload has opted out of instrumentation",
+		func(
+			labels map[string]string,
+			hasOwner bool,
+			policy dash0v1alpha1.InstrumentationPolicy,
+			expected bool,
+		) {
+			objectMeta := &metav1.ObjectMeta{Labels: labels}
+			if hasOwner {
+				objectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "owner"}}
+			}
+			Expect(HasOptedOutOfInstrumentation(objectMeta, policy)).To(Equal(expected))
+		},
+		Entry("opt-out: no label", nil, false, dash0v1alpha1.OptOut, false),
+		Entry("opt-out: dash0.com/enable=true", map[string]string{"dash0.com/enable": "true"}, false, dash0v1alpha1.OptOut, false),
+		Entry("opt-out: dash0.com/enable=false", map[string]string{"dash0.com/enable": "false"}, false, dash0v1alpha1.OptOut, true),
+		Entry("opt-in: no label", nil, false, dash0v1alpha1.OptIn, true),
+		Entry("opt-in: dash0.com/enable=true", map[string]string{"dash0.com/enable": "true"}, false, dash0v1alpha1.OptIn, false),
+		Entry("opt-in: dash0.com/enable=false", map[string]string{"dash0.com/enable": "false"}, false, dash0v1alpha1.OptIn, true),
+		Entry("opt-in: dash0.com/enable=other", map[string]string{"dash0.com/enable": "other"}, false, dash0v1alpha1.OptIn, true),
+		Entry("opt-in: owned, not instrumented", nil, true, dash0v1alpha1.OptIn, true),
+		Entry("opt-in: owned, inherited instrumentation from owner",
+			map[string]string{"dash0.com/instrumented": "true"}, true, dash0v1alpha1.OptIn, false),
+		Entry("opt-in: owned, inherited instrumentation, dash0.com/enable=false",
+			map[string]string{"dash0.com/instrumented": "true", "dash0.com/enable": "false"}, true, dash0v1alpha1.OptIn, true),
+	)
+
+	Describe("marking workloads for lazy uninstrumentation", func() {
+		It("should mark and unmark a workload", func() {
+			objectMeta := &metav1.ObjectMeta{}
+			Expect(IsUninstrumentationPending(objectMeta)).To(BeFalse())
+			MarkUninstrumentationPending(objectMeta)
+			Expect(IsUninstrumentationPending(objectMeta)).To(BeTrue())
+			Expect(RemoveUninstrumentationPendingLabel(objectMeta)).To(BeTrue())
+			Expect(IsUninstrumentationPending(objectMeta)).To(BeFalse())
+			Expect(RemoveUninstrumentationPendingLabel(objectMeta)).To(BeFalse())
+		})
+
+		It("should remove the mark together with the other instrumentation labels", func() {
+			objectMeta := &metav1.ObjectMeta{}
+			MarkUninstrumentationPending(objectMeta)
+			RemoveInstrumentationLabels(objectMeta)
+			Expect(IsUninstrumentationPending(objectMeta)).To(BeFalse())
+		})
+	})
+
+	DescribeTable("checking whether a workload is managed by the Dash0 operator",
+		func(labels map[string]string, expected bool) {
+			Expect(IsManagedByDash0Operator(&metav1.ObjectMeta{Labels: labels})).To(Equal(expected))
+		},
+		Entry("no labels", nil, false),
+		Entry("managed by the Dash0 operator", map[string]string{"app.kubernetes.io/managed-by": "dash0-operator"}, true),
+		Entry("managed by something else", map[string]string{"app.kubernetes.io/managed-by": "Helm"}, false),
+	)
 })
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+)
+
+const (
+	MirroredSecretLabelKey            = "dash0.com/mirrored-secret"
+	mirroredSecretSourceAnnotationKey = "dash0.com/mirrored-from"
+	mirroredSecretNamePrefix          = "dash0-mirrored-secret-"
+)
+
+// IsCrossNamespaceSecretRef returns true if the secret ref points to a secret in a namespace other than the operator
+// namespace. Pods can only reference secrets in their own namespace, so the operator needs to mirror such a secret into
+// its own namespace.
+func IsCrossNamespaceSecretRef(secretRef *dash0v1alpha1.SecretRef, operatorNamespace string) bool {
+	return secretRef != nil && secretRef.Namespace != "" && secretRef.Namespace != operatorNamespace
+}
+
+// IsSecretRefAllowedForMonitoringResource returns false if the secret ref of a Dash0 monitoring resource points to a
+// secret in a namespace other than the namespace of the monitoring resource itself. Only the cluster-scoped Dash0
+// operator configuration resource may reference secrets in arbitrary namespaces. Otherwise, everyone who is allowed to
+// create a monitoring resource in their own namespace could make the operator send any secret in the cluster to an
+// endpoint of their choice.
+func IsSecretRefAllowedForMonitoringResource(
+	secretRef *dash0v1alpha1.SecretRef,
+	monitoringResourceNamespace string,
+) bool {
+	return secretRef == nil || secretRef.Namespace == "" || secretRef.Namespace == monitoringResourceNamespace
+}
+
+// MirroredSecretName returns the name of the secret in the operator namespace that mirrors the secret referenced by the
+// given secret ref. The name is derived from a hash of the source namespace and name, so that secrets with the same name
+// in different namespaces do not collide and the name does not exceed the maximum length.
+func MirroredSecretName(secretRef dash0v1alpha1.SecretRef) string {
+	hash := sha256.Sum256([]byte(secretRef.Namespace + "/" + secretRef.Name))
+	return mirroredSecretNamePrefix + hex.EncodeToString(hash[:])[:16]
+}
+
+// ResolveMirroredSecretRef returns a copy of the authorization in which a secret ref pointing to a secret in a different
+// namespace is replaced by a secret ref to its mirrored copy in the operator namespace. All other authorization settings
+// are returned unchanged, apart from dropping a namespace that is identical to the operator namespace.
+func ResolveMirroredSecretRef(
+	authorization dash0v1alpha1.Authorization,
+	operatorNamespace string,
+) dash0v1alpha1.Authorization {
+	secretRef := authorization.SecretRef
+	if secretRef == nil || secretRef.Namespace == "" {
+		return authorization
+	}
+	resolved := *authorization.DeepCopy()
+	if IsCrossNamespaceSecretRef(secretRef, operatorNamespace) {
+		resolved.SecretRef = &dash0v1alpha1.SecretRef{
+			Name: MirroredSecretName(*secretRef),
+			Key:  secretRef.Key,
+		}
+	} else {
+		resolved.SecretRef.Namespace = ""
+	}
+	return resolved
+}
+
+// MirrorSecret copies the key referenced by the given secret ref from the secret in its namespace to the mirrored secret
+// in the operator namespace (see MirroredSecretName), creating or updating the mirrored secret as necessary. The
+// mirrored secret is owned by the given owner, so it is garbage collected together with it. Secret refs that do not
+// point to a different namespace are ignored.
+func MirrorSecret(
+	ctx context.Context,
+	k8sClient client.Client,
+	scheme *runtime.Scheme,
+	secretRef *dash0v1alpha1.SecretRef,
+	operatorNamespace string,
+	owner client.Object,
+	logger *logr.Logger,
+) error {
+	if !IsCrossNamespaceSecretRef(secretRef, operatorNamespace) {
+		return nil
+	}
+
+	sourceSecret := &corev1.Secret{}
+	if err := k8sClient.Get(
+		ctx,
+		client.ObjectKey{Namespace: secretRef.Namespace, Name: secretRef.Name},
+		sourceSecret,
+	); err != nil {
+		return fmt.Errorf("cannot read the secret %s/%s: %w", secretRef.Namespace, secretRef.Name, err)
+	}
+	value, hasKey := sourceSecret.Data[secretRef.Key]
+	if !hasKey {
+		return fmt.Errorf("the secret %s/%s does not have the key %s", secretRef.Namespace, secretRef.Name, secretRef.Key)
+	}
+
+	desiredData := map[string][]byte{secretRef.Key: value}
+	source := fmt.Sprintf("%s/%s", secretRef.Namespace, secretRef.Name)
+	mirroredSecret := &corev1.Secret{}
+	err := k8sClient.Get(
+		ctx,
+		client.ObjectKey{Namespace: operatorNamespace, Name: MirroredSecretName(*secretRef)},
+		mirroredSecret,
+	)
+	if apierrors.IsNotFound(err) {
+		mirroredSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   operatorNamespace,
+				Name:        MirroredSecretName(*secretRef),
+				Labels:      map[string]string{MirroredSecretLabelKey: "true"},
+				Annotations: map[string]string{mirroredSecretSourceAnnotationKey: source},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: desiredData,
+		}
+		if err = controllerutil.SetControllerReference(owner, mirroredSecret, scheme); err != nil {
+			return fmt.Errorf("cannot set the owner reference on the mirrored secret: %w", err)
+		}
+		if err = k8sClient.Create(ctx, mirroredSecret); err != nil {
+			return fmt.Errorf("cannot create the mirrored secret for %s: %w", source, err)
+		}
+		logger.Info("The secret has been mirrored into the operator namespace.", "source", source, "mirror", mirroredSecret.Name)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read the mirrored secret for %s: %w", source, err)
+	}
+
+	if maps.EqualFunc(mirroredSecret.Data, desiredData, slices.Equal) &&
+		mirroredSecret.Labels[MirroredSecretLabelKey] == "true" &&
+		mirroredSecret.Annotations[mirroredSecretSourceAnnotationKey] == source {
+		return nil
+	}
+	if mirroredSecret.Labels == nil {
+		mirroredSecret.Labels = make(map[string]string)
+	}
+	mirroredSecret.Labels[MirroredSecretLabelKey] = "true"
+	if mirroredSecret.Annotations == nil {
+		mirroredSecret.Annotations = make(map[string]string)
+	}
+	mirroredSecret.Annotations[mirroredSecretSourceAnnotationKey] = source
+	mirroredSecret.Data = desiredData
+	if err = k8sClient.Update(ctx, mirroredSecret); err != nil {
+		return fmt.Errorf("cannot update the mirrored secret for %s: %w", source, err)
+	}
+	logger.Info("The mirrored secret has been updated.", "source", source, "mirror", mirroredSecret.Name)
+	return nil
+}
+
+// DeleteUnreferencedMirroredSecrets deletes all mirrored secrets in the operator namespace that do not mirror any of the
+// given secret refs anymore.
+func DeleteUnreferencedMirroredSecrets(
+	ctx context.Context,
+	k8sClient client.Client,
+	operatorNamespace string,
+	referencedSecretRefs []dash0v1alpha1.SecretRef,
+	logger *logr.Logger,
+) error {
+	mirroredSecrets := &corev1.SecretList{}
+	if err := k8sClient.List(
+		ctx,
+		mirroredSecrets,
+		client.InNamespace(operatorNamespace),
+		client.MatchingLabels{MirroredSecretLabelKey: "true"},
+	); err != nil {
+		return fmt.Errorf("cannot list the mirrored secrets: %w", err)
+	}
+	referencedNames := make(map[string]bool, len(referencedSecretRefs))
+	for _, secretRef := range referencedSecretRefs {
+		if IsCrossNamespaceSecretRef(&secretRef, operatorNamespace) {
+			referencedNames[MirroredSecretName(secretRef)] = true
+		}
+	}
+	for _, mirroredSecret := range mirroredSecrets.Items {
+		if referencedNames[mirroredSecret.Name] {
+			continue
+		}
+		if err := k8sClient.Delete(ctx, &mirroredSecret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete the mirrored secret %s: %w", mirroredSecret.Name, err)
+		}
+		logger.Info(
+			"The mirrored secret is no longer referenced and has been deleted.",
+			"mirror", mirroredSecret.Name,
+			"source", mirroredSecret.Annotations[mirroredSecretSourceAnnotationKey],
+		)
+	}
+	return nil
+}
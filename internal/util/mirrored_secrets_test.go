@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"k8s.io/utils/ptr"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const operatorNamespace = "dash0-system"
+
+var _ = Describe("Mirroring secrets into the operator namespace", func() {
+
+	Describe("deriving the name of the mirrored secret", func() {
+		It("should derive a stable name", func() {
+			secretRef := dash0v1alpha1.SecretRef{Namespace: "secrets", Name: "dash0", Key: "token"}
+			Expect(MirroredSecretName(secretRef)).To(Equal(MirroredSecretName(secretRef)))
+			Expect(MirroredSecretName(secretRef)).To(HavePrefix("dash0-mirrored-secret-"))
+			Expect(len(MirroredSecretName(secretRef))).To(BeNumerically("<=", 63))
+		})
+
+		It("should not depend on the key", func() {
+			Expect(MirroredSecretName(dash0v1alpha1.SecretRef{Namespace: "secrets", Name: "dash0", Key: "a"})).To(
+				Equal(MirroredSecretName(dash0v1alpha1.SecretRef{Namespace: "secrets", Name: "dash0", Key: "b"})))
+		})
+
+		It("should derive different names for secrets whose namespace and name only differ in the separator", func() {
+			Expect(MirroredSecretName(dash0v1alpha1.SecretRef{Namespace: "a-b", Name: "c"})).ToNot(
+				Equal(MirroredSecretName(dash0v1alpha1.SecretRef{Namespace: "a", Name: "b-c"})))
+		})
+	})
+
+	Describe("checking the secret ref of a monitoring resource", func() {
+		It("should allow a secret ref without namespace", func() {
+			Expect(IsSecretRefAllowedForMonitoringResource(
+				&dash0v1alpha1.SecretRef{Name: "dash0", Key: "token"}, "team-a")).To(BeTrue())
+		})
+
+		It("should allow a secret ref to the namespace of the monitoring resource", func() {
+			Expect(IsSecretRefAllowedForMonitoringResource(
+				&dash0v1alpha1.SecretRef{Name: "dash0", Key: "token", Namespace: "team-a"}, "team-a")).To(BeTrue())
+		})
+
+		It("should reject a secret ref to a different namespace", func() {
+			Expect(IsSecretRefAllowedForMonitoringResource(
+				&dash0v1alpha1.SecretRef{Name: "dash0", Key: "token", Namespace: "team-b"}, "team-a")).To(BeFalse())
+		})
+	})
+
+	Describe("resolving the secret ref", func() {
+		It("should leave a token unchanged", func() {
+			authorization := dash0v1alpha1.Authorization{Token: ptr.To("token")}
+			Expect(ResolveMirroredSecretRef(authorization, operatorNamespace)).To(Equal(authorization))
+		})
+
+		It("should leave a secret ref without namespace unchanged", func() {
+			authorization := dash0v1alpha1.Authorization{
+				SecretRef: &dash0v1alpha1.SecretRef{Name: "dash0", Key: "token"},
+			}
+			Expect(ResolveMirroredSecretRef(authorization, operatorNamespace)).To(Equal(authorization))
+		})
+
+		It("should drop a namespace that is identical to the operator namespace", func() {
+			secretRef := &dash0v1alpha1.SecretRef{Name: "dash0", Key: "token", Namespace: operatorNamespace}
+			resolved := ResolveMirroredSecretRef(dash0v1alpha1.Authorization{SecretRef: secretRef}, operatorNamespace)
+			Expect(*resolved.SecretRef).To(Equal(dash0v1alpha1.SecretRef{Name: "dash0", Key: "token"}))
+			Expect(secretRef.Namespace).To(Equal(operatorNamespace))
+		})
+
+		It("should point a secret ref to a different namespace to the mirrored secret", func() {
+			secretRef := &dash0v1alpha1.SecretRef{Name: "dash0", Key: "token", Namespace: "secrets"}
+			resolved := ResolveMirroredSecretRef(dash0v1alpha1.Authorization{SecretRef: secretRef}, operatorNamespace)
+			Expect(*resolved.SecretRef).To(Equal(dash0v1alpha1.SecretRef{
+				Name: MirroredSecretName(*secretRef),
+				Key:  "token",
+			}))
+			Expect(secretRef.Name).To(Equal("dash0"))
+		})
+	})
+})
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ottlEditorInvocation matches the start of an OTTL statement, which is always the invocation of an editor function.
+// Editor names are lower case, in contrast to converter names.
+var ottlEditorInvocation = regexp.MustCompile(`^[a-z][a-z0-9_]*\(`)
+
+var ottlClosingBrackets = map[rune]rune{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// CheckOttlCondition performs a basic syntax check of a user-provided OTTL condition: it must not be blank, string
+// literals must be terminated, and parentheses, brackets and braces must be balanced outside of string literals. This
+// does not replace the OTTL parser of the collector, but it rejects conditions that would either not be accepted by the
+// collector at all (which would make the collector fail at startup for all namespaces) or that could escape the
+// parentheses the operator puts around them, e.g. `true) or (true`.
+func CheckOttlCondition(condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return fmt.Errorf("the condition is empty")
+	}
+	return checkOttlBrackets(condition)
+}
+
+// CheckOttlStatement performs a basic syntax check of a user-provided OTTL statement, in the same way as
+// CheckOttlCondition. Additionally, the statement must start with the invocation of an editor function, e.g. `set(`.
+func CheckOttlStatement(statement string) error {
+	trimmedStatement := strings.TrimSpace(statement)
+	if trimmedStatement == "" {
+		return fmt.Errorf("the statement is empty")
+	}
+	if !ottlEditorInvocation.MatchString(trimmedStatement) {
+		return fmt.Errorf("the statement does not start with the invocation of an editor function, like set(...)")
+	}
+	return checkOttlBrackets(statement)
+}
+
+func checkOttlBrackets(expression string) error {
+	var expectedClosingBrackets []rune
+	inStringLiteral := false
+	escaped := false
+	for position, character := range expression {
+		if inStringLiteral {
+			switch {
+			case escaped:
+				escaped = false
+			case character == '\\':
+				escaped = true
+			case character == '"':
+				inStringLiteral = false
+			}
+			continue
+		}
+		switch character {
+		case '"':
+			inStringLiteral = true
+		case '(', '[', '{':
+			expectedClosingBrackets = append(expectedClosingBrackets, ottlClosingBrackets[character])
+		case ')', ']', '}':
+			if len(expectedClosingBrackets) == 0 ||
+				expectedClosingBrackets[len(expectedClosingBrackets)-1] != character {
+				return fmt.Errorf("unexpected \"%c\" at position %d", character, position)
+			}
+			expectedClosingBrackets = expectedClosingBrackets[:len(expectedClosingBrackets)-1]
+		}
+	}
+	if inStringLiteral {
+		return fmt.Errorf("unterminated string literal")
+	}
+	if len(expectedClosingBrackets) > 0 {
+		return fmt.Errorf("missing \"%c\"", expectedClosingBrackets[len(expectedClosingBrackets)-1])
+	}
+	return nil
+}
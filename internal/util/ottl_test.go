@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checking OTTL syntax", func() {
+
+	DescribeTable("should accept well-formed conditions",
+		func(condition string) {
+			Expect(CheckOttlCondition(condition)).To(Succeed())
+		},
+		Entry("a comparison", `attributes["url.path"] == "/healthz"`),
+		Entry("a converter call", `IsMatch(name, "GET /(healthz|readyz)")`),
+		Entry("nested parentheses", `(name == "a" or name == "b") and (kind == 2)`),
+		Entry("brackets in string literals", `body == "(unbalanced]"`),
+		Entry("escaped quotes in string literals", `body == "say \"hello\" )"`),
+		Entry("a map literal", `attributes == {"a": ["b", "c"]}`),
+	)
+
+	DescribeTable("should reject malformed conditions",
+		func(condition string, expectedMessage string) {
+			Expect(CheckOttlCondition(condition)).To(MatchError(expectedMessage))
+		},
+		Entry("a blank condition", "  ", "the condition is empty"),
+		Entry("a condition escaping its parentheses", `true) or (true`, `unexpected ")" at position 4`),
+		Entry("a missing closing parenthesis", `IsMatch(name, "GET"`, `missing ")"`),
+		Entry("mismatched brackets", `attributes["a")`, `unexpected ")" at position 14`),
+		Entry("an unterminated string literal", `name == "GET /healthz`, "unterminated string literal"),
+	)
+
+	DescribeTable("should accept well-formed statements",
+		func(statement string) {
+			Expect(CheckOttlStatement(statement)).To(Succeed())
+		},
+		Entry("an editor call", `delete_key(attributes, "uid")`),
+		Entry("an editor call with a where clause",
+			`set(attributes["user.id"], attributes["uid"]) where attributes["uid"] != nil`),
+		Entry("an editor call with a converter",
+			`replace_pattern(attributes["http.url"], "token=[^&]*", "token=***")`),
+	)
+
+	DescribeTable("should reject malformed statements",
+		func(statement string, expectedMessage string) {
+			Expect(CheckOttlStatement(statement)).To(MatchError(ContainSubstring(expectedMessage)))
+		},
+		Entry("a blank statement", " ", "the statement is empty"),
+		Entry("a condition", `attributes["a"] == "b"`, "does not start with the invocation of an editor function"),
+		Entry("a converter call", `Concat(["a", "b"], "-")`, "does not start with the invocation of an editor function"),
+		Entry("a missing closing parenthesis", `set(attributes["a"], "b"`, `missing ")"`),
+		Entry("an unterminated string literal", `set(attributes["a"], "b)`, "unterminated string literal"),
+	)
+})
@@ -4,6 +4,8 @@
 package util
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -18,6 +20,13 @@ const (
 	ReasonSuccessfulUninstrumentation  Reason = "SuccessfulUninstrumentation"
 	ReasonNoUninstrumentationNecessary Reason = "AlreadyNotInstrumented"
 	ReasonFailedUninstrumentation      Reason = "FailedUninstrumentation"
+	ReasonOwnerInstrumented            Reason = "OwnerInstrumented"
+	ReasonOptedOutOfInstrumentation    Reason = "OptedOutOfInstrumentation"
+	ReasonNotOptedInToInstrumentation  Reason = "NotOptedInToInstrumentation"
+	ReasonOwnedByHigherOrderWorkload   Reason = "OwnedByHigherOrderWorkload"
+	ReasonReinstrumentedAfterUpgrade   Reason = "ReinstrumentedAfterUpgrade"
+	ReasonContainersNotInstrumented    Reason = "ContainersNotInstrumented"
+	ReasonWorkloadTooLargeForWebhook   Reason = "WorkloadTooLargeForWebhook"
 )
 
 var AllEvents = []Reason{
@@ -27,6 +36,13 @@ var AllEvents = []Reason{
 	ReasonSuccessfulUninstrumentation,
 	ReasonNoUninstrumentationNecessary,
 	ReasonFailedUninstrumentation,
+	ReasonOwnerInstrumented,
+	ReasonOptedOutOfInstrumentation,
+	ReasonNotOptedInToInstrumentation,
+	ReasonOwnedByHigherOrderWorkload,
+	ReasonReinstrumentedAfterUpgrade,
+	ReasonContainersNotInstrumented,
+	ReasonWorkloadTooLargeForWebhook,
 }
 
 type Images struct {
@@ -39,12 +55,66 @@ type Images struct {
 	ConfigurationReloaderImagePullPolicy corev1.PullPolicy
 	FilelogOffsetSynchImage              string
 	FilelogOffsetSynchImagePullPolicy    corev1.PullPolicy
+	// InitContainerImageAllowedRegistries lists the registries (optionally including a repository path prefix, like
+	// ghcr.io/dash0hq) from which images can be used to override the init container image for individual workloads via
+	// the annotation dash0.com/init-container-image. If it is empty, only images from the registry of
+	// InitContainerImage are allowed.
+	InitContainerImageAllowedRegistries []string
 }
 
 func (i Images) GetOperatorVersion() string {
 	return getImageVersion(i.OperatorImage)
 }
 
+// CheckCollectorImageVersion checks whether the version of the collector image is compatible with the version of the
+// operator. Dash0 releases the collector image together with the operator, so collector images with the same major and
+// minor version as the operator are compatible. It returns an error describing the mismatch for incompatible versions.
+// If one of the versions cannot be determined (e.g. for an image that is referenced by digest, or a tag like latest),
+// compatibility cannot be checked, and the returned boolean is false.
+func (i Images) CheckCollectorImageVersion() (bool, error) {
+	operatorVersion := getImageVersion(i.OperatorImage)
+	collectorVersion := getImageVersion(i.CollectorImage)
+	operatorMajor, operatorMinor, operatorVersionOk := parseMajorMinorVersion(operatorVersion)
+	collectorMajor, collectorMinor, collectorVersionOk := parseMajorMinorVersion(collectorVersion)
+	if !operatorVersionOk || !collectorVersionOk {
+		return false, nil
+	}
+	if operatorMajor != collectorMajor || operatorMinor != collectorMinor {
+		return true, fmt.Errorf(
+			"the collector image %s has version %s, which is not compatible with the operator version %s; only "+
+				"collector images with version %d.%d.x are known to work with this operator version",
+			i.CollectorImage,
+			collectorVersion,
+			operatorVersion,
+			operatorMajor,
+			operatorMinor,
+		)
+	}
+	return true, nil
+}
+
+// parseMajorMinorVersion extracts the major and minor version from a semantic version like 1.2.3, v1.2.3 or
+// 1.2.3-rc.1.
+func parseMajorMinorVersion(version string) (int, int, bool) {
+	version = strings.TrimPrefix(version, "v")
+	if idx := strings.IndexAny(version, "-+"); idx >= 0 {
+		version = version[:idx]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
 func getImageVersion(image string) string {
 	idx := strings.LastIndex(image, "@")
 	if idx >= 0 {
@@ -57,11 +127,28 @@ func getImageVersion(image string) string {
 	return ""
 }
 
+// InitContainerSecurityContext configures the security context of the Dash0 instrumentation init container. The zero
+// value yields the hardened defaults, that is, the RuntimeDefault seccomp profile and all capabilities dropped.
+type InitContainerSecurityContext struct {
+	// SeccompProfileType is either RuntimeDefault or Unconfined, defaults to RuntimeDefault.
+	SeccompProfileType corev1.SeccompProfileType
+	// AddCapabilities lists capabilities that are added to the init container. All other capabilities are dropped.
+	AddCapabilities []corev1.Capability
+}
+
 type InstrumentationMetadata struct {
 	Images
-	OTelCollectorBaseUrl string
-	IsIPv6Cluster        bool
-	InstrumentedBy       string
+	OTelCollectorBaseUrl         string
+	IsIPv6Cluster                bool
+	CollectorGatewayMode         bool
+	InstrumentedBy               string
+	InitContainerSecurityContext InitContainerSecurityContext
+	// InitContainerResources overrides the resource requests and limits of the Dash0 instrumentation init container.
+	// Requests and limits which are not set here fall back to small defaults.
+	InitContainerResources corev1.ResourceRequirements
+	// PropagatedWorkloadLabels lists the keys of workload labels that are added to the OTEL_RESOURCE_ATTRIBUTES of
+	// instrumented containers, as k8s.<kind>.label.<key>.
+	PropagatedWorkloadLabels []string
 }
 
 type ModificationMode string
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Images", func() {
+
+	DescribeTable("checking the collector image version",
+		func(operatorImage string, collectorImage string, expectChecked bool, expectCompatible bool) {
+			images := Images{
+				OperatorImage:  operatorImage,
+				CollectorImage: collectorImage,
+			}
+			checked, err := images.CheckCollectorImageVersion()
+			Expect(checked).To(Equal(expectChecked))
+			if expectCompatible {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring("is not compatible with the operator version")))
+			}
+		},
+		Entry("same version",
+			"ghcr.io/dash0hq/operator-controller:0.45.1", "ghcr.io/dash0hq/collector:0.45.1", true, true),
+		Entry("different patch version",
+			"ghcr.io/dash0hq/operator-controller:0.45.1", "ghcr.io/dash0hq/collector:0.45.3", true, true),
+		Entry("v prefix and pre-release",
+			"ghcr.io/dash0hq/operator-controller:v0.45.1", "ghcr.io/dash0hq/collector:0.45.0-rc.1", true, true),
+		Entry("different minor version",
+			"ghcr.io/dash0hq/operator-controller:0.45.1", "ghcr.io/dash0hq/collector:0.44.0", true, false),
+		Entry("different major version",
+			"ghcr.io/dash0hq/operator-controller:1.0.0", "ghcr.io/dash0hq/collector:0.99.0", true, false),
+		Entry("registry with port",
+			"registry.example.com:5000/operator-controller:0.45.1", "registry.example.com:5000/collector:0.45.1", true,
+			true),
+		Entry("collector image by digest",
+			"ghcr.io/dash0hq/operator-controller:0.45.1",
+			"ghcr.io/dash0hq/collector@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			false, true),
+		Entry("latest tag", "ghcr.io/dash0hq/operator-controller:0.45.1", "ghcr.io/dash0hq/collector:latest", false, true),
+		Entry("operator image without tag", "operator-controller", "ghcr.io/dash0hq/collector:0.45.1", false, true),
+	)
+})
@@ -9,6 +9,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -45,3 +46,13 @@ func GetReceiverForWorkloadType(
 	return nil, fmt.Errorf(
 		"unexpected combination of APIVersion and Kind for referenced object: '%s/%s'", apiVersion, kind)
 }
+
+// FindHigherOrderOwner returns the first owner reference of the given workload, or nil if the workload has no owner.
+// Replica sets and pods that are managed by a higher order workload (e.g. a deployment) are not instrumented
+// themselves, the instrumentation is applied to the template of their owner instead.
+func FindHigherOrderOwner(meta *metav1.ObjectMeta) *metav1.OwnerReference {
+	if len(meta.OwnerReferences) == 0 {
+		return nil
+	}
+	return &meta.OwnerReferences[0]
+}
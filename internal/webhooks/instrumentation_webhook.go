@@ -4,20 +4,28 @@
 package webhooks
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,26 +37,56 @@ import (
 )
 
 type InstrumentationWebhookHandler struct {
-	Client               client.Client
-	Recorder             record.EventRecorder
-	Images               util.Images
-	OTelCollectorBaseUrl string
-	IsIPv6Cluster        bool
+	Client                       client.Client
+	Recorder                     record.EventRecorder
+	Images                       util.Images
+	OTelCollectorBaseUrl         string
+	IsIPv6Cluster                bool
+	CollectorGatewayMode         bool
+	InitContainerSecurityContext util.InitContainerSecurityContext
+	InitContainerResources       corev1.ResourceRequirements
+	PropagatedWorkloadLabels     []string
+	// MaxObjectSize is the maximum size of the serialized workload in an admission request, in bytes. The webhook
+	// skips larger workloads instead of decoding and modifying them, to stay well below the webhook timeout. Zero means
+	// no limit.
+	MaxObjectSize int64
+	// OperatorNamespace is the namespace the operator is installed in. The webhook never modifies workloads in this
+	// namespace, even if a Dash0 monitoring resource has been deployed to it.
+	OperatorNamespace string
+	AuditLog          util.InstrumentationAuditLog
 }
 
-type resourceHandler func(h *InstrumentationWebhookHandler, request admission.Request, gvkLabel string, logger *logr.Logger) admission.Response
+type resourceHandler func(
+	h *InstrumentationWebhookHandler,
+	request admission.Request,
+	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
+	logger *logr.Logger,
+) admission.Response
 type routing map[string]map[string]map[string]resourceHandler
 
 const (
-	optOutAdmissionAllowedMessage    = "not instrumenting this workload due to dash0.com/enable=false"
+	optOutAdmissionAllowedMessage          = "not instrumenting this workload due to dash0.com/enable=false"
+	namespaceOptOutAdmissionAllowedMessage = "not instrumenting this workload, its namespace has the label " +
+		"dash0.com/enable=false"
+	notOptedInAdmissionAllowedMessage = "not instrumenting this workload, the instrumentation " +
+		"policy of this namespace is opt-in and the workload does not have the label dash0.com/enable=true"
+	kindExcludedAdmissionAllowedMessage               = "kind excluded"
+	ownedByHigherOrderWorkloadAdmissionAllowedMessage = "not instrumenting this workload, since it is managed by a " +
+		"higher order workload which will be instrumented instead"
 	sameVersionNoModificationMessage = "not updating the existing instrumentation for this workload, it has already " +
 		"been successfully instrumented by the same operator version"
+	operatorNamespaceAdmissionAllowedMessage = "not instrumenting this workload, it is deployed in the namespace of " +
+		"the Dash0 operator"
+	managedByOperatorAdmissionAllowedMessage = "not instrumenting this workload, it is managed by the Dash0 operator"
 )
 
 var (
 	log     = logf.Log.WithName("instrumentation-webhook")
 	decoder = scheme.Codecs.UniversalDecoder()
 
+	optedOutWorkloadsMetric otelmetric.Int64Counter
+
 	routes = routing{
 		"": {
 			"Pod": {
@@ -83,6 +121,7 @@ var (
 		h *InstrumentationWebhookHandler,
 		request admission.Request,
 		gvkLabel string,
+		_ dash0v1alpha1.InstrumentationPolicy,
 		logger *logr.Logger,
 	) admission.Response {
 		return logAndReturnAllowed(fmt.Sprintf("resource type not supported: %s", gvkLabel), logger)
@@ -103,6 +142,24 @@ func (h *InstrumentationWebhookHandler) SetupWebhookWithManager(mgr ctrl.Manager
 	return nil
 }
 
+func (h *InstrumentationWebhookHandler) InitializeSelfMonitoringMetrics(
+	meter otelmetric.Meter,
+	metricNamePrefix string,
+	logger *logr.Logger,
+) {
+	optedOutWorkloadsMetricName := fmt.Sprintf("%s%s", metricNamePrefix, "instrumentation.opted_out_workloads")
+	var err error
+	if optedOutWorkloadsMetric, err = meter.Int64Counter(
+		optedOutWorkloadsMetricName,
+		otelmetric.WithUnit("1"),
+		otelmetric.WithDescription(
+			"Counter for workloads the webhook has not instrumented due to the label dash0.com/enable=false, by "+
+				"namespace and kind"),
+	); err != nil {
+		logger.Error(err, fmt.Sprintf("Cannot initialize the metric %s.", optedOutWorkloadsMetricName))
+	}
+}
+
 func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admission.Request) admission.Response {
 	logger := log.WithValues(
 		"operation",
@@ -115,6 +172,18 @@ func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admi
 		request.Name,
 	)
 
+	if h.MaxObjectSize > 0 && int64(len(request.Object.Raw)) > h.MaxObjectSize {
+		return h.skipTooLargeObject(request, &logger)
+	}
+
+	if skipMessage, isOwnWorkload := h.isOperatorOwnWorkload(request); isOwnWorkload {
+		if request.Operation == admissionv1.Update {
+			// the operator updates its own workloads frequently, do not spam the log with those requests
+			return admission.Allowed(skipMessage)
+		}
+		return logAndReturnAllowed(skipMessage, &logger)
+	}
+
 	targetNamespace := request.Namespace
 
 	dash0List := &dash0v1alpha1.Dash0MonitoringList{}
@@ -127,6 +196,9 @@ func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admi
 				targetNamespace,
 			)
 			if request.Operation == admissionv1.Update {
+				if response, handled := h.revertPendingUninstrumentation(request, &logger); handled {
+					return response
+				}
 				// some operators update the resources they manage very frequently (e.g. every few seconds), do not spam
 				// the log with those requests
 				return admission.Allowed(msg)
@@ -153,6 +225,9 @@ func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admi
 			targetNamespace,
 		)
 		if request.Operation == admissionv1.Update {
+			if response, handled := h.revertPendingUninstrumentation(request, &logger); handled {
+				return response
+			}
 			// some operators update the resources they manage very frequently (e.g. every few seconds), do not spam
 			// the log with those requests
 			return admission.Allowed(msg)
@@ -172,6 +247,9 @@ func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admi
 					"not be modified to send telemetry to Dash0.", targetNamespace), &logger)
 	}
 	if dash0MonitoringResource.IsMarkedForDeletion() {
+		if response, handled := h.revertPendingUninstrumentation(request, &logger); handled {
+			return response
+		}
 		return logAndReturnAllowed(
 			fmt.Sprintf(
 				"The Dash0 monitoring resource in the namespace %s is about to be deleted, this workload will not be "+
@@ -184,22 +262,65 @@ func (h *InstrumentationWebhookHandler) Handle(ctx context.Context, request admi
 	}
 	instrumentWorkloads := dash0MonitoringResource.ReadInstrumentWorkloadsSetting()
 	if instrumentWorkloads == dash0v1alpha1.None {
+		if response, handled := h.revertPendingUninstrumentation(request, &logger); handled {
+			return response
+		}
 		return logAndReturnAllowed(fmt.Sprintf("Instrumenting workloads is not enabled in namespace %s, this %s "+
 			"workload will not be modified to send telemetry to Dash0.", targetNamespace, actionPartial), &logger)
 	}
 
+	namespaceExcluded, err := util.IsNamespaceExcludedFromInstrumentation(ctx, h.Client, targetNamespace)
+	if err != nil {
+		return logErrorAndReturnAllowed(
+			fmt.Errorf(
+				"failed to read namespace %s, workload will not be instrumented: %w",
+				targetNamespace,
+				err,
+			),
+			&logger,
+		)
+	}
+	if namespaceExcluded {
+		if request.Operation == admissionv1.Update {
+			return admission.Allowed(namespaceOptOutAdmissionAllowedMessage)
+		}
+		return logAndReturnAllowed(namespaceOptOutAdmissionAllowedMessage, &logger)
+	}
+
 	gkv := request.Kind
 	group := gkv.Group
 	version := gkv.Version
 	kind := gkv.Kind
 	gvkLabel := fmt.Sprintf("%s/%s.%s", group, version, kind)
 
-	return routes.routeFor(group, kind, version)(h, request, gvkLabel, &logger)
+	excludedWorkloadKinds, err := util.FindExcludedWorkloadKinds(ctx, h.Client, &logger)
+	if err != nil {
+		return logErrorAndReturnAllowed(
+			fmt.Errorf(
+				"failed to read the excluded workload kinds from the Dash0 operator configuration resource, workload "+
+					"will not be instrumented: %w",
+				err,
+			),
+			&logger,
+		)
+	}
+	if slices.Contains(excludedWorkloadKinds, dash0v1alpha1.WorkloadKind(kind)) {
+		if request.Operation != admissionv1.Update {
+			logger.Info(fmt.Sprintf("Instrumenting workloads of kind %s has been disabled in the Dash0 operator "+
+				"configuration resource, this %s workload will not be modified to send telemetry to Dash0.",
+				kind, actionPartial))
+		}
+		return admission.Allowed(kindExcludedAdmissionAllowedMessage)
+	}
+
+	policy := dash0MonitoringResource.ReadInstrumentationPolicy()
+	return routes.routeFor(group, kind, version)(h, request, gvkLabel, policy, &logger)
 }
 
 func (h *InstrumentationWebhookHandler) handleCronJob(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	cronJob := &batchv1.CronJob{}
@@ -211,9 +332,9 @@ func (h *InstrumentationWebhookHandler) handleCronJob(
 	if util.CheckAndDeleteIgnoreOnceLabel(&cronJob.ObjectMeta) {
 		return h.postProcessInstrumentation(request, cronJob, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&cronJob.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&cronJob.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&cronJob.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, cronJob, &cronJob.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&cronJob.ObjectMeta, policy) {
 		hasBeenModified := h.newWorkloadModifier(logger).RevertCronJob(cronJob)
 		return h.postProcessUninstrumentation(request, cronJob, hasBeenModified, false, logger)
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(&cronJob.ObjectMeta, h.Images) {
@@ -227,6 +348,7 @@ func (h *InstrumentationWebhookHandler) handleCronJob(
 func (h *InstrumentationWebhookHandler) handleDaemonSet(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	daemonSet := &appsv1.DaemonSet{}
@@ -238,9 +360,9 @@ func (h *InstrumentationWebhookHandler) handleDaemonSet(
 	if util.CheckAndDeleteIgnoreOnceLabel(&daemonSet.ObjectMeta) {
 		return h.postProcessInstrumentation(request, daemonSet, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&daemonSet.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&daemonSet.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&daemonSet.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, daemonSet, &daemonSet.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&daemonSet.ObjectMeta, policy) {
 		hasBeenModified := h.newWorkloadModifier(logger).RevertDaemonSet(daemonSet)
 		return h.postProcessUninstrumentation(request, daemonSet, hasBeenModified, false, logger)
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(&daemonSet.ObjectMeta, h.Images) {
@@ -254,6 +376,7 @@ func (h *InstrumentationWebhookHandler) handleDaemonSet(
 func (h *InstrumentationWebhookHandler) handleDeployment(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	deployment := &appsv1.Deployment{}
@@ -265,9 +388,9 @@ func (h *InstrumentationWebhookHandler) handleDeployment(
 	if util.CheckAndDeleteIgnoreOnceLabel(&deployment.ObjectMeta) {
 		return h.postProcessInstrumentation(request, deployment, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&deployment.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&deployment.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&deployment.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, deployment, &deployment.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&deployment.ObjectMeta, policy) {
 		hasBeenModified := h.newWorkloadModifier(logger).RevertDeployment(deployment)
 		return h.postProcessUninstrumentation(request, deployment, hasBeenModified, false, logger)
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(&deployment.ObjectMeta, h.Images) {
@@ -281,6 +404,7 @@ func (h *InstrumentationWebhookHandler) handleDeployment(
 func (h *InstrumentationWebhookHandler) handleJob(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	job := &batchv1.Job{}
@@ -292,9 +416,9 @@ func (h *InstrumentationWebhookHandler) handleJob(
 	if util.CheckAndDeleteIgnoreOnceLabel(&job.ObjectMeta) {
 		return h.postProcessInstrumentation(request, job, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&job.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&job.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&job.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, job, &job.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&job.ObjectMeta, policy) {
 		// This should not happen, since it can only happen for an admission request with operation=UPDATE, and we are
 		// not listening to udpates for jobs. We cannot uninstrument jobs if the user adds an opt-out label after the
 		// job has been already instrumented, since jobs are immutable.
@@ -311,6 +435,7 @@ func (h *InstrumentationWebhookHandler) handleJob(
 func (h *InstrumentationWebhookHandler) handlePod(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	pod := &corev1.Pod{}
@@ -322,9 +447,9 @@ func (h *InstrumentationWebhookHandler) handlePod(
 	if util.CheckAndDeleteIgnoreOnceLabel(&pod.ObjectMeta) {
 		return h.postProcessInstrumentation(request, pod, false, true, true, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&pod.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&pod.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&pod.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, pod, &pod.ObjectMeta, true, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&pod.ObjectMeta, policy) {
 		// This should not happen, since it can only happen for an admission request with operation=UPDATE, and we are
 		// not listening to udpates for pods. We cannot uninstrument ownerless pods if the user adds an opt-out label
 		// after the pod has been already instrumented, since we cannot restart ownerless pods, which makes them
@@ -342,6 +467,7 @@ func (h *InstrumentationWebhookHandler) handlePod(
 func (h *InstrumentationWebhookHandler) handleReplicaSet(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	replicaSet := &appsv1.ReplicaSet{}
@@ -353,13 +479,16 @@ func (h *InstrumentationWebhookHandler) handleReplicaSet(
 	if util.CheckAndDeleteIgnoreOnceLabel(&replicaSet.ObjectMeta) {
 		return h.postProcessInstrumentation(request, replicaSet, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&replicaSet.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&replicaSet.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&replicaSet.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, replicaSet, &replicaSet.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&replicaSet.ObjectMeta, policy) {
 		hasBeenModified := h.newWorkloadModifier(logger).RevertReplicaSet(replicaSet)
 		return h.postProcessUninstrumentation(request, replicaSet, hasBeenModified, false, logger)
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(&replicaSet.ObjectMeta, h.Images) {
 		return logAndReturnAllowed(sameVersionNoModificationMessage, logger)
+	} else if owner := util.FindHigherOrderOwner(&replicaSet.ObjectMeta); owner != nil {
+		util.QueueOwnedByHigherOrderWorkloadEvent(h.Recorder, replicaSet, owner.Kind, owner.Name, "webhook")
+		return logAndReturnAllowed(ownedByHigherOrderWorkloadAdmissionAllowedMessage, logger)
 	} else {
 		hasBeenModified := h.newWorkloadModifier(logger).ModifyReplicaSet(replicaSet)
 		return h.postProcessInstrumentation(request, replicaSet, hasBeenModified, false, false, logger)
@@ -369,6 +498,7 @@ func (h *InstrumentationWebhookHandler) handleReplicaSet(
 func (h *InstrumentationWebhookHandler) handleStatefulSet(
 	request admission.Request,
 	gvkLabel string,
+	policy dash0v1alpha1.InstrumentationPolicy,
 	logger *logr.Logger,
 ) admission.Response {
 	statefulSet := &appsv1.StatefulSet{}
@@ -380,9 +510,9 @@ func (h *InstrumentationWebhookHandler) handleStatefulSet(
 	if util.CheckAndDeleteIgnoreOnceLabel(&statefulSet.ObjectMeta) {
 		return h.postProcessInstrumentation(request, statefulSet, false, true, false, logger)
 	}
-	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&statefulSet.ObjectMeta) {
-		return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
-	} else if util.WasInstrumentedButHasOptedOutNow(&statefulSet.ObjectMeta) {
+	if util.HasOptedOutOfInstrumentationAndIsUninstrumented(&statefulSet.ObjectMeta, policy) {
+		return h.postProcessOptOut(request, statefulSet, &statefulSet.ObjectMeta, false, logger)
+	} else if util.WasInstrumentedButHasOptedOutNow(&statefulSet.ObjectMeta, policy) {
 		hasBeenModified := h.newWorkloadModifier(logger).RevertStatefulSet(statefulSet)
 		return h.postProcessUninstrumentation(request, statefulSet, hasBeenModified, false, logger)
 	} else if util.HasBeenInstrumentedSuccessfullyByThisVersion(&statefulSet.ObjectMeta, h.Images) {
@@ -393,6 +523,77 @@ func (h *InstrumentationWebhookHandler) handleStatefulSet(
 	}
 }
 
+// revertPendingUninstrumentation removes the Dash0 instrumentation from a workload that the controller has marked with
+// dash0.com/uninstrumentation-pending (see dash0v1alpha1.Lazy), if the admission request is an update that changes the
+// pod template of the workload. Such an update rolls out new pods anyway, so removing the instrumentation at this point
+// does not cause an additional restart. The second return value is false if the request is not such an update, the
+// caller then handles the request as usual.
+func (h *InstrumentationWebhookHandler) revertPendingUninstrumentation(
+	request admission.Request,
+	logger *logr.Logger,
+) (admission.Response, bool) {
+	if request.Operation != admissionv1.Update || len(request.OldObject.Raw) == 0 {
+		return admission.Response{}, false
+	}
+
+	modifier := h.newWorkloadModifier(logger)
+	var workload, oldWorkload runtime.Object
+	var objectMeta *metav1.ObjectMeta
+	var podTemplate, oldPodTemplate *corev1.PodTemplateSpec
+	var revert func() bool
+	switch request.Kind.Group + "/" + request.Kind.Kind {
+	case "batch/CronJob":
+		cronJob, oldCronJob := &batchv1.CronJob{}, &batchv1.CronJob{}
+		workload, oldWorkload = cronJob, oldCronJob
+		objectMeta = &cronJob.ObjectMeta
+		podTemplate, oldPodTemplate = &cronJob.Spec.JobTemplate.Spec.Template, &oldCronJob.Spec.JobTemplate.Spec.Template
+		revert = func() bool { return modifier.RevertCronJob(cronJob) }
+	case "apps/DaemonSet":
+		daemonSet, oldDaemonSet := &appsv1.DaemonSet{}, &appsv1.DaemonSet{}
+		workload, oldWorkload = daemonSet, oldDaemonSet
+		objectMeta = &daemonSet.ObjectMeta
+		podTemplate, oldPodTemplate = &daemonSet.Spec.Template, &oldDaemonSet.Spec.Template
+		revert = func() bool { return modifier.RevertDaemonSet(daemonSet) }
+	case "apps/Deployment":
+		deployment, oldDeployment := &appsv1.Deployment{}, &appsv1.Deployment{}
+		workload, oldWorkload = deployment, oldDeployment
+		objectMeta = &deployment.ObjectMeta
+		podTemplate, oldPodTemplate = &deployment.Spec.Template, &oldDeployment.Spec.Template
+		revert = func() bool { return modifier.RevertDeployment(deployment) }
+	case "apps/ReplicaSet":
+		replicaSet, oldReplicaSet := &appsv1.ReplicaSet{}, &appsv1.ReplicaSet{}
+		workload, oldWorkload = replicaSet, oldReplicaSet
+		objectMeta = &replicaSet.ObjectMeta
+		podTemplate, oldPodTemplate = &replicaSet.Spec.Template, &oldReplicaSet.Spec.Template
+		revert = func() bool { return modifier.RevertReplicaSet(replicaSet) }
+	case "apps/StatefulSet":
+		statefulSet, oldStatefulSet := &appsv1.StatefulSet{}, &appsv1.StatefulSet{}
+		workload, oldWorkload = statefulSet, oldStatefulSet
+		objectMeta = &statefulSet.ObjectMeta
+		podTemplate, oldPodTemplate = &statefulSet.Spec.Template, &oldStatefulSet.Spec.Template
+		revert = func() bool { return modifier.RevertStatefulSet(statefulSet) }
+	default:
+		// Jobs and pods are immutable, they cannot be uninstrumented.
+		return admission.Response{}, false
+	}
+
+	if _, _, err := decoder.Decode(request.Object.Raw, nil, workload); err != nil {
+		return admission.Response{}, false
+	}
+	if !util.IsUninstrumentationPending(objectMeta) {
+		return admission.Response{}, false
+	}
+	if _, _, err := decoder.Decode(request.OldObject.Raw, nil, oldWorkload); err != nil {
+		return admission.Response{}, false
+	}
+	if equality.Semantic.DeepEqual(podTemplate, oldPodTemplate) {
+		// Only the metadata of the workload has changed (e.g. the controller has just marked it), removing the
+		// instrumentation now would restart its pods.
+		return admission.Response{}, false
+	}
+	return h.postProcessUninstrumentation(request, workload, revert(), false, logger), true
+}
+
 func (h *InstrumentationWebhookHandler) preProcess(
 	request admission.Request,
 	gvkLabel string,
@@ -424,7 +625,7 @@ func (h *InstrumentationWebhookHandler) postProcessInstrumentation(
 		return admission.Allowed("no changes")
 	}
 
-	marshalled, err := json.Marshal(resource)
+	response, err := patchResponse(request, resource)
 	if err != nil {
 		wrappedErr := fmt.Errorf("error when marshalling modfied resource to JSON: %w", err)
 		util.QueueFailedInstrumentationEvent(h.Recorder, resource, "webhook", wrappedErr)
@@ -434,12 +635,60 @@ func (h *InstrumentationWebhookHandler) postProcessInstrumentation(
 	if ignored {
 		logger.Info("Ignoring this admission request due to the presence of dash0.com/webhook-ignore-once")
 		// deliberately not queueing an event for this case
-		return admission.PatchResponseFromRaw(request.Object.Raw, marshalled)
+		return response
 	}
 
 	logger.Info("The webhook has added Dash0 instrumentation to the workload.")
 	util.QueueSuccessfulInstrumentationEvent(h.Recorder, resource, "webhook")
-	return admission.PatchResponseFromRaw(request.Object.Raw, marshalled)
+	if containerNames := workloads.FindUninstrumentableContainers(resource); len(containerNames) > 0 {
+		logger.Info("Some containers of the workload could not be instrumented.", "containers", containerNames)
+		util.QueueContainersNotInstrumentedEvent(h.Recorder, resource, containerNames, "webhook")
+	}
+	h.recordAudit(request, resource, util.ModificationModeInstrumentation, util.ReasonSuccessfulInstrumentation, logger)
+	return response
+}
+
+func (h *InstrumentationWebhookHandler) postProcessOptOut(
+	request admission.Request,
+	resource runtime.Object,
+	objectMeta *metav1.ObjectMeta,
+	isPod bool,
+	logger *logr.Logger,
+) admission.Response {
+	if !util.HasExplicitlyOptedOutOfInstrumentation(objectMeta) {
+		if !isPod {
+			util.QueueNotOptedInToInstrumentationEvent(h.Recorder, resource, "webhook")
+		}
+		return logAndReturnAllowed(notOptedInAdmissionAllowedMessage, logger)
+	}
+	if !isPod {
+		util.QueueOptedOutOfInstrumentationEvent(h.Recorder, resource, "webhook")
+	}
+	recordOptedOutWorkload(request, resource)
+	return logAndReturnAllowed(optOutAdmissionAllowedMessage, logger)
+}
+
+// recordOptedOutWorkload increments the counter for workloads that have not been instrumented due to the opt-out label.
+// Pods with an owner are not counted, since they inherit the label from their owner, which has been counted already.
+func recordOptedOutWorkload(request admission.Request, resource runtime.Object) {
+	if optedOutWorkloadsMetric == nil {
+		return
+	}
+	objectMeta, err := meta.Accessor(resource)
+	if err != nil {
+		return
+	}
+	if _, isPod := resource.(*corev1.Pod); isPod && len(objectMeta.GetOwnerReferences()) > 0 {
+		return
+	}
+	optedOutWorkloadsMetric.Add(
+		context.Background(),
+		1,
+		otelmetric.WithAttributes(
+			attribute.String("k8s.namespace.name", cmp.Or(objectMeta.GetNamespace(), request.Namespace)),
+			attribute.String("kind", request.Kind.Kind),
+		),
+	)
 }
 
 func (h *InstrumentationWebhookHandler) postProcessUninstrumentation(
@@ -463,7 +712,7 @@ func (h *InstrumentationWebhookHandler) postProcessUninstrumentation(
 		return admission.Allowed("no changes")
 	}
 
-	marshalled, err := json.Marshal(resource)
+	response, err := patchResponse(request, resource)
 	if err != nil {
 		wrappedErr := fmt.Errorf("error when marshalling modfied resource to JSON: %w", err)
 		util.QueueFailedUninstrumentationEvent(h.Recorder, resource, "webhook", wrappedErr)
@@ -472,16 +721,55 @@ func (h *InstrumentationWebhookHandler) postProcessUninstrumentation(
 
 	logger.Info("The webhook has removed the Dash0 instrumentation from the workload.")
 	util.QueueSuccessfulUninstrumentationEvent(h.Recorder, resource, "webhook")
-	return admission.PatchResponseFromRaw(request.Object.Raw, marshalled)
+	h.recordAudit(request, resource, util.ModificationModeUninstrumentation, util.ReasonSuccessfulUninstrumentation, logger)
+	return response
+}
+
+// recordAudit writes an audit record for a modification by the webhook, unless the admission request is a dry run, in
+// which case the modification is not persisted.
+func (h *InstrumentationWebhookHandler) recordAudit(
+	request admission.Request,
+	resource runtime.Object,
+	action util.ModificationMode,
+	reason util.Reason,
+	logger *logr.Logger,
+) {
+	if ptr.Deref(request.DryRun, false) {
+		return
+	}
+	objectMeta, err := meta.Accessor(resource)
+	if err != nil {
+		logger.Error(err, "Cannot read the metadata of the workload for the instrumentation audit record.")
+		return
+	}
+	// The namespace of the object in the admission request is not always set, e.g. for pods created by a replica set.
+	auditRecord := util.InstrumentationAuditRecord{
+		Action:          action,
+		Reason:          reason,
+		InstrumentedBy:  "webhook",
+		User:            request.UserInfo.Username,
+		Kind:            request.Kind.Kind,
+		Namespace:       cmp.Or(objectMeta.GetNamespace(), request.Namespace),
+		Name:            objectMeta.GetName(),
+		OperatorVersion: h.Images.GetOperatorVersion(),
+	}
+	if auditRecord.Name == "" {
+		auditRecord.GenerateName = objectMeta.GetGenerateName()
+	}
+	util.RecordInstrumentationAudit(h.AuditLog, auditRecord, logger)
 }
 
 func (h *InstrumentationWebhookHandler) newWorkloadModifier(logger *logr.Logger) *workloads.ResourceModifier {
 	return workloads.NewResourceModifier(
 		util.InstrumentationMetadata{
-			Images:               h.Images,
-			InstrumentedBy:       "webhook",
-			OTelCollectorBaseUrl: h.OTelCollectorBaseUrl,
-			IsIPv6Cluster:        h.IsIPv6Cluster,
+			Images:                       h.Images,
+			InstrumentedBy:               "webhook",
+			OTelCollectorBaseUrl:         h.OTelCollectorBaseUrl,
+			IsIPv6Cluster:                h.IsIPv6Cluster,
+			CollectorGatewayMode:         h.CollectorGatewayMode,
+			InitContainerSecurityContext: h.InitContainerSecurityContext,
+			InitContainerResources:       h.InitContainerResources,
+			PropagatedWorkloadLabels:     h.PropagatedWorkloadLabels,
 		},
 		logger,
 	)
@@ -503,6 +791,57 @@ func (r *routing) routeFor(group, kind, version string) resourceHandler {
 	return routesForVersion
 }
 
+// skipTooLargeObject allows the admission request without decoding the object, and records a warning event for it.
+// The event only refers to the object by kind, namespace and name; the monitoring controller attaches it to the object
+// later, like all events the webhook records for workloads that are being created.
+func (h *InstrumentationWebhookHandler) skipTooLargeObject(
+	request admission.Request,
+	logger *logr.Logger,
+) admission.Response {
+	size := len(request.Object.Raw)
+	if request.Name != "" {
+		util.QueueWorkloadTooLargeForWebhookEvent(
+			h.Recorder,
+			&corev1.ObjectReference{
+				APIVersion: metav1.GroupVersion{Group: request.Kind.Group, Version: request.Kind.Version}.String(),
+				Kind:       request.Kind.Kind,
+				Namespace:  request.Namespace,
+				Name:       request.Name,
+			},
+			size,
+			h.MaxObjectSize,
+		)
+	}
+	return logAndReturnAllowed(
+		fmt.Sprintf(
+			"The size of the object (%d bytes) exceeds the configured maximum of %d bytes, the webhook will not "+
+				"process it.",
+			size,
+			h.MaxObjectSize,
+		),
+		logger,
+	)
+}
+
+// isOperatorOwnWorkload checks whether the workload in the admission request lives in the operator's namespace or
+// carries the label app.kubernetes.io/managed-by=dash0-operator. The webhook never instruments these workloads, to rule
+// out the operator instrumenting its own components (e.g. the OpenTelemetry collectors), independent of whether they
+// carry the label dash0.com/enable=false. Only the metadata of the workload is decoded for this check.
+func (h *InstrumentationWebhookHandler) isOperatorOwnWorkload(request admission.Request) (string, bool) {
+	if h.OperatorNamespace != "" && request.Namespace == h.OperatorNamespace {
+		return operatorNamespaceAdmissionAllowedMessage, true
+	}
+	objectMeta := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(request.Object.Raw, objectMeta); err != nil {
+		// Decoding errors are reported when the workload is decoded completely by the resource type specific handler.
+		return "", false
+	}
+	if util.IsManagedByDash0Operator(&objectMeta.ObjectMeta) {
+		return managedByOperatorAdmissionAllowedMessage, true
+	}
+	return "", false
+}
+
 func logAndReturnAllowed(message string, logger *logr.Logger) admission.Response {
 	logger.Info(message)
 	return admission.Allowed(message)
@@ -4,10 +4,19 @@
 package webhooks
 
 import (
+	"encoding/json"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
 
@@ -117,7 +126,15 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 				createdObjects = append(createdObjects, workload)
 				workload = GetReplicaSet(ctx, k8sClient, TestNamespaceName, name)
 				VerifyUnmodifiedReplicaSet(workload)
-				VerifyNoInstrumentationNecessaryEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+				VerifyOwnedByHigherOrderWorkloadEvent(
+					ctx,
+					clientset,
+					TestNamespaceName,
+					name,
+					"Deployment",
+					"deployment",
+					"webhook",
+				)
 			})
 		})
 
@@ -127,7 +144,12 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 			createdObjects = append(createdObjects, workload.Get())
 			workload = config.GetFn(ctx, k8sClient, TestNamespaceName, name)
 			config.VerifyFn(workload)
-			VerifyNoEvents(ctx, clientset, TestNamespaceName)
+			if config.WorkloadNamePrefix == PodNamePrefix {
+				// the webhook does not record events for pods
+				VerifyNoEvents(ctx, clientset, TestNamespaceName)
+			} else {
+				VerifyOptedOutOfInstrumentationEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+			}
 		}, Entry("should not instrument a cron job that has opted out of instrumentation", WorkloadTestConfig{
 			WorkloadNamePrefix: CronJobNamePrefix,
 			CreateFn:           WrapCronJobFnAsTestableWorkload(CreateCronJobWithOptOutLabel),
@@ -179,6 +201,39 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 			},
 		}))
 
+		It("should count workloads that have opted out of instrumentation", func() {
+			logger := log.WithName("test")
+			metricReader := sdkmetric.NewManualReader()
+			meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(metricReader))
+			(&InstrumentationWebhookHandler{}).InitializeSelfMonitoringMetrics(
+				meterProvider.Meter("test"),
+				"dash0.operator.manager.",
+				&logger,
+			)
+			DeferCleanup(func() {
+				optedOutWorkloadsMetric = nil
+			})
+
+			name := UniqueName(DeploymentNamePrefix)
+			workload := CreateDeploymentWithOptOutLabel(ctx, k8sClient, TestNamespaceName, name)
+			createdObjects = append(createdObjects, workload)
+
+			var resourceMetrics metricdata.ResourceMetrics
+			Expect(metricReader.Collect(ctx, &resourceMetrics)).To(Succeed())
+			Expect(resourceMetrics.ScopeMetrics).To(HaveLen(1))
+			Expect(resourceMetrics.ScopeMetrics[0].Metrics).To(HaveLen(1))
+			metric := resourceMetrics.ScopeMetrics[0].Metrics[0]
+			Expect(metric.Name).To(Equal("dash0.operator.manager.instrumentation.opted_out_workloads"))
+			sum, ok := metric.Data.(metricdata.Sum[int64])
+			Expect(ok).To(BeTrue())
+			Expect(sum.DataPoints).To(HaveLen(1))
+			Expect(sum.DataPoints[0].Value).To(Equal(int64(1)))
+			namespace, _ := sum.DataPoints[0].Attributes.Value("k8s.namespace.name")
+			Expect(namespace.AsString()).To(Equal(TestNamespaceName))
+			kind, _ := sum.DataPoints[0].Attributes.Value("kind")
+			Expect(kind.AsString()).To(Equal("Deployment"))
+		})
+
 		Describe("when mutating new workloads with multiple containers and volumes", func() {
 			It("should instrument a new deployment that has multiple containers, and already has volumes and init containers", func() {
 				name := UniqueName(DeploymentNamePrefix)
@@ -196,20 +251,26 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 						{
 							VolumeMounts:                             2,
 							Dash0VolumeMountIdx:                      1,
-							EnvVars:                                  4,
+							EnvVars:                                  7,
 							LdPreloadEnvVarIdx:                       1,
 							NodeIpIdx:                                2,
 							Dash0CollectorBaseUrlEnvVarIdx:           3,
 							Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+							OTelServiceNameEnvVarIdx:                 4,
+							OTelResourceAttributesEnvVarIdx:          5,
+							Dash0InjectedEnvVarsIdx:                  6,
 						},
 						{
 							VolumeMounts:                             3,
 							Dash0VolumeMountIdx:                      2,
-							EnvVars:                                  5,
+							EnvVars:                                  8,
 							LdPreloadEnvVarIdx:                       2,
 							NodeIpIdx:                                3,
 							Dash0CollectorBaseUrlEnvVarIdx:           4,
 							Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+							OTelServiceNameEnvVarIdx:                 5,
+							OTelResourceAttributesEnvVarIdx:          6,
+							Dash0InjectedEnvVarsIdx:                  7,
 						},
 					},
 				})
@@ -234,26 +295,39 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 						{
 							VolumeMounts:                             2,
 							Dash0VolumeMountIdx:                      1,
-							EnvVars:                                  4,
+							EnvVars:                                  7,
 							LdPreloadEnvVarIdx:                       1,
 							LdPreloadUsesValueFrom:                   true,
 							NodeIpIdx:                                2,
 							Dash0CollectorBaseUrlEnvVarIdx:           3,
 							Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+							OTelServiceNameEnvVarIdx:                 4,
+							OTelResourceAttributesEnvVarIdx:          5,
+							Dash0InjectedEnvVarsIdx:                  6,
 						},
 						{
 							VolumeMounts:                             3,
 							Dash0VolumeMountIdx:                      1,
-							EnvVars:                                  4,
+							EnvVars:                                  7,
 							LdPreloadEnvVarIdx:                       1,
 							LdPreloadValue:                           "/__dash0__/dash0_injector.so third_party_preload.so another_third_party_preload.so",
 							NodeIpIdx:                                2,
 							Dash0CollectorBaseUrlEnvVarIdx:           0,
 							Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+							OTelServiceNameEnvVarIdx:                 4,
+							OTelResourceAttributesEnvVarIdx:          5,
+							Dash0InjectedEnvVarsIdx:                  6,
 						},
 					},
 				})
-				VerifySuccessfulInstrumentationEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+				VerifySuccessfulInstrumentationEventWithContainersNotInstrumented(
+					ctx,
+					clientset,
+					TestNamespaceName,
+					name,
+					"webhook",
+					"test-container-0",
+				)
 			})
 		})
 
@@ -531,6 +605,35 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 		})
 	})
 
+	Describe("when workload kinds are excluded in the operator configuration resource", Ordered, func() {
+		BeforeAll(func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			operatorConfigurationSpec := *OperatorConfigurationResourceDefaultSpec.DeepCopy()
+			operatorConfigurationSpec.ExcludedWorkloadKinds = []dash0v1alpha1.WorkloadKind{
+				dash0v1alpha1.WorkloadKindStatefulSet,
+			}
+			CreateOperatorConfigurationResourceWithSpec(ctx, k8sClient, operatorConfigurationSpec)
+		})
+
+		AfterAll(func() {
+			DeleteAllOperatorConfigurationResources(ctx, k8sClient)
+			DeleteMonitoringResource(ctx, k8sClient)
+		})
+
+		It("should not instrument workloads of an excluded kind", func() {
+			name := UniqueName(StatefulSetNamePrefix)
+			workload := CreateBasicStatefulSet(ctx, k8sClient, TestNamespaceName, name)
+			createdObjects = append(createdObjects, workload)
+			workload = GetStatefulSet(ctx, k8sClient, TestNamespaceName, name)
+			VerifyUnmodifiedStatefulSet(workload)
+			VerifyNoEvents(ctx, clientset, TestNamespaceName)
+		})
+
+		It("should instrument workloads of other kinds", func() {
+			createdObjects = verifyThatDeploymentIsInstrumented(createdObjects)
+		})
+	})
+
 	Describe("when the Dash0 monitoring resource exists and is available and has InstrumentWorkloads=created-and-updated set", Ordered, func() {
 		BeforeAll(func() {
 			dash0MonitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
@@ -546,6 +649,127 @@ var _ = Describe("The Dash0 instrumentation webhook", func() {
 			createdObjects = verifyThatDeploymentIsInstrumented(createdObjects)
 		})
 	})
+
+	Describe("when the Dash0 monitoring resource exists and is available and has InstrumentationPolicy=opt-in set", Ordered, func() {
+		BeforeAll(func() {
+			dash0MonitoringResource := EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			dash0MonitoringResource.Spec.InstrumentationPolicy = dash0v1alpha1.OptIn
+			Expect(k8sClient.Update(ctx, dash0MonitoringResource)).To(Succeed())
+		})
+
+		AfterAll(func() {
+			DeleteMonitoringResource(ctx, k8sClient)
+		})
+
+		It("should not instrument workloads that have not opted in", func() {
+			name := UniqueName(DeploymentNamePrefix)
+			workload := CreateBasicDeployment(ctx, k8sClient, TestNamespaceName, name)
+			createdObjects = append(createdObjects, workload)
+			workload = GetDeployment(ctx, k8sClient, TestNamespaceName, name)
+			VerifyUnmodifiedDeployment(workload)
+			VerifyNotOptedInToInstrumentationEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+		})
+
+		It("should instrument workloads that have opted in", func() {
+			name := UniqueName(DeploymentNamePrefix)
+			workload := BasicDeployment(TestNamespaceName, name)
+			AddOptInLabel(&workload.ObjectMeta)
+			workload = CreateWorkload(ctx, k8sClient, workload).(*appsv1.Deployment)
+			createdObjects = append(createdObjects, workload)
+			workload = GetDeployment(ctx, k8sClient, TestNamespaceName, name)
+			VerifyModifiedDeployment(workload, BasicInstrumentedPodSpecExpectations())
+			VerifySuccessfulInstrumentationEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+		})
+
+		It("should not instrument workloads that have opted out", func() {
+			name := UniqueName(DeploymentNamePrefix)
+			workload := CreateDeploymentWithOptOutLabel(ctx, k8sClient, TestNamespaceName, name)
+			createdObjects = append(createdObjects, workload)
+			workload = GetDeployment(ctx, k8sClient, TestNamespaceName, name)
+			VerifyDeploymentWithOptOutLabel(workload)
+			VerifyOptedOutOfInstrumentationEvent(ctx, clientset, TestNamespaceName, name, "webhook")
+		})
+	})
+
+	Describe("when the namespace has opted out of instrumentation", Ordered, func() {
+		BeforeAll(func() {
+			EnsureMonitoringResourceExistsAndIsAvailable(ctx, k8sClient)
+			SetNamespaceOptOutLabel(ctx, k8sClient, TestNamespaceName, true)
+		})
+
+		AfterAll(func() {
+			SetNamespaceOptOutLabel(ctx, k8sClient, TestNamespaceName, false)
+			DeleteMonitoringResource(ctx, k8sClient)
+		})
+
+		It("should not instrument workloads", func() {
+			createdObjects = verifyThatDeploymentIsNotBeingInstrumented(createdObjects)
+		})
+	})
+
+	Describe("when the workload exceeds the maximum object size", func() {
+		It("should allow the request without modifying the workload and record an event", func() {
+			rawWorkload, err := json.Marshal(BasicDeployment(TestNamespaceName, DeploymentNamePrefix))
+			Expect(err).ToNot(HaveOccurred())
+			recorder := record.NewFakeRecorder(10)
+			handler := &InstrumentationWebhookHandler{
+				Recorder:      recorder,
+				Images:        TestImages,
+				MaxObjectSize: int64(len(rawWorkload) - 1),
+			}
+
+			response := handler.Handle(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					Namespace: TestNamespaceName,
+					Name:      DeploymentNamePrefix,
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: rawWorkload},
+				},
+			})
+
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(ContainSubstring("WorkloadTooLargeForWebhook")))
+		})
+	})
+
+	Describe("when the workload belongs to the operator itself", func() {
+		handleDeployment := func(deployment *appsv1.Deployment) admission.Response {
+			rawWorkload, err := json.Marshal(deployment)
+			Expect(err).ToNot(HaveOccurred())
+			// The handler has no client, the request must be answered before looking up the Dash0 monitoring resource.
+			handler := &InstrumentationWebhookHandler{
+				Images:            TestImages,
+				OperatorNamespace: OperatorNamespace,
+			}
+			return handler.Handle(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					Namespace: deployment.Namespace,
+					Name:      deployment.Name,
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: rawWorkload},
+				},
+			})
+		}
+
+		It("should not instrument workloads in the operator namespace", func() {
+			response := handleDeployment(BasicDeployment(OperatorNamespace, DeploymentNamePrefix))
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+			Expect(response.Result.Message).To(Equal(operatorNamespaceAdmissionAllowedMessage))
+		})
+
+		It("should not instrument workloads managed by the operator", func() {
+			deployment := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			deployment.Labels = map[string]string{"app.kubernetes.io/managed-by": "dash0-operator"}
+			response := handleDeployment(deployment)
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Patches).To(BeEmpty())
+			Expect(response.Result.Message).To(Equal(managedByOperatorAdmissionAllowedMessage))
+		})
+	})
 })
 
 func verifyThatDeploymentIsInstrumented(createdObjects []client.Object) []client.Object {
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+)
+
+type MonitoringMutatingWebhookHandler struct {
+	Client client.Client
+}
+
+func (h *MonitoringMutatingWebhookHandler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhook := &admission.Webhook{
+		Handler: h,
+	}
+
+	handler, err := admission.StandaloneWebhook(webhook, admission.StandaloneOptions{})
+	if err != nil {
+		return err
+	}
+	mgr.GetWebhookServer().Register("/v1alpha1/mutate/monitoring", handler)
+
+	return nil
+}
+
+func (h *MonitoringMutatingWebhookHandler) Handle(_ context.Context, request admission.Request) admission.Response {
+	monitoringResource := &dash0v1alpha1.Dash0Monitoring{}
+	if _, _, err := decoder.Decode(request.Object.Raw, nil, monitoringResource); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !applyMonitoringDefaults(monitoringResource) {
+		return admission.Allowed("no changes")
+	}
+
+	marshalled, err := json.Marshal(monitoringResource)
+	if err != nil {
+		return admission.Errored(
+			http.StatusInternalServerError,
+			fmt.Errorf("error when marshalling the defaulted monitoring resource to JSON: %w", err),
+		)
+	}
+	return admission.PatchResponseFromRaw(request.Object.Raw, marshalled)
+}
+
+// applyMonitoringDefaults sets all optional fields of the monitoring resource that have not been set explicitly to
+// their default values, so that the stored resource reflects the effective settings. It returns true if the resource
+// has been modified.
+//
+// Note that spec.dataset is deliberately not defaulted: If it is omitted, the dataset from the export settings is used,
+// and persisting a default would pin the namespace to a dataset that is no longer updated when the export settings
+// change.
+func applyMonitoringDefaults(monitoringResource *dash0v1alpha1.Dash0Monitoring) bool {
+	spec := &monitoringResource.Spec
+	modified := false
+
+	if spec.InstrumentWorkloads == "" {
+		spec.InstrumentWorkloads = dash0v1alpha1.All
+		modified = true
+	}
+	if spec.SynchronizePersesDashboards == nil {
+		spec.SynchronizePersesDashboards = ptr.To(true)
+		modified = true
+	}
+	if spec.SynchronizePrometheusRules == nil {
+		spec.SynchronizePrometheusRules = ptr.To(true)
+		modified = true
+	}
+	if spec.PrometheusScrapingEnabled == nil {
+		spec.PrometheusScrapingEnabled = ptr.To(true)
+		modified = true
+	}
+	if spec.Export != nil && spec.Export.Dash0 != nil && spec.Export.Dash0.Dataset == "" {
+		spec.Export.Dash0.Dataset = util.DatasetDefault
+		modified = true
+	}
+
+	return modified
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+var _ = Describe("The mutating webhook for the monitoring resource", func() {
+
+	Describe("when applying defaults", func() {
+
+		It("should set all optional settings that have been omitted", func() {
+			monitoringResource := &dash0v1alpha1.Dash0Monitoring{
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint: EndpointDash0Test,
+						},
+					},
+				},
+			}
+
+			Expect(applyMonitoringDefaults(monitoringResource)).To(BeTrue())
+
+			spec := monitoringResource.Spec
+			Expect(spec.InstrumentWorkloads).To(Equal(dash0v1alpha1.All))
+			Expect(*spec.SynchronizePersesDashboards).To(BeTrue())
+			Expect(*spec.SynchronizePrometheusRules).To(BeTrue())
+			Expect(*spec.PrometheusScrapingEnabled).To(BeTrue())
+			Expect(spec.Export.Dash0.Dataset).To(Equal(util.DatasetDefault))
+			Expect(spec.Dataset).To(BeEmpty())
+		})
+
+		It("should not overwrite explicit settings", func() {
+			monitoringResource := &dash0v1alpha1.Dash0Monitoring{
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint: EndpointDash0Test,
+							Dataset:  "custom-dataset",
+						},
+					},
+					Dataset:                     "namespace-dataset",
+					InstrumentWorkloads:         dash0v1alpha1.None,
+					SynchronizePersesDashboards: ptr.To(false),
+					SynchronizePrometheusRules:  ptr.To(false),
+					PrometheusScrapingEnabled:   ptr.To(false),
+				},
+			}
+
+			Expect(applyMonitoringDefaults(monitoringResource)).To(BeFalse())
+
+			spec := monitoringResource.Spec
+			Expect(spec.InstrumentWorkloads).To(Equal(dash0v1alpha1.None))
+			Expect(*spec.SynchronizePersesDashboards).To(BeFalse())
+			Expect(*spec.SynchronizePrometheusRules).To(BeFalse())
+			Expect(*spec.PrometheusScrapingEnabled).To(BeFalse())
+			Expect(spec.Export.Dash0.Dataset).To(Equal("custom-dataset"))
+			Expect(spec.Dataset).To(Equal("namespace-dataset"))
+		})
+
+		It("should not add an export to a monitoring resource without export", func() {
+			monitoringResource := &dash0v1alpha1.Dash0Monitoring{}
+			applyMonitoringDefaults(monitoringResource)
+			Expect(monitoringResource.Spec.Export).To(BeNil())
+		})
+	})
+
+	Describe("when creating monitoring resources", func() {
+
+		AfterEach(func() {
+			Expect(
+				k8sClient.DeleteAllOf(ctx, &dash0v1alpha1.Dash0Monitoring{}, client.InNamespace(TestNamespaceName)),
+			).To(Succeed())
+		})
+
+		It("should store the defaulted monitoring resource", func() {
+			_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: MonitoringResourceDefaultObjectMeta,
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: ExportToPrt(Dash0ExportWithEndpointAndToken()),
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			monitoringResource := LoadMonitoringResourceOrFail(ctx, k8sClient, Default)
+			spec := monitoringResource.Spec
+			Expect(spec.InstrumentWorkloads).To(Equal(dash0v1alpha1.All))
+			Expect(spec.SynchronizePersesDashboards).To(Equal(ptr.To(true)))
+			Expect(spec.SynchronizePrometheusRules).To(Equal(ptr.To(true)))
+			Expect(spec.PrometheusScrapingEnabled).To(Equal(ptr.To(true)))
+			Expect(spec.Export.Dash0.Dataset).To(Equal(util.DatasetDefault))
+		})
+	})
+})
@@ -5,7 +5,9 @@ package webhooks
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -13,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	dash0v1alpha1 "github.com/dash0hq/dash0-operator/api/dash0monitoring/v1alpha1"
+	"github.com/dash0hq/dash0-operator/internal/util"
 )
 
 type MonitoringValidationWebhookHandler struct {
@@ -39,6 +42,10 @@ func (h *MonitoringValidationWebhookHandler) Handle(ctx context.Context, request
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	if validationErr := ValidateMonitoringResource(monitoringResource); validationErr != "" {
+		return admission.Denied(validationErr)
+	}
+
 	if monitoringResource.Spec.Export != nil {
 		return admission.Allowed("")
 	}
@@ -80,3 +87,109 @@ func (h *MonitoringValidationWebhookHandler) Handle(ctx context.Context, request
 
 	return admission.Allowed("")
 }
+
+// ValidateMonitoringResource runs the checks of the validation webhook for Dash0 monitoring resources that do not
+// depend on other resources in the cluster. It returns an empty string if the resource is valid, and the reason for
+// denying it otherwise. The check whether an export configuration is available for monitoring resources without
+// spec.export is not part of it, since it requires reading the Dash0 operator configuration resources.
+func ValidateMonitoringResource(monitoringResource *dash0v1alpha1.Dash0Monitoring) string {
+	if validationErr := validateSecretRefNamespace(monitoringResource); validationErr != "" {
+		return validationErr
+	}
+	if validationErr := validateFilter(monitoringResource.Spec.Filter); validationErr != "" {
+		return validationErr
+	}
+	return validateTransform(monitoringResource.Spec.Transform)
+}
+
+// validateSecretRefNamespace checks that spec.export.dash0.authorization.secretRef does not reference a secret in a
+// namespace other than the namespace of the monitoring resource. It returns an empty string if the reference is valid.
+func validateSecretRefNamespace(monitoringResource *dash0v1alpha1.Dash0Monitoring) string {
+	export := monitoringResource.Spec.Export
+	if export == nil || export.Dash0 == nil {
+		return ""
+	}
+	secretRef := export.Dash0.Authorization.SecretRef
+	if util.IsSecretRefAllowedForMonitoringResource(secretRef, monitoringResource.Namespace) {
+		return ""
+	}
+	return fmt.Sprintf(
+		"The provided Dash0 monitoring resource references the secret %s/%s in "+
+			"spec.export.dash0.authorization.secretRef, but a Dash0 monitoring resource can only reference secrets in "+
+			"its own namespace (%s). Secrets from other namespaces can only be referenced by the Dash0 operator "+
+			"configuration resource.",
+		secretRef.Namespace,
+		secretRef.Name,
+		monitoringResource.Namespace,
+	)
+}
+
+// validateFilter checks the conditions in spec.filter with util.CheckOttlCondition. The conditions of all monitoring
+// resources end up in the configuration of the collectors that are shared by all namespaces, so a single malformed
+// condition must not make it into the configuration. It returns an empty string if the filter is valid.
+func validateFilter(filter *dash0v1alpha1.Filter) string {
+	if filter == nil {
+		return ""
+	}
+	if filter.Traces != nil {
+		if validationErr :=
+			validateFilterConditions(filter.Traces.SpanFilter, "spec.filter.traces.span"); validationErr != "" {
+			return validationErr
+		}
+	}
+	if filter.Logs != nil {
+		return validateFilterConditions(filter.Logs.LogRecordFilter, "spec.filter.logs.logRecord")
+	}
+	return ""
+}
+
+func validateFilterConditions(conditions []string, path string) string {
+	for i, condition := range conditions {
+		if err := util.CheckOttlCondition(condition); err != nil {
+			return fmt.Sprintf(
+				"The provided Dash0 monitoring resource has an invalid condition at index %d in %s: %v.",
+				i,
+				path,
+				err,
+			)
+		}
+	}
+	return ""
+}
+
+// validateTransform checks the statements in spec.transform with util.CheckOttlStatement. Like the filter conditions,
+// the statements of all monitoring resources end up in the configuration of the collectors that are shared by all
+// namespaces, and the transform processor would reject the whole configuration because of a single malformed
+// statement. It returns an empty string if the transform is valid.
+func validateTransform(transform *dash0v1alpha1.Transform) string {
+	if transform == nil {
+		return ""
+	}
+	for _, statementsForSignal := range []struct {
+		signal     string
+		statements []string
+	}{
+		{signal: "traces", statements: transform.Traces},
+		{signal: "metrics", statements: transform.Metrics},
+		{signal: "logs", statements: transform.Logs},
+	} {
+		for i, statement := range statementsForSignal.statements {
+			if strings.TrimSpace(statement) == "" {
+				return fmt.Sprintf(
+					"The provided Dash0 monitoring resource has an empty statement at index %d in spec.transform.%s.",
+					i,
+					statementsForSignal.signal,
+				)
+			}
+			if err := util.CheckOttlStatement(statement); err != nil {
+				return fmt.Sprintf(
+					"The provided Dash0 monitoring resource has an invalid statement at index %d in spec.transform.%s: %v.",
+					i,
+					statementsForSignal.signal,
+					err,
+				)
+			}
+		}
+	}
+	return ""
+}
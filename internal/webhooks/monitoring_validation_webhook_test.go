@@ -147,6 +147,121 @@ var _ = Describe("The validation webhook for the monitoring resource", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("should reject monitoring resources with blank transform statements", func() {
+			spec := MonitoringResourceDefaultSpec
+			spec.Transform = &dash0v1alpha1.Transform{
+				Logs: []string{`set(attributes["redacted"], true)`, "  "},
+			}
+			_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: MonitoringResourceDefaultObjectMeta,
+				Spec:       spec,
+			})
+			Expect(err).To(MatchError(ContainSubstring(
+				"admission webhook \"validate-monitoring.dash0.com\" denied the request: The provided Dash0 " +
+					"monitoring resource has an empty statement at index 1 in spec.transform.logs.")))
+		})
+
+		DescribeTable("should reject monitoring resources with malformed transform statements",
+			func(transform dash0v1alpha1.Transform, expectedMessage string) {
+				spec := MonitoringResourceDefaultSpec
+				spec.Transform = &transform
+				_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+					ObjectMeta: MonitoringResourceDefaultObjectMeta,
+					Spec:       spec,
+				})
+				Expect(err).To(MatchError(ContainSubstring(
+					"admission webhook \"validate-monitoring.dash0.com\" denied the request: The provided Dash0 " +
+						"monitoring resource has an invalid statement at index " + expectedMessage)))
+			},
+			Entry("with a missing closing parenthesis",
+				dash0v1alpha1.Transform{Traces: []string{`set(attributes["a"], "b"`}},
+				"0 in spec.transform.traces: missing \")\".",
+			),
+			Entry("with a condition instead of a statement",
+				dash0v1alpha1.Transform{Metrics: []string{`delete_key(attributes, "a")`, `attributes["a"] == "b"`}},
+				"1 in spec.transform.metrics: the statement does not start with the invocation of an editor "+
+					"function, like set(...).",
+			),
+		)
+
+		DescribeTable("should reject monitoring resources with malformed filter conditions",
+			func(filter dash0v1alpha1.Filter, expectedMessage string) {
+				spec := MonitoringResourceDefaultSpec
+				spec.Filter = &filter
+				_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+					ObjectMeta: MonitoringResourceDefaultObjectMeta,
+					Spec:       spec,
+				})
+				Expect(err).To(MatchError(ContainSubstring(
+					"admission webhook \"validate-monitoring.dash0.com\" denied the request: The provided Dash0 " +
+						"monitoring resource has an invalid condition at index " + expectedMessage)))
+			},
+			Entry("with a condition that escapes its parentheses",
+				dash0v1alpha1.Filter{Traces: &dash0v1alpha1.TraceFilter{
+					SpanFilter: []string{`name == "GET /healthz"`, `true) or (true`},
+				}},
+				"1 in spec.filter.traces.span: unexpected \")\" at position 4.",
+			),
+			Entry("with an unterminated string literal",
+				dash0v1alpha1.Filter{Logs: &dash0v1alpha1.LogFilter{
+					LogRecordFilter: []string{`IsMatch(body, "GET /healthz)`},
+				}},
+				"0 in spec.filter.logs.logRecord: unterminated string literal.",
+			),
+			Entry("with a blank condition",
+				dash0v1alpha1.Filter{Logs: &dash0v1alpha1.LogFilter{
+					LogRecordFilter: []string{" "},
+				}},
+				"0 in spec.filter.logs.logRecord: the condition is empty.",
+			),
+		)
+
+		It("should reject monitoring resources that reference a secret in a different namespace", func() {
+			_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: MonitoringResourceDefaultObjectMeta,
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint: EndpointDash0Test,
+							Authorization: dash0v1alpha1.Authorization{
+								SecretRef: &dash0v1alpha1.SecretRef{
+									Name:      "database-credentials",
+									Key:       "password",
+									Namespace: "other-team",
+								},
+							},
+						},
+					},
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring(
+				"admission webhook \"validate-monitoring.dash0.com\" denied the request: The provided Dash0 " +
+					"monitoring resource references the secret other-team/database-credentials in " +
+					"spec.export.dash0.authorization.secretRef, but a Dash0 monitoring resource can only reference " +
+					"secrets in its own namespace")))
+		})
+
+		It("should allow monitoring resources that reference a secret in their own namespace", func() {
+			_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
+				ObjectMeta: MonitoringResourceDefaultObjectMeta,
+				Spec: dash0v1alpha1.Dash0MonitoringSpec{
+					Export: &dash0v1alpha1.Export{
+						Dash0: &dash0v1alpha1.Dash0Configuration{
+							Endpoint: EndpointDash0Test,
+							Authorization: dash0v1alpha1.Authorization{
+								SecretRef: &dash0v1alpha1.SecretRef{
+									Name:      "dash0-authorization-secret",
+									Key:       "token",
+									Namespace: TestNamespaceName,
+								},
+							},
+						},
+					},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("should allow monitoring resource creation with export settings", func() {
 			_, err := CreateMonitoringResourceWithPotentialError(ctx, k8sClient, &dash0v1alpha1.Dash0Monitoring{
 				ObjectMeta: MonitoringResourceDefaultObjectMeta,
@@ -6,6 +6,7 @@ package webhooks
 import (
 	"context"
 	"net/http"
+	"reflect"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,13 +40,38 @@ func (h *OperatorConfigurationValidationWebhookHandler) Handle(_ context.Context
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	if validationErr := ValidateOperatorConfigurationResource(operatorConfigurationResource); validationErr != "" {
+		return admission.Denied(validationErr)
+	}
+	return admission.Allowed("")
+}
+
+// ValidateOperatorConfigurationResource runs the checks of the validation webhook for Dash0 operator configuration
+// resources. None of them depend on other resources in the cluster. It returns an empty string if the resource is
+// valid, and the reason for denying it otherwise.
+func ValidateOperatorConfigurationResource(
+	operatorConfigurationResource *dash0v1alpha1.Dash0OperatorConfiguration,
+) string {
 	if util.ReadBoolPointerWithDefault(operatorConfigurationResource.Spec.SelfMonitoring.Enabled, true) &&
-		operatorConfigurationResource.Spec.Export == nil {
-		return admission.Denied(
-			"The provided Dash0 operator configuration resource has self-monitoring enabled, but it does not have an " +
-				"export configuration. Either disable self-monitoring or provide an export configuration for self-" +
-				"monitoring telemetry.")
+		operatorConfigurationResource.Spec.Export == nil &&
+		operatorConfigurationResource.Spec.SelfMonitoring.Export == nil {
+		return "The provided Dash0 operator configuration resource has self-monitoring enabled, but it does not have " +
+			"an export configuration. Either disable self-monitoring or provide an export configuration for self-" +
+			"monitoring telemetry."
+	}
 
+	spec := operatorConfigurationResource.Spec
+	if util.ReadBoolPointerWithDefault(spec.SelfMonitoring.Enabled, true) &&
+		spec.SelfMonitoring.Export != nil &&
+		spec.Export != nil &&
+		spec.Export.Dash0 != nil &&
+		spec.Export.Dash0.ApiEndpoint != "" &&
+		(spec.SelfMonitoring.Export.Dash0 == nil ||
+			!reflect.DeepEqual(spec.SelfMonitoring.Export.Dash0.Authorization, spec.Export.Dash0.Authorization)) {
+		return "The provided Dash0 operator configuration resource has a dedicated self-monitoring export and a Dash0 " +
+			"API endpoint. The operator uses the same authorization for self-monitoring telemetry and for the Dash0 " +
+			"API, so the dedicated self-monitoring export needs to be a Dash0 export with the same authorization as " +
+			"spec.export.dash0."
 	}
-	return admission.Allowed("")
+	return ""
 }
@@ -97,5 +97,62 @@ var _ = Describe("The validation webhook for the operator configuration resource
 				})
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("should allow an operator configuration resource with self-monitoring enabled if it only has a dedicated self-monitoring export", func() {
+			_, err := CreateOperatorConfigurationResource(
+				ctx,
+				k8sClient,
+				&dash0v1alpha1.Dash0OperatorConfiguration{
+					ObjectMeta: OperatorConfigurationResourceDefaultObjectMeta,
+					Spec: dash0v1alpha1.Dash0OperatorConfigurationSpec{
+						SelfMonitoring: dash0v1alpha1.SelfMonitoring{
+							Enabled: ptr.To(true),
+							Export:  ExportToPrt(GrpcExportTest()),
+						},
+					},
+				})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should allow a dedicated Dash0 self-monitoring export with the same authorization as the export with API endpoint", func() {
+			_, err := CreateOperatorConfigurationResource(
+				ctx,
+				k8sClient,
+				&dash0v1alpha1.Dash0OperatorConfiguration{
+					ObjectMeta: OperatorConfigurationResourceDefaultObjectMeta,
+					Spec: dash0v1alpha1.Dash0OperatorConfigurationSpec{
+						SelfMonitoring: dash0v1alpha1.SelfMonitoring{
+							Enabled: ptr.To(true),
+							Export:  ExportToPrt(Dash0ExportWithEndpointAndToken()),
+						},
+						Export: ExportToPrt(Dash0ExportWithEndpointAndTokenAndApiEndpoint()),
+					},
+				})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("should reject a dedicated self-monitoring export that cannot share the authorization with the Dash0 API", func(selfMonitoringExport dash0v1alpha1.Export) {
+			_, err := CreateOperatorConfigurationResource(
+				ctx,
+				k8sClient,
+				&dash0v1alpha1.Dash0OperatorConfiguration{
+					ObjectMeta: OperatorConfigurationResourceDefaultObjectMeta,
+					Spec: dash0v1alpha1.Dash0OperatorConfigurationSpec{
+						SelfMonitoring: dash0v1alpha1.SelfMonitoring{
+							Enabled: ptr.To(true),
+							Export:  &selfMonitoringExport,
+						},
+						Export: ExportToPrt(Dash0ExportWithEndpointAndTokenAndApiEndpoint()),
+					},
+				})
+			Expect(err).To(MatchError(ContainSubstring(
+				"admission webhook \"validate-operator-configuration.dash0.com\" denied the request: The provided " +
+					"Dash0 operator configuration resource has a dedicated self-monitoring export and a Dash0 API " +
+					"endpoint.")))
+		},
+			Entry("gRPC export", GrpcExportTest()),
+			Entry("HTTP export", HttpExportTest()),
+			Entry("Dash0 export with a different authorization", Dash0ExportWithEndpointAndSecretRef()),
+		)
 	})
 })
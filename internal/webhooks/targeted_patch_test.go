@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: Copyright 2024 Dash0 Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	jsonpatchapply "github.com/evanphx/json-patch/v5"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/dash0hq/dash0-operator/internal/util"
+	"github.com/dash0hq/dash0-operator/internal/workloads"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/dash0hq/dash0-operator/test/util"
+)
+
+var targetedPatchTestInstrumentationMetadata = util.InstrumentationMetadata{
+	Images:               TestImages,
+	OTelCollectorBaseUrl: OTelCollectorBaseUrlTest,
+	InstrumentedBy:       "targeted_patch_test",
+}
+
+var _ = Describe("The targeted JSON patch", func() {
+	logger := logr.Discard()
+
+	// verifyPatch applies the targeted patch for the modified workload to the original workload and checks that the
+	// result is equal to the modified workload. The patched workload is decoded and encoded again before comparing it,
+	// since the patch sets empty labels and lists explicitly, where the modified workload omits them.
+	verifyPatch := func(original runtime.Object, modify func(runtime.Object) bool) {
+		originalJson, err := json.Marshal(original)
+		Expect(err).ToNot(HaveOccurred())
+		modified := original.DeepCopyObject()
+		Expect(modify(modified)).To(BeTrue())
+		modifiedJson, err := json.Marshal(modified)
+		Expect(err).ToNot(HaveOccurred())
+
+		patch, ok := targetedPatch(modified)
+		Expect(ok).To(BeTrue())
+		patchJson, err := json.Marshal(patch)
+		Expect(err).ToNot(HaveOccurred())
+		decodedPatch, err := jsonpatchapply.DecodePatch(patchJson)
+		Expect(err).ToNot(HaveOccurred())
+		patchedJson, err := decodedPatch.Apply(originalJson)
+		Expect(err).ToNot(HaveOccurred())
+		patched := reflect.New(reflect.TypeOf(original).Elem()).Interface()
+		Expect(json.Unmarshal(patchedJson, patched)).To(Succeed())
+		patchedJson, err = json.Marshal(patched)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(patchedJson).To(MatchJSON(modifiedJson))
+	}
+
+	It("should instrument a deployment", func() {
+		modifier := workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger)
+		verifyPatch(
+			DeploymentWithMoreBellsAndWhistles(TestNamespaceName, DeploymentNamePrefix),
+			func(workload runtime.Object) bool { return modifier.ModifyDeployment(workload.(*appsv1.Deployment)) },
+		)
+	})
+
+	It("should instrument a deployment without labels and volumes", func() {
+		modifier := workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger)
+		deployment := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+		deployment.Labels = nil
+		deployment.Spec.Template.Labels = nil
+		deployment.Spec.Template.Spec.Volumes = nil
+		verifyPatch(
+			deployment,
+			func(workload runtime.Object) bool { return modifier.ModifyDeployment(workload.(*appsv1.Deployment)) },
+		)
+	})
+
+	It("should instrument a cron job", func() {
+		modifier := workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger)
+		verifyPatch(
+			BasicCronJob(TestNamespaceName, CronJobNamePrefix),
+			func(workload runtime.Object) bool { return modifier.ModifyCronJob(workload.(*batchv1.CronJob)) },
+		)
+	})
+
+	It("should instrument a pod", func() {
+		modifier := workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger)
+		verifyPatch(
+			BasicPod(TestNamespaceName, PodNamePrefix),
+			func(workload runtime.Object) bool { return modifier.ModifyPod(workload.(*corev1.Pod)) },
+		)
+	})
+
+	It("should uninstrument a deployment", func() {
+		modifier := workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger)
+		verifyPatch(
+			InstrumentedDeploymentWithMoreBellsAndWhistles(TestNamespaceName, DeploymentNamePrefix),
+			func(workload runtime.Object) bool { return modifier.RevertDeployment(workload.(*appsv1.Deployment)) },
+		)
+	})
+})
+
+// largeDeployment returns a deployment with many containers and environment variables, similar to the pathologically
+// large manifests the targeted patch is meant for.
+func largeDeployment() *appsv1.Deployment {
+	deployment := DeploymentWithMoreBellsAndWhistles(TestNamespaceName, DeploymentNamePrefix)
+	containers := make([]corev1.Container, 0, 20)
+	for i := 0; i < 20; i++ {
+		container := corev1.Container{
+			Name:  fmt.Sprintf("container-%d", i),
+			Image: "ubuntu",
+		}
+		for j := 0; j < 250; j++ {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  fmt.Sprintf("ENV_VAR_%d", j),
+				Value: fmt.Sprintf("some configuration value for variable %d of container %d", j, i),
+			})
+		}
+		containers = append(containers, container)
+	}
+	deployment.Spec.Template.Spec.Containers = containers
+	return deployment
+}
+
+func benchmarkPatchResponse(b *testing.B, createResponse func(admission.Request, *appsv1.Deployment) admission.Response) {
+	logger := logr.Discard()
+	original := largeDeployment()
+	originalJson, err := json.Marshal(original)
+	if err != nil {
+		b.Fatal(err)
+	}
+	request := admission.Request{}
+	request.Object.Raw = originalJson
+	modified := original.DeepCopy()
+	workloads.NewResourceModifier(targetedPatchTestInstrumentationMetadata, &logger).ModifyDeployment(modified)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		response := createResponse(request, modified)
+		// marshalling the patch operations is part of responding to the admission request
+		if _, err := json.Marshal(response.Patches); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPatchResponseFullObject(b *testing.B) {
+	benchmarkPatchResponse(b, func(request admission.Request, modified *appsv1.Deployment) admission.Response {
+		marshalled, err := json.Marshal(modified)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return admission.PatchResponseFromRaw(request.Object.Raw, marshalled)
+	})
+}
+
+func BenchmarkPatchResponseTargeted(b *testing.B) {
+	benchmarkPatchResponse(b, func(request admission.Request, modified *appsv1.Deployment) admission.Response {
+		response, err := patchResponse(request, modified)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return response
+	})
+}
@@ -126,6 +126,10 @@ var _ = BeforeSuite(func() {
 		Client: k8sClient,
 	}).SetupWebhookWithManager(manager)
 	Expect(err).NotTo(HaveOccurred())
+	err = (&MonitoringMutatingWebhookHandler{
+		Client: k8sClient,
+	}).SetupWebhookWithManager(manager)
+	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
 
@@ -5,6 +5,7 @@ package workloads
 
 import (
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/dash0hq/dash0-operator/internal/backendconnection/otelcolresources"
@@ -32,6 +34,20 @@ const (
 	envVarLdPreloadValue              = "/__dash0__/dash0_injector.so"
 	envVarDash0CollectorBaseUrlName   = "DASH0_OTEL_COLLECTOR_BASE_URL"
 	envVarDash0NodeIp                 = "DASH0_NODE_IP"
+	envVarOTelServiceName             = "OTEL_SERVICE_NAME"
+	envVarOTelResourceAttributes      = "OTEL_RESOURCE_ATTRIBUTES"
+	// envVarDash0InjectedEnvVars lists the environment variables that the operator has added to a container in
+	// addition to its own DASH0_* variables, that is, those variables which the container did not set itself.
+	// Reverting the instrumentation removes exactly the variables listed here.
+	envVarDash0InjectedEnvVars = "DASH0_INJECTED_ENV_VARS"
+
+	workloadKindCronJob     = "cronjob"
+	workloadKindDaemonSet   = "daemonset"
+	workloadKindDeployment  = "deployment"
+	workloadKindJob         = "job"
+	workloadKindPod         = "pod"
+	workloadKindReplicaSet  = "replicaset"
+	workloadKindStatefulSet = "statefulset"
 )
 
 var (
@@ -40,8 +56,34 @@ var (
 	initContainerAllowPrivilegeEscalation       = false
 	initContainerPrivileged                     = false
 	initContainerReadOnlyRootFilesystem         = true
+
+	// defaultInitContainerRequests and defaultInitContainerLimits are used for the instrumentation init container unless
+	// they are overridden via InstrumentationMetadata.InitContainerResources. The init container only copies the
+	// instrumentation files to the Dash0 volume, so small values are sufficient. Setting both requests and limits is
+	// required to deploy instrumented workloads in namespaces with a resource quota for CPU and memory.
+	defaultInitContainerRequests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("32Mi"),
+	}
+	defaultInitContainerLimits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("250m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	}
 )
 
+// workloadInfo holds the metadata of the workload that is being instrumented, which is used to derive the service name
+// and resource attributes for the instrumented containers.
+type workloadInfo struct {
+	// kind is the lower case kind of the workload, as used in the semantic convention attribute k8s.<kind>.name.
+	kind        string
+	name        string
+	namespace   string
+	serviceName string
+	// labels holds the labels of the pod template, merged with the labels of the workload itself, the latter taking
+	// precedence.
+	labels map[string]string
+}
+
 type ResourceModifier struct {
 	instrumentationMetadata util.InstrumentationMetadata
 	logger                  *logr.Logger
@@ -58,23 +100,27 @@ func NewResourceModifier(
 }
 
 func (m *ResourceModifier) ModifyCronJob(cronJob *batchv1.CronJob) bool {
-	return m.modifyResource(&cronJob.Spec.JobTemplate.Spec.Template, &cronJob.ObjectMeta)
+	return m.modifyResource(&cronJob.Spec.JobTemplate.Spec.Template, &cronJob.ObjectMeta, workloadKindCronJob)
 }
 
 func (m *ResourceModifier) ModifyDaemonSet(daemonSet *appsv1.DaemonSet) bool {
-	return m.modifyResource(&daemonSet.Spec.Template, &daemonSet.ObjectMeta)
+	return m.modifyResource(&daemonSet.Spec.Template, &daemonSet.ObjectMeta, workloadKindDaemonSet)
 }
 
 func (m *ResourceModifier) ModifyDeployment(deployment *appsv1.Deployment) bool {
-	return m.modifyResource(&deployment.Spec.Template, &deployment.ObjectMeta)
+	return m.modifyResource(&deployment.Spec.Template, &deployment.ObjectMeta, workloadKindDeployment)
 }
 
 func (m *ResourceModifier) ModifyJob(job *batchv1.Job) bool {
-	return m.modifyResource(&job.Spec.Template, &job.ObjectMeta)
+	return m.modifyResource(&job.Spec.Template, &job.ObjectMeta, workloadKindJob)
 }
 
 func (m *ResourceModifier) AddLabelsToImmutableJob(job *batchv1.Job) bool {
-	util.AddInstrumentationLabels(&job.ObjectMeta, false, m.instrumentationMetadata)
+	util.AddInstrumentationLabels(
+		&job.ObjectMeta,
+		false,
+		m.instrumentationMetadataForWorkload(&job.ObjectMeta, &job.Spec.Template.ObjectMeta),
+	)
 	// adding labels always works and is a modification that requires an update
 	return true
 }
@@ -83,9 +129,14 @@ func (m *ResourceModifier) ModifyPod(pod *corev1.Pod) bool {
 	if m.hasOwnerReference(pod) {
 		return false
 	}
-	hasBeenModified := m.modifyPodSpec(&pod.Spec)
+	instrumentationMetadata := m.instrumentationMetadataForWorkload(&pod.ObjectMeta, nil)
+	hasBeenModified := m.modifyPodSpec(
+		&pod.Spec,
+		newWorkloadInfo(workloadKindPod, &pod.ObjectMeta, nil),
+		instrumentationMetadata.InitContainerImage,
+	)
 	if hasBeenModified {
-		util.AddInstrumentationLabels(&pod.ObjectMeta, true, m.instrumentationMetadata)
+		util.AddInstrumentationLabels(&pod.ObjectMeta, true, instrumentationMetadata)
 	}
 	return hasBeenModified
 }
@@ -94,29 +145,83 @@ func (m *ResourceModifier) ModifyReplicaSet(replicaSet *appsv1.ReplicaSet) bool
 	if m.hasOwnerReference(replicaSet) {
 		return false
 	}
-	return m.modifyResource(&replicaSet.Spec.Template, &replicaSet.ObjectMeta)
+	return m.modifyResource(&replicaSet.Spec.Template, &replicaSet.ObjectMeta, workloadKindReplicaSet)
 }
 
 func (m *ResourceModifier) ModifyStatefulSet(statefulSet *appsv1.StatefulSet) bool {
-	return m.modifyResource(&statefulSet.Spec.Template, &statefulSet.ObjectMeta)
-}
-
-func (m *ResourceModifier) modifyResource(podTemplateSpec *corev1.PodTemplateSpec, meta *metav1.ObjectMeta) bool {
-	hasBeenModified := m.modifyPodSpec(&podTemplateSpec.Spec)
+	return m.modifyResource(&statefulSet.Spec.Template, &statefulSet.ObjectMeta, workloadKindStatefulSet)
+}
+
+func (m *ResourceModifier) modifyResource(
+	podTemplateSpec *corev1.PodTemplateSpec,
+	meta *metav1.ObjectMeta,
+	kind string,
+) bool {
+	instrumentationMetadata := m.instrumentationMetadataForWorkload(meta, &podTemplateSpec.ObjectMeta)
+	hasBeenModified := m.modifyPodSpec(
+		&podTemplateSpec.Spec,
+		newWorkloadInfo(kind, meta, &podTemplateSpec.ObjectMeta),
+		instrumentationMetadata.InitContainerImage,
+	)
 	if hasBeenModified {
-		util.AddInstrumentationLabels(meta, true, m.instrumentationMetadata)
-		util.AddInstrumentationLabels(&podTemplateSpec.ObjectMeta, true, m.instrumentationMetadata)
-	}
-	return hasBeenModified
-}
-
-func (m *ResourceModifier) modifyPodSpec(podSpec *corev1.PodSpec) bool {
+		util.AddInstrumentationLabels(meta, true, instrumentationMetadata)
+		util.AddInstrumentationLabels(&podTemplateSpec.ObjectMeta, true, instrumentationMetadata)
+	}
+	// A workload that has been marked for lazy uninstrumentation is still instrumented, so instrumenting it again might
+	// not change the pod spec, but the mark needs to be removed nonetheless.
+	hasMarkBeenRemoved := util.RemoveUninstrumentationPendingLabel(meta)
+	return hasBeenModified || hasMarkBeenRemoved
+}
+
+// instrumentationMetadataForWorkload returns the instrumentation metadata with the init container image replaced by the
+// image from the annotation dash0.com/init-container-image, if the workload has this annotation and the image is from an
+// allowed registry.
+func (m *ResourceModifier) instrumentationMetadataForWorkload(
+	meta *metav1.ObjectMeta,
+	podTemplateMeta *metav1.ObjectMeta,
+) util.InstrumentationMetadata {
+	instrumentationMetadata := m.instrumentationMetadata
+	initContainerImage, err := util.InitContainerImageForWorkload(instrumentationMetadata.Images, meta, podTemplateMeta)
+	if err != nil {
+		m.logger.Info(err.Error(), "workload", meta.Name)
+	}
+	instrumentationMetadata.InitContainerImage = initContainerImage
+	return instrumentationMetadata
+}
+
+func newWorkloadInfo(kind string, meta *metav1.ObjectMeta, podTemplateMeta *metav1.ObjectMeta) workloadInfo {
+	serviceName := meta.Name
+	if podTemplateMeta != nil && podTemplateMeta.Annotations[util.ServiceNameAnnotationKey] != "" {
+		serviceName = podTemplateMeta.Annotations[util.ServiceNameAnnotationKey]
+	}
+	if meta.Annotations[util.ServiceNameAnnotationKey] != "" {
+		serviceName = meta.Annotations[util.ServiceNameAnnotationKey]
+	}
+	labels := make(map[string]string)
+	if podTemplateMeta != nil {
+		maps.Copy(labels, podTemplateMeta.Labels)
+	}
+	maps.Copy(labels, meta.Labels)
+	return workloadInfo{
+		kind:        kind,
+		name:        meta.Name,
+		namespace:   meta.Namespace,
+		serviceName: strings.TrimSpace(serviceName),
+		labels:      labels,
+	}
+}
+
+func (m *ResourceModifier) modifyPodSpec(
+	podSpec *corev1.PodSpec,
+	workload workloadInfo,
+	initContainerImage string,
+) bool {
 	originalSpec := podSpec.DeepCopy()
 	m.addInstrumentationVolume(podSpec)
-	m.addInitContainer(podSpec)
+	m.addInitContainer(podSpec, initContainerImage)
 	for idx := range podSpec.Containers {
 		container := &podSpec.Containers[idx]
-		m.instrumentContainer(container)
+		m.instrumentContainer(container, workload)
 	}
 
 	return !reflect.DeepEqual(originalSpec, podSpec)
@@ -145,7 +250,7 @@ func (m *ResourceModifier) addInstrumentationVolume(podSpec *corev1.PodSpec) {
 	}
 }
 
-func (m *ResourceModifier) addInitContainer(podSpec *corev1.PodSpec) {
+func (m *ResourceModifier) addInitContainer(podSpec *corev1.PodSpec, initContainerImage string) {
 	// The init container has all the instrumentation packages (e.g. the Dash0 Node.js distribution etc.), stored under
 	// /dash0-init-container/instrumentation. Its main responsibility is to copy these files to the Kubernetes volume
 	// created and mounted in addInstrumentationVolume (mounted at /__dash0__/instrumentation in the init container and
@@ -157,7 +262,7 @@ func (m *ResourceModifier) addInitContainer(podSpec *corev1.PodSpec) {
 	idx := slices.IndexFunc(podSpec.InitContainers, func(c corev1.Container) bool {
 		return c.Name == initContainerName
 	})
-	initContainer := m.createInitContainer(podSpec)
+	initContainer := m.createInitContainer(podSpec, initContainerImage)
 	if idx < 0 {
 		podSpec.InitContainers = append(podSpec.InitContainers, *initContainer)
 	} else {
@@ -165,7 +270,7 @@ func (m *ResourceModifier) addInitContainer(podSpec *corev1.PodSpec) {
 	}
 }
 
-func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec) *corev1.Container {
+func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec, initContainerImage string) *corev1.Container {
 	initContainerUser := &defaultInitContainerUser
 	initContainerGroup := &defaultInitContainerGroup
 
@@ -178,9 +283,21 @@ func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec) *corev1.
 		initContainerGroup = securityContext.FSGroup
 	}
 
+	seccompProfileType := m.instrumentationMetadata.InitContainerSecurityContext.SeccompProfileType
+	if seccompProfileType == "" {
+		seccompProfileType = corev1.SeccompProfileTypeRuntimeDefault
+	}
+	capabilities := &corev1.Capabilities{
+		Drop: []corev1.Capability{"ALL"},
+	}
+	if len(m.instrumentationMetadata.InitContainerSecurityContext.AddCapabilities) > 0 {
+		capabilities.Add = m.instrumentationMetadata.InitContainerSecurityContext.AddCapabilities
+	}
+	resources := m.instrumentationMetadata.InitContainerResources
+
 	initContainer := &corev1.Container{
 		Name:  initContainerName,
-		Image: m.instrumentationMetadata.InitContainerImage,
+		Image: initContainerImage,
 		Env: []corev1.EnvVar{
 			{
 				Name:  dash0DirectoryEnvVarName,
@@ -194,6 +311,10 @@ func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec) *corev1.
 			RunAsNonRoot:             securityContext.RunAsNonRoot,
 			RunAsUser:                initContainerUser,
 			RunAsGroup:               initContainerGroup,
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: seccompProfileType,
+			},
+			Capabilities: capabilities,
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			{
@@ -202,6 +323,10 @@ func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec) *corev1.
 				MountPath: dash0InstrumentationBaseDirectory,
 			},
 		},
+		Resources: corev1.ResourceRequirements{
+			Requests: withDefaultQuantities(resources.Requests, defaultInitContainerRequests),
+			Limits:   withDefaultQuantities(resources.Limits, defaultInitContainerLimits),
+		},
 	}
 
 	if m.instrumentationMetadata.InitContainerImagePullPolicy != "" {
@@ -210,10 +335,20 @@ func (m *ResourceModifier) createInitContainer(podSpec *corev1.PodSpec) *corev1.
 	return initContainer
 }
 
-func (m *ResourceModifier) instrumentContainer(container *corev1.Container) {
+// withDefaultQuantities returns a copy of the given resource list, with the default quantities added for all resources
+// the list does not specify.
+func withDefaultQuantities(resources corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	result := defaults.DeepCopy()
+	for name, quantity := range resources {
+		result[name] = quantity.DeepCopy()
+	}
+	return result
+}
+
+func (m *ResourceModifier) instrumentContainer(container *corev1.Container, workload workloadInfo) {
 	perContainerLogger := m.logger.WithValues("container", container.Name)
 	m.addMount(container)
-	m.addEnvironmentVariables(container, perContainerLogger)
+	m.addEnvironmentVariables(container, workload, perContainerLogger)
 }
 
 func (m *ResourceModifier) addMount(container *corev1.Container) {
@@ -235,7 +370,11 @@ func (m *ResourceModifier) addMount(container *corev1.Container) {
 	}
 }
 
-func (m *ResourceModifier) addEnvironmentVariables(container *corev1.Container, perContainerLogger logr.Logger) {
+func (m *ResourceModifier) addEnvironmentVariables(
+	container *corev1.Container,
+	workload workloadInfo,
+	perContainerLogger logr.Logger,
+) {
 	m.handleLdPreloadEnvVar(container, perContainerLogger)
 
 	m.addOrReplaceEnvironmentVariable(
@@ -265,7 +404,9 @@ func (m *ResourceModifier) addEnvironmentVariables(container *corev1.Container,
 	// If successful, we can then also eliminate the setting OTelCollectorBaseUrl in all components.
 
 	collectorBaseUrl := fmt.Sprintf(collectorBaseUrlPattern, envVarDash0NodeIp, otelcolresources.OtlpHttpHostPort)
-	if m.instrumentationMetadata.IsIPv6Cluster {
+	// In gateway mode, there is no collector pod on the node (and no host port), the workloads need to send their
+	// telemetry to the collector service instead.
+	if m.instrumentationMetadata.IsIPv6Cluster || m.instrumentationMetadata.CollectorGatewayMode {
 		collectorBaseUrl = m.instrumentationMetadata.OTelCollectorBaseUrl
 	}
 
@@ -276,6 +417,91 @@ func (m *ResourceModifier) addEnvironmentVariables(container *corev1.Container,
 			Value: collectorBaseUrl,
 		},
 	)
+
+	m.addWorkloadMetadataEnvironmentVariables(container, workload)
+}
+
+// addWorkloadMetadataEnvironmentVariables sets OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES from the workload
+// metadata, unless the container already sets them itself. The variables that have been added are recorded in
+// DASH0_INJECTED_ENV_VARS, so that they can be updated when the workload is instrumented again, and removed when the
+// instrumentation is reverted, without touching variables that have been set by the user.
+func (m *ResourceModifier) addWorkloadMetadataEnvironmentVariables(
+	container *corev1.Container,
+	workload workloadInfo,
+) {
+	previouslyInjected := injectedEnvironmentVariables(container)
+	var injected []string
+	for _, envVar := range []corev1.EnvVar{
+		{Name: envVarOTelServiceName, Value: workload.serviceName},
+		{
+			Name:  envVarOTelResourceAttributes,
+			Value: resourceAttributes(workload, m.instrumentationMetadata.PropagatedWorkloadLabels),
+		},
+	} {
+		hasBeenInjectedPreviously := slices.Contains(previouslyInjected, envVar.Name)
+		isSetByContainer := !hasBeenInjectedPreviously && slices.ContainsFunc(container.Env, func(e corev1.EnvVar) bool {
+			return e.Name == envVar.Name
+		})
+		if isSetByContainer {
+			continue
+		}
+		if envVar.Value == "" {
+			if hasBeenInjectedPreviously {
+				m.removeEnvironmentVariable(container, envVar.Name)
+			}
+			continue
+		}
+		m.addOrReplaceEnvironmentVariable(container, envVar)
+		injected = append(injected, envVar.Name)
+	}
+
+	if len(injected) > 0 {
+		m.addOrReplaceEnvironmentVariable(
+			container,
+			corev1.EnvVar{
+				Name:  envVarDash0InjectedEnvVars,
+				Value: strings.Join(injected, ","),
+			},
+		)
+	} else {
+		m.removeEnvironmentVariable(container, envVarDash0InjectedEnvVars)
+	}
+}
+
+// resourceAttributes renders the value for OTEL_RESOURCE_ATTRIBUTES. Workload labels listed in propagatedLabels are
+// added as k8s.<kind>.label.<key>, in the order in which they are configured. Label values cannot contain characters
+// that would need to be escaped in OTEL_RESOURCE_ATTRIBUTES, so they are used verbatim.
+func resourceAttributes(workload workloadInfo, propagatedLabels []string) string {
+	var attributes []string
+	if workload.namespace != "" {
+		attributes = append(attributes, fmt.Sprintf("k8s.namespace.name=%s", workload.namespace))
+	}
+	if workload.name != "" {
+		attributes = append(attributes, fmt.Sprintf("k8s.%s.name=%s", workload.kind, workload.name))
+	}
+	for _, key := range propagatedLabels {
+		if value, ok := workload.labels[key]; ok && value != "" {
+			attributes = append(attributes, fmt.Sprintf("k8s.%s.label.%s=%s", workload.kind, key, value))
+		}
+	}
+	return strings.Join(attributes, ",")
+}
+
+func injectedEnvironmentVariables(container *corev1.Container) []string {
+	idx := slices.IndexFunc(container.Env, func(c corev1.EnvVar) bool {
+		return c.Name == envVarDash0InjectedEnvVars
+	})
+	if idx < 0 || container.Env[idx].Value == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(container.Env[idx].Value, ",") {
+		// Only ever consider the variables that the operator actually injects, in case the list has been tampered with.
+		if name == envVarOTelServiceName || name == envVarOTelResourceAttributes {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 func (m *ResourceModifier) handleLdPreloadEnvVar(
@@ -297,7 +523,7 @@ func (m *ResourceModifier) handleLdPreloadEnvVar(
 	} else {
 		// Note: This needs to be a point to the env var, otherwise updates would only be local to this function.
 		envVar := &container.Env[idx]
-		if envVar.Value == "" && envVar.ValueFrom != nil {
+		if isSetViaValueFrom(envVar) {
 			perContainerLogger.Info(
 				fmt.Sprintf(
 					"Dash0 cannot prepend anything to the environment variable %s as it is specified via "+
@@ -425,6 +651,10 @@ func (m *ResourceModifier) removeMount(container *corev1.Container) {
 }
 
 func (m *ResourceModifier) removeEnvironmentVariables(container *corev1.Container) {
+	for _, name := range injectedEnvironmentVariables(container) {
+		m.removeEnvironmentVariable(container, name)
+	}
+	m.removeEnvironmentVariable(container, envVarDash0InjectedEnvVars)
 	m.removeLdPreload(container)
 	m.removeEnvironmentVariable(container, envVarDash0NodeIp)
 	m.removeEnvironmentVariable(container, envVarDash0CollectorBaseUrlName)
@@ -468,3 +698,48 @@ func (m *ResourceModifier) removeEnvironmentVariable(container *corev1.Container
 func (m *ResourceModifier) hasOwnerReference(workload client.Object) bool {
 	return len(workload.GetOwnerReferences()) > 0
 }
+
+// FindUninstrumentableContainers returns the names of the containers of the given workload that cannot be instrumented,
+// because they set an environment variable the instrumentation needs to extend (LD_PRELOAD) via ValueFrom. The workload
+// is left unchanged for these containers apart from the volume mount and the Dash0 environment variables, hence they
+// will not send telemetry.
+func FindUninstrumentableContainers(workload runtime.Object) []string {
+	podSpec := podSpecOf(workload)
+	if podSpec == nil {
+		return nil
+	}
+	var containerNames []string
+	for _, container := range podSpec.Containers {
+		if slices.ContainsFunc(container.Env, func(envVar corev1.EnvVar) bool {
+			return envVar.Name == envVarLdPreloadName && isSetViaValueFrom(&envVar)
+		}) {
+			containerNames = append(containerNames, container.Name)
+		}
+	}
+	return containerNames
+}
+
+func isSetViaValueFrom(envVar *corev1.EnvVar) bool {
+	return envVar.Value == "" && envVar.ValueFrom != nil
+}
+
+func podSpecOf(workload runtime.Object) *corev1.PodSpec {
+	switch w := workload.(type) {
+	case *batchv1.CronJob:
+		return &w.Spec.JobTemplate.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template.Spec
+	case *appsv1.Deployment:
+		return &w.Spec.Template.Spec
+	case *batchv1.Job:
+		return &w.Spec.Template.Spec
+	case *corev1.Pod:
+		return &w.Spec
+	case *appsv1.ReplicaSet:
+		return &w.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template.Spec
+	default:
+		return nil
+	}
+}
@@ -10,6 +10,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/dash0hq/dash0-operator/internal/util"
@@ -58,6 +60,7 @@ var _ = Describe("Dash0 Workload Modification", func() {
 
 			Expect(hasBeenModified).To(BeTrue())
 			VerifyModifiedDeployment(workload, BasicInstrumentedPodSpecExpectations())
+			Expect(FindUninstrumentableContainers(workload)).To(BeEmpty())
 		})
 
 		It("should instrument a deployment that has multiple containers, and already has volumes and init containers", func() {
@@ -74,20 +77,26 @@ var _ = Describe("Dash0 Workload Modification", func() {
 					{
 						VolumeMounts:                             2,
 						Dash0VolumeMountIdx:                      1,
-						EnvVars:                                  4,
+						EnvVars:                                  7,
 						LdPreloadEnvVarIdx:                       1,
 						NodeIpIdx:                                2,
 						Dash0CollectorBaseUrlEnvVarIdx:           3,
 						Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+						OTelServiceNameEnvVarIdx:                 4,
+						OTelResourceAttributesEnvVarIdx:          5,
+						Dash0InjectedEnvVarsIdx:                  6,
 					},
 					{
 						VolumeMounts:                             3,
 						Dash0VolumeMountIdx:                      2,
-						EnvVars:                                  5,
+						EnvVars:                                  8,
 						LdPreloadEnvVarIdx:                       2,
 						NodeIpIdx:                                3,
 						Dash0CollectorBaseUrlEnvVarIdx:           4,
 						Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+						OTelServiceNameEnvVarIdx:                 5,
+						OTelResourceAttributesEnvVarIdx:          6,
+						Dash0InjectedEnvVarsIdx:                  7,
 					},
 				},
 			})
@@ -98,6 +107,7 @@ var _ = Describe("Dash0 Workload Modification", func() {
 			hasBeenModified := workloadModifier.ModifyDeployment(workload)
 
 			Expect(hasBeenModified).To(BeTrue())
+			Expect(FindUninstrumentableContainers(workload)).To(Equal([]string{"test-container-0"}))
 			VerifyModifiedDeployment(workload, PodSpecExpectations{
 				Volumes:               3,
 				Dash0VolumeIdx:        1,
@@ -107,22 +117,28 @@ var _ = Describe("Dash0 Workload Modification", func() {
 					{
 						VolumeMounts:                             2,
 						Dash0VolumeMountIdx:                      1,
-						EnvVars:                                  4,
+						EnvVars:                                  7,
 						LdPreloadEnvVarIdx:                       1,
 						LdPreloadUsesValueFrom:                   true,
 						NodeIpIdx:                                2,
 						Dash0CollectorBaseUrlEnvVarIdx:           3,
 						Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+						OTelServiceNameEnvVarIdx:                 4,
+						OTelResourceAttributesEnvVarIdx:          5,
+						Dash0InjectedEnvVarsIdx:                  6,
 					},
 					{
 						VolumeMounts:                             3,
 						Dash0VolumeMountIdx:                      1,
-						EnvVars:                                  4,
+						EnvVars:                                  7,
 						LdPreloadEnvVarIdx:                       1,
 						LdPreloadValue:                           "/__dash0__/dash0_injector.so third_party_preload.so another_third_party_preload.so",
 						NodeIpIdx:                                2,
 						Dash0CollectorBaseUrlEnvVarIdx:           0,
 						Dash0CollectorBaseUrlEnvVarExpectedValue: OTelCollectorBaseUrlTest,
+						OTelServiceNameEnvVarIdx:                 4,
+						OTelResourceAttributesEnvVarIdx:          5,
+						Dash0InjectedEnvVarsIdx:                  6,
 					},
 				},
 			})
@@ -175,6 +191,174 @@ var _ = Describe("Dash0 Workload Modification", func() {
 			Expect(hasBeenModified).To(BeTrue())
 			VerifyModifiedStatefulSet(workload, BasicInstrumentedPodSpecExpectations())
 		})
+
+		It("should apply the configured init container security context", func() {
+			customInstrumentationMetadata := instrumentationMetadata
+			customInstrumentationMetadata.InitContainerSecurityContext = util.InitContainerSecurityContext{
+				SeccompProfileType: corev1.SeccompProfileTypeUnconfined,
+				AddCapabilities:    []corev1.Capability{"CHOWN"},
+			}
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			hasBeenModified := NewResourceModifier(customInstrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			initContainers := workload.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(1))
+			securityContext := initContainers[0].SecurityContext
+			Expect(securityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeUnconfined))
+			Expect(securityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+			Expect(securityContext.Capabilities.Add).To(Equal([]corev1.Capability{"CHOWN"}))
+		})
+
+		It("should apply the configured init container resources, falling back to the defaults", func() {
+			customInstrumentationMetadata := instrumentationMetadata
+			customInstrumentationMetadata.InitContainerResources = corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			hasBeenModified := NewResourceModifier(customInstrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			initContainers := workload.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(1))
+			resources := initContainers[0].Resources
+			Expect(resources.Requests.Cpu().String()).To(Equal("10m"))
+			Expect(resources.Requests.Memory().String()).To(Equal("64Mi"))
+			Expect(resources.Limits.Cpu().String()).To(Equal("1"))
+			Expect(resources.Limits.Memory().String()).To(Equal("128Mi"))
+		})
+
+		It("should use the init container image from the workload annotation", func() {
+			overrideImage := "some-registry.com:1234/dash0hq/instrumentation:4.6.0-rc.1"
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			workload.Annotations = map[string]string{util.InitContainerImageAnnotationKey: overrideImage}
+			hasBeenModified := NewResourceModifier(instrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			initContainers := workload.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(1))
+			Expect(initContainers[0].Image).To(Equal(overrideImage))
+			Expect(workload.Labels["dash0.com/init-container-image"]).To(Equal(util.ImageNameToLabel(overrideImage)))
+			Expect(util.HasBeenInstrumentedSuccessfullyByThisVersion(&workload.ObjectMeta, TestImages)).To(BeTrue())
+		})
+
+		It("should ignore an init container image override from a registry that is not allowed", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			workload.Annotations = map[string]string{
+				util.InitContainerImageAnnotationKey: "evil.example.com/instrumentation:6.6.6",
+			}
+			hasBeenModified := NewResourceModifier(instrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			initContainers := workload.Spec.Template.Spec.InitContainers
+			Expect(initContainers).To(HaveLen(1))
+			Expect(initContainers[0].Image).To(Equal(InitContainerImageTest))
+		})
+
+		It("should use the collector service URL in collector gateway mode", func() {
+			customInstrumentationMetadata := instrumentationMetadata
+			customInstrumentationMetadata.OTelCollectorBaseUrl =
+				"http://dash0-operator-opentelemetry-collector-service.dash0-system.svc.cluster.local:4318"
+			customInstrumentationMetadata.CollectorGatewayMode = true
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			hasBeenModified := NewResourceModifier(customInstrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].Dash0CollectorBaseUrlEnvVarExpectedValue =
+				customInstrumentationMetadata.OTelCollectorBaseUrl
+			VerifyModifiedDeployment(workload, expectations)
+		})
+	})
+
+	Describe("when setting the service name and resource attributes", func() {
+		It("should derive the service name and resource attributes from the workload", func() {
+			workload := BasicStatefulSet(TestNamespaceName, StatefulSetNamePrefix)
+			hasBeenModified := workloadModifier.ModifyStatefulSet(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].OTelServiceNameEnvVarExpectedValue = StatefulSetNamePrefix
+			expectations.Containers[0].OTelResourceAttributesEnvVarExpectedValue =
+				"k8s.namespace.name=" + TestNamespaceName + ",k8s.statefulset.name=" + StatefulSetNamePrefix
+			VerifyModifiedStatefulSet(workload, expectations)
+		})
+
+		It("should use the service name annotation of the workload", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			workload.Annotations = map[string]string{"dash0.com/service-name": "checkout"}
+			workload.Spec.Template.Annotations = map[string]string{"dash0.com/service-name": "ignored"}
+			hasBeenModified := workloadModifier.ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].OTelServiceNameEnvVarExpectedValue = "checkout"
+			expectations.Containers[0].OTelResourceAttributesEnvVarExpectedValue =
+				"k8s.namespace.name=" + TestNamespaceName + ",k8s.deployment.name=" + DeploymentNamePrefix
+			VerifyModifiedDeployment(workload, expectations)
+		})
+
+		It("should use the service name annotation of the pod template", func() {
+			workload := BasicCronJob(TestNamespaceName, CronJobNamePrefix)
+			workload.Spec.JobTemplate.Spec.Template.Annotations = map[string]string{"dash0.com/service-name": "nightly"}
+			hasBeenModified := workloadModifier.ModifyCronJob(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].OTelServiceNameEnvVarExpectedValue = "nightly"
+			expectations.Containers[0].OTelResourceAttributesEnvVarExpectedValue =
+				"k8s.namespace.name=" + TestNamespaceName + ",k8s.cronjob.name=" + CronJobNamePrefix
+			VerifyModifiedCronJob(workload, expectations)
+		})
+
+		It("should add the configured workload labels to the resource attributes", func() {
+			customInstrumentationMetadata := instrumentationMetadata
+			customInstrumentationMetadata.PropagatedWorkloadLabels = []string{"team", "app.kubernetes.io/version", "unset"}
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			workload.Labels = map[string]string{"team": "checkout", "not-propagated": "value"}
+			workload.Spec.Template.Labels = map[string]string{"team": "ignored", "app.kubernetes.io/version": "1.2.3"}
+			hasBeenModified := NewResourceModifier(customInstrumentationMetadata, &logger).ModifyDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].OTelResourceAttributesEnvVarExpectedValue =
+				"k8s.namespace.name=" + TestNamespaceName +
+					",k8s.deployment.name=" + DeploymentNamePrefix +
+					",k8s.deployment.label.team=checkout" +
+					",k8s.deployment.label.app.kubernetes.io/version=1.2.3"
+			VerifyModifiedDeployment(workload, expectations)
+		})
+
+		It("should not overwrite a service name that the container sets itself", func() {
+			workload := BasicPod(TestNamespaceName, PodNamePrefix)
+			workload.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "OTEL_SERVICE_NAME", Value: "my-service"}}
+			hasBeenModified := workloadModifier.ModifyPod(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			env := workload.Spec.Containers[0].Env
+			Expect(env).To(HaveLen(6))
+			Expect(env[0]).To(Equal(corev1.EnvVar{Name: "OTEL_SERVICE_NAME", Value: "my-service"}))
+			Expect(env).To(ContainElement(MatchEnvVar(
+				"OTEL_RESOURCE_ATTRIBUTES",
+				"k8s.namespace.name="+TestNamespaceName+",k8s.pod.name="+PodNamePrefix,
+			)))
+			Expect(env).To(ContainElement(MatchEnvVar("DASH0_INJECTED_ENV_VARS", "OTEL_RESOURCE_ATTRIBUTES")))
+
+			hasBeenModified = workloadModifier.ModifyPod(workload)
+			Expect(hasBeenModified).To(BeFalse())
+		})
+
+		It("should update the injected variables when the service name annotation changes", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			Expect(workloadModifier.ModifyDeployment(workload)).To(BeTrue())
+			workload.Annotations = map[string]string{"dash0.com/service-name": "renamed"}
+			Expect(workloadModifier.ModifyDeployment(workload)).To(BeTrue())
+
+			expectations := BasicInstrumentedPodSpecExpectations()
+			expectations.Containers[0].OTelServiceNameEnvVarExpectedValue = "renamed"
+			VerifyModifiedDeployment(workload, expectations)
+		})
 	})
 
 	Describe("when instrumenting workloads multiple times (instrumentation needs to be idempotent)", func() {
@@ -254,6 +438,16 @@ var _ = Describe("Dash0 Workload Modification", func() {
 			VerifyModifiedStatefulSet(workload, BasicInstrumentedPodSpecExpectations())
 			Expect(reflect.DeepEqual(instrumentedOnce, workload)).To(BeTrue())
 		})
+
+		It("should remove the uninstrumentation pending mark when instrumenting an instrumented workload again", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			Expect(workloadModifier.ModifyDeployment(workload)).To(BeTrue())
+			util.MarkUninstrumentationPending(&workload.ObjectMeta)
+			hasBeenModified := workloadModifier.ModifyDeployment(workload)
+			Expect(hasBeenModified).To(BeTrue())
+			Expect(util.IsUninstrumentationPending(&workload.ObjectMeta)).To(BeFalse())
+			VerifyModifiedDeployment(workload, BasicInstrumentedPodSpecExpectations())
+		})
 	})
 
 	Describe("when reverting workloads", func() {
@@ -293,25 +487,56 @@ var _ = Describe("Dash0 Workload Modification", func() {
 				Dash0InitContainerIdx: -1,
 				Containers: []ContainerExpectations{
 					{
-						VolumeMounts:                   1,
-						Dash0VolumeMountIdx:            -1,
-						EnvVars:                        1,
-						LdPreloadEnvVarIdx:             -1,
-						NodeIpIdx:                      -1,
-						Dash0CollectorBaseUrlEnvVarIdx: -1,
+						VolumeMounts:                    1,
+						Dash0VolumeMountIdx:             -1,
+						EnvVars:                         1,
+						LdPreloadEnvVarIdx:              -1,
+						NodeIpIdx:                       -1,
+						Dash0CollectorBaseUrlEnvVarIdx:  -1,
+						OTelServiceNameEnvVarIdx:        -1,
+						OTelResourceAttributesEnvVarIdx: -1,
+						Dash0InjectedEnvVarsIdx:         -1,
 					},
 					{
-						VolumeMounts:                   2,
-						Dash0VolumeMountIdx:            -1,
-						EnvVars:                        2,
-						LdPreloadEnvVarIdx:             -1,
-						NodeIpIdx:                      -1,
-						Dash0CollectorBaseUrlEnvVarIdx: -1,
+						VolumeMounts:                    2,
+						Dash0VolumeMountIdx:             -1,
+						EnvVars:                         2,
+						LdPreloadEnvVarIdx:              -1,
+						NodeIpIdx:                       -1,
+						Dash0CollectorBaseUrlEnvVarIdx:  -1,
+						OTelServiceNameEnvVarIdx:        -1,
+						OTelResourceAttributesEnvVarIdx: -1,
+						Dash0InjectedEnvVarsIdx:         -1,
 					},
 				},
 			})
 		})
 
+		It("should remove exactly the variables that have been added when instrumenting the workload", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			Expect(workloadModifier.ModifyDeployment(workload)).To(BeTrue())
+			hasBeenModified := workloadModifier.RevertDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			VerifyUnmodifiedDeployment(workload)
+		})
+
+		It("should keep the service name and resource attributes that the container sets itself", func() {
+			workload := BasicDeployment(TestNamespaceName, DeploymentNamePrefix)
+			userEnvVars := []corev1.EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "my-service"},
+				{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: "team=checkout"},
+			}
+			workload.Spec.Template.Spec.Containers[0].Env = userEnvVars
+			Expect(workloadModifier.ModifyDeployment(workload)).To(BeTrue())
+			// LD_PRELOAD, DASH0_NODE_IP and DASH0_OTEL_COLLECTOR_BASE_URL, but no DASH0_INJECTED_ENV_VARS
+			Expect(workload.Spec.Template.Spec.Containers[0].Env).To(HaveLen(5))
+			hasBeenModified := workloadModifier.RevertDeployment(workload)
+
+			Expect(hasBeenModified).To(BeTrue())
+			Expect(workload.Spec.Template.Spec.Containers[0].Env).To(Equal(userEnvVars))
+		})
+
 		It("should remove Dash0 from an instrumented ownerless replica set", func() {
 			workload := InstrumentedReplicaSet(TestNamespaceName, ReplicaSetNamePrefix)
 			hasBeenModified := workloadModifier.RevertReplicaSet(workload)
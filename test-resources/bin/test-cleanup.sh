@@ -26,10 +26,11 @@ sleep 1
 # test-namespace. Also, we cannot remove the finalizer from the resource via kubectl patch if the
 # validatingwebhookconfiguration is not reachable due to the operator image being faulty (the edit made via kubectl
 # patch is send to the validation webhook first, and if the webhook service is not up, the patch never gets executed).
-# To get out of this state, we remove the validatingwebhookconfiguration first and then remove the finalizer. All of
+# To get out of this state, we remove the webhook configurations first and then remove the finalizer. All of
 # this is only relevant for local development, a fully tested operator image from an official release cannot get into
 # this state.
 kubectl delete validatingwebhookconfiguration --ignore-not-found dash0-operator-monitoring-validator
+kubectl delete mutatingwebhookconfiguration --ignore-not-found dash0-operator-monitoring-mutator
 kubectl patch -n "${target_namespace}" -f test-resources/customresources/dash0monitoring/dash0monitoring.yaml -p '{"metadata":{"finalizers":null}}' --type=merge --request-timeout=1s || true
 kubectl delete -f test-resources/customresources/dash0operatorconfiguration/dash0operatorconfiguration.token.yaml || true
 kubectl delete dash0operatorconfigurations.operator.dash0.com/dash0-operator-configuration-auto-resource || true
@@ -70,6 +71,7 @@ kubectl delete clusterrolebinding           --ignore-not-found dash0-operator-ma
 kubectl delete clusterrolebinding           --ignore-not-found dash0-operator-opentelemetry-collector-crb
 kubectl delete clusterrolebinding           --ignore-not-found dash0-operator-proxy-rolebinding
 kubectl delete mutatingwebhookconfiguration --ignore-not-found dash0-operator-injector
+kubectl delete mutatingwebhookconfiguration --ignore-not-found dash0-operator-monitoring-mutator
 kubectl delete validatingwebhookconfiguration --ignore-not-found dash0-operator-operator-configuration-validator
 kubectl delete validatingwebhookconfiguration --ignore-not-found dash0-operator-monitoring-validator
 
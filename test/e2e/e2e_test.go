@@ -271,6 +271,9 @@ var _ = Describe("Dash0 Kubernetes Operator", Ordered, func() {
 					images,
 					"controller",
 				)
+				Eventually(func(g Gomega) {
+					verifyReinstrumentedAfterUpgradeEvent(g, applicationUnderTestNamespace, "deployment", "controller")
+				}, labelChangeTimeout, pollingInterval).Should(Succeed())
 			})
 		})
 	})
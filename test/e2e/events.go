@@ -32,6 +32,22 @@ func verifySuccessfulInstrumentationEvent(
 	)
 }
 
+func verifyReinstrumentedAfterUpgradeEvent(
+	g Gomega,
+	namespace string,
+	workloadType string,
+	eventSource string,
+) {
+	verifyEvent(
+		g,
+		namespace,
+		workloadType,
+		util.ReasonReinstrumentedAfterUpgrade,
+		fmt.Sprintf("The %s has updated the Dash0 instrumentation of this workload, since it had been instrumented "+
+			"by a different version of the operator.", eventSource),
+	)
+}
+
 func verifyFailedInstrumentationEvent(
 	g Gomega,
 	namespace string,
@@ -40,12 +40,28 @@ var (
 			RunAsNonRoot:             ptr.To(false),
 			RunAsUser:                &ArbitraryNumer,
 			RunAsGroup:               &ArbitraryNumer,
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
 		},
 		VolumeMounts: []corev1.VolumeMount{{
 			Name:      "dash0-instrumentation",
 			ReadOnly:  false,
 			MountPath: "/__dash0__",
 		}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
 	}
 )
 
@@ -85,6 +101,26 @@ func EnsureNamespaceExists(
 	return object.(*corev1.Namespace)
 }
 
+// SetNamespaceOptOutLabel adds the label dash0.com/enable=false to the given namespace, or removes it again.
+func SetNamespaceOptOutLabel(
+	ctx context.Context,
+	k8sClient client.Client,
+	namespace string,
+	optOut bool,
+) {
+	namespaceResource := &corev1.Namespace{}
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Name: namespace}, namespaceResource)).To(Succeed())
+	if optOut {
+		if namespaceResource.Labels == nil {
+			namespaceResource.Labels = map[string]string{}
+		}
+		namespaceResource.Labels["dash0.com/enable"] = "false"
+	} else {
+		delete(namespaceResource.Labels, "dash0.com/enable")
+	}
+	Expect(k8sClient.Update(ctx, namespaceResource)).To(Succeed())
+}
+
 func EnsureKubernetesObjectExists(
 	ctx context.Context,
 	k8sClient client.Client,
@@ -131,7 +167,7 @@ func CreateBasicCronJob(
 
 func InstrumentedCronJob(namespace string, name string) *batchv1.CronJob {
 	workload := BasicCronJob(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.JobTemplate.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.JobTemplate.Spec.Template, &workload.ObjectMeta, "cronjob")
 	return workload
 }
 
@@ -180,7 +216,7 @@ func CreateBasicDaemonSet(
 
 func InstrumentedDaemonSet(namespace string, name string) *appsv1.DaemonSet {
 	workload := BasicDaemonSet(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "daemonset")
 	return workload
 }
 
@@ -229,7 +265,7 @@ func CreateBasicDeployment(
 
 func InstrumentedDeployment(namespace string, name string) *appsv1.Deployment {
 	workload := BasicDeployment(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "deployment")
 	return workload
 }
 
@@ -278,7 +314,7 @@ func CreateBasicJob(
 
 func InstrumentedJob(namespace string, name string) *batchv1.Job {
 	workload := BasicJob(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "job")
 	return workload
 }
 
@@ -291,6 +327,27 @@ func CreateInstrumentedJob(
 	return CreateWorkload(ctx, k8sClient, InstrumentedJob(namespace, name)).(*batchv1.Job)
 }
 
+func JobOwnedByCronJob(namespace string, name string, cronJobName string) *batchv1.Job {
+	workload := BasicJob(namespace, name)
+	workload.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+		Name:       cronJobName,
+		UID:        "1234",
+	}}
+	return workload
+}
+
+func CreateJobOwnedByCronJob(
+	ctx context.Context,
+	k8sClient client.Client,
+	namespace string,
+	name string,
+	cronJobName string,
+) *batchv1.Job {
+	return CreateWorkload(ctx, k8sClient, JobOwnedByCronJob(namespace, name, cronJobName)).(*batchv1.Job)
+}
+
 func JobForWhichAnInstrumentationAttemptHasFailed(namespace string, name string) *batchv1.Job {
 	workload := BasicJob(namespace, name)
 	addInstrumentationLabels(&workload.ObjectMeta, false)
@@ -341,7 +398,7 @@ func CreateBasicPod(
 
 func InstrumentedPod(namespace string, name string) *corev1.Pod {
 	workload := BasicPod(namespace, name)
-	simulateInstrumentedPodSpec(&workload.Spec, &workload.ObjectMeta)
+	simulateInstrumentedPodSpec(&workload.Spec, &workload.ObjectMeta, "pod")
 	return workload
 }
 
@@ -414,7 +471,7 @@ func CreateBasicReplicaSet(
 
 func InstrumentedReplicaSet(namespace string, name string) *appsv1.ReplicaSet {
 	workload := BasicReplicaSet(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "replicaset")
 	return workload
 }
 
@@ -453,7 +510,7 @@ func CreateReplicaSetOwnedByDeployment(
 
 func InstrumentedReplicaSetOwnedByDeployment(namespace string, name string) *appsv1.ReplicaSet {
 	workload := ReplicaSetOwnedByDeployment(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "replicaset")
 	return workload
 }
 
@@ -493,7 +550,7 @@ func CreateBasicStatefulSet(
 
 func InstrumentedStatefulSet(namespace string, name string) *appsv1.StatefulSet {
 	workload := BasicStatefulSet(namespace, name)
-	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta)
+	simulateInstrumentedResource(&workload.Spec.Template, &workload.ObjectMeta, "statefulset")
 	return workload
 }
 
@@ -877,12 +934,12 @@ func InstrumentedDeploymentWithMoreBellsAndWhistles(namespace string, name strin
 	return deployment
 }
 
-func simulateInstrumentedResource(podTemplateSpec *corev1.PodTemplateSpec, meta *metav1.ObjectMeta) {
-	simulateInstrumentedPodSpec(&podTemplateSpec.Spec, meta)
+func simulateInstrumentedResource(podTemplateSpec *corev1.PodTemplateSpec, meta *metav1.ObjectMeta, kind string) {
+	simulateInstrumentedPodSpec(&podTemplateSpec.Spec, meta, kind)
 	addInstrumentationLabels(&podTemplateSpec.ObjectMeta, true)
 }
 
-func simulateInstrumentedPodSpec(podSpec *corev1.PodSpec, meta *metav1.ObjectMeta) {
+func simulateInstrumentedPodSpec(podSpec *corev1.PodSpec, meta *metav1.ObjectMeta, kind string) {
 	podSpec.Volumes = []corev1.Volume{
 		{
 			Name: "dash0-instrumentation",
@@ -913,6 +970,18 @@ func simulateInstrumentedPodSpec(podSpec *corev1.PodSpec, meta *metav1.ObjectMet
 			Name:  "DASH0_OTEL_COLLECTOR_BASE_URL",
 			Value: OTelCollectorBaseUrlTest,
 		},
+		{
+			Name:  "OTEL_SERVICE_NAME",
+			Value: meta.Name,
+		},
+		{
+			Name:  "OTEL_RESOURCE_ATTRIBUTES",
+			Value: fmt.Sprintf("k8s.namespace.name=%s,k8s.%s.name=%s", meta.Namespace, kind, meta.Name),
+		},
+		{
+			Name:  "DASH0_INJECTED_ENV_VARS",
+			Value: "OTEL_SERVICE_NAME,OTEL_RESOURCE_ATTRIBUTES",
+		},
 	}
 
 	addInstrumentationLabels(meta, true)
@@ -1044,6 +1113,10 @@ func AddOptOutLabel(meta *metav1.ObjectMeta) {
 	AddLabel(meta, "dash0.com/enable", "false")
 }
 
+func AddOptInLabel(meta *metav1.ObjectMeta) {
+	AddLabel(meta, "dash0.com/enable", "true")
+}
+
 func RemoveOptOutLabel(meta *metav1.ObjectMeta) {
 	RemoveLabel(meta, "dash0.com/enable")
 }
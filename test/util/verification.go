@@ -6,6 +6,8 @@ package util
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -19,16 +21,23 @@ import (
 	"github.com/dash0hq/dash0-operator/internal/util"
 )
 
+// ContainerExpectations describes the expected state of a container. The expected values for OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES are only verified if they are not empty, since they depend on the workload kind and name.
 type ContainerExpectations struct {
-	VolumeMounts                             int
-	Dash0VolumeMountIdx                      int
-	EnvVars                                  int
-	LdPreloadEnvVarIdx                       int
-	LdPreloadValue                           string
-	LdPreloadUsesValueFrom                   bool
-	NodeIpIdx                                int
-	Dash0CollectorBaseUrlEnvVarIdx           int
-	Dash0CollectorBaseUrlEnvVarExpectedValue string
+	VolumeMounts                              int
+	Dash0VolumeMountIdx                       int
+	EnvVars                                   int
+	LdPreloadEnvVarIdx                        int
+	LdPreloadValue                            string
+	LdPreloadUsesValueFrom                    bool
+	NodeIpIdx                                 int
+	Dash0CollectorBaseUrlEnvVarIdx            int
+	Dash0CollectorBaseUrlEnvVarExpectedValue  string
+	OTelServiceNameEnvVarIdx                  int
+	OTelServiceNameEnvVarExpectedValue        string
+	OTelResourceAttributesEnvVarIdx           int
+	OTelResourceAttributesEnvVarExpectedValue string
+	Dash0InjectedEnvVarsIdx                   int
 }
 
 type PodSpecExpectations struct {
@@ -50,12 +59,15 @@ func BasicInstrumentedPodSpecExpectations() PodSpecExpectations {
 		InitContainers:        1,
 		Dash0InitContainerIdx: 0,
 		Containers: []ContainerExpectations{{
-			VolumeMounts:                   1,
-			Dash0VolumeMountIdx:            0,
-			EnvVars:                        3,
-			LdPreloadEnvVarIdx:             0,
-			NodeIpIdx:                      1,
-			Dash0CollectorBaseUrlEnvVarIdx: 2,
+			VolumeMounts:                    1,
+			Dash0VolumeMountIdx:             0,
+			EnvVars:                         6,
+			LdPreloadEnvVarIdx:              0,
+			NodeIpIdx:                       1,
+			Dash0CollectorBaseUrlEnvVarIdx:  2,
+			OTelServiceNameEnvVarIdx:        3,
+			OTelResourceAttributesEnvVarIdx: 4,
+			Dash0InjectedEnvVarsIdx:         5,
 			Dash0CollectorBaseUrlEnvVarExpectedValue://
 			OTelCollectorBaseUrlTest,
 		}},
@@ -222,6 +234,15 @@ func verifyPodSpec(podSpec corev1.PodSpec, expectations PodSpecExpectations) {
 			Expect(initContainer.Env).To(HaveLen(1))
 			Expect(initContainer.Env).To(ContainElement(MatchEnvVar("DASH0_INSTRUMENTATION_FOLDER_DESTINATION", "/__dash0__")))
 			Expect(initContainer.SecurityContext).NotTo(BeNil())
+			Expect(*initContainer.SecurityContext.AllowPrivilegeEscalation).To(BeFalse())
+			Expect(initContainer.SecurityContext.SeccompProfile).NotTo(BeNil())
+			Expect(initContainer.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+			Expect(initContainer.SecurityContext.Capabilities).NotTo(BeNil())
+			Expect(initContainer.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+			Expect(initContainer.Resources.Requests.Cpu().String()).To(Equal("10m"))
+			Expect(initContainer.Resources.Requests.Memory().String()).To(Equal("32Mi"))
+			Expect(initContainer.Resources.Limits.Cpu().String()).To(Equal("250m"))
+			Expect(initContainer.Resources.Limits.Memory().String()).To(Equal("128Mi"))
 			Expect(initContainer.VolumeMounts).To(HaveLen(1))
 			Expect(initContainer.VolumeMounts).To(ContainElement(MatchVolumeMount("dash0-instrumentation", "/__dash0__")))
 		} else {
@@ -278,6 +299,21 @@ func verifyPodSpec(podSpec corev1.PodSpec, expectations PodSpecExpectations) {
 				Expect(envVar.Name).To(Equal("DASH0_OTEL_COLLECTOR_BASE_URL"))
 				Expect(envVar.Value).To(Equal(containerExpectations.Dash0CollectorBaseUrlEnvVarExpectedValue))
 				Expect(envVar.ValueFrom).To(BeNil())
+			} else if j == containerExpectations.OTelServiceNameEnvVarIdx {
+				Expect(envVar.Name).To(Equal("OTEL_SERVICE_NAME"))
+				Expect(envVar.Value).NotTo(BeEmpty())
+				if containerExpectations.OTelServiceNameEnvVarExpectedValue != "" {
+					Expect(envVar.Value).To(Equal(containerExpectations.OTelServiceNameEnvVarExpectedValue))
+				}
+			} else if j == containerExpectations.OTelResourceAttributesEnvVarIdx {
+				Expect(envVar.Name).To(Equal("OTEL_RESOURCE_ATTRIBUTES"))
+				Expect(envVar.Value).NotTo(BeEmpty())
+				if containerExpectations.OTelResourceAttributesEnvVarExpectedValue != "" {
+					Expect(envVar.Value).To(Equal(containerExpectations.OTelResourceAttributesEnvVarExpectedValue))
+				}
+			} else if j == containerExpectations.Dash0InjectedEnvVarsIdx {
+				Expect(envVar.Name).To(Equal("DASH0_INJECTED_ENV_VARS"))
+				Expect(envVar.Value).To(Equal(expectedInjectedEnvVars(containerExpectations)))
 			} else {
 				Expect(envVar.Name).To(Equal(fmt.Sprintf("TEST%d", j)))
 			}
@@ -285,6 +321,17 @@ func verifyPodSpec(podSpec corev1.PodSpec, expectations PodSpecExpectations) {
 	}
 }
 
+func expectedInjectedEnvVars(containerExpectations ContainerExpectations) string {
+	var injected []string
+	if containerExpectations.OTelServiceNameEnvVarIdx >= 0 {
+		injected = append(injected, "OTEL_SERVICE_NAME")
+	}
+	if containerExpectations.OTelResourceAttributesEnvVarIdx >= 0 {
+		injected = append(injected, "OTEL_RESOURCE_ATTRIBUTES")
+	}
+	return strings.Join(injected, ",")
+}
+
 func verifyUnmodifiedPodSpec(podSpec corev1.PodSpec) {
 	verifyUnmodifiedPodSpecEventually(Default, podSpec)
 }
@@ -377,6 +424,25 @@ func VerifySuccessfulInstrumentationEvent(
 	)
 }
 
+func VerifyReinstrumentedAfterUpgradeEvent(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	eventSource string,
+) *corev1.Event {
+	return verifyEvent(
+		ctx,
+		clientset,
+		namespace,
+		resourceName,
+		util.ReasonReinstrumentedAfterUpgrade,
+		fmt.Sprintf(
+			"The %s has updated the Dash0 instrumentation of this workload, since it had been instrumented by a "+
+				"different version of the operator.", eventSource),
+	)
+}
+
 func VerifyNoInstrumentationNecessaryEvent(
 	ctx context.Context,
 	clientset *kubernetes.Clientset,
@@ -391,11 +457,106 @@ func VerifyNoInstrumentationNecessaryEvent(
 		resourceName,
 		util.ReasonNoInstrumentationNecessary,
 		fmt.Sprintf(
-			"Dash0 instrumentation was already present on this workload, or the workload is part of a higher order "+
-				"workload that will be instrumented, no modification by the %s is necessary.", eventSource),
+			"Dash0 instrumentation was already present on this workload, no modification by the %s is necessary.",
+			eventSource),
+	)
+}
+
+func VerifyOptedOutOfInstrumentationEvent(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	eventSource string,
+) *corev1.Event {
+	return verifyEvent(
+		ctx,
+		clientset,
+		namespace,
+		resourceName,
+		util.ReasonOptedOutOfInstrumentation,
+		fmt.Sprintf(
+			"This workload has opted out of Dash0 instrumentation via the label dash0.com/enable=false, it has not "+
+				"been modified by the %s.", eventSource),
+	)
+}
+
+func VerifyNotOptedInToInstrumentationEvent(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	eventSource string,
+) *corev1.Event {
+	return verifyEvent(
+		ctx,
+		clientset,
+		namespace,
+		resourceName,
+		util.ReasonNotOptedInToInstrumentation,
+		fmt.Sprintf(
+			"This workload has not opted in to Dash0 instrumentation via the label dash0.com/enable=true, which is "+
+				"required by the opt-in instrumentation policy of this namespace, it has not been modified by the %s.",
+			eventSource),
 	)
 }
 
+func VerifyOwnedByHigherOrderWorkloadEvent(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	ownerKind string,
+	ownerName string,
+	eventSource string,
+) *corev1.Event {
+	return verifyEvent(
+		ctx,
+		clientset,
+		namespace,
+		resourceName,
+		util.ReasonOwnedByHigherOrderWorkload,
+		fmt.Sprintf(
+			"This workload is managed by the %s %s, it has not been modified by the %s. Dash0 instrumentation is "+
+				"applied to the %s instead.", ownerKind, ownerName, eventSource, ownerKind),
+	)
+}
+
+// VerifySuccessfulInstrumentationEventWithContainersNotInstrumented verifies that the workload has exactly two events,
+// one for the successful instrumentation and a warning about the containers that could not be instrumented.
+func VerifySuccessfulInstrumentationEventWithContainersNotInstrumented(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	eventSource string,
+	containerNames string,
+) {
+	Eventually(func(g Gomega) {
+		allEvents, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		eventsForResource := slices.DeleteFunc(allEvents.Items, func(event corev1.Event) bool {
+			return event.InvolvedObject.Name != resourceName
+		})
+		g.Expect(eventsForResource).To(ConsistOf(
+			MatchEvent(
+				namespace,
+				resourceName,
+				util.ReasonSuccessfulInstrumentation,
+				fmt.Sprintf("Dash0 instrumentation of this workload by the %s has been successful.", eventSource),
+			),
+			MatchEvent(
+				namespace,
+				resourceName,
+				util.ReasonContainersNotInstrumented,
+				fmt.Sprintf("The %s could not instrument the container(s) %s of this workload, since they set the "+
+					"environment variable LD_PRELOAD via valueFrom, which Dash0 cannot extend. These containers will "+
+					"not send telemetry to Dash0.", eventSource, containerNames),
+			),
+		))
+	}, eventTimeout).Should(Succeed())
+}
+
 func VerifyFailedInstrumentationEvent(
 	ctx context.Context,
 	clientset *kubernetes.Clientset,
@@ -413,6 +574,23 @@ func VerifyFailedInstrumentationEvent(
 	)
 }
 
+func VerifyOwnerInstrumentedEvent(
+	ctx context.Context,
+	clientset *kubernetes.Clientset,
+	namespace string,
+	resourceName string,
+	message string,
+) *corev1.Event {
+	return verifyEvent(
+		ctx,
+		clientset,
+		namespace,
+		resourceName,
+		util.ReasonOwnerInstrumented,
+		message,
+	)
+}
+
 func VerifySuccessfulUninstrumentationEvent(
 	ctx context.Context,
 	clientset *kubernetes.Clientset,
@@ -516,10 +694,15 @@ func verifyEventEventually(
 
 	allEvents, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
 	g.Expect(err).NotTo(HaveOccurred())
-	g.Expect(allEvents.Items).To(HaveLen(1))
-	g.Expect(allEvents.Items).To(ContainElement(matcher))
-
-	for _, event := range allEvents.Items {
+	// Some operations record events for more than one workload (e.g. for a job and the cron job owning it), so we only
+	// require that there is exactly one event for the given resource.
+	eventsForResource := slices.DeleteFunc(allEvents.Items, func(event corev1.Event) bool {
+		return event.InvolvedObject.Name != resourceName
+	})
+	g.Expect(eventsForResource).To(HaveLen(1))
+	g.Expect(eventsForResource).To(ContainElement(matcher))
+
+	for _, event := range eventsForResource {
 		if success, _ := matcher.Match(event); success {
 			return &event
 		}